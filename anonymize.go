@@ -0,0 +1,190 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// anonymizeExtensionNames are the lowercase local names of extension elements
+// that identify devices or people.
+var anonymizeExtensionNames = map[string]bool{
+	"author":       true,
+	"creator":      true,
+	"device":       true,
+	"deviceid":     true,
+	"deviceinfo":   true,
+	"devicename":   true,
+	"productid":    true,
+	"serial":       true,
+	"serialnumber": true,
+	"unitid":       true,
+	"user":         true,
+	"userid":       true,
+	"username":     true,
+}
+
+// AnonymizeOptions control GPX.Anonymize. All distances are in meters.
+type AnonymizeOptions struct {
+	// Creator replaces the creator of the document.
+	Creator string
+	// KeepExtensions keeps extensions, except for top-level extension elements
+	// that identify devices or people, such as serial numbers. By default all
+	// extensions are removed.
+	KeepExtensions bool
+	// StripTimes removes all times.
+	StripTimes bool
+	// StartTime, if not zero, shifts all times so that the earliest time is
+	// StartTime, hiding when the document was recorded while preserving
+	// durations. It is ignored if StripTimes is set.
+	StartTime time.Time
+	// PrivacyRadius, if positive, removes all points within PrivacyRadius of
+	// the first and last points of each track and route, hiding where they
+	// start and end.
+	PrivacyRadius float64
+	// PrivacyRadiusJitter, if positive, adds a random distance of up to
+	// PrivacyRadiusJitter to PrivacyRadius for each start and end, so that
+	// the start and end cannot be found from the edges of the removed areas.
+	PrivacyRadiusJitter float64
+	// Rand is the source of randomness. If nil, a source seeded from the
+	// current time is used.
+	Rand *rand.Rand
+}
+
+// Anonymize removes personal data from g according to options so that g can
+// be shared publicly. It always replaces the creator, removes the metadata
+// author and copyright, and removes the sources of routes, tracks, and
+// points, which often name devices. Extensions, times, and the starts and
+// ends of tracks and routes are removed or changed according to options.
+// Metadata bounds are removed if any points are removed. Positions are not
+// otherwise changed, see Obfuscate.
+func (g *GPX) Anonymize(options AnonymizeOptions) {
+	g.Creator = options.Creator
+	if g.Metadata != nil {
+		g.Metadata.Author = nil
+		g.Metadata.Copyright = nil
+	}
+
+	extensions := func(e *ExtensionsType) *ExtensionsType {
+		if !options.KeepExtensions {
+			return nil
+		}
+		return e.replaceExtensions(func(name xml.Name) bool {
+			return anonymizeExtensionNames[strings.ToLower(name.Local)]
+		}, nil)
+	}
+	g.Extensions = extensions(g.Extensions)
+	if g.Metadata != nil {
+		g.Metadata.Extensions = extensions(g.Metadata.Extensions)
+	}
+	for _, rte := range g.Rte {
+		rte.Src = ""
+		rte.Extensions = extensions(rte.Extensions)
+	}
+	for _, trk := range g.Trk {
+		trk.Src = ""
+		trk.Extensions = extensions(trk.Extensions)
+		for _, ts := range trk.TrkSeg {
+			ts.Extensions = extensions(ts.Extensions)
+		}
+	}
+	for wpt := range g.Points() {
+		wpt.Src = ""
+		wpt.Extensions = extensions(wpt.Extensions)
+	}
+
+	switch {
+	case options.StripTimes:
+		if g.Metadata != nil {
+			g.Metadata.Time = time.Time{}
+		}
+		for wpt := range g.Points() {
+			wpt.Time = time.Time{}
+		}
+	case !options.StartTime.IsZero():
+		if start, ok := g.earliestTime(); ok {
+			g.ShiftTime(options.StartTime.Sub(start))
+		}
+	}
+
+	if options.PrivacyRadius > 0 {
+		g.removePrivacyZones(options)
+	}
+}
+
+// earliestTime returns the earliest time of the metadata and points in g.
+func (g *GPX) earliestTime() (time.Time, bool) {
+	var earliest time.Time
+	if g.Metadata != nil {
+		earliest = g.Metadata.Time
+	}
+	_ = g.Walk(func(_ PointKind, wpt *WptType) error {
+		if !wpt.Time.IsZero() && (earliest.IsZero() || wpt.Time.Before(earliest)) {
+			earliest = wpt.Time
+		}
+		return nil
+	})
+	return earliest, !earliest.IsZero()
+}
+
+// removePrivacyZones removes all points in g within options.PrivacyRadius,
+// plus jitter, of the starts and ends of the tracks and routes in g. Empty
+// track segments are removed.
+func (g *GPX) removePrivacyZones(options AnonymizeOptions) {
+	r := options.Rand
+	if r == nil && options.PrivacyRadiusJitter > 0 {
+		r = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	}
+	type zone struct {
+		lat, lon, radius float64
+	}
+	var zones []zone
+	addZones := func(wpts []*WptType) {
+		if len(wpts) == 0 {
+			return
+		}
+		for _, wpt := range []*WptType{wpts[0], wpts[len(wpts)-1]} {
+			radius := options.PrivacyRadius
+			if options.PrivacyRadiusJitter > 0 {
+				radius += options.PrivacyRadiusJitter * r.Float64()
+			}
+			zones = append(zones, zone{lat: wpt.Lat, lon: wpt.Lon, radius: radius})
+		}
+	}
+	for _, rte := range g.Rte {
+		addZones(rte.RtePt)
+	}
+	for _, trk := range g.Trk {
+		var trkPts []*WptType
+		for _, ts := range trk.TrkSeg {
+			trkPts = append(trkPts, ts.TrkPt...)
+		}
+		addZones(trkPts)
+	}
+	if len(zones) == 0 {
+		return
+	}
+
+	_ = g.TransformPoints(func(wpt *WptType) (*WptType, error) {
+		for _, z := range zones {
+			if HaversineDistance(wpt.Lat, wpt.Lon, z.lat, z.lon) <= z.radius {
+				return nil, nil //nolint:nilnil
+			}
+		}
+		return wpt, nil
+	})
+	for _, trk := range g.Trk {
+		trkSegs := trk.TrkSeg[:0]
+		for _, ts := range trk.TrkSeg {
+			if len(ts.TrkPt) > 0 {
+				trkSegs = append(trkSegs, ts)
+			}
+		}
+		clear(trk.TrkSeg[len(trkSegs):])
+		trk.TrkSeg = trkSegs
+	}
+	if g.Metadata != nil {
+		g.Metadata.Bounds = nil
+	}
+}
@@ -0,0 +1,108 @@
+package gpx_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestAnonymize(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	original := &gpx.GPX{
+		Version: "1.1",
+		Creator: "Garmin Edge 530 (3312345678)",
+		Metadata: &gpx.MetadataType{
+			Name:      "Morning ride",
+			Author:    &gpx.PersonType{Name: "Jane Doe"},
+			Copyright: &gpx.CopyrightType{Author: "Jane Doe"},
+			Time:      start.Add(-time.Minute),
+			Bounds:    &gpx.BoundsType{MinLat: 46, MinLon: 7, MaxLat: 46.01, MaxLon: 7.01},
+			Extensions: &gpx.ExtensionsType{
+				XML: []byte("<device><serial>3312345678</serial></device><activity>ride</activity>"),
+			},
+		},
+		Wpt: []*gpx.WptType{{Lat: 46.0001, Lon: 7, Name: "Home", Src: "Edge 530"}},
+		Trk: []*gpx.TrkType{
+			{
+				Src: "Edge 530",
+				TrkSeg: []*gpx.TrkSegType{
+					newTestTrkSeg(11, func(i int) *gpx.WptType {
+						return &gpx.WptType{
+							Lat:        46 + float64(i)*1e-3,
+							Lon:        7,
+							Time:       start.Add(time.Duration(i) * time.Minute),
+							Extensions: &gpx.ExtensionsType{XML: []byte("<hr>120</hr><serialNumber>3312345678</serialNumber>")},
+						}
+					}),
+				},
+			},
+		},
+	}
+
+	g := original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{})
+	assert.Empty(t, g.Creator)
+	assert.Nil(t, g.Metadata.Author)
+	assert.Nil(t, g.Metadata.Copyright)
+	assert.Nil(t, g.Metadata.Extensions)
+	assert.NotNil(t, g.Metadata.Bounds)
+	assert.Empty(t, g.Wpt[0].Src)
+	assert.Empty(t, g.Trk[0].Src)
+	assert.Len(t, g.Trk[0].TrkSeg[0].TrkPt, 11)
+	for _, trkPt := range g.Trk[0].TrkSeg[0].TrkPt {
+		assert.Nil(t, trkPt.Extensions)
+		assert.False(t, trkPt.Time.IsZero())
+	}
+
+	// Extensions are kept without identifying elements.
+	g = original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{
+		Creator:        "anonymous",
+		KeepExtensions: true,
+	})
+	assert.Equal(t, "anonymous", g.Creator)
+	assert.Equal(t, "<activity>ride</activity>", string(g.Metadata.Extensions.XML))
+	assert.Equal(t, "<hr>120</hr>", string(g.Trk[0].TrkSeg[0].TrkPt[0].Extensions.XML))
+
+	// Times are stripped or shifted.
+	g = original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{StripTimes: true})
+	assert.True(t, g.Metadata.Time.IsZero())
+	for _, trkPt := range g.Trk[0].TrkSeg[0].TrkPt {
+		assert.True(t, trkPt.Time.IsZero())
+	}
+
+	startTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	g = original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{StartTime: startTime})
+	assert.Equal(t, startTime, g.Metadata.Time)
+	assert.Equal(t, startTime.Add(time.Minute), g.Trk[0].TrkSeg[0].TrkPt[0].Time)
+	assert.Equal(t, startTime.Add(11*time.Minute), g.Trk[0].TrkSeg[0].TrkPt[10].Time)
+
+	// Points near the starts and ends of tracks are removed.
+	g = original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{PrivacyRadius: 250})
+	assert.Nil(t, g.Metadata.Bounds)
+	assert.Empty(t, g.Wpt)
+	trkPts := g.Trk[0].TrkSeg[0].TrkPt
+	assert.Len(t, trkPts, 5)
+	assert.InDelta(t, 46.003, trkPts[0].Lat, 1e-9)
+	assert.InDelta(t, 46.007, trkPts[4].Lat, 1e-9)
+
+	g = original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{
+		PrivacyRadius:       250,
+		PrivacyRadiusJitter: 100,
+		Rand:                rand.New(rand.NewSource(1)), //nolint:gosec
+	})
+	assert.GreaterOrEqual(t, len(g.Trk[0].TrkSeg[0].TrkPt), 3)
+	assert.LessOrEqual(t, len(g.Trk[0].TrkSeg[0].TrkPt), 5)
+
+	g = original.Clone()
+	g.Anonymize(gpx.AnonymizeOptions{PrivacyRadius: 1000})
+	assert.Empty(t, g.Trk[0].TrkSeg)
+}
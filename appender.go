@@ -0,0 +1,158 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"golang.org/x/net/html/charset"
+)
+
+// appenderTailSize is the number of bytes at the end of a file searched for
+// the closing tags.
+const appenderTailSize = 4096
+
+var appenderTailRx = regexp.MustCompile(`</trkseg>\s*</trk>\s*</gpx>\s*$`)
+
+// An Appender appends track points to an existing GPX file without
+// re-serializing the whole document. New data is spliced in before the
+// closing tags at the end of the file and flushed to stable storage, making
+// it suitable for periodic durable writes during long recordings.
+type Appender struct {
+	f      *os.File
+	offset int64
+	tail   []byte
+	// trackPointExtensionSpaces are the Garmin TrackPointExtension v2
+	// namespaces of the document, in which speeds and courses are written, or
+	// nil if they are written as elements, as they are in GPX 1.0 documents.
+	trackPointExtensionSpaces []string
+}
+
+// OpenAppender opens the GPX file called name for appending. The file must
+// end with the closing tags of a track segment, a track, and the document,
+// optionally separated by whitespace.
+func OpenAppender(name string) (*Appender, error) {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size := fi.Size()
+	start := size - appenderTailSize
+	if start < 0 {
+		start = 0
+	}
+	buf := make([]byte, size-start)
+	if _, err := f.ReadAt(buf, start); err != nil && !errors.Is(err, io.EOF) {
+		f.Close()
+		return nil, err
+	}
+	loc := appenderTailRx.FindIndex(buf)
+	if loc == nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: does not end with </trkseg></trk></gpx>", name)
+	}
+	attrs, err := readGPXAttrs(io.NewSectionReader(f, 0, size))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var trackPointExtensionSpaces []string
+	if attrs["version"] != "1.0" {
+		trackPointExtensionSpaces = trackPointExtensionSpacesFor(attrs)
+	}
+	return &Appender{
+		f:                         f,
+		offset:                    start + int64(loc[0]),
+		tail:                      buf[loc[0]:],
+		trackPointExtensionSpaces: trackPointExtensionSpaces,
+	}, nil
+}
+
+// AppendTrkPts appends trkPts to the last track segment.
+func (a *Appender) AppendTrkPts(trkPts ...*WptType) error {
+	buf := &bytes.Buffer{}
+	if err := encodeTrkPts(buf, trkPts, a.trackPointExtensionSpaces); err != nil {
+		return err
+	}
+	return a.write(buf.Bytes())
+}
+
+// AppendTrkSeg appends a new track segment containing trkPts to the last
+// track. Subsequent calls to AppendTrkPts append to the new segment.
+func (a *Appender) AppendTrkSeg(trkPts ...*WptType) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString("</trkseg>\n<trkseg>\n")
+	if err := encodeTrkPts(buf, trkPts, a.trackPointExtensionSpaces); err != nil {
+		return err
+	}
+	return a.write(buf.Bytes())
+}
+
+// Close closes the underlying file.
+func (a *Appender) Close() error {
+	return a.f.Close()
+}
+
+// write writes data followed by the closing tags and syncs the file.
+func (a *Appender) write(data []byte) error {
+	if _, err := a.f.WriteAt(append(data, a.tail...), a.offset); err != nil {
+		return err
+	}
+	a.offset += int64(len(data))
+	if err := a.f.Truncate(a.offset + int64(len(a.tail))); err != nil {
+		return err
+	}
+	return a.f.Sync()
+}
+
+// encodeTrkPts writes trkPts to w, one per line, writing speeds and courses
+// as Garmin TrackPointExtension v2 extensions with a namespace in
+// trackPointExtensionSpaces, if it is not nil.
+func encodeTrkPts(w *bytes.Buffer, trkPts []*WptType, trackPointExtensionSpaces []string) error {
+	for _, trkPt := range trkPts {
+		e := xml.NewEncoder(w)
+		if trackPointExtensionSpaces != nil {
+			speedCourseExtensionEncoders.Store(e, trackPointExtensionSpaces)
+		}
+		err := e.EncodeElement(trkPt, xml.StartElement{Name: xml.Name{Local: "trkpt"}})
+		speedCourseExtensionEncoders.Delete(e)
+		if err != nil {
+			return err
+		}
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// readGPXAttrs returns the attributes of the gpx element at the start of r,
+// with namespace declarations keyed by xmlns:prefix, as in GPX.XMLAttrs.
+func readGPXAttrs(r io.Reader) (map[string]string, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			attrs := make(map[string]string, len(start.Attr))
+			for _, attr := range start.Attr {
+				if attr.Name.Space == "xmlns" {
+					attrs["xmlns:"+attr.Name.Local] = attr.Value
+				} else {
+					attrs[attr.Name.Local] = attr.Value
+				}
+			}
+			return attrs, nil
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package gpx_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestAppender(t *testing.T) {
+	t0 := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Trk: []*gpx.TrkType{
+			{
+				Name: "recording",
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 1, Lon: 2, Time: t0},
+						},
+					},
+				},
+			},
+		},
+	}
+	name := filepath.Join(t.TempDir(), "recording.gpx")
+	f, err := os.Create(name)
+	assert.NoError(t, err)
+	assert.NoError(t, g.WriteIndent(f, "", "  "))
+	assert.NoError(t, f.Close())
+
+	a, err := gpx.OpenAppender(name)
+	assert.NoError(t, err)
+	assert.NoError(t, a.AppendTrkPts(&gpx.WptType{Lat: 3, Lon: 4, Time: t0.Add(time.Second)}))
+	assert.NoError(t, a.AppendTrkPts(&gpx.WptType{Lat: 5, Lon: 6}, &gpx.WptType{Lat: 7, Lon: 8}))
+	assert.NoError(t, a.AppendTrkSeg(&gpx.WptType{Lat: 9, Lon: 10}))
+	assert.NoError(t, a.AppendTrkPts(&gpx.WptType{Lat: 11, Lon: 12}))
+	assert.NoError(t, a.Close())
+
+	got, err := gpx.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.TrkSegType{
+		{
+			TrkPt: []*gpx.WptType{
+				{Lat: 1, Lon: 2, Time: t0},
+				{Lat: 3, Lon: 4, Time: t0.Add(time.Second)},
+				{Lat: 5, Lon: 6},
+				{Lat: 7, Lon: 8},
+			},
+		},
+		{
+			TrkPt: []*gpx.WptType{
+				{Lat: 9, Lon: 10},
+				{Lat: 11, Lon: 12},
+			},
+		},
+	}, got.Trk[0].TrkSeg)
+
+	g.Trk = nil
+	f, err = os.Create(name)
+	assert.NoError(t, err)
+	assert.NoError(t, g.Write(f))
+	assert.NoError(t, f.Close())
+	_, err = gpx.OpenAppender(name)
+	assert.Error(t, err)
+}
+
+func TestAppenderSpeedCourse(t *testing.T) {
+	for i, tc := range []struct {
+		version  string
+		expected string
+	}{
+		{
+			version:  "1.0",
+			expected: `<trkpt lat="3" lon="4"><speed>5</speed><course>90</course></trkpt>`,
+		},
+		{
+			version:  "1.1",
+			expected: `<trkpt lat="3" lon="4"><extensions><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:speed>5</gpxtpx:speed><gpxtpx:course>90</gpxtpx:course></gpxtpx:TrackPointExtension></extensions></trkpt>`,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := &gpx.GPX{
+				Version: tc.version,
+				Trk: []*gpx.TrkType{
+					{
+						TrkSeg: []*gpx.TrkSegType{
+							{
+								TrkPt: []*gpx.WptType{
+									{Lat: 1, Lon: 2},
+								},
+							},
+						},
+					},
+				},
+			}
+			name := filepath.Join(t.TempDir(), "recording.gpx")
+			f, err := os.Create(name)
+			assert.NoError(t, err)
+			assert.NoError(t, g.Write(f))
+			assert.NoError(t, f.Close())
+
+			a, err := gpx.OpenAppender(name)
+			assert.NoError(t, err)
+			assert.NoError(t, a.AppendTrkPts(&gpx.WptType{Lat: 3, Lon: 4, Speed: 5, Course: 90}))
+			assert.NoError(t, a.Close())
+
+			data, err := os.ReadFile(name)
+			assert.NoError(t, err)
+			assert.Contains(t, string(data), tc.expected)
+
+			got, err := gpx.ReadFile(name)
+			assert.NoError(t, err)
+			assert.Equal(t, 5.0, got.Trk[0].TrkSeg[0].TrkPt[1].Speed)
+			assert.Equal(t, 90.0, got.Trk[0].TrkSeg[0].TrkPt[1].Course)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package gpx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// appleHealthDateLayout is the layout of dates in Apple Health's export.xml.
+const appleHealthDateLayout = "2006-01-02 15:04:05 -0700"
+
+// appleHealthActivityTypePrefix is the prefix of Apple Health workout
+// activity types.
+const appleHealthActivityTypePrefix = "HKWorkoutActivityType"
+
+// An AppleHealthWorkout is a workout from an Apple Health export.
+type AppleHealthWorkout struct {
+	// ActivityType is the workout's activity type without its
+	// HKWorkoutActivityType prefix, for example "Running".
+	ActivityType string
+	SourceName   string
+	Start        time.Time
+	End          time.Time
+	Duration     time.Duration
+	// Distance is the total distance in meters, or zero if it is not recorded
+	// or its unit is not known.
+	Distance float64
+	// Energy is the total energy burned in kilocalories, or zero if it is not
+	// recorded or its unit is not known.
+	Energy float64
+	// GPX is the workout's route, with its metadata time and track types
+	// filled in from the workout, or nil if the workout has no route.
+	GPX *GPX
+}
+
+// appleHealthWorkout is the XML representation of a workout in Apple Health's
+// export.xml.
+type appleHealthWorkout struct {
+	ActivityType          string `xml:"workoutActivityType,attr"`
+	SourceName            string `xml:"sourceName,attr"`
+	StartDate             string `xml:"startDate,attr"`
+	EndDate               string `xml:"endDate,attr"`
+	Duration              string `xml:"duration,attr"`
+	DurationUnit          string `xml:"durationUnit,attr"`
+	TotalDistance         string `xml:"totalDistance,attr"`
+	TotalDistanceUnit     string `xml:"totalDistanceUnit,attr"`
+	TotalEnergyBurned     string `xml:"totalEnergyBurned,attr"`
+	TotalEnergyBurnedUnit string `xml:"totalEnergyBurnedUnit,attr"`
+	Routes                []struct {
+		FileReferences []struct {
+			Path string `xml:"path,attr"`
+		} `xml:"FileReference"`
+	} `xml:"WorkoutRoute"`
+}
+
+// appleHealthDistanceUnits are the lengths in meters of Apple Health distance
+// units.
+var appleHealthDistanceUnits = map[string]float64{
+	"m":  1,
+	"km": 1000,
+	"mi": 1609.344,
+}
+
+// appleHealthDurationUnits are the durations of Apple Health duration units.
+var appleHealthDurationUnits = map[string]time.Duration{
+	"s":   time.Second,
+	"min": time.Minute,
+	"hr":  time.Hour,
+}
+
+// appleHealthEnergyUnits are the energies in kilocalories of Apple Health
+// energy units.
+var appleHealthEnergyUnits = map[string]float64{
+	"kcal": 1,
+	"Cal":  1,
+	"kJ":   1 / 4.184,
+}
+
+// FromAppleHealthExport reads the workouts in the Apple Health export zip
+// archive called zipPath, in the order of its export.xml, and pairs them with
+// their routes from the archive's workout-routes directory.
+func FromAppleHealthExport(zipPath string) ([]*AppleHealthWorkout, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var exportName string
+	for _, zf := range zr.File {
+		if path.Base(zf.Name) == "export.xml" {
+			exportName = zf.Name
+			break
+		}
+	}
+	if exportName == "" {
+		return nil, fmt.Errorf("%s: no export.xml in archive", zipPath)
+	}
+	f, err := zr.Open(exportName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", zipPath, err)
+	}
+	defer f.Close()
+
+	var workouts []*AppleHealthWorkout
+	d := xml.NewDecoder(f)
+	for {
+		token, err := d.Token()
+		switch {
+		case errors.Is(err, io.EOF):
+			return workouts, nil
+		case err != nil:
+			return nil, fmt.Errorf("%s: %s: %w", zipPath, exportName, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Workout" {
+			continue
+		}
+		var w appleHealthWorkout
+		if err := d.DecodeElement(&w, &start); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", zipPath, exportName, err)
+		}
+		workout := w.workout()
+		for _, route := range w.Routes {
+			for _, fileReference := range route.FileReferences {
+				if workout.GPX != nil || !strings.EqualFold(path.Ext(fileReference.Path), ".gpx") {
+					continue
+				}
+				name := path.Join(path.Dir(exportName), strings.TrimPrefix(fileReference.Path, "/"))
+				g, err := readFS(zr, name)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", zipPath, err)
+				}
+				workout.mergeInto(g)
+				workout.GPX = g
+			}
+		}
+		workouts = append(workouts, workout)
+	}
+}
+
+// workout returns the AppleHealthWorkout represented by w.
+func (w *appleHealthWorkout) workout() *AppleHealthWorkout {
+	workout := &AppleHealthWorkout{
+		ActivityType: strings.TrimPrefix(w.ActivityType, appleHealthActivityTypePrefix),
+		SourceName:   w.SourceName,
+	}
+	if t, err := time.Parse(appleHealthDateLayout, w.StartDate); err == nil {
+		workout.Start = t
+	}
+	if t, err := time.Parse(appleHealthDateLayout, w.EndDate); err == nil {
+		workout.End = t
+	}
+	if duration, err := strconv.ParseFloat(w.Duration, 64); err == nil {
+		workout.Duration = time.Duration(duration * float64(appleHealthDurationUnits[w.DurationUnit]))
+	}
+	if distance, err := strconv.ParseFloat(w.TotalDistance, 64); err == nil {
+		workout.Distance = distance * appleHealthDistanceUnits[w.TotalDistanceUnit]
+	}
+	if energy, err := strconv.ParseFloat(w.TotalEnergyBurned, 64); err == nil {
+		workout.Energy = energy * appleHealthEnergyUnits[w.TotalEnergyBurnedUnit]
+	}
+	return workout
+}
+
+// mergeInto sets g's metadata time and track types from w, where they are not
+// already set.
+func (w *AppleHealthWorkout) mergeInto(g *GPX) {
+	if metadata := g.metadata(); metadata.Time.IsZero() {
+		metadata.Time = w.Start
+	}
+	for _, trk := range g.Trk {
+		if trk.Type == "" {
+			trk.Type = w.ActivityType
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gpx_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFromAppleHealthExport(t *testing.T) {
+	route := &gpx.GPX{
+		Version: "1.1",
+		Creator: "Apple Health Export",
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 51.5, Lon: -0.1, Time: time.Date(2024, 1, 2, 7, 15, 0, 0, time.UTC)},
+							{Lat: 51.6, Lon: -0.1, Time: time.Date(2024, 1, 2, 7, 45, 0, 0, time.UTC)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	zipName := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(zipName)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("apple_health_export/export.xml")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<HealthData locale="en_GB">
+ <Record type="HKQuantityTypeIdentifierStepCount" value="100"/>
+ <Workout workoutActivityType="HKWorkoutActivityTypeRunning" duration="30" durationUnit="min" totalDistance="5.5" totalDistanceUnit="km" totalEnergyBurned="1255.2" totalEnergyBurnedUnit="kJ" sourceName="Apple Watch" startDate="2024-01-02 07:15:00 +0000" endDate="2024-01-02 07:45:00 +0000">
+  <MetadataEntry key="HKIndoorWorkout" value="0"/>
+  <WorkoutRoute sourceName="Apple Watch">
+   <FileReference path="/workout-routes/route_2024-01-02_7.15am.gpx"/>
+  </WorkoutRoute>
+ </Workout>
+ <Workout workoutActivityType="HKWorkoutActivityTypeYoga" duration="3600" durationUnit="s" sourceName="iPhone" startDate="2024-01-03 18:00:00 +0100" endDate="2024-01-03 19:00:00 +0100"/>
+</HealthData>
+`))
+	assert.NoError(t, err)
+	w, err = zw.Create("apple_health_export/workout-routes/route_2024-01-02_7.15am.gpx")
+	assert.NoError(t, err)
+	assert.NoError(t, route.Write(w))
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+
+	got, err := gpx.FromAppleHealthExport(zipName)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	run := got[0]
+	assert.Equal(t, "Running", run.ActivityType)
+	assert.Equal(t, "Apple Watch", run.SourceName)
+	assert.True(t, time.Date(2024, 1, 2, 7, 15, 0, 0, time.UTC).Equal(run.Start))
+	assert.True(t, time.Date(2024, 1, 2, 7, 45, 0, 0, time.UTC).Equal(run.End))
+	assert.Equal(t, 30*time.Minute, run.Duration)
+	assert.InDelta(t, 5500, run.Distance, 1e-9)
+	assert.InDelta(t, 300, run.Energy, 1e-9)
+	assert.NotNil(t, run.GPX)
+	assert.Equal(t, "Running", run.GPX.Trk[0].Type)
+	assert.True(t, run.Start.Equal(run.GPX.Metadata.Time))
+	assert.Len(t, run.GPX.Trk[0].TrkSeg[0].TrkPt, 2)
+
+	yoga := got[1]
+	assert.Equal(t, "Yoga", yoga.ActivityType)
+	assert.Equal(t, time.Hour, yoga.Duration)
+	assert.Equal(t, 0.0, yoga.Distance)
+	assert.Nil(t, yoga.GPX)
+
+	_, err = gpx.FromAppleHealthExport(filepath.Join(t.TempDir(), "missing.zip"))
+	assert.Error(t, err)
+}
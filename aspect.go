@@ -0,0 +1,57 @@
+package gpx
+
+import "math"
+
+// AspectDirections are the names of the compass directions of an
+// AspectHistogram.
+var AspectDirections = [8]string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// An AspectHistogram is the horizontal distance in meters travelled downhill
+// in each of eight compass directions, starting at north and proceeding
+// clockwise as in AspectDirections. Each direction covers a 45° sector.
+type AspectHistogram [8]float64
+
+// NewAspectHistogram returns the aspect histogram of ts, counting only steps
+// between consecutive points whose downhill gradient is at least minGradient,
+// e.g. 0.1 for 10%. Steps to or from points without elevations are ignored.
+func NewAspectHistogram(ts *TrkSegType, minGradient float64) AspectHistogram {
+	var h AspectHistogram
+	for i := 1; i < len(ts.TrkPt); i++ {
+		p0, p1 := ts.TrkPt[i-1], ts.TrkPt[i]
+		if !p0.Has(WptEle) || !p1.Has(WptEle) {
+			continue
+		}
+		distance := HaversineDistance(p0.Lat, p0.Lon, p1.Lat, p1.Lon)
+		if distance == 0 {
+			continue
+		}
+		if gradient := (p0.Ele - p1.Ele) / distance; gradient <= 0 || gradient < minGradient {
+			continue
+		}
+		sector := int(math.Mod(bearing(p0.Lat, p0.Lon, p1.Lat, p1.Lon)+22.5, 360) / 45)
+		h[sector] += distance
+	}
+	return h
+}
+
+// Total returns the total distance in h.
+func (h AspectHistogram) Total() float64 {
+	total := 0.0
+	for _, distance := range h {
+		total += distance
+	}
+	return total
+}
+
+// Fractions returns the fraction of the total distance in each direction.
+func (h AspectHistogram) Fractions() [8]float64 {
+	var fractions [8]float64
+	total := h.Total()
+	if total == 0 {
+		return fractions
+	}
+	for i, distance := range h {
+		fractions[i] = distance / total
+	}
+	return fractions
+}
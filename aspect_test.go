@@ -0,0 +1,35 @@
+package gpx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestAspectHistogram(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 46, Lon: 7, Ele: 2000},
+			{Lat: 46.001, Lon: 7, Ele: 1950},     // north, downhill
+			{Lat: 46.001, Lon: 7.001, Ele: 1990}, // east, uphill
+			{Lat: 46, Lon: 7.001, Ele: 1940},     // south, downhill
+			{Lat: 45.999, Lon: 7.001, Ele: 1939}, // south, nearly flat
+			{Lat: 45.999, Lon: 7},                // west, no elevation
+			{Lat: 45.999, Lon: 6.999, Ele: 1800}, // west, from no elevation
+		},
+	}
+
+	h := gpx.NewAspectHistogram(ts, 0.1)
+	assert.InDelta(t, 111.2, h[0], 0.1)
+	assert.Equal(t, 0.0, h[2])
+	assert.Equal(t, 0.0, h[6])
+	assert.InDelta(t, 111.2, h[4], 0.1)
+	assert.InDelta(t, 222.4, h.Total(), 0.1)
+	assert.Equal(t, [8]float64{0.5, 0, 0, 0, 0.5, 0, 0, 0}, h.Fractions())
+
+	h = gpx.NewAspectHistogram(ts, 0)
+	assert.InDelta(t, 222.4, h[4], 0.1)
+	assert.Equal(t, "S", gpx.AspectDirections[4])
+}
@@ -0,0 +1,59 @@
+package gpx
+
+import "math"
+
+// InitialBearing returns the initial bearing in degrees clockwise from true
+// north of the great-circle path from a to b, in the range [0, 360).
+func InitialBearing(a, b *WptType) float64 {
+	return bearing(a.Lat, a.Lon, b.Lat, b.Lon)
+}
+
+// FinalBearing returns the final bearing in degrees clockwise from true north
+// of the great-circle path from a to b, on arrival at b, in the range [0,
+// 360). It differs from the initial bearing on long paths that are not along
+// a meridian or the equator.
+func FinalBearing(a, b *WptType) float64 {
+	return math.Mod(bearing(b.Lat, b.Lon, a.Lat, a.Lon)+180, 360)
+}
+
+// Courses returns the course over ground in degrees clockwise from true north
+// at each of ts's points, measured between the points up to window points
+// before and after it. Values of window less than one are treated as one. The
+// course at points at which it cannot be determined, because they are
+// stationary, is NaN.
+func (ts *TrkSegType) Courses(window int) []float64 {
+	n := len(ts.TrkPt)
+	if n == 0 {
+		return nil
+	}
+	window = max(window, 1)
+	courses := make([]float64, n)
+	for i := range courses {
+		a, b := ts.TrkPt[max(i-window, 0)], ts.TrkPt[min(i+window, n-1)]
+		if a.Lat == b.Lat && a.Lon == b.Lon {
+			courses[i] = math.NaN()
+			continue
+		}
+		courses[i] = InitialBearing(a, b)
+	}
+	return courses
+}
+
+// FillCourses sets the courses of ts's points without courses to
+// Courses(window), and returns the number of points whose courses were set.
+// Courses are written in the Garmin TrackPointExtension in GPX 1.1 documents.
+func (ts *TrkSegType) FillCourses(window int) int {
+	filled := 0
+	for i, course := range ts.Courses(window) {
+		trkPt := ts.TrkPt[i]
+		if math.IsNaN(course) || trkPt.Has(WptCourse) {
+			continue
+		}
+		trkPt.Course = course
+		if course == 0 {
+			trkPt.ZeroFields |= WptCourse
+		}
+		filled++
+	}
+	return filled
+}
@@ -0,0 +1,81 @@
+package gpx_test
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestBearings(t *testing.T) {
+	for i, tc := range []struct {
+		a, b            *gpx.WptType
+		expectedInitial float64
+		expectedFinal   float64
+	}{
+		{
+			a:               &gpx.WptType{Lat: 0, Lon: 0},
+			b:               &gpx.WptType{Lat: 1, Lon: 0},
+			expectedInitial: 0,
+			expectedFinal:   0,
+		},
+		{
+			a:               &gpx.WptType{Lat: 0, Lon: 0},
+			b:               &gpx.WptType{Lat: 0, Lon: -1},
+			expectedInitial: 270,
+			expectedFinal:   270,
+		},
+		{
+			// London to New York.
+			a:               &gpx.WptType{Lat: 51.5074, Lon: -0.1278},
+			b:               &gpx.WptType{Lat: 40.7128, Lon: -74.006},
+			expectedInitial: 288.3,
+			expectedFinal:   231.2,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.InDelta(t, tc.expectedInitial, gpx.InitialBearing(tc.a, tc.b), 0.1)
+			assert.InDelta(t, tc.expectedFinal, gpx.FinalBearing(tc.a, tc.b), 0.1)
+		})
+	}
+}
+
+func TestCourses(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0.001, Lon: 0},
+			{Lat: 0.001, Lon: 0.001},
+			{Lat: 0.001, Lon: 0.001},
+			{Lat: 0.001, Lon: 0.001},
+			{Lat: 0, Lon: 0.001, Course: 123},
+		},
+	}
+	courses := ts.Courses(1)
+	assert.InDelta(t, 0, courses[0], 1e-6)
+	assert.InDelta(t, 45, courses[1], 1e-3)
+	assert.InDelta(t, 90, courses[2], 1e-6)
+	assert.True(t, math.IsNaN(courses[3]))
+	assert.InDelta(t, 180, courses[4], 1e-6)
+	assert.InDelta(t, 180, courses[5], 1e-6)
+	assert.InDelta(t, 90, ts.Courses(0)[2], 1e-6)
+	assert.Nil(t, (&gpx.TrkSegType{}).Courses(1))
+
+	assert.Equal(t, 4, ts.FillCourses(1))
+	assert.True(t, ts.TrkPt[0].Has(gpx.WptCourse))
+	assert.False(t, ts.TrkPt[3].Has(gpx.WptCourse))
+	assert.InDelta(t, 123, ts.TrkPt[5].Course, 0)
+
+	g := &gpx.GPX{
+		Version: "1.1",
+		Trk:     []*gpx.TrkType{{TrkSeg: []*gpx.TrkSegType{ts}}},
+	}
+	b := &bytes.Buffer{}
+	assert.NoError(t, g.Write(b))
+	assert.Contains(t, b.String(), "<gpxtpx:course>0</gpxtpx:course>")
+	assert.Contains(t, b.String(), "<gpxtpx:course>180</gpxtpx:course>")
+}
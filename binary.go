@@ -0,0 +1,52 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// binaryVersion is the version of the binary encoding written by
+// MarshalBinary.
+const binaryVersion = 1
+
+// ErrBinaryVersion is returned when binary encoded data has an unsupported
+// version.
+var ErrBinaryVersion = errors.New("unsupported binary version")
+
+// gpxBinary is GPX without its methods, so that gob does not call
+// MarshalBinary recursively.
+type gpxBinary GPX
+
+// MarshalBinary implements encoding.BinaryMarshaler.MarshalBinary. The binary
+// encoding is a version byte followed by a gob encoding of g, which is much
+// faster to decode than XML and is suitable for caching parsed documents. It
+// is only intended to be read by UnmarshalBinary from the same or later
+// versions of this package. Points must not be nil.
+func (g *GPX) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte(binaryVersion)
+	if err := gob.NewEncoder(&b).Encode((*gpxBinary)(g)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.UnmarshalBinary. It
+// returns an error wrapping ErrBinaryVersion if data was not written by a
+// supported version of MarshalBinary.
+func (g *GPX) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: no data", ErrBinaryVersion)
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("%w: %d", ErrBinaryVersion, data[0])
+	}
+	var gb gpxBinary
+	if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&gb); err != nil {
+		return err
+	}
+	*g = GPX(gb)
+	return nil
+}
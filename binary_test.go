@@ -0,0 +1,59 @@
+package gpx_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestBinary(t *testing.T) {
+	xmlData, err := os.ReadFile("testdata/ashland.gpx")
+	assert.NoError(t, err)
+	g, err := gpx.Read(bytes.NewReader(xmlData))
+	assert.NoError(t, err)
+	g.Wpt[0].ZeroFields = gpx.WptEle
+
+	data, err := g.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Less(t, len(data), len(xmlData))
+	var got gpx.GPX
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, g, &got)
+
+	// GPX can be embedded in other gob encoded values.
+	type cached struct {
+		Key string
+		GPX *gpx.GPX
+	}
+	var b bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&b).Encode(cached{Key: "key", GPX: g}))
+	var gotCached cached
+	assert.NoError(t, gob.NewDecoder(&b).Decode(&gotCached))
+	assert.Equal(t, g, gotCached.GPX)
+
+	data[0] = 0
+	assert.ErrorIs(t, got.UnmarshalBinary(data), gpx.ErrBinaryVersion)
+	assert.ErrorIs(t, got.UnmarshalBinary(nil), gpx.ErrBinaryVersion)
+}
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	xmlData, err := os.ReadFile("testdata/ashland.gpx")
+	assert.NoError(b, err)
+	g, err := gpx.Read(bytes.NewReader(xmlData))
+	assert.NoError(b, err)
+	data, err := g.MarshalBinary()
+	assert.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		var got gpx.GPX
+		if err := got.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package gpx
+
+import "time"
+
+// An Option sets an option on a GPX created by New.
+type Option func(*GPX)
+
+// A Builder builds a GPX.
+type Builder struct {
+	gpx *GPX
+}
+
+// New returns a new Builder for a GPX 1.1 document with the given options.
+func New(options ...Option) *Builder {
+	g := &GPX{
+		Version: "1.1",
+	}
+	for _, option := range options {
+		option(g)
+	}
+	return &Builder{
+		gpx: g,
+	}
+}
+
+// WithCreator sets the creator.
+func WithCreator(creator string) Option {
+	return func(g *GPX) {
+		g.Creator = creator
+	}
+}
+
+// WithVersion sets the version.
+func WithVersion(version string) Option {
+	return func(g *GPX) {
+		g.Version = version
+	}
+}
+
+// WithMetadata sets the metadata.
+func WithMetadata(metadata *MetadataType) Option {
+	return func(g *GPX) {
+		g.Metadata = metadata
+	}
+}
+
+// WithMetadataName sets the metadata name.
+func WithMetadataName(name string) Option {
+	return func(g *GPX) {
+		g.metadata().Name = name
+	}
+}
+
+// WithMetadataDesc sets the metadata description.
+func WithMetadataDesc(desc string) Option {
+	return func(g *GPX) {
+		g.metadata().Desc = desc
+	}
+}
+
+// WithMetadataTime sets the metadata time.
+func WithMetadataTime(t time.Time) Option {
+	return func(g *GPX) {
+		g.metadata().Time = t
+	}
+}
+
+// AddWaypoint adds wpts as waypoints and returns b.
+func (b *Builder) AddWaypoint(wpts ...*WptType) *Builder {
+	b.gpx.Wpt = append(b.gpx.Wpt, wpts...)
+	return b
+}
+
+// AddRoute adds a route with the given name and route points and returns b.
+func (b *Builder) AddRoute(name string, rtePts ...*WptType) *Builder {
+	b.gpx.Rte = append(b.gpx.Rte, &RteType{
+		Name:  name,
+		RtePt: rtePts,
+	})
+	return b
+}
+
+// AddTrack adds a track with the given name and a single segment containing
+// trkPts and returns b.
+func (b *Builder) AddTrack(name string, trkPts ...*WptType) *Builder {
+	b.gpx.Trk = append(b.gpx.Trk, &TrkType{
+		Name: name,
+		TrkSeg: []*TrkSegType{
+			{
+				TrkPt: trkPts,
+			},
+		},
+	})
+	return b
+}
+
+// AddTrackSegment adds a segment containing trkPts to the last track, adding
+// an unnamed track if there are none, and returns b.
+func (b *Builder) AddTrackSegment(trkPts ...*WptType) *Builder {
+	if len(b.gpx.Trk) == 0 {
+		return b.AddTrack("", trkPts...)
+	}
+	trk := b.gpx.Trk[len(b.gpx.Trk)-1]
+	trk.TrkSeg = append(trk.TrkSeg, &TrkSegType{
+		TrkPt: trkPts,
+	})
+	return b
+}
+
+// GPX returns the built GPX.
+func (b *Builder) GPX() *GPX {
+	return b.gpx
+}
+
+// metadata returns g's metadata, creating it if needed.
+func (g *GPX) metadata() *MetadataType {
+	if g.Metadata == nil {
+		g.Metadata = &MetadataType{}
+	}
+	return g.Metadata
+}
@@ -0,0 +1,70 @@
+package gpx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestBuilder(t *testing.T) {
+	g := gpx.New(
+		gpx.WithCreator("myapp"),
+		gpx.WithMetadataName("Morning run"),
+		gpx.WithMetadataTime(time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)),
+	).
+		AddWaypoint(&gpx.WptType{Lat: 1, Lon: 2, Name: "Start"}).
+		AddRoute("Planned", &gpx.WptType{Lat: 1, Lon: 2}, &gpx.WptType{Lat: 3, Lon: 4}).
+		AddTrack("Run", &gpx.WptType{Lat: 1, Lon: 2}).
+		AddTrackSegment(&gpx.WptType{Lat: 3, Lon: 4}).
+		GPX()
+	assert.Equal(t, &gpx.GPX{
+		Version: "1.1",
+		Creator: "myapp",
+		Metadata: &gpx.MetadataType{
+			Name: "Morning run",
+			Time: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+		},
+		Wpt: []*gpx.WptType{
+			{Lat: 1, Lon: 2, Name: "Start"},
+		},
+		Rte: []*gpx.RteType{
+			{
+				Name: "Planned",
+				RtePt: []*gpx.WptType{
+					{Lat: 1, Lon: 2},
+					{Lat: 3, Lon: 4},
+				},
+			},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				Name: "Run",
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{{Lat: 1, Lon: 2}}},
+					{TrkPt: []*gpx.WptType{{Lat: 3, Lon: 4}}},
+				},
+			},
+		},
+	}, g)
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, g.Write(b))
+	got, err := gpx.Read(b)
+	assert.NoError(t, err)
+	assert.Equal(t, g.Trk, got.Trk)
+}
+
+func TestBuilderAddTrackSegment(t *testing.T) {
+	g := gpx.New().AddTrackSegment(&gpx.WptType{Lat: 1, Lon: 2}).GPX()
+	assert.Equal(t, []*gpx.TrkType{
+		{
+			TrkSeg: []*gpx.TrkSegType{
+				{TrkPt: []*gpx.WptType{{Lat: 1, Lon: 2}}},
+			},
+		},
+	}, g.Trk)
+}
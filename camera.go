@@ -0,0 +1,42 @@
+package gpx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// exifTimeLayout is the layout of EXIF DateTime, DateTimeOriginal, and
+// DateTimeDigitized tags.
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// ParseEXIFTime parses an EXIF date and time, such as the value of a
+// DateTimeOriginal tag, optionally followed by a fractional second. EXIF times
+// are the wall clock time of the camera and do not include a time zone, so
+// the time is in loc, unless offset, the value of the corresponding
+// OffsetTimeOriginal tag such as "+02:00", is not empty.
+func ParseEXIFTime(value, offset string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	offset = strings.TrimSpace(strings.TrimRight(offset, "\x00"))
+	if offset != "" {
+		zone, err := time.Parse("Z07:00", offset)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: invalid EXIF time offset", offset)
+		}
+		loc = zone.Location()
+	}
+	t, err := time.ParseInLocation(exifTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: invalid EXIF time", value)
+	}
+	return t, nil
+}
+
+// CameraClockOffset returns how far a camera's clock is ahead of the clock
+// that recorded a track, given the EXIF time cameraTime of a photo of a
+// reference clock, such as the display of the GPS receiver, showing
+// referenceTime. Passing the offset to GPX.ShiftTime aligns the times of the
+// track with the EXIF times of the camera's photos.
+func CameraClockOffset(cameraTime, referenceTime time.Time) time.Duration {
+	return cameraTime.Sub(referenceTime)
+}
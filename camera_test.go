@@ -0,0 +1,91 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestParseEXIFTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	for i, tc := range []struct {
+		value       string
+		offset      string
+		loc         *time.Location
+		expected    time.Time
+		expectedErr bool
+	}{
+		{
+			value:    "2024:05:01 10:00:00",
+			loc:      time.UTC,
+			expected: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			value:    "2024:05:01 10:00:00\x00",
+			loc:      berlin,
+			expected: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			value:    "2024:05:01 10:00:00.25",
+			offset:   "-05:00",
+			loc:      berlin,
+			expected: time.Date(2024, 5, 1, 15, 0, 0, 250000000, time.UTC),
+		},
+		{
+			value:    "2024:05:01 10:00:00",
+			offset:   "Z",
+			loc:      berlin,
+			expected: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			value:       "2024-05-01T10:00:00Z",
+			loc:         time.UTC,
+			expectedErr: true,
+		},
+		{
+			value:       "2024:05:01 10:00:00",
+			offset:      "CEST",
+			loc:         time.UTC,
+			expectedErr: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			actual, err := gpx.ParseEXIFTime(tc.value, tc.offset, tc.loc)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tc.expected.Equal(actual), "expected %s, got %s", tc.expected, actual)
+		})
+	}
+}
+
+func TestCameraClockOffset(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Metadata: &gpx.MetadataType{Time: start},
+		Wpt:      []*gpx.WptType{{Lat: 1, Lon: 2}},
+		Trk: []*gpx.TrkType{{TrkSeg: []*gpx.TrkSegType{{TrkPt: []*gpx.WptType{
+			{Lat: 1, Lon: 2, Time: start},
+			{Lat: 1, Lon: 3, Time: start.Add(time.Minute)},
+		}}}}},
+	}
+
+	// The camera's clock is 95 seconds slow.
+	cameraTime, err := gpx.ParseEXIFTime("2024:05:01 10:28:25", "", time.UTC)
+	assert.NoError(t, err)
+	offset := gpx.CameraClockOffset(cameraTime, start.Add(30*time.Minute))
+	assert.Equal(t, -95*time.Second, offset)
+
+	g.ShiftTime(offset)
+	assert.Equal(t, start.Add(-95*time.Second), g.Metadata.Time)
+	assert.True(t, g.Wpt[0].Time.IsZero())
+	assert.Equal(t, start.Add(-95*time.Second), g.Trk[0].TrkSeg[0].TrkPt[0].Time)
+	assert.Equal(t, start.Add(-35*time.Second), g.Trk[0].TrkSeg[0].TrkPt[1].Time)
+}
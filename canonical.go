@@ -0,0 +1,184 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// canonicalIndent is the indentation used by CanonicalBytes.
+const canonicalIndent = "  "
+
+// CanonicalBytes returns g encoded in the package's canonical format: an XML
+// declaration, a newline, the gpx element indented by two spaces per level
+// with no prefix, and a final newline. Extensions are written verbatim.
+//
+// The canonical format is stable: for the same GPX value, CanonicalBytes
+// returns the same bytes in all versions of this package, so its output can
+// be diffed and hashed deterministically, for example in tests and storage.
+// Any change to the format will be treated as a breaking change.
+func CanonicalBytes(g *GPX) ([]byte, error) {
+	var b bytes.Buffer
+	if err := g.WriteWithOptions(&b, &WriteOptions{Indent: canonicalIndent}); err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// WriteCanonical canonicalizes g, see Canonicalize, and writes it to w in the
+// canonical format of CanonicalBytes. g is modified.
+func (g *GPX) WriteCanonical(w io.Writer) error {
+	if err := g.Canonicalize(); err != nil {
+		return err
+	}
+	data, err := CanonicalBytes(g)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Canonicalize normalizes g in place so that documents that differ only in
+// insignificant whitespace or in the formatting of their extensions have the
+// same canonical bytes. Line endings in text are normalized to newlines and
+// leading and trailing whitespace is removed. Extensions are rewritten with
+// their attributes sorted, whitespace-only text removed, text trimmed, and
+// comments, processing instructions, and directives removed. Extensions that
+// become empty are removed. It returns an error if any extensions are not
+// well-formed XML.
+func (g *GPX) Canonicalize() error {
+	g.Creator = canonicalText(g.Creator)
+	var err error
+	if g.Extensions, err = canonicalExtensions(g.Extensions); err != nil {
+		return err
+	}
+	if m := g.Metadata; m != nil {
+		canonicalTexts(&m.Name, &m.Desc, &m.Keywords)
+		if m.Author != nil {
+			canonicalTexts(&m.Author.Name)
+			canonicalLinks(m.Author.Link)
+		}
+		if m.Copyright != nil {
+			canonicalTexts(&m.Copyright.Author, &m.Copyright.License)
+		}
+		canonicalLinks(m.Link...)
+		if m.Extensions, err = canonicalExtensions(m.Extensions); err != nil {
+			return err
+		}
+	}
+	for _, rte := range g.Rte {
+		canonicalTexts(&rte.Name, &rte.Cmt, &rte.Desc, &rte.Src, &rte.Type)
+		canonicalLinks(rte.Link...)
+		if rte.Extensions, err = canonicalExtensions(rte.Extensions); err != nil {
+			return err
+		}
+	}
+	for _, trk := range g.Trk {
+		canonicalTexts(&trk.Name, &trk.Cmt, &trk.Desc, &trk.Src, &trk.Type)
+		canonicalLinks(trk.Link...)
+		if trk.Extensions, err = canonicalExtensions(trk.Extensions); err != nil {
+			return err
+		}
+		for _, ts := range trk.TrkSeg {
+			if ts.Extensions, err = canonicalExtensions(ts.Extensions); err != nil {
+				return err
+			}
+		}
+	}
+	for wpt := range g.Points() {
+		canonicalTexts(&wpt.Name, &wpt.Cmt, &wpt.Desc, &wpt.Src, &wpt.Sym, &wpt.Type)
+		canonicalLinks(wpt.Link...)
+		if wpt.Extensions, err = canonicalExtensions(wpt.Extensions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canonicalText returns s with line endings normalized and leading and
+// trailing whitespace removed.
+func canonicalText(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// canonicalTexts replaces each of ss with its canonical text.
+func canonicalTexts(ss ...*string) {
+	for _, s := range ss {
+		*s = canonicalText(*s)
+	}
+}
+
+// canonicalLinks replaces the texts of links with their canonical texts.
+func canonicalLinks(links ...*LinkType) {
+	for _, link := range links {
+		if link != nil {
+			canonicalTexts(&link.HREF, &link.Text, &link.Type)
+		}
+	}
+}
+
+// canonicalExtensions returns e rewritten canonically, or nil if the result
+// is empty.
+func canonicalExtensions(e *ExtensionsType) (*ExtensionsType, error) {
+	if e == nil {
+		return nil, nil //nolint:nilnil
+	}
+	var b bytes.Buffer
+	var open []xml.Name
+	d := xml.NewDecoder(bytes.NewReader(e.XML))
+	for {
+		token, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			if len(open) != 0 {
+				return nil, fmt.Errorf("extensions: unclosed element %s", qualifiedName(open[len(open)-1]))
+			}
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("extensions: %w", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			open = append(open, token.Name)
+			b.WriteString("<" + qualifiedName(token.Name))
+			attrs := slices.Clone(token.Attr)
+			slices.SortFunc(attrs, func(a, b xml.Attr) int {
+				return strings.Compare(qualifiedName(a.Name), qualifiedName(b.Name))
+			})
+			for _, attr := range attrs {
+				b.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				_ = xml.EscapeText(&b, []byte(attr.Value))
+				b.WriteByte('"')
+			}
+			b.WriteByte('>')
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != token.Name {
+				return nil, fmt.Errorf("extensions: unexpected end element %s", qualifiedName(token.Name))
+			}
+			open = open[:len(open)-1]
+			b.WriteString("</" + qualifiedName(token.Name) + ">")
+		case xml.CharData:
+			_ = xml.EscapeText(&b, bytes.TrimSpace(bytes.ReplaceAll(token, []byte("\r\n"), []byte("\n"))))
+		}
+	}
+	if b.Len() == 0 {
+		return nil, nil //nolint:nilnil
+	}
+	return &ExtensionsType{
+		XML: b.Bytes(),
+	}, nil
+}
+
+// qualifiedName returns name as written in a document, with its prefix if it
+// has one.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,107 @@
+package gpx_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestCanonicalBytes(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Metadata: &gpx.MetadataType{
+			Name: "Example",
+			Time: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		},
+		Wpt: []*gpx.WptType{
+			{Lat: 1.5, Lon: -2.25, Ele: 3, Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Name: "A & B"},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				Name: "Track",
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 1, Lon: 2, Extensions: &gpx.ExtensionsType{XML: []byte("<hr>120</hr>")}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// This output must never change.
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns="http://www.topografix.com/GPX/1/1" xsi:schemaLocation="http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd">
+  <metadata>
+    <name>Example</name>
+    <time>2024-05-01T09:00:00Z</time>
+  </metadata>
+  <wpt lat="1.5" lon="-2.25">
+    <ele>3</ele>
+    <time>2024-05-01T10:00:00Z</time>
+    <name>A &amp; B</name>
+  </wpt>
+  <trk>
+    <name>Track</name>
+    <trkseg>
+      <trkpt lat="1" lon="2">
+        <extensions><hr>120</hr></extensions>
+      </trkpt>
+    </trkseg>
+  </trk>
+</gpx>
+`
+	got, err := gpx.CanonicalBytes(g)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(got))
+
+	g2, err := gpx.Read(bytes.NewReader(got))
+	assert.NoError(t, err)
+	got2, err := gpx.CanonicalBytes(g2)
+	assert.NoError(t, err)
+	assert.Equal(t, got, got2)
+}
+
+func TestWriteCanonical(t *testing.T) {
+	for i, data := range []string{
+		"<gpx version=\"1.1\" creator=\"test\">\r\n" +
+			"<wpt lat=\"1\" lon=\"2\"><name>  A\r\nB </name><link href=\" https://example.com/ \"/>\r\n" +
+			"<extensions>\r\n  <x:a b=\"2\" a=\"1\"> 3 </x:a>\r\n  <!-- comment -->\r\n</extensions></wpt>\r\n" +
+			"<trk><trkseg><extensions>\n</extensions></trkseg></trk>\r\n" +
+			"</gpx>",
+		`<gpx version="1.1" creator="test"><wpt lat="1" lon="2"><name>A
+B</name><link href="https://example.com/"></link><extensions><x:a a="1" b="2">3</x:a></extensions></wpt><trk><trkseg></trkseg></trk></gpx>`,
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := gpx.Read(strings.NewReader(data))
+			assert.NoError(t, err)
+			var b bytes.Buffer
+			assert.NoError(t, g.WriteCanonical(&b))
+			assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns="http://www.topografix.com/GPX/1/1" xsi:schemaLocation="http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd">
+  <wpt lat="1" lon="2">
+    <name>A&#xA;B</name>
+    <link href="https://example.com/"></link>
+    <extensions><x:a a="1" b="2">3</x:a></extensions>
+  </wpt>
+  <trk>
+    <trkseg></trkseg>
+  </trk>
+</gpx>
+`, b.String())
+		})
+	}
+
+	for _, data := range []string{"<a>", "<a></b>", "</a>"} {
+		g := &gpx.GPX{Extensions: &gpx.ExtensionsType{XML: []byte(data)}}
+		assert.Error(t, g.WriteCanonical(&bytes.Buffer{}))
+	}
+}
@@ -0,0 +1,142 @@
+package gpx
+
+// A ClimbCategory is the difficulty category of a climb, as used in cycling.
+type ClimbCategory int
+
+// Climb categories, from easiest to hardest.
+const (
+	ClimbCategoryNone ClimbCategory = iota
+	ClimbCategory4
+	ClimbCategory3
+	ClimbCategory2
+	ClimbCategory1
+	ClimbCategoryHC
+)
+
+// climbCategoryScores are the minimum scores, the product of a climb's
+// distance in meters and its grade in percent, of each climb category from
+// ClimbCategory4 upwards.
+var climbCategoryScores = []float64{8000, 16000, 32000, 64000, 80000}
+
+func (c ClimbCategory) String() string {
+	switch c {
+	case ClimbCategoryNone:
+		return "none"
+	case ClimbCategory4:
+		return "4"
+	case ClimbCategory3:
+		return "3"
+	case ClimbCategory2:
+		return "2"
+	case ClimbCategory1:
+		return "1"
+	case ClimbCategoryHC:
+		return "HC"
+	default:
+		return "unknown"
+	}
+}
+
+// A Climb is a sustained climb in a track segment.
+type Climb struct {
+	// Start and End are the indexes of the first and last points of the
+	// climb.
+	Start int
+	End   int
+	// Distance is the length of the climb in meters.
+	Distance float64
+	// ElevationGain is the difference in elevation between the first and
+	// last points of the climb in meters.
+	ElevationGain float64
+	// Grade is the average grade of the climb in percent.
+	Grade    float64
+	Category ClimbCategory
+}
+
+// A ClimbDetector finds climbs in track segments.
+type ClimbDetector struct {
+	// MinDistance is the minimum length of a climb in meters.
+	MinDistance float64
+	// MinGrade is the minimum average grade of a climb in percent.
+	MinGrade float64
+	// MaxDescent is the largest descent in meters from the highest point so
+	// far that does not end a climb.
+	MaxDescent float64
+}
+
+// Gradients returns the grade in percent of the path to each of ts's points
+// from the previous point. The grade of the first point, and of points where
+// either point has no elevation or the points are at the same position, is
+// zero.
+func (ts *TrkSegType) Gradients() []float64 {
+	if len(ts.TrkPt) == 0 {
+		return nil
+	}
+	gradients := make([]float64, len(ts.TrkPt))
+	for i := 1; i < len(ts.TrkPt); i++ {
+		a, b := ts.TrkPt[i-1], ts.TrkPt[i]
+		if !a.Has(WptEle) || !b.Has(WptEle) {
+			continue
+		}
+		if distance := HaversineDistance(a.Lat, a.Lon, b.Lat, b.Lon); distance > 0 {
+			gradients[i] = 100 * (b.Ele - a.Ele) / distance
+		}
+	}
+	return gradients
+}
+
+// Detect returns the climbs in ts. Each climb runs from a low point to the
+// highest point reached before the elevation drops by more than
+// d.MaxDescent. Points without elevations are ignored.
+func (d *ClimbDetector) Detect(ts *TrkSegType) []Climb {
+	distances := cumulativeDistances(ts.TrkPt)
+	var climbs []Climb
+	start, top := -1, -1
+	finish := func() {
+		if start == -1 || top == start {
+			return
+		}
+		climb := Climb{
+			Start:         start,
+			End:           top,
+			Distance:      distances[top] - distances[start],
+			ElevationGain: ts.TrkPt[top].Ele - ts.TrkPt[start].Ele,
+		}
+		if climb.Distance <= 0 || climb.Distance < d.MinDistance {
+			return
+		}
+		climb.Grade = 100 * climb.ElevationGain / climb.Distance
+		if climb.Grade < d.MinGrade {
+			return
+		}
+		climb.Category = climbCategory(climb.Distance * climb.Grade)
+		climbs = append(climbs, climb)
+	}
+	for i, trkPt := range ts.TrkPt {
+		switch {
+		case !trkPt.Has(WptEle):
+		case start == -1:
+			start, top = i, i
+		case top == start && trkPt.Ele <= ts.TrkPt[start].Ele:
+			start, top = i, i
+		case trkPt.Ele > ts.TrkPt[top].Ele:
+			top = i
+		case ts.TrkPt[top].Ele-trkPt.Ele > d.MaxDescent:
+			finish()
+			start, top = i, i
+		}
+	}
+	finish()
+	return climbs
+}
+
+// climbCategory returns the category of a climb with score.
+func climbCategory(score float64) ClimbCategory {
+	category := ClimbCategoryNone
+	for i, minScore := range climbCategoryScores {
+		if score >= minScore {
+			category = ClimbCategory4 + ClimbCategory(i)
+		}
+	}
+	return category
+}
@@ -0,0 +1,112 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+// climbTestStep is the longitude step between points 100m apart along the
+// equator.
+var climbTestStep = 100 / gpx.HaversineDistance(0, 0, 0, 1)
+
+func TestGradients(t *testing.T) {
+	eles := []float64{100, 105, 0, 110, 100}
+	ts := newTestTrkSeg(len(eles), func(i int) *gpx.WptType {
+		return &gpx.WptType{Lon: float64(i) * climbTestStep, Ele: eles[i]}
+	})
+	got := ts.Gradients()
+	assert.Len(t, got, 5)
+	assert.Equal(t, 0.0, got[0])
+	assert.InDelta(t, 5, got[1], 1e-6)
+	assert.Equal(t, 0.0, got[2])
+	assert.Equal(t, 0.0, got[3])
+	assert.InDelta(t, -10, got[4], 1e-6)
+	assert.Nil(t, (&gpx.TrkSegType{}).Gradients())
+
+	// Points at sea level have elevations.
+	ts.TrkPt[2].SetZero(gpx.WptEle)
+	assert.InDelta(t, -105, ts.Gradients()[2], 1e-6)
+}
+
+func TestClimbDetector(t *testing.T) {
+	for i, tc := range []struct {
+		detector gpx.ClimbDetector
+		eles     []float64
+		expected []gpx.Climb
+	}{
+		{
+			eles: []float64{100, 100, 110, 120, 115, 130, 100},
+			expected: []gpx.Climb{
+				{Start: 1, End: 3, Distance: 200, ElevationGain: 20, Grade: 10},
+				{Start: 4, End: 5, Distance: 100, ElevationGain: 15, Grade: 15},
+			},
+		},
+		{
+			detector: gpx.ClimbDetector{MaxDescent: 10},
+			eles:     []float64{100, 100, 110, 120, 115, 130, 100},
+			expected: []gpx.Climb{
+				{Start: 1, End: 5, Distance: 400, ElevationGain: 30, Grade: 7.5},
+			},
+		},
+		{
+			detector: gpx.ClimbDetector{MinDistance: 150},
+			eles:     []float64{100, 100, 110, 120, 115, 130, 100},
+			expected: []gpx.Climb{
+				{Start: 1, End: 3, Distance: 200, ElevationGain: 20, Grade: 10},
+			},
+		},
+		{
+			detector: gpx.ClimbDetector{MinGrade: 12},
+			eles:     []float64{100, 100, 110, 120, 115, 130, 100},
+			expected: []gpx.Climb{
+				{Start: 4, End: 5, Distance: 100, ElevationGain: 15, Grade: 15},
+			},
+		},
+		{
+			eles: []float64{200, 150, 100},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := tc.detector.Detect(newTestTrkSeg(len(tc.eles), func(i int) *gpx.WptType {
+				return &gpx.WptType{Lon: float64(i) * climbTestStep, Ele: tc.eles[i]}
+			}))
+			assert.Len(t, got, len(tc.expected))
+			for j, climb := range got {
+				assert.Equal(t, tc.expected[j].Start, climb.Start)
+				assert.Equal(t, tc.expected[j].End, climb.End)
+				assert.InDelta(t, tc.expected[j].Distance, climb.Distance, 1e-6)
+				assert.InDelta(t, tc.expected[j].ElevationGain, climb.ElevationGain, 1e-6)
+				assert.InDelta(t, tc.expected[j].Grade, climb.Grade, 1e-6)
+			}
+		})
+	}
+}
+
+func TestClimbCategory(t *testing.T) {
+	for i, tc := range []struct {
+		distanceSteps int
+		grade         float64
+		expected      gpx.ClimbCategory
+	}{
+		{distanceSteps: 10, grade: 5, expected: gpx.ClimbCategoryNone},
+		{distanceSteps: 20, grade: 5, expected: gpx.ClimbCategory4},
+		{distanceSteps: 40, grade: 5, expected: gpx.ClimbCategory3},
+		{distanceSteps: 80, grade: 5, expected: gpx.ClimbCategory2},
+		{distanceSteps: 150, grade: 5, expected: gpx.ClimbCategory1},
+		{distanceSteps: 200, grade: 5, expected: gpx.ClimbCategoryHC},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := (&gpx.ClimbDetector{}).Detect(newTestTrkSeg(tc.distanceSteps+1, func(i int) *gpx.WptType {
+				return &gpx.WptType{Lon: float64(i) * climbTestStep, Ele: 100 + float64(i)*tc.grade}
+			}))
+			assert.Len(t, got, 1)
+			assert.Equal(t, tc.expected, got[0].Category)
+		})
+	}
+	assert.Equal(t, "HC", gpx.ClimbCategoryHC.String())
+	assert.Equal(t, "4", gpx.ClimbCategory4.String())
+}
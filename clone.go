@@ -0,0 +1,155 @@
+package gpx
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of g. It returns nil if g is nil.
+func (g *GPX) Clone() *GPX {
+	if g == nil {
+		return nil
+	}
+	clone := *g
+	clone.XMLSchemaLocations = slices.Clone(g.XMLSchemaLocations)
+	clone.XMLAttrs = maps.Clone(g.XMLAttrs)
+	clone.Metadata = g.Metadata.Clone()
+	clone.Wpt = cloneWpts(g.Wpt)
+	if g.Rte != nil {
+		clone.Rte = make([]*RteType, len(g.Rte))
+		for i, rte := range g.Rte {
+			clone.Rte[i] = rte.Clone()
+		}
+	}
+	if g.Trk != nil {
+		clone.Trk = make([]*TrkType, len(g.Trk))
+		for i, trk := range g.Trk {
+			clone.Trk[i] = trk.Clone()
+		}
+	}
+	clone.Extensions = cloneExtensions(g.Extensions)
+	return &clone
+}
+
+// Clone returns a deep copy of m. It returns nil if m is nil.
+func (m *MetadataType) Clone() *MetadataType {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	if m.Author != nil {
+		author := *m.Author
+		if m.Author.Email != nil {
+			email := *m.Author.Email
+			author.Email = &email
+		}
+		author.Link = cloneLink(m.Author.Link)
+		clone.Author = &author
+	}
+	if m.Copyright != nil {
+		copyright := *m.Copyright
+		clone.Copyright = &copyright
+	}
+	clone.Link = cloneLinks(m.Link)
+	if m.Bounds != nil {
+		bounds := *m.Bounds
+		clone.Bounds = &bounds
+	}
+	clone.Extensions = cloneExtensions(m.Extensions)
+	return &clone
+}
+
+// Clone returns a deep copy of r. It returns nil if r is nil.
+func (r *RteType) Clone() *RteType {
+	if r == nil {
+		return nil
+	}
+	clone := *r
+	clone.Link = cloneLinks(r.Link)
+	clone.Extensions = cloneExtensions(r.Extensions)
+	clone.RtePt = cloneWpts(r.RtePt)
+	return &clone
+}
+
+// Clone returns a deep copy of t. It returns nil if t is nil.
+func (t *TrkType) Clone() *TrkType {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	clone.Link = cloneLinks(t.Link)
+	clone.Extensions = cloneExtensions(t.Extensions)
+	if t.TrkSeg != nil {
+		clone.TrkSeg = make([]*TrkSegType, len(t.TrkSeg))
+		for i, ts := range t.TrkSeg {
+			clone.TrkSeg[i] = ts.Clone()
+		}
+	}
+	return &clone
+}
+
+// Clone returns a deep copy of ts. It returns nil if ts is nil.
+func (ts *TrkSegType) Clone() *TrkSegType {
+	if ts == nil {
+		return nil
+	}
+	return &TrkSegType{
+		TrkPt:      cloneWpts(ts.TrkPt),
+		Extensions: cloneExtensions(ts.Extensions),
+	}
+}
+
+// Clone returns a deep copy of w. It returns nil if w is nil.
+func (w *WptType) Clone() *WptType {
+	if w == nil {
+		return nil
+	}
+	clone := *w
+	clone.Link = cloneLinks(w.Link)
+	clone.Extensions = cloneExtensions(w.Extensions)
+	return &clone
+}
+
+// cloneExtensions returns a deep copy of e.
+func cloneExtensions(e *ExtensionsType) *ExtensionsType {
+	if e == nil {
+		return nil
+	}
+	return &ExtensionsType{
+		XML: bytes.Clone(e.XML),
+	}
+}
+
+// cloneLink returns a copy of l.
+func cloneLink(l *LinkType) *LinkType {
+	if l == nil {
+		return nil
+	}
+	clone := *l
+	return &clone
+}
+
+// cloneLinks returns a deep copy of links.
+func cloneLinks(links []*LinkType) []*LinkType {
+	if links == nil {
+		return nil
+	}
+	clone := make([]*LinkType, len(links))
+	for i, link := range links {
+		clone[i] = cloneLink(link)
+	}
+	return clone
+}
+
+// cloneWpts returns a deep copy of wpts.
+func cloneWpts(wpts []*WptType) []*WptType {
+	if wpts == nil {
+		return nil
+	}
+	clone := make([]*WptType, len(wpts))
+	for i, wpt := range wpts {
+		clone[i] = wpt.Clone()
+	}
+	return clone
+}
@@ -0,0 +1,49 @@
+package gpx_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestClone(t *testing.T) {
+	read := func() *gpx.GPX {
+		f, err := os.Open("testdata/mystic_basin_trail.gpx")
+		assert.NoError(t, err)
+		defer f.Close()
+		g, err := gpx.Read(f)
+		assert.NoError(t, err)
+		g.Metadata.Author = &gpx.PersonType{
+			Name:  "author",
+			Email: &gpx.EmailType{Name: "author", Domain: "example.com"},
+			Link:  &gpx.LinkType{HREF: "https://example.com/"},
+		}
+		g.Wpt[0].Link = []*gpx.LinkType{{HREF: "https://example.com/wpt"}}
+		g.Wpt[0].Extensions = &gpx.ExtensionsType{XML: []byte("<hr>120</hr>")}
+		g.Trk[0].TrkSeg[0].Extensions = &gpx.ExtensionsType{XML: []byte("<seg/>")}
+		return g
+	}
+
+	g := read()
+	clone := g.Clone()
+	assert.Equal(t, g, clone)
+	assert.NotSame(t, g.Wpt[0], clone.Wpt[0])
+
+	clone.Metadata.Name = "Renamed"
+	clone.Metadata.Author.Email.Domain = "example.org"
+	clone.Metadata.Author.Link.HREF = "https://example.org/"
+	clone.Wpt[0].Lat = 0
+	clone.Wpt[0].Link[0].Text = "text"
+	clone.Wpt[0].Extensions.XML[1] = 'x'
+	clone.Rte[0].RtePt[0].Name = "Renamed"
+	clone.Trk[0].TrkSeg[0].TrkPt[0].Ele = 0
+	clone.Trk[0].TrkSeg[0].Extensions.XML[1] = 'x'
+	clone.Trk[0].TrkSeg = clone.Trk[0].TrkSeg[:0]
+	assert.Equal(t, read(), g)
+
+	assert.Nil(t, (*gpx.GPX)(nil).Clone())
+	assert.Nil(t, (*gpx.WptType)(nil).Clone())
+}
@@ -0,0 +1,180 @@
+package gpx
+
+import "sort"
+
+// routeClusterSamples is the number of points to which routes are resampled
+// for comparison.
+const routeClusterSamples = 64
+
+// A RouteCluster is a group of documents that follow the same route.
+type RouteCluster struct {
+	// Members are the indexes of the documents in the cluster.
+	Members []int
+	// Representative is the average of the members' routes, resampled to
+	// evenly spaced points.
+	Representative *TrkSegType
+}
+
+// ClusterRoutes groups the documents in docs whose tracks follow the same
+// route, in the same direction, to within threshold meters, as measured by
+// the Fréchet distance between their tracks resampled to evenly spaced
+// points. Each document's tracks are treated as a single path. Identical
+// documents are grouped without comparison. Documents without track points
+// are not included in any cluster. Clusters are returned in the order of
+// their first members.
+func ClusterRoutes(docs []*GPX, threshold float64) []*RouteCluster {
+	type cluster struct {
+		*RouteCluster
+		fingerprint string
+		first       []*WptType
+		sum         []*WptType
+	}
+	var clusters []*cluster
+DOCS:
+	for i, g := range docs {
+		var trkPts []*WptType
+		for _, trk := range g.Trk {
+			trkPts = append(trkPts, trk.trkPts()...)
+		}
+		if len(trkPts) == 0 {
+			continue
+		}
+		fp := g.Fingerprint(exactFingerprintOptions)
+		samples := resample(trkPts, routeClusterSamples)
+		for _, c := range clusters {
+			if c.fingerprint != fp && !sameRoute(c.first, samples, threshold) {
+				continue
+			}
+			c.Members = append(c.Members, i)
+			for j, sample := range samples {
+				c.sum[j].Lat += sample.Lat
+				c.sum[j].Lon += normalizeLon(sample.Lon - c.first[j].Lon)
+				c.sum[j].Ele += sample.Ele
+			}
+			continue DOCS
+		}
+		sum := make([]*WptType, len(samples))
+		for j, sample := range samples {
+			sum[j] = &WptType{Lat: sample.Lat, Ele: sample.Ele}
+		}
+		clusters = append(clusters, &cluster{
+			RouteCluster: &RouteCluster{
+				Members: []int{i},
+			},
+			fingerprint: fp,
+			first:       samples,
+			sum:         sum,
+		})
+	}
+
+	result := make([]*RouteCluster, 0, len(clusters))
+	for _, c := range clusters {
+		n := float64(len(c.Members))
+		c.Representative = &TrkSegType{
+			TrkPt: make([]*WptType, len(c.sum)),
+		}
+		for j, sum := range c.sum {
+			c.Representative.TrkPt[j] = &WptType{
+				Lat: sum.Lat / n,
+				Lon: normalizeLon(c.first[j].Lon + sum.Lon/n),
+				Ele: sum.Ele / n,
+			}
+		}
+		result = append(result, c.RouteCluster)
+	}
+	return result
+}
+
+// sameRoute returns whether the resampled routes a and b are within
+// threshold meters of each other.
+func sameRoute(a, b []*WptType, threshold float64) bool {
+	for _, i := range []int{0, len(a) - 1} {
+		if HaversineDistance(a[i].Lat, a[i].Lon, b[i].Lat, b[i].Lon) > threshold {
+			return false
+		}
+	}
+	return warpingDistance(a, b, func(cost, distance float64) float64 {
+		return max(cost, distance)
+	}) <= threshold
+}
+
+// resample returns n points evenly spaced along the path through wpts. Only
+// positions, elevations, and times are kept.
+func resample(wpts []*WptType, n int) []*WptType {
+	distances := cumulativeDistances(wpts)
+	total := distances[len(distances)-1]
+	result := make([]*WptType, n)
+	for i := range result {
+		if total == 0 {
+			result[i] = &WptType{Lat: wpts[0].Lat, Lon: wpts[0].Lon, Ele: wpts[0].Ele, Time: wpts[0].Time, ZeroFields: wpts[0].ZeroFields & WptEle}
+			continue
+		}
+		wpt := pointAtDistance(wpts, distances, min(total*float64(i)/float64(n-1), total))
+		result[i] = &WptType{Lat: wpt.Lat, Lon: wpt.Lon, Ele: wpt.Ele, Time: wpt.Time, ZeroFields: wpt.ZeroFields & WptEle}
+	}
+	return result
+}
+
+// ConsensusRoute returns the median of tracks, which should follow the same
+// route in the same direction. Each track is resampled to as many evenly
+// spaced points as the longest track has points, and the median latitude,
+// longitude, and elevation of corresponding points is taken, so that noise in
+// individual tracks does not affect the result. Times are not kept. Empty
+// tracks are ignored. It returns nil if all tracks are empty.
+func ConsensusRoute(tracks []*TrkSegType) *TrkSegType {
+	n := 0
+	var nonEmpty []*TrkSegType
+	for _, ts := range tracks {
+		if len(ts.TrkPt) != 0 {
+			nonEmpty = append(nonEmpty, ts)
+			n = max(n, len(ts.TrkPt))
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return nil
+	}
+	n = max(n, 2)
+	samples := make([][]*WptType, len(nonEmpty))
+	for i, ts := range nonEmpty {
+		samples[i] = resample(ts.TrkPt, n)
+	}
+
+	result := &TrkSegType{
+		TrkPt: make([]*WptType, n),
+	}
+	lats := make([]float64, 0, len(samples))
+	lons := make([]float64, 0, len(samples))
+	eles := make([]float64, 0, len(samples))
+	for j := 0; j < n; j++ {
+		lats, lons, eles = lats[:0], lons[:0], eles[:0]
+		lon0 := samples[0][j].Lon
+		for _, s := range samples {
+			lats = append(lats, s[j].Lat)
+			lons = append(lons, normalizeLon(s[j].Lon-lon0))
+			if s[j].Has(WptEle) {
+				eles = append(eles, s[j].Ele)
+			}
+		}
+		result.TrkPt[j] = &WptType{
+			Lat: median(lats),
+			Lon: normalizeLon(lon0 + median(lons)),
+		}
+		if len(eles) > 0 {
+			result.TrkPt[j].setEle(median(eles))
+		}
+	}
+	return result
+}
+
+// median returns the median of values, or zero if values is empty. values is
+// sorted.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	if n := len(values); n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[len(values)/2-1] + values[len(values)/2]) / 2
+}
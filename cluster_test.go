@@ -0,0 +1,97 @@
+package gpx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestClusterRoutes(t *testing.T) {
+	// newDoc returns a document with a track from lat0, lon0 heading east for
+	// 0.01 degrees, then north for 0.01 degrees.
+	newDoc := func(lat0, lon0 float64) *gpx.GPX {
+		return &gpx.GPX{
+			Trk: []*gpx.TrkType{
+				{
+					TrkSeg: []*gpx.TrkSegType{
+						{
+							TrkPt: []*gpx.WptType{
+								{Lat: lat0, Lon: lon0},
+								{Lat: lat0, Lon: lon0 + 0.005},
+								{Lat: lat0, Lon: lon0 + 0.01},
+							},
+						},
+						{
+							TrkPt: []*gpx.WptType{
+								{Lat: lat0 + 0.01, Lon: lon0 + 0.01},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	reversed := newDoc(47, 7)
+	trkPts := reversed.Trk[0].TrkSeg[0].TrkPt
+	reversed.Trk[0].TrkSeg[0].TrkPt = []*gpx.WptType{{Lat: 47.01, Lon: 7.01}, trkPts[2], trkPts[1]}
+	reversed.Trk[0].TrkSeg[1].TrkPt = []*gpx.WptType{trkPts[0]}
+
+	docs := []*gpx.GPX{
+		newDoc(47, 7),
+		newDoc(47.0001, 7),
+		{},
+		newDoc(48, 7),
+		newDoc(47, 7),
+		reversed,
+		newDoc(46.9999, 7.0001),
+	}
+	got := gpx.ClusterRoutes(docs, 50)
+	assert.Len(t, got, 3)
+	assert.Equal(t, []int{0, 1, 4, 6}, got[0].Members)
+	assert.Equal(t, []int{3}, got[1].Members)
+	assert.Equal(t, []int{5}, got[2].Members)
+
+	representative := got[0].Representative.TrkPt
+	assert.Len(t, representative, 64)
+	assert.InDelta(t, 47, representative[0].Lat, 1e-4)
+	assert.InDelta(t, 7.000025, representative[0].Lon, 1e-6)
+	assert.InDelta(t, 47.01, representative[63].Lat, 1e-4)
+	assert.InDelta(t, 7.01, representative[63].Lon, 1e-4)
+
+	assert.Empty(t, gpx.ClusterRoutes(nil, 50))
+}
+
+func TestConsensusRoute(t *testing.T) {
+	newTrkSeg := func(lats ...float64) *gpx.TrkSegType {
+		ts := &gpx.TrkSegType{}
+		for i, lat := range lats {
+			ts.TrkPt = append(ts.TrkPt, &gpx.WptType{Lat: lat, Lon: float64(i) * 0.001, Ele: 100})
+		}
+		return ts
+	}
+	got := gpx.ConsensusRoute([]*gpx.TrkSegType{
+		newTrkSeg(47, 47, 47),
+		newTrkSeg(47.0001, 47.0001, 47.0001),
+		newTrkSeg(47, 47.01, 47),
+		newTrkSeg(46.9999, 46.9999, 46.9999),
+		newTrkSeg(47, 47, 47),
+		{},
+	})
+	assert.Len(t, got.TrkPt, 3)
+	for i, trkPt := range got.TrkPt {
+		assert.InDelta(t, 47, trkPt.Lat, 1e-9)
+		assert.InDelta(t, float64(i)*0.001, trkPt.Lon, 1e-6)
+		assert.Equal(t, 100.0, trkPt.Ele)
+	}
+
+	// Tracks crossing the antimeridian.
+	got = gpx.ConsensusRoute([]*gpx.TrkSegType{
+		{TrkPt: []*gpx.WptType{{Lon: 179.9}, {Lon: -179.9}}},
+		{TrkPt: []*gpx.WptType{{Lon: 179.9}, {Lon: -179.9}}},
+	})
+	assert.InDelta(t, -179.9, got.TrkPt[1].Lon, 1e-6)
+
+	assert.Nil(t, gpx.ConsensusRoute(nil))
+}
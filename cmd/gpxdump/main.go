@@ -11,6 +11,8 @@ import (
 	"github.com/twpayne/go-gpx"
 )
 
+var format = flag.String("format", "gpx", "input format")
+
 func dumpFile(filename string) error {
 	f, err := os.Open(filename)
 	if err != nil {
@@ -21,7 +23,7 @@ func dumpFile(filename string) error {
 }
 
 func dump(r io.Reader) error {
-	g, err := gpx.Read(r)
+	g, err := gpx.ReadFormat(*format, r)
 	if err != nil {
 		return err
 	}
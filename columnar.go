@@ -0,0 +1,88 @@
+package gpx
+
+import (
+	"math"
+	"time"
+)
+
+// A PointTable is a columnar representation of the track points in a GPX
+// document, suitable for handing to columnar formats such as Parquet and
+// Arrow. All columns have the same length, with one entry per track point.
+type PointTable struct {
+	TrackIndex   []int
+	SegmentIndex []int
+	Lat          []float64
+	Lon          []float64
+	Ele          []float64
+	// HasEle records whether each point has an elevation, distinguishing
+	// points at sea level from points without elevations.
+	HasEle []bool
+	Time   []time.Time
+	// HeartRate is the heart rate in beats per minute from the point's
+	// extensions, or zero if absent.
+	HeartRate []float64
+	// HasHeartRate records whether each point has a heart rate.
+	HasHeartRate []bool
+	// Cadence is the cadence in revolutions per minute from the point's
+	// extensions, or zero if absent.
+	Cadence []float64
+	// HasCadence records whether each point has a cadence, distinguishing
+	// points with a cadence of zero, for example while coasting, from points
+	// without cadences.
+	HasCadence []bool
+}
+
+// PointTable returns the track points of g as a PointTable.
+func (g *GPX) PointTable() *PointTable {
+	n := 0
+	for _, trk := range g.Trk {
+		for _, trkSeg := range trk.TrkSeg {
+			n += len(trkSeg.TrkPt)
+		}
+	}
+	pt := &PointTable{
+		TrackIndex:   make([]int, 0, n),
+		SegmentIndex: make([]int, 0, n),
+		Lat:          make([]float64, 0, n),
+		Lon:          make([]float64, 0, n),
+		Ele:          make([]float64, 0, n),
+		HasEle:       make([]bool, 0, n),
+		Time:         make([]time.Time, 0, n),
+		HeartRate:    make([]float64, 0, n),
+		HasHeartRate: make([]bool, 0, n),
+		Cadence:      make([]float64, 0, n),
+		HasCadence:   make([]bool, 0, n),
+	}
+	for trackIndex, trk := range g.Trk {
+		for segmentIndex, trkSeg := range trk.TrkSeg {
+			for _, trkPt := range trkSeg.TrkPt {
+				sensors := trkPt.Sensors()
+				hasHeartRate, hasCadence := !math.IsNaN(sensors.HeartRate), !math.IsNaN(sensors.Cadence)
+				var heartRate, cadence float64
+				if hasHeartRate {
+					heartRate = sensors.HeartRate
+				}
+				if hasCadence {
+					cadence = sensors.Cadence
+				}
+				pt.TrackIndex = append(pt.TrackIndex, trackIndex)
+				pt.SegmentIndex = append(pt.SegmentIndex, segmentIndex)
+				pt.Lat = append(pt.Lat, trkPt.Lat)
+				pt.Lon = append(pt.Lon, trkPt.Lon)
+				pt.Ele = append(pt.Ele, trkPt.Ele)
+				pt.HasEle = append(pt.HasEle, trkPt.Has(WptEle))
+				pt.Time = append(pt.Time, trkPt.Time)
+				pt.HeartRate = append(pt.HeartRate, heartRate)
+				pt.HasHeartRate = append(pt.HasHeartRate, hasHeartRate)
+				pt.Cadence = append(pt.Cadence, cadence)
+				pt.HasCadence = append(pt.HasCadence, hasCadence)
+			}
+		}
+	}
+	return pt
+}
+
+// Len returns the number of rows in pt.
+func (pt *PointTable) Len() int {
+	return len(pt.Lat)
+}
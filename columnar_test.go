@@ -0,0 +1,60 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPointTable(t *testing.T) {
+	t0 := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{
+								Lat:  1,
+								Lon:  2,
+								Ele:  3,
+								Time: t0,
+								Extensions: &gpx.ExtensionsType{
+									XML: []byte("<gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr><gpxtpx:cad>90</gpxtpx:cad></gpxtpx:TrackPointExtension>"),
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{},
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 4, Lon: 5},
+						},
+					},
+				},
+			},
+		},
+	}
+	pt := g.PointTable()
+	assert.Equal(t, 2, pt.Len())
+	assert.Equal(t, &gpx.PointTable{
+		TrackIndex:   []int{0, 1},
+		SegmentIndex: []int{0, 1},
+		Lat:          []float64{1, 4},
+		Lon:          []float64{2, 5},
+		Ele:          []float64{3, 0},
+		HasEle:       []bool{true, false},
+		Time:         []time.Time{t0, {}},
+		HeartRate:    []float64{150, 0},
+		HasHeartRate: []bool{true, false},
+		Cadence:      []float64{90, 0},
+		HasCadence:   []bool{true, false},
+	}, pt)
+}
@@ -0,0 +1,153 @@
+package gpx
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// A CompactActionKind is the kind of a CompactAction.
+type CompactActionKind int
+
+// Compact action kinds.
+const (
+	CompactActionDuplicate CompactActionKind = iota
+	CompactActionMerge
+)
+
+// CompactOptions control CompactArchive.
+type CompactOptions struct {
+	// MergeGap is the maximum time between the last track point of one
+	// document and the first track point of the next for them to be merged.
+	// If zero, documents are not merged.
+	MergeGap time.Duration
+}
+
+// A CompactAction records an action taken by CompactArchive.
+type CompactAction struct {
+	Kind CompactActionKind
+	// Index is the index of the document that was removed.
+	Index int
+	// Target is the index of the document that Index duplicated or was merged
+	// into.
+	Target int
+}
+
+// String returns a description of k.
+func (k CompactActionKind) String() string {
+	switch k {
+	case CompactActionDuplicate:
+		return "duplicate"
+	case CompactActionMerge:
+		return "merge"
+	default:
+		return "unknown"
+	}
+}
+
+// CompactArchive removes duplicate documents from docs and merges documents
+// whose tracks continue each other within options.MergeGap. Documents are
+// duplicates if they contain the same waypoints, route points, and track
+// points, ignoring names and other metadata. It returns the remaining
+// documents, in the order of their first occurrence in docs, and the actions
+// taken. docs is not modified, but the returned documents share tracks,
+// routes, and waypoints with docs.
+func CompactArchive(docs []*GPX, options CompactOptions) ([]*GPX, []CompactAction) {
+	var actions []CompactAction
+
+	// Remove duplicates.
+	fingerprints := make(map[string]int)
+	var indexes []int
+	for i, doc := range docs {
+		fingerprint := doc.Fingerprint(exactFingerprintOptions)
+		if target, ok := fingerprints[fingerprint]; ok {
+			actions = append(actions, CompactAction{
+				Kind:   CompactActionDuplicate,
+				Index:  i,
+				Target: target,
+			})
+			continue
+		}
+		fingerprints[fingerprint] = i
+		indexes = append(indexes, i)
+	}
+
+	// Merge documents that continue each other.
+	merged := make(map[int]*GPX)
+	removed := make(map[int]bool)
+	if options.MergeGap > 0 {
+		type timeRange struct {
+			index      int
+			start, end time.Time
+		}
+		var timeRanges []timeRange
+		for _, index := range indexes {
+			if start, end, ok := docs[index].trkPtTimeRange(); ok {
+				timeRanges = append(timeRanges, timeRange{index: index, start: start, end: end})
+			}
+		}
+		sort.SliceStable(timeRanges, func(i, j int) bool {
+			return timeRanges[i].start.Before(timeRanges[j].start)
+		})
+		var target timeRange
+		for i, tr := range timeRanges {
+			if gap := tr.start.Sub(target.end); i == 0 || gap < 0 || gap > options.MergeGap {
+				target = tr
+				continue
+			}
+			doc, ok := merged[target.index]
+			if !ok {
+				copied := *docs[target.index]
+				copied.Wpt = slices.Clone(copied.Wpt)
+				copied.Rte = slices.Clone(copied.Rte)
+				copied.Trk = slices.Clone(copied.Trk)
+				doc = &copied
+				merged[target.index] = doc
+			}
+			doc.Wpt = append(doc.Wpt, docs[tr.index].Wpt...)
+			doc.Rte = append(doc.Rte, docs[tr.index].Rte...)
+			doc.Trk = append(doc.Trk, docs[tr.index].Trk...)
+			removed[tr.index] = true
+			actions = append(actions, CompactAction{
+				Kind:   CompactActionMerge,
+				Index:  tr.index,
+				Target: target.index,
+			})
+			if tr.end.After(target.end) {
+				target.end = tr.end
+			}
+		}
+	}
+
+	result := make([]*GPX, 0, len(indexes))
+	for _, index := range indexes {
+		switch {
+		case removed[index]:
+		case merged[index] != nil:
+			result = append(result, merged[index])
+		default:
+			result = append(result, docs[index])
+		}
+	}
+	return result, actions
+}
+
+// trkPtTimeRange returns the times of the first and last track points in g
+// with times.
+func (g *GPX) trkPtTimeRange() (time.Time, time.Time, bool) {
+	var start, end time.Time
+	for _, trk := range g.Trk {
+		for _, ts := range trk.TrkSeg {
+			for _, trkPt := range ts.TrkPt {
+				if trkPt.Time.IsZero() {
+					continue
+				}
+				if start.IsZero() {
+					start = trkPt.Time
+				}
+				end = trkPt.Time
+			}
+		}
+	}
+	return start, end, !start.IsZero()
+}
@@ -0,0 +1,62 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestCompactArchive(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	// doc returns a document called name with a ten second track heading
+	// east from start.
+	doc := func(name string, start time.Time) *gpx.GPX {
+		return &gpx.GPX{
+			Metadata: &gpx.MetadataType{Name: name},
+			Trk: []*gpx.TrkType{
+				{
+					Name: name,
+					TrkSeg: []*gpx.TrkSegType{
+						newTestTrkSeg(10, func(i int) *gpx.WptType {
+							return &gpx.WptType{Lat: 46, Lon: 7 + float64(i)*1e-4, Time: start.Add(time.Duration(i) * time.Second)}
+						}),
+					},
+				},
+			},
+		}
+	}
+	part1 := doc("part1", t0)
+	part2 := doc("part2", t0.Add(15*time.Second))
+	part3 := doc("part3", t0.Add(30*time.Second))
+	duplicate := doc("copy of part1", t0)
+	other := doc("other", t0.Add(24*time.Hour))
+	untimed := &gpx.GPX{Wpt: []*gpx.WptType{{Lat: 1, Lon: 2}}}
+	docs := []*gpx.GPX{part3, part1, duplicate, other, part2, untimed}
+
+	got, actions := gpx.CompactArchive(docs, gpx.CompactOptions{})
+	assert.Equal(t, []*gpx.GPX{part3, part1, other, part2, untimed}, got)
+	assert.Equal(t, []gpx.CompactAction{
+		{Kind: gpx.CompactActionDuplicate, Index: 2, Target: 1},
+	}, actions)
+
+	got, actions = gpx.CompactArchive(docs, gpx.CompactOptions{MergeGap: time.Minute})
+	assert.Equal(t, []gpx.CompactAction{
+		{Kind: gpx.CompactActionDuplicate, Index: 2, Target: 1},
+		{Kind: gpx.CompactActionMerge, Index: 4, Target: 1},
+		{Kind: gpx.CompactActionMerge, Index: 0, Target: 1},
+	}, actions)
+	assert.Len(t, got, 3)
+	assert.Equal(t, "part1", got[0].Metadata.Name)
+	assert.Equal(t, []*gpx.TrkType{part1.Trk[0], part2.Trk[0], part3.Trk[0]}, got[0].Trk)
+	assert.Same(t, other, got[1])
+	assert.Same(t, untimed, got[2])
+
+	// The inputs are not modified.
+	assert.Len(t, part1.Trk, 1)
+
+	assert.Equal(t, "duplicate", gpx.CompactActionDuplicate.String())
+	assert.Equal(t, "merge", gpx.CompactActionMerge.String())
+}
@@ -0,0 +1,144 @@
+package gpx
+
+import "time"
+
+// defaultMaxZeroTimeDistance is the default largest distance in meters
+// between points with the same time that is reported as a duplicate time.
+const defaultMaxZeroTimeDistance = 10
+
+// A TimeDistanceIssue is a kind of inconsistency between the times and
+// positions of consecutive track points.
+type TimeDistanceIssue int
+
+// Time and distance issues.
+const (
+	// IssueNonMonotonicTime is a point with a time before that of the
+	// previous point.
+	IssueNonMonotonicTime TimeDistanceIssue = iota
+	// IssueDuplicateTime is a point with the same time as the previous point
+	// but a different position, at most MaxZeroTimeDistance away.
+	IssueDuplicateTime
+	// IssueZeroTimeDisplacement is a point with the same time as the previous
+	// point but more than MaxZeroTimeDistance away.
+	IssueZeroTimeDisplacement
+	// IssueExcessiveSpeed is a point that was reached from the previous point
+	// faster than MaxSpeed.
+	IssueExcessiveSpeed
+)
+
+// TimeDistanceOptions control CheckTimeDistance.
+type TimeDistanceOptions struct {
+	// MaxSpeed is the largest plausible implied speed in meters per second.
+	// If zero, implied speeds are not checked.
+	MaxSpeed float64
+	// MaxZeroTimeDistance is the largest distance in meters between
+	// consecutive points with the same time that is reported as
+	// IssueDuplicateTime rather than IssueZeroTimeDisplacement. If zero, 10
+	// meters is used.
+	MaxZeroTimeDistance float64
+}
+
+// A TimeDistanceFinding is an inconsistency between the time and position of
+// a track point and those of the previous point with a time in the same
+// segment. Findings are suitable for encoding as JSON.
+type TimeDistanceFinding struct {
+	Issue  TimeDistanceIssue `json:"issue"`
+	Trk    int               `json:"trk"`    // Index of the track.
+	TrkSeg int               `json:"trkseg"` // Index of the segment.
+	TrkPt  int               `json:"trkpt"`  // Index of the point.
+	Prev   int               `json:"prev"`   // Index of the previous point.
+	Time   time.Time         `json:"time"`
+	// Elapsed is the time since the previous point, negative if time is
+	// non-monotonic.
+	Elapsed time.Duration `json:"elapsed"`
+	// Distance is the distance from the previous point in meters.
+	Distance float64 `json:"distance"`
+	// Speed is the implied speed in meters per second, or zero if Elapsed
+	// is not positive.
+	Speed float64 `json:"speed"`
+}
+
+// CheckTimeDistance returns the inconsistencies between the times and the
+// distances of consecutive points in g's track segments, in order. Points
+// without times are skipped.
+func (g *GPX) CheckTimeDistance(options TimeDistanceOptions) []TimeDistanceFinding {
+	var findings []TimeDistanceFinding
+	for i, trk := range g.Trk {
+		for j, ts := range trk.TrkSeg {
+			findings = ts.appendTimeDistanceFindings(findings, i, j, options)
+		}
+	}
+	return findings
+}
+
+// CheckTimeDistance returns the inconsistencies between the times and the
+// distances of ts's consecutive points, with Trk and TrkSeg set to zero.
+func (ts *TrkSegType) CheckTimeDistance(options TimeDistanceOptions) []TimeDistanceFinding {
+	return ts.appendTimeDistanceFindings(nil, 0, 0, options)
+}
+
+func (ts *TrkSegType) appendTimeDistanceFindings(findings []TimeDistanceFinding, trk, trkSeg int, options TimeDistanceOptions) []TimeDistanceFinding {
+	maxZeroTimeDistance := options.MaxZeroTimeDistance
+	if maxZeroTimeDistance == 0 {
+		maxZeroTimeDistance = defaultMaxZeroTimeDistance
+	}
+	prev := -1
+	for i, trkPt := range ts.TrkPt {
+		if trkPt.Time.IsZero() {
+			continue
+		}
+		if prev == -1 {
+			prev = i
+			continue
+		}
+		prevTrkPt := ts.TrkPt[prev]
+		finding := TimeDistanceFinding{
+			Trk:      trk,
+			TrkSeg:   trkSeg,
+			TrkPt:    i,
+			Prev:     prev,
+			Time:     trkPt.Time,
+			Elapsed:  trkPt.Time.Sub(prevTrkPt.Time),
+			Distance: HaversineDistance(prevTrkPt.Lat, prevTrkPt.Lon, trkPt.Lat, trkPt.Lon),
+		}
+		if finding.Elapsed > 0 {
+			finding.Speed = finding.Distance / finding.Elapsed.Seconds()
+		}
+		prev = i
+		switch {
+		case finding.Elapsed < 0:
+			finding.Issue = IssueNonMonotonicTime
+		case finding.Elapsed == 0 && finding.Distance > maxZeroTimeDistance:
+			finding.Issue = IssueZeroTimeDisplacement
+		case finding.Elapsed == 0 && finding.Distance > 0:
+			finding.Issue = IssueDuplicateTime
+		case options.MaxSpeed > 0 && finding.Speed > options.MaxSpeed:
+			finding.Issue = IssueExcessiveSpeed
+		default:
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText.
+func (i TimeDistanceIssue) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// String returns a string representation of i.
+func (i TimeDistanceIssue) String() string {
+	switch i {
+	case IssueNonMonotonicTime:
+		return "non-monotonic-time"
+	case IssueDuplicateTime:
+		return "duplicate-time"
+	case IssueZeroTimeDisplacement:
+		return "zero-time-displacement"
+	case IssueExcessiveSpeed:
+		return "excessive-speed"
+	default:
+		return "unknown"
+	}
+}
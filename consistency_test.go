@@ -0,0 +1,66 @@
+package gpx_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestCheckTimeDistance(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	step := 100 / gpx.HaversineDistance(0, 0, 0, 1)
+
+	g := &gpx.GPX{
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lon: 0, Time: t0},
+							{Lon: 1 * step, Time: t0.Add(20 * time.Second)},
+							{Lon: 1.05 * step, Time: t0.Add(20 * time.Second)},
+							{Lon: 1.05 * step},
+							{Lon: 2 * step, Time: t0.Add(10 * time.Second)},
+							{Lon: 2 * step, Time: t0.Add(30 * time.Second)},
+							{Lon: 3 * step, Time: t0.Add(30 * time.Second)},
+							{Lon: 9 * step, Time: t0.Add(40 * time.Second)},
+						},
+					},
+				},
+			},
+		},
+	}
+	findings := g.CheckTimeDistance(gpx.TimeDistanceOptions{
+		MaxSpeed: 20,
+	})
+	assert.Len(t, findings, 4)
+
+	assert.Equal(t, gpx.IssueDuplicateTime, findings[0].Issue)
+	assert.Equal(t, 2, findings[0].TrkPt)
+	assert.Equal(t, 1, findings[0].Prev)
+	assert.InDelta(t, 5, findings[0].Distance, 1e-6)
+	assert.Zero(t, findings[0].Speed)
+
+	assert.Equal(t, gpx.IssueNonMonotonicTime, findings[1].Issue)
+	assert.Equal(t, 4, findings[1].TrkPt)
+	assert.Equal(t, 2, findings[1].Prev)
+	assert.Equal(t, -10*time.Second, findings[1].Elapsed)
+
+	assert.Equal(t, gpx.IssueZeroTimeDisplacement, findings[2].Issue)
+	assert.Equal(t, 6, findings[2].TrkPt)
+
+	assert.Equal(t, gpx.IssueExcessiveSpeed, findings[3].Issue)
+	assert.Equal(t, 7, findings[3].TrkPt)
+	assert.InDelta(t, 60, findings[3].Speed, 1e-6)
+
+	assert.Len(t, g.CheckTimeDistance(gpx.TimeDistanceOptions{MaxZeroTimeDistance: 1000}), 3)
+
+	data, err := json.Marshal(findings[3])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"issue":"excessive-speed"`)
+	assert.Contains(t, string(data), `"trkpt":7`)
+}
@@ -0,0 +1,233 @@
+package gpx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCSVColumns are the columns written by WriteCSV by default.
+var defaultCSVColumns = []string{"lat", "lon", "ele", "time", "name"}
+
+// csvColumns are the columns that WriteCSV can write, and their values.
+var csvColumns = map[string]func(PointKind, *WptType) string{
+	"kind": func(kind PointKind, _ *WptType) string { return kind.String() },
+	"lat":  func(_ PointKind, wpt *WptType) string { return strconv.FormatFloat(wpt.Lat, 'f', -1, 64) },
+	"lon":  func(_ PointKind, wpt *WptType) string { return strconv.FormatFloat(wpt.Lon, 'f', -1, 64) },
+	"ele":  func(_ PointKind, wpt *WptType) string { return formatCSVFloat(wpt, WptEle, wpt.Ele) },
+	"time": func(_ PointKind, wpt *WptType) string {
+		if wpt.Time.IsZero() {
+			return ""
+		}
+		return wpt.Time.UTC().Format(timeLayout)
+	},
+	"name": func(_ PointKind, wpt *WptType) string { return wpt.Name },
+	"cmt":  func(_ PointKind, wpt *WptType) string { return wpt.Cmt },
+	"desc": func(_ PointKind, wpt *WptType) string { return wpt.Desc },
+	"sym":  func(_ PointKind, wpt *WptType) string { return wpt.Sym },
+	"type": func(_ PointKind, wpt *WptType) string { return wpt.Type },
+}
+
+// CSVOptions are options for GPX.WriteCSV.
+type CSVOptions struct {
+	// Columns are the columns to write, from kind, lat, lon, ele, time, name,
+	// cmt, desc, sym, and type. If empty, lat, lon, ele, time, and name are
+	// written.
+	Columns []string
+	// Comma is the field delimiter. If zero, a comma is used.
+	Comma rune
+}
+
+// A CSVMapping maps CSV columns to point fields for ReadCSV. Columns are
+// identified by their headers, ignoring case. Empty column names are not
+// read.
+type CSVMapping struct {
+	Lat  string
+	Lon  string
+	Ele  string
+	Time string
+	Name string
+	Desc string
+	// Kind is the column containing each point's kind, as written by
+	// PointKind.String.
+	Kind string
+	// DefaultKind is the kind of points without a kind column.
+	DefaultKind PointKind
+	// TimeLayout is the layout of times. If empty, RFC 3339 is used.
+	TimeLayout string
+	// Comma is the field delimiter. If zero, a comma is used.
+	Comma rune
+}
+
+// DefaultCSVMapping is the CSVMapping that reads the columns written by
+// WriteCSV, as waypoints if there is no kind column.
+var DefaultCSVMapping = CSVMapping{
+	Lat:  "lat",
+	Lon:  "lon",
+	Ele:  "ele",
+	Time: "time",
+	Name: "name",
+	Desc: "desc",
+	Kind: "kind",
+}
+
+// WriteCSV writes the waypoints, route points, and track points of g to w as
+// CSV with a header row.
+func (g *GPX) WriteCSV(w io.Writer, options CSVOptions) error {
+	columns := options.Columns
+	if len(columns) == 0 {
+		columns = defaultCSVColumns
+	}
+	values := make([]func(PointKind, *WptType) string, len(columns))
+	for i, column := range columns {
+		value, ok := csvColumns[column]
+		if !ok {
+			return fmt.Errorf("%s: unknown column", column)
+		}
+		values[i] = value
+	}
+
+	cw := csv.NewWriter(w)
+	if options.Comma != 0 {
+		cw.Comma = options.Comma
+	}
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	if err := g.Walk(func(kind PointKind, wpt *WptType) error {
+		for i, value := range values {
+			record[i] = value(kind, wpt)
+		}
+		return cw.Write(record)
+	}); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV returns a new GPX 1.1 document containing the points in the CSV read
+// from r, which must have a header row, using mapping. Route points are added
+// to a single route and track points to a single track segment, in order.
+func ReadCSV(r io.Reader, mapping CSVMapping) (*GPX, error) {
+	cr := csv.NewReader(r)
+	if mapping.Comma != 0 {
+		cr.Comma = mapping.Comma
+	}
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	columnIndex := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+		return -1
+	}
+	latIndex, lonIndex := columnIndex(mapping.Lat), columnIndex(mapping.Lon)
+	if latIndex == -1 || lonIndex == -1 {
+		return nil, errors.New("no latitude or longitude column")
+	}
+	eleIndex := columnIndex(mapping.Ele)
+	timeIndex := columnIndex(mapping.Time)
+	nameIndex := columnIndex(mapping.Name)
+	descIndex := columnIndex(mapping.Desc)
+	kindIndex := columnIndex(mapping.Kind)
+	timeLayout := mapping.TimeLayout
+	if timeLayout == "" {
+		timeLayout = time.RFC3339Nano
+	}
+
+	g := &GPX{
+		Version: "1.1",
+	}
+	var rte *RteType
+	var ts *TrkSegType
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return g, nil
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(index int) string {
+			if index == -1 || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		wpt := &WptType{
+			Name: field(nameIndex),
+			Desc: field(descIndex),
+		}
+		if wpt.Lat, err = strconv.ParseFloat(field(latIndex), 64); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if wpt.Lon, err = strconv.ParseFloat(field(lonIndex), 64); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if value := field(eleIndex); value != "" {
+			ele, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			wpt.setEle(ele)
+		}
+		if value := field(timeIndex); value != "" {
+			if wpt.Time, err = time.Parse(timeLayout, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		kind := mapping.DefaultKind
+		switch value := field(kindIndex); value {
+		case "":
+		case PointKindWpt.String():
+			kind = PointKindWpt
+		case PointKindRtePt.String():
+			kind = PointKindRtePt
+		case PointKindTrkPt.String():
+			kind = PointKindTrkPt
+		default:
+			return nil, fmt.Errorf("line %d: %s: unknown point kind", line, value)
+		}
+		switch kind {
+		case PointKindRtePt:
+			if rte == nil {
+				rte = &RteType{}
+				g.Rte = append(g.Rte, rte)
+			}
+			rte.RtePt = append(rte.RtePt, wpt)
+		case PointKindTrkPt:
+			if ts == nil {
+				ts = &TrkSegType{}
+				g.Trk = append(g.Trk, &TrkType{TrkSeg: []*TrkSegType{ts}})
+			}
+			ts.TrkPt = append(ts.TrkPt, wpt)
+		default:
+			g.Wpt = append(g.Wpt, wpt)
+		}
+	}
+}
+
+// formatCSVFloat returns f, the value of wpt's field, formatted for CSV, or an
+// empty string if wpt does not have field.
+func formatCSVFloat(wpt *WptType, field WptFields, f float64) string {
+	if !wpt.Has(field) {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
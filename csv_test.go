@@ -0,0 +1,103 @@
+package gpx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestCSV(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Version: "1.1",
+		Wpt: []*gpx.WptType{
+			{Lat: 47.5, Lon: 7.5, Ele: 260, Name: "Start, finish", Desc: "Car park"},
+		},
+		Rte: []*gpx.RteType{
+			{RtePt: []*gpx.WptType{{Lat: 47.6, Lon: 7.6}}},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 47.5, Lon: 7.5, Ele: 261.5, Time: t0},
+							{Lat: 47.51, Lon: 7.52, Time: t0.Add(time.Second)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, g.WriteCSV(&b, gpx.CSVOptions{}))
+	assert.Equal(t, "lat,lon,ele,time,name\n"+
+		"47.5,7.5,260,,\"Start, finish\"\n"+
+		"47.6,7.6,,,\n"+
+		"47.5,7.5,261.5,2024-05-01T10:00:00Z,\n"+
+		"47.51,7.52,,2024-05-01T10:00:01Z,\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, g.WriteCSV(&b, gpx.CSVOptions{
+		Columns: []string{"kind", "lat", "lon", "ele", "time", "name", "desc"},
+	}))
+	got, err := gpx.ReadCSV(&b, gpx.DefaultCSVMapping)
+	assert.NoError(t, err)
+	assert.Equal(t, g, got)
+
+	assert.Error(t, g.WriteCSV(&b, gpx.CSVOptions{Columns: []string{"unknown"}}))
+}
+
+func TestCSVSeaLevel(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 47.5, Lon: 7.5},
+			{Lat: 47.6, Lon: 7.6},
+		},
+	}
+	g.Wpt[1].SetZero(gpx.WptEle)
+
+	var b bytes.Buffer
+	assert.NoError(t, g.WriteCSV(&b, gpx.CSVOptions{Columns: []string{"lat", "lon", "ele"}}))
+	assert.Equal(t, "lat,lon,ele\n47.5,7.5,\n47.6,7.6,0\n", b.String())
+
+	got, err := gpx.ReadCSV(&b, gpx.DefaultCSVMapping)
+	assert.NoError(t, err)
+	assert.False(t, got.Wpt[0].Has(gpx.WptEle))
+	assert.True(t, got.Wpt[1].Has(gpx.WptEle))
+}
+
+func TestReadCSV(t *testing.T) {
+	got, err := gpx.ReadCSV(strings.NewReader(
+		"Name;Latitude;Longitude;Altitude;Recorded\n"+
+			"A; 47.5;7.5;260;01/05/2024 10:00\n"+
+			"B;47.6;7.6;;\n",
+	), gpx.CSVMapping{
+		Lat:         "latitude",
+		Lon:         "longitude",
+		Ele:         "altitude",
+		Time:        "recorded",
+		Name:        "name",
+		DefaultKind: gpx.PointKindTrkPt,
+		TimeLayout:  "02/01/2006 15:04",
+		Comma:       ';',
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.WptType{
+		{Lat: 47.5, Lon: 7.5, Ele: 260, Name: "A", Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{Lat: 47.6, Lon: 7.6, Name: "B"},
+	}, got.Trk[0].TrkSeg[0].TrkPt)
+
+	_, err = gpx.ReadCSV(strings.NewReader("x,y\n1,2\n"), gpx.DefaultCSVMapping)
+	assert.Error(t, err)
+	_, err = gpx.ReadCSV(strings.NewReader("lat,lon\n1,2\nx,2\n"), gpx.DefaultCSVMapping)
+	assert.EqualError(t, err, `line 3: strconv.ParseFloat: parsing "x": invalid syntax`)
+	_, err = gpx.ReadCSV(strings.NewReader("lat,lon,kind\n1,2,poi\n"), gpx.DefaultCSVMapping)
+	assert.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package gpx
+
+import (
+	"math"
+	"strconv"
+)
+
+// Default cue options.
+const (
+	defaultCueMinTurnAngle = 30
+	defaultCueLookDistance = 20
+)
+
+// A Turn is the kind of a Cue.
+type Turn int
+
+// Turns.
+const (
+	TurnStart Turn = iota
+	TurnSlightLeft
+	TurnLeft
+	TurnSharpLeft
+	TurnSlightRight
+	TurnRight
+	TurnSharpRight
+	TurnUTurn
+	TurnArrive
+)
+
+// CueOptions control Cues.
+type CueOptions struct {
+	// MinTurnAngle is the smallest change of bearing in degrees that is a
+	// turn. If zero, 30 degrees is used.
+	MinTurnAngle float64
+	// LookDistance is the distance in meters before and after each point over
+	// which the bearings into and out of the point are measured, which
+	// smooths GPS noise in dense tracks. Turns closer together than
+	// LookDistance are reported as a single turn. If zero, 20 meters is used.
+	LookDistance float64
+}
+
+// A Cue is an instruction of a cue sheet.
+type Cue struct {
+	Turn Turn `json:"turn"`
+	// Index is the index of the point at which the cue applies.
+	Index int      `json:"index"`
+	Wpt   *WptType `json:"-"`
+	// Angle is the change of bearing in degrees at the point, positive to the
+	// right and negative to the left, in the range (-180, 180].
+	Angle float64 `json:"angle"`
+	// Distance is the distance in meters from the start to the point.
+	Distance float64 `json:"distance"`
+	// DistanceToNext is the distance in meters from the point to the point of
+	// the next cue.
+	DistanceToNext float64 `json:"distanceToNext"`
+}
+
+// Cues returns the cue sheet of r. See TrkSegType.Cues.
+func (r *RteType) Cues(options CueOptions) []Cue {
+	return cues(r.RtePt, options)
+}
+
+// Cues returns the cue sheet of ts: a start cue at its first point, a cue at
+// each point where its bearing changes by at least options.MinTurnAngle, and
+// an arrive cue at its last point. It returns nil if ts has fewer than two
+// points.
+func (ts *TrkSegType) Cues(options CueOptions) []Cue {
+	return cues(ts.TrkPt, options)
+}
+
+// NewCueRteType returns a new RteType with a point for each of cues, named
+// with its instruction and with a comment giving the distance to the next
+// cue.
+func NewCueRteType(cues []Cue) *RteType {
+	rte := &RteType{
+		RtePt: make([]*WptType, 0, len(cues)),
+	}
+	for _, cue := range cues {
+		rtePt := &WptType{
+			Lat:  cue.Wpt.Lat,
+			Lon:  cue.Wpt.Lon,
+			Name: cue.Turn.Instruction(),
+			Type: cue.Turn.String(),
+		}
+		if cue.Turn != TurnArrive {
+			rtePt.Cmt = "Continue for " + formatCueDistance(cue.DistanceToNext)
+		}
+		rte.RtePt = append(rte.RtePt, rtePt)
+	}
+	return rte
+}
+
+// Instruction returns a human-readable instruction for t.
+func (t Turn) Instruction() string {
+	switch t {
+	case TurnStart:
+		return "Start"
+	case TurnSlightLeft:
+		return "Bear left"
+	case TurnLeft:
+		return "Turn left"
+	case TurnSharpLeft:
+		return "Turn sharp left"
+	case TurnSlightRight:
+		return "Bear right"
+	case TurnRight:
+		return "Turn right"
+	case TurnSharpRight:
+		return "Turn sharp right"
+	case TurnUTurn:
+		return "Make a U-turn"
+	case TurnArrive:
+		return "Arrive"
+	default:
+		return "Continue"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.MarshalText.
+func (t Turn) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// String returns a string representation of t.
+func (t Turn) String() string {
+	switch t {
+	case TurnStart:
+		return "start"
+	case TurnSlightLeft:
+		return "slight-left"
+	case TurnLeft:
+		return "left"
+	case TurnSharpLeft:
+		return "sharp-left"
+	case TurnSlightRight:
+		return "slight-right"
+	case TurnRight:
+		return "right"
+	case TurnSharpRight:
+		return "sharp-right"
+	case TurnUTurn:
+		return "u-turn"
+	case TurnArrive:
+		return "arrive"
+	default:
+		return "unknown"
+	}
+}
+
+// cues returns the cue sheet of wpts.
+func cues(wpts []*WptType, options CueOptions) []Cue {
+	n := len(wpts)
+	if n < 2 {
+		return nil
+	}
+	minTurnAngle := options.MinTurnAngle
+	if minTurnAngle == 0 {
+		minTurnAngle = defaultCueMinTurnAngle
+	}
+	lookDistance := options.LookDistance
+	if lookDistance == 0 {
+		lookDistance = defaultCueLookDistance
+	}
+	distances := cumulativeDistances(wpts)
+
+	result := []Cue{
+		{Turn: TurnStart, Index: 0, Wpt: wpts[0]},
+	}
+	var turn *Cue
+	for i, j, k := 1, 0, 1; i < n-1; i++ {
+		// Find the points j before i and k after i at least lookDistance away,
+		// or the first and last points.
+		for j+1 < i && distances[i]-distances[j+1] >= lookDistance {
+			j++
+		}
+		k = max(k, i+1)
+		for k < n-1 && distances[k]-distances[i] < lookDistance {
+			k++
+		}
+		if turn != nil && distances[i]-turn.Distance >= lookDistance {
+			result = append(result, *turn)
+			turn = nil
+		}
+		if distances[i] == distances[j] || distances[k] == distances[i] {
+			continue
+		}
+		in := bearing(wpts[j].Lat, wpts[j].Lon, wpts[i].Lat, wpts[i].Lon)
+		out := bearing(wpts[i].Lat, wpts[i].Lon, wpts[k].Lat, wpts[k].Lon)
+		angle := math.Mod(out-in+540, 360) - 180
+		if angle == -180 {
+			angle = 180
+		}
+		if math.Abs(angle) < minTurnAngle || turn != nil && math.Abs(angle) <= math.Abs(turn.Angle) {
+			continue
+		}
+		turn = &Cue{
+			Turn:     turnForAngle(angle),
+			Index:    i,
+			Wpt:      wpts[i],
+			Angle:    angle,
+			Distance: distances[i],
+		}
+	}
+	if turn != nil {
+		result = append(result, *turn)
+	}
+	result = append(result, Cue{
+		Turn:     TurnArrive,
+		Index:    n - 1,
+		Wpt:      wpts[n-1],
+		Distance: distances[n-1],
+	})
+	for i := range len(result) - 1 {
+		result[i].DistanceToNext = result[i+1].Distance - result[i].Distance
+	}
+	return result
+}
+
+// turnForAngle returns the turn for a change of bearing of angle degrees.
+func turnForAngle(angle float64) Turn {
+	switch a := math.Abs(angle); {
+	case a >= 165:
+		return TurnUTurn
+	case a >= 120 && angle < 0:
+		return TurnSharpLeft
+	case a >= 120:
+		return TurnSharpRight
+	case a >= 60 && angle < 0:
+		return TurnLeft
+	case a >= 60:
+		return TurnRight
+	case angle < 0:
+		return TurnSlightLeft
+	default:
+		return TurnSlightRight
+	}
+}
+
+// formatCueDistance returns a human-readable representation of meters.
+func formatCueDistance(meters float64) string {
+	if meters < 1000 {
+		return strconv.FormatFloat(meters, 'f', 0, 64) + " m"
+	}
+	return strconv.FormatFloat(meters/1000, 'f', 1, 64) + " km"
+}
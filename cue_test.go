@@ -0,0 +1,89 @@
+package gpx_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestCues(t *testing.T) {
+	// Ride north, turn right to ride east, then bear left to ride north east,
+	// with a point about every 11 meters and some noise.
+	ts := &gpx.TrkSegType{}
+	lat, lon := 0.0, 0.0
+	add := func(n int, dLat, dLon float64) {
+		for i := range n {
+			noise := 0.0
+			if i%2 == 1 {
+				noise = 1e-5
+			}
+			lat += dLat
+			lon += dLon
+			ts.TrkPt = append(ts.TrkPt, &gpx.WptType{Lat: lat + noise*dLon, Lon: lon + noise*dLat})
+		}
+	}
+	ts.TrkPt = append(ts.TrkPt, &gpx.WptType{})
+	add(50, 1e-4, 0)
+	add(30, 0, 1e-4)
+	add(20, 1e-4, 1e-4)
+
+	cues := ts.Cues(gpx.CueOptions{})
+	var turns []gpx.Turn
+	var indexes []int
+	for _, cue := range cues {
+		turns = append(turns, cue.Turn)
+		indexes = append(indexes, cue.Index)
+	}
+	assert.Equal(t, []gpx.Turn{gpx.TurnStart, gpx.TurnRight, gpx.TurnSlightLeft, gpx.TurnArrive}, turns)
+	assert.Equal(t, []int{0, 50, 80, 100}, indexes)
+	assert.InDelta(t, 90, cues[1].Angle, 1)
+	assert.InDelta(t, -45, cues[2].Angle, 1)
+	assert.InDelta(t, 556, cues[1].Distance, 1)
+	assert.InDelta(t, 334, cues[1].DistanceToNext, 1)
+	assert.Zero(t, cues[3].DistanceToNext)
+	assert.InDelta(t, ts.Length(nil), cues[3].Distance, 1e-9)
+
+	rte := gpx.NewCueRteType(cues)
+	assert.Len(t, rte.RtePt, 4)
+	assert.Equal(t, "Turn right", rte.RtePt[1].Name)
+	assert.Equal(t, "Continue for 334 m", rte.RtePt[1].Cmt)
+	assert.Equal(t, "Continue for 556 m", rte.RtePt[0].Cmt)
+	assert.Equal(t, "Arrive", rte.RtePt[3].Name)
+	assert.Empty(t, rte.RtePt[3].Cmt)
+
+	data, err := json.Marshal(cues[1])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"turn":"right"`)
+
+	assert.Nil(t, (&gpx.TrkSegType{TrkPt: ts.TrkPt[:1]}).Cues(gpx.CueOptions{}))
+}
+
+func TestCuesRte(t *testing.T) {
+	for i, tc := range []struct {
+		dLat, dLon float64
+		expected   gpx.Turn
+	}{
+		{dLat: 0.01, dLon: 0.001, expected: gpx.TurnStart},
+		{dLat: 0.01, dLon: 0.01, expected: gpx.TurnSlightRight},
+		{dLat: 0, dLon: -0.01, expected: gpx.TurnLeft},
+		{dLat: -0.01, dLon: 0.005, expected: gpx.TurnSharpRight},
+		{dLat: -0.01, dLon: 0, expected: gpx.TurnUTurn},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rte := &gpx.RteType{
+				RtePt: []*gpx.WptType{
+					{Lat: 0, Lon: 0},
+					{Lat: 0.01, Lon: 0},
+					{Lat: 0.01 + tc.dLat, Lon: tc.dLon},
+				},
+			}
+			cues := rte.Cues(gpx.CueOptions{})
+			assert.Equal(t, tc.expected, cues[len(cues)-2].Turn)
+			assert.Regexp(t, `^Continue for \d\.\d km$`, gpx.NewCueRteType(cues).RtePt[0].Cmt)
+		})
+	}
+}
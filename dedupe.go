@@ -0,0 +1,146 @@
+package gpx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DedupeOptions are options for GPX.DedupeWaypoints.
+type DedupeOptions struct {
+	// MaxDistance is the largest distance in meters between duplicate
+	// waypoints.
+	MaxDistance float64
+	// MinNameSimilarity is the smallest similarity of the names of duplicate
+	// waypoints, from 0 to 1, where 1 means that the names are the same
+	// ignoring case, spaces, and punctuation. If zero, names are ignored.
+	MinNameSimilarity float64
+}
+
+// DedupeWaypoints merges duplicate waypoints in g, keeping the first of each
+// group of duplicates. Waypoints are duplicates if they are within
+// options.MaxDistance meters of each other and their names are similar,
+// either directly or through other duplicates. Empty fields of the kept
+// waypoint are filled in from its duplicates and their links are added. It
+// returns the number of waypoints removed.
+func (g *GPX) DedupeWaypoints(options DedupeOptions) int {
+	if len(g.Wpt) < 2 {
+		return 0
+	}
+	parents := make([]int, len(g.Wpt))
+	for i := range parents {
+		parents[i] = i
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parents[i] != i {
+			parents[i] = root(parents[i])
+		}
+		return parents[i]
+	}
+
+	names := make([]string, len(g.Wpt))
+	for i, wpt := range g.Wpt {
+		names[i] = normalizeName(wpt.Name)
+	}
+	index := NewIndex(&GPX{Wpt: g.Wpt})
+	for i, wpt := range g.Wpt {
+		for _, entry := range index.Radius(wpt.Lat, wpt.Lon, options.MaxDistance) {
+			j := entry.Index
+			if j <= i || root(i) == root(j) {
+				continue
+			}
+			if options.MinNameSimilarity > 0 && nameSimilarity(names[i], names[j]) < options.MinNameSimilarity {
+				continue
+			}
+			// Keep the earliest waypoint as the root.
+			ri, rj := root(i), root(j)
+			parents[max(ri, rj)] = min(ri, rj)
+		}
+	}
+
+	wpts := g.Wpt[:0:0]
+	for i, wpt := range g.Wpt {
+		if r := root(i); r != i {
+			mergeWpt(g.Wpt[r], wpt)
+			continue
+		}
+		wpts = append(wpts, wpt)
+	}
+	n := len(g.Wpt) - len(wpts)
+	g.Wpt = wpts
+	return n
+}
+
+// mergeWpt fills in the empty fields of dst from src and adds src's links
+// that dst does not already have.
+func mergeWpt(dst, src *WptType) {
+	if !dst.Has(WptEle) && src.Has(WptEle) {
+		dst.setEle(src.Ele)
+	}
+	if dst.Time.IsZero() {
+		dst.Time = src.Time
+	}
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&dst.Name, src.Name},
+		{&dst.Cmt, src.Cmt},
+		{&dst.Desc, src.Desc},
+		{&dst.Src, src.Src},
+		{&dst.Sym, src.Sym},
+		{&dst.Type, src.Type},
+	} {
+		if *field.dst == "" {
+			*field.dst = field.src
+		}
+	}
+LINKS:
+	for _, link := range src.Link {
+		for _, dstLink := range dst.Link {
+			if dstLink.HREF == link.HREF {
+				continue LINKS
+			}
+		}
+		dst.Link = append(dst.Link, link)
+	}
+	if dst.Extensions == nil {
+		dst.Extensions = src.Extensions
+	}
+}
+
+// normalizeName returns name in lower case without spaces or punctuation.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// nameSimilarity returns the similarity of a and b, from 0 to 1, based on
+// their Levenshtein distance.
+func nameSimilarity(a, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 && len(br) == 0 {
+		return 1
+	}
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range ar {
+		curr[0] = i + 1
+		for j := range br {
+			cost := 1
+			if ar[i] == br[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j]+cost, prev[j+1]+1, curr[j]+1)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(br)])/float64(max(len(ar), len(br)))
+}
@@ -0,0 +1,54 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestDedupeWaypoints(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newGPX := func() *gpx.GPX {
+		return &gpx.GPX{
+			Wpt: []*gpx.WptType{
+				{Lat: 47, Lon: 7, Name: "Café Central"},
+				{Lat: 47.0001, Lon: 7, Name: "cafe central", Ele: 400, Link: []*gpx.LinkType{{HREF: "https://example.com/a"}}},
+				{Lat: 47.0002, Lon: 7, Name: "Bakery"},
+				{Lat: 47.5, Lon: 7, Name: "Café Central"},
+				{Lat: 47.00015, Lon: 7, Name: "Cafe Central!", Time: t0, Link: []*gpx.LinkType{{HREF: "https://example.com/a"}, {HREF: "https://example.com/b"}}},
+			},
+		}
+	}
+
+	g := newGPX()
+	assert.Equal(t, 2, g.DedupeWaypoints(gpx.DedupeOptions{
+		MaxDistance:       50,
+		MinNameSimilarity: 0.8,
+	}))
+	assert.Len(t, g.Wpt, 3)
+	assert.Equal(t, &gpx.WptType{
+		Lat:  47,
+		Lon:  7,
+		Ele:  400,
+		Time: t0,
+		Name: "Café Central",
+		Link: []*gpx.LinkType{{HREF: "https://example.com/a"}, {HREF: "https://example.com/b"}},
+	}, g.Wpt[0])
+	assert.Equal(t, "Bakery", g.Wpt[1].Name)
+	assert.Equal(t, 47.5, g.Wpt[2].Lat)
+
+	g = newGPX()
+	assert.Equal(t, 3, g.DedupeWaypoints(gpx.DedupeOptions{
+		MaxDistance: 50,
+	}))
+	assert.Len(t, g.Wpt, 2)
+
+	g = newGPX()
+	assert.Equal(t, 0, g.DedupeWaypoints(gpx.DedupeOptions{
+		MaxDistance:       5,
+		MinNameSimilarity: 0.8,
+	}))
+}
@@ -0,0 +1,151 @@
+package gpx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// defaultEffortTolerance is the default tolerance of EffortOptions.
+const defaultEffortTolerance = 25
+
+// EffortOptions control TrkType.Efforts.
+type EffortOptions struct {
+	// Tolerance is the largest distance in meters between an effort and the
+	// reference segment. It allows for GPS errors. If zero, 25 meters is used.
+	Tolerance float64
+}
+
+// An Effort is a traversal of a reference segment by a track, like a segment
+// effort on Strava.
+type Effort struct {
+	// TrkSeg is the index of the track segment containing the effort.
+	TrkSeg int
+	// Start and End are the indexes of the track points at the start of the
+	// line segments on which the effort starts and ends.
+	Start int
+	End   int
+	// StartTime and EndTime are the interpolated times at which the track
+	// passes closest to the start and end of the reference segment.
+	StartTime time.Time
+	EndTime   time.Time
+	// Elapsed is the elapsed time of the effort.
+	Elapsed time.Duration
+	// Distance is the distance in meters traveled during the effort.
+	Distance float64
+}
+
+// effortEndpoint is a point on a track segment where it passes close to the
+// start or end of a reference segment.
+type effortEndpoint struct {
+	index int
+	f     float64
+	wpt   *WptType
+}
+
+// after returns whether e is after other along the track segment.
+func (e effortEndpoint) after(other effortEndpoint) bool {
+	return e.index > other.index || e.index == other.index && e.f > other.f
+}
+
+// Efforts returns all traversals of reference by t, in order. A traversal
+// starts and ends where t passes closest to the start and end of reference,
+// stays within options.Tolerance of reference, and passes within
+// options.Tolerance of every point of reference, so shortcuts and partial
+// traversals are not efforts. Efforts do not span track segments and do not
+// overlap. It returns an error wrapping ErrNonMonotonicTime if a track
+// segment's times are not valid, see TrkSegType.ValidateTimes.
+func (t *TrkType) Efforts(reference *TrkSegType, options EffortOptions) ([]Effort, error) {
+	if len(reference.TrkPt) < 2 {
+		return nil, nil
+	}
+	tolerance := options.Tolerance
+	if tolerance == 0 {
+		tolerance = defaultEffortTolerance
+	}
+	refStart, refEnd := reference.TrkPt[0], reference.TrkPt[len(reference.TrkPt)-1]
+
+	var efforts []Effort
+	for i, ts := range t.TrkSeg {
+		if err := ts.ValidateTimes(); err != nil {
+			return nil, fmt.Errorf("trkseg %d: %w", i, err)
+		}
+		starts := ts.effortEndpoints(refStart, tolerance)
+		ends := ts.effortEndpoints(refEnd, tolerance)
+		var prevEnd *effortEndpoint
+	STARTS:
+		for _, start := range starts {
+			if prevEnd != nil && !start.after(*prevEnd) {
+				continue
+			}
+			for _, end := range ends {
+				if !end.after(start) {
+					continue
+				}
+				path := make([]*WptType, 0, end.index-start.index+2)
+				path = append(path, start.wpt)
+				path = append(path, ts.TrkPt[start.index+1:end.index+1]...)
+				path = append(path, end.wpt)
+				if !isEffort(path, reference.TrkPt, tolerance) {
+					continue STARTS
+				}
+				efforts = append(efforts, Effort{
+					TrkSeg:    i,
+					Start:     start.index,
+					End:       end.index,
+					StartTime: start.wpt.Time,
+					EndTime:   end.wpt.Time,
+					Elapsed:   end.wpt.Time.Sub(start.wpt.Time),
+					Distance:  length(path, nil),
+				})
+				prevEnd = &end
+				continue STARTS
+			}
+		}
+	}
+	return efforts, nil
+}
+
+// effortEndpoints returns the points where ts passes closest to wpt in each
+// of its passes within tolerance meters of wpt.
+func (ts *TrkSegType) effortEndpoints(wpt *WptType, tolerance float64) []effortEndpoint {
+	var endpoints []effortEndpoint
+	var best *effortEndpoint
+	bestDistance := math.Inf(1)
+	for j := 0; j+1 < len(ts.TrkPt); j++ {
+		a, b := ts.TrkPt[j], ts.TrkPt[j+1]
+		f := segmentFraction(a, b, wpt.Lat, wpt.Lon)
+		nearest := interpolate(a, b, f)
+		distance := HaversineDistance(wpt.Lat, wpt.Lon, nearest.Lat, nearest.Lon)
+		switch {
+		case distance > tolerance:
+			if best != nil {
+				endpoints = append(endpoints, *best)
+				best, bestDistance = nil, math.Inf(1)
+			}
+		case distance < bestDistance:
+			best = &effortEndpoint{index: j, f: f, wpt: nearest}
+			bestDistance = distance
+		}
+	}
+	if best != nil {
+		endpoints = append(endpoints, *best)
+	}
+	return endpoints
+}
+
+// isEffort returns whether every point of path is within tolerance meters of
+// reference and every point of reference is within tolerance meters of path.
+func isEffort(path, reference []*WptType, tolerance float64) bool {
+	for _, wpt := range path {
+		if distanceToPath(reference, wpt.Lat, wpt.Lon) > tolerance {
+			return false
+		}
+	}
+	for _, wpt := range reference {
+		if distanceToPath(path, wpt.Lat, wpt.Lon) > tolerance {
+			return false
+		}
+	}
+	return true
+}
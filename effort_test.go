@@ -0,0 +1,67 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestTrkTypeEfforts(t *testing.T) {
+	reference := &gpx.TrkSegType{}
+	for i := 0; i <= 10; i++ {
+		reference.TrkPt = append(reference.TrkPt, &gpx.WptType{Lat: 46 + float64(i)*1e-3, Lon: 7})
+	}
+
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ts := &gpx.TrkSegType{}
+	tm := t0
+	add := func(lat, lon float64, step time.Duration) {
+		ts.TrkPt = append(ts.TrkPt, &gpx.WptType{Lat: lat, Lon: lon, Time: tm})
+		tm = tm.Add(step)
+	}
+	// Ride north along the reference at 10 seconds per point, starting and
+	// ending half way between points, then return south on another road.
+	for i := -2; i <= 12; i++ {
+		add(46+(float64(i)+0.5)*1e-3, 7, 10*time.Second)
+	}
+	add(46.0125, 7.01, time.Minute)
+	add(45.9975, 7.01, time.Minute)
+	// Ride the reference again at 5 seconds per point.
+	for i := -2; i <= 12; i++ {
+		add(46+(float64(i)+0.5)*1e-3, 7, 5*time.Second)
+	}
+	add(46.0125, 7.01, time.Minute)
+	add(45.9975, 7.01, time.Minute)
+	// Ride half of the reference, then turn off.
+	for i := -2; i <= 5; i++ {
+		add(46+(float64(i)+0.5)*1e-3, 7, 5*time.Second)
+	}
+	add(46.0055, 7.01, time.Minute)
+	add(46.0125, 7.01, time.Minute)
+	add(46.0125, 7, time.Minute)
+
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{ts},
+	}
+	efforts, err := trk.Efforts(reference, gpx.EffortOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, efforts, 2)
+	assert.InDelta(t, 100, efforts[0].Elapsed.Seconds(), 1e-6)
+	assert.WithinDuration(t, t0.Add(15*time.Second), efforts[0].StartTime, time.Microsecond)
+	assert.Equal(t, 1, efforts[0].Start)
+	assert.Equal(t, 11, efforts[0].End)
+	assert.InDelta(t, reference.Length(nil), efforts[0].Distance, 1e-6)
+	assert.InDelta(t, 50, efforts[1].Elapsed.Seconds(), 1e-6)
+	assert.Equal(t, 0, efforts[1].TrkSeg)
+
+	efforts, err = trk.Efforts(&gpx.TrkSegType{}, gpx.EffortOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, efforts)
+
+	ts.TrkPt[3].Time = time.Time{}
+	_, err = trk.Efforts(reference, gpx.EffortOptions{})
+	assert.ErrorIs(t, err, gpx.ErrNonMonotonicTime)
+}
@@ -0,0 +1,172 @@
+package gpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+)
+
+// FillWaypointElevations sets the elevation of each waypoint in g that has no
+// elevation to the elevation of the nearest track point with an elevation, if
+// that track point is within maxDistance meters. It returns the number of
+// waypoints whose elevation was set.
+func FillWaypointElevations(g *GPX, maxDistance float64) int {
+	n := 0
+	for _, wpt := range g.Wpt {
+		if wpt.Has(WptEle) {
+			continue
+		}
+		var nearest *WptType
+		nearestDistance := maxDistance
+		for _, trk := range g.Trk {
+			for _, trkSeg := range trk.TrkSeg {
+				for _, trkPt := range trkSeg.TrkPt {
+					if !trkPt.Has(WptEle) {
+						continue
+					}
+					if distance := HaversineDistance(wpt.Lat, wpt.Lon, trkPt.Lat, trkPt.Lon); distance <= nearestDistance {
+						nearest = trkPt
+						nearestDistance = distance
+					}
+				}
+			}
+		}
+		if nearest != nil {
+			wpt.setEle(nearest.Ele)
+			n++
+		}
+	}
+	return n
+}
+
+// defaultOpenElevationURL is the URL of the public Open-Elevation lookup API.
+const defaultOpenElevationURL = "https://api.open-elevation.com/api/v1/lookup"
+
+// defaultOpenElevationBatchSize is the default number of points per
+// Open-Elevation request.
+const defaultOpenElevationBatchSize = 100
+
+// An ElevationProvider provides the elevations of points.
+type ElevationProvider interface {
+	// Elevations returns the elevation in meters of each of wpts, or NaN
+	// where the elevation is not known.
+	Elevations(ctx context.Context, wpts []*WptType) ([]float64, error)
+}
+
+// FillElevations sets the elevation of each point in g that has no elevation
+// or an elevation of zero to its elevation from provider, requesting all such
+// points at once. Zero elevations are treated as missing because route
+// planners often write them for points whose elevations they do not know. It
+// returns the number of points whose elevation was set.
+func (g *GPX) FillElevations(ctx context.Context, provider ElevationProvider) (int, error) {
+	var wpts []*WptType
+	for wpt := range g.Points() {
+		if !wpt.Has(WptEle) || wpt.Ele == 0 {
+			wpts = append(wpts, wpt)
+		}
+	}
+	if len(wpts) == 0 {
+		return 0, nil
+	}
+	elevations, err := provider.Elevations(ctx, wpts)
+	if err != nil {
+		return 0, err
+	}
+	if len(elevations) != len(wpts) {
+		return 0, fmt.Errorf("got %d elevations, want %d", len(elevations), len(wpts))
+	}
+	n := 0
+	for i, elevation := range elevations {
+		if !math.IsNaN(elevation) {
+			wpts[i].setEle(elevation)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// An OpenElevation is an ElevationProvider that uses an Open-Elevation
+// compatible lookup API.
+type OpenElevation struct {
+	// URL is the URL of the lookup endpoint. If empty, the public
+	// Open-Elevation API is used.
+	URL string
+	// Client is the HTTP client. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// BatchSize is the maximum number of points per request. If zero, 100
+	// is used.
+	BatchSize int
+}
+
+type openElevationLocation struct {
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Elevation *float64 `json:"elevation,omitempty"`
+}
+
+// Elevations implements ElevationProvider.
+func (o *OpenElevation) Elevations(ctx context.Context, wpts []*WptType) ([]float64, error) {
+	url := o.URL
+	if url == "" {
+		url = defaultOpenElevationURL
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	batchSize := o.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultOpenElevationBatchSize
+	}
+	elevations := make([]float64, 0, len(wpts))
+	for batch := range slices.Chunk(wpts, batchSize) {
+		var request struct {
+			Locations []openElevationLocation `json:"locations"`
+		}
+		for _, wpt := range batch {
+			request.Locations = append(request.Locations, openElevationLocation{Latitude: wpt.Lat, Longitude: wpt.Lon})
+		}
+		body, err := json.Marshal(&request)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var response struct {
+			Results []openElevationLocation `json:"results"`
+		}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("%s: %s", url, resp.Status)
+			}
+			return json.NewDecoder(resp.Body).Decode(&response)
+		}()
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Results) != len(batch) {
+			return nil, fmt.Errorf("%s: got %d results, want %d", url, len(response.Results), len(batch))
+		}
+		for _, result := range response.Results {
+			if result.Elevation == nil {
+				elevations = append(elevations, math.NaN())
+			} else {
+				elevations = append(elevations, *result.Elevation)
+			}
+		}
+	}
+	return elevations, nil
+}
@@ -0,0 +1,131 @@
+package gpx_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFillWaypointElevations(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 46.0001, Lon: 7},
+			{Lat: 46, Lon: 7.0101},
+			{Lat: 47, Lon: 8},
+			{Lat: 46, Lon: 7, Ele: 500},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 46, Lon: 7, Ele: 1000},
+							{Lat: 46, Lon: 7.005},
+							{Lat: 46, Lon: 7.01, Ele: 1100},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, 2, gpx.FillWaypointElevations(g, 100))
+	assert.Equal(t, 1000.0, g.Wpt[0].Ele)
+	assert.Equal(t, 1100.0, g.Wpt[1].Ele)
+	assert.Equal(t, 0.0, g.Wpt[2].Ele)
+	assert.Equal(t, 500.0, g.Wpt[3].Ele)
+}
+
+type testElevationProvider func(lat, lon float64) float64
+
+func (p testElevationProvider) Elevations(_ context.Context, wpts []*gpx.WptType) ([]float64, error) {
+	elevations := make([]float64, len(wpts))
+	for i, wpt := range wpts {
+		elevations[i] = p(wpt.Lat, wpt.Lon)
+	}
+	return elevations, nil
+}
+
+func TestFillElevations(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 1, Lon: 1},
+			{Lat: 2, Lon: 2, Ele: 5},
+			{Lat: 4, Lon: 4, ZeroFields: gpx.WptEle},
+		},
+		Rte: []*gpx.RteType{
+			{RtePt: []*gpx.WptType{{Lat: 3, Lon: 3}}},
+		},
+		Trk: []*gpx.TrkType{
+			{TrkSeg: []*gpx.TrkSegType{{TrkPt: []*gpx.WptType{{Lat: -1, Lon: -1}}}}},
+		},
+	}
+	n, err := g.FillElevations(context.Background(), testElevationProvider(func(lat, _ float64) float64 {
+		if lat < 0 {
+			return math.NaN()
+		}
+		return 100 * lat
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 100.0, g.Wpt[0].Ele)
+	assert.Equal(t, 5.0, g.Wpt[1].Ele)
+	assert.Equal(t, 400.0, g.Wpt[2].Ele)
+	assert.Equal(t, 300.0, g.Rte[0].RtePt[0].Ele)
+	assert.Equal(t, 0.0, g.Trk[0].TrkSeg[0].TrkPt[0].Ele)
+}
+
+func TestOpenElevation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, http.MethodPost, r.Method)
+		var request struct {
+			Locations []map[string]float64 `json:"locations"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		results := make([]map[string]any, 0, len(request.Locations))
+		for _, location := range request.Locations {
+			result := map[string]any{
+				"latitude":  location["latitude"],
+				"longitude": location["longitude"],
+			}
+			if location["latitude"] >= 0 {
+				result["elevation"] = 10 * location["latitude"]
+			}
+			results = append(results, result)
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"results": results}))
+	}))
+	defer server.Close()
+
+	provider := &gpx.OpenElevation{
+		URL:       server.URL,
+		Client:    server.Client(),
+		BatchSize: 2,
+	}
+	got, err := provider.Elevations(context.Background(), []*gpx.WptType{
+		{Lat: 1, Lon: 1},
+		{Lat: 2, Lon: 2},
+		{Lat: -3, Lon: 3},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, got, 3)
+	assert.Equal(t, 10.0, got[0])
+	assert.Equal(t, 20.0, got[1])
+	assert.True(t, math.IsNaN(got[2]))
+
+	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer errorServer.Close()
+	_, err = (&gpx.OpenElevation{URL: errorServer.URL}).Elevations(context.Background(), []*gpx.WptType{{}})
+	assert.Error(t, err)
+}
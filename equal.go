@@ -0,0 +1,271 @@
+package gpx
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EqualOptions control GPX.Equal. The zero value compares documents exactly.
+type EqualOptions struct {
+	// CoordinateEpsilon is the largest difference in degrees between
+	// latitudes or longitudes that are considered equal.
+	CoordinateEpsilon float64
+	// EleEpsilon is the largest difference in meters between elevations that
+	// are considered equal.
+	EleEpsilon float64
+	// TimeRounding, if positive, is the duration to which times are rounded
+	// before they are compared.
+	TimeRounding time.Duration
+	// IgnoreExtensions ignores all extensions.
+	IgnoreExtensions bool
+}
+
+// A differ collects the differences between two documents.
+type differ struct {
+	options EqualOptions
+	// quick stops at the first difference without describing it.
+	quick       bool
+	differences []string
+	different   bool
+}
+
+// Equal returns whether g and other are equal within the tolerances of
+// options. Times are compared as instants, regardless of their location.
+func (g *GPX) Equal(other *GPX, options EqualOptions) bool {
+	d := &differ{
+		options: options,
+		quick:   true,
+	}
+	d.gpx(g, other)
+	return !d.different
+}
+
+// Diff returns a human-readable summary of the changes from g to other, one
+// change per line. It returns nil if g and other are exactly equal.
+func (g *GPX) Diff(other *GPX) []string {
+	d := &differ{}
+	d.gpx(g, other)
+	return d.differences
+}
+
+// addf records a difference at path.
+func (d *differ) addf(path, format string, args ...any) {
+	d.different = true
+	if d.quick {
+		return
+	}
+	d.differences = append(d.differences, path+": "+fmt.Sprintf(format, args...))
+}
+
+// done returns whether d can stop comparing.
+func (d *differ) done() bool {
+	return d.quick && d.different
+}
+
+func (d *differ) gpx(a, b *GPX) {
+	aGPX, bGPX := *a, *b
+	aGPX.Metadata, bGPX.Metadata = nil, nil
+	aGPX.Wpt, bGPX.Wpt = nil, nil
+	aGPX.Rte, bGPX.Rte = nil, nil
+	aGPX.Trk, bGPX.Trk = nil, nil
+	d.extensions(&aGPX.Extensions, &bGPX.Extensions)
+	d.fields("gpx", aGPX, bGPX)
+
+	switch {
+	case a.Metadata == nil && b.Metadata != nil:
+		d.addf("metadata", "added")
+	case a.Metadata != nil && b.Metadata == nil:
+		d.addf("metadata", "removed")
+	case a.Metadata != nil && b.Metadata != nil:
+		aMetadata, bMetadata := *a.Metadata, *b.Metadata
+		aMetadata.Time, bMetadata.Time = d.time(aMetadata.Time), d.time(bMetadata.Time)
+		d.extensions(&aMetadata.Extensions, &bMetadata.Extensions)
+		d.fields("metadata", aMetadata, bMetadata)
+	}
+
+	d.points("wpt", a.Wpt, b.Wpt)
+
+	for i := range max(len(a.Rte), len(b.Rte)) {
+		if d.done() {
+			return
+		}
+		path := "rte[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(a.Rte):
+			d.addf(path, "added with %d points", len(b.Rte[i].RtePt))
+		case i >= len(b.Rte):
+			d.addf(path, "removed")
+		default:
+			aRte, bRte := *a.Rte[i], *b.Rte[i]
+			aRte.RtePt, bRte.RtePt = nil, nil
+			d.extensions(&aRte.Extensions, &bRte.Extensions)
+			d.fields(path, aRte, bRte)
+			d.points(path+".rtept", a.Rte[i].RtePt, b.Rte[i].RtePt)
+		}
+	}
+
+	for i := range max(len(a.Trk), len(b.Trk)) {
+		if d.done() {
+			return
+		}
+		path := "trk[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(a.Trk):
+			d.addf(path, "added with %d segments", len(b.Trk[i].TrkSeg))
+		case i >= len(b.Trk):
+			d.addf(path, "removed")
+		default:
+			d.trk(path, a.Trk[i], b.Trk[i])
+		}
+	}
+}
+
+func (d *differ) trk(path string, a, b *TrkType) {
+	aTrk, bTrk := *a, *b
+	aTrk.TrkSeg, bTrk.TrkSeg = nil, nil
+	d.extensions(&aTrk.Extensions, &bTrk.Extensions)
+	d.fields(path, aTrk, bTrk)
+	for i := range max(len(a.TrkSeg), len(b.TrkSeg)) {
+		if d.done() {
+			return
+		}
+		segPath := path + ".trkseg[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(a.TrkSeg):
+			d.addf(segPath, "added with %d points", len(b.TrkSeg[i].TrkPt))
+		case i >= len(b.TrkSeg):
+			d.addf(segPath, "removed")
+		default:
+			aExtensions, bExtensions := a.TrkSeg[i].Extensions, b.TrkSeg[i].Extensions
+			d.extensions(&aExtensions, &bExtensions)
+			if !reflect.DeepEqual(aExtensions, bExtensions) {
+				d.addf(segPath, "extensions changed")
+			}
+			d.points(segPath+".trkpt", a.TrkSeg[i].TrkPt, b.TrkSeg[i].TrkPt)
+		}
+	}
+}
+
+// points records the differences between the point lists a and b. Lists of
+// the same length are compared point by point, otherwise the numbers of
+// inserted and deleted points are recorded.
+func (d *differ) points(path string, a, b []*WptType) {
+	if len(a) == len(b) {
+		for i := range a {
+			if d.done() {
+				return
+			}
+			d.wpt(path+"["+strconv.Itoa(i)+"]", a[i], b[i])
+		}
+		return
+	}
+	if d.quick {
+		d.different = true
+		return
+	}
+	inserted, deleted := 0, 0
+	for _, op := range appendPointListDiffFunc(nil, path, a, b, d.equalWpt) {
+		switch op.Op {
+		case PatchOpInsert:
+			inserted++
+		case PatchOpDelete:
+			deleted++
+		}
+	}
+	d.addf(path, "%d points inserted, %d points deleted", inserted, deleted)
+}
+
+// equalWpt returns whether a and b are equal.
+func (d *differ) equalWpt(a, b *WptType) bool {
+	e := &differ{
+		options: d.options,
+		quick:   true,
+	}
+	e.wpt("", a, b)
+	return !e.different
+}
+
+func (d *differ) wpt(path string, a, b *WptType) {
+	aWpt, bWpt := *a, *b
+	if math.Abs(aWpt.Lat-bWpt.Lat) <= d.options.CoordinateEpsilon {
+		bWpt.Lat = aWpt.Lat
+	}
+	if math.Abs(normalizeLon(aWpt.Lon-bWpt.Lon)) <= d.options.CoordinateEpsilon {
+		bWpt.Lon = aWpt.Lon
+	}
+	if math.Abs(aWpt.Ele-bWpt.Ele) <= d.options.EleEpsilon {
+		bWpt.Ele = aWpt.Ele
+	}
+	aWpt.Time, bWpt.Time = d.time(aWpt.Time), d.time(bWpt.Time)
+	d.extensions(&aWpt.Extensions, &bWpt.Extensions)
+	d.fields(path, aWpt, bWpt)
+}
+
+// extensions clears a and b if extensions are ignored.
+func (d *differ) extensions(a, b **ExtensionsType) {
+	if d.options.IgnoreExtensions {
+		*a, *b = nil, nil
+	}
+}
+
+// time returns t normalized for comparison.
+func (d *differ) time(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+	if d.options.TimeRounding > 0 {
+		t = t.Round(d.options.TimeRounding)
+	}
+	return t.UTC()
+}
+
+// fields records the differences between the fields of the structs a and
+// b, which must have the same type. Fields are named by their JSON names.
+func (d *differ) fields(path string, a, b any) {
+	aValue, bValue := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range aValue.NumField() {
+		aField, bField := aValue.Field(i).Interface(), bValue.Field(i).Interface()
+		if reflect.DeepEqual(aField, bField) {
+			continue
+		}
+		if d.quick {
+			d.different = true
+			return
+		}
+		name := diffFieldName(aValue.Type().Field(i))
+		switch aValue.Field(i).Kind() {
+		case reflect.Map, reflect.Pointer, reflect.Slice:
+			d.addf(path, "%s changed", name)
+		default:
+			d.addf(path, "%s changed from %s to %s", name, diffValue(aField), diffValue(bField))
+		}
+	}
+}
+
+// diffFieldName returns the name of field in diffs.
+func diffFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// diffValue returns the representation of value in diffs.
+func diffValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return strconv.Quote(value)
+	case time.Time:
+		if value.IsZero() {
+			return "none"
+		}
+		return value.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
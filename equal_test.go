@@ -0,0 +1,143 @@
+package gpx_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestEqual(t *testing.T) {
+	newGPX := func() *gpx.GPX {
+		return &gpx.GPX{
+			Version: "1.1",
+			Creator: "test",
+			Wpt: []*gpx.WptType{
+				{
+					Lat:        1,
+					Lon:        2,
+					Ele:        3,
+					Time:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+					Extensions: &gpx.ExtensionsType{XML: []byte("<hr>120</hr>")},
+				},
+			},
+		}
+	}
+
+	for i, tc := range []struct {
+		edit     func(*gpx.GPX)
+		options  gpx.EqualOptions
+		expected bool
+	}{
+		{
+			edit:     func(*gpx.GPX) {},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Lat += 1e-7
+			},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Lat += 1e-7
+				g.Wpt[0].Lon -= 1e-7
+			},
+			options:  gpx.EqualOptions{CoordinateEpsilon: 1e-6},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Ele += 0.5
+			},
+			options:  gpx.EqualOptions{CoordinateEpsilon: 1e-6},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Ele += 0.5
+			},
+			options:  gpx.EqualOptions{EleEpsilon: 1},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Time = g.Wpt[0].Time.In(time.FixedZone("CEST", 2*60*60))
+			},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Time = g.Wpt[0].Time.Add(100 * time.Millisecond)
+			},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Time = g.Wpt[0].Time.Add(100 * time.Millisecond)
+			},
+			options:  gpx.EqualOptions{TimeRounding: time.Second},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Extensions = nil
+			},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt[0].Extensions = nil
+			},
+			options:  gpx.EqualOptions{IgnoreExtensions: true},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt = append(g.Wpt, &gpx.WptType{Lat: 3, Lon: 4})
+			},
+			options:  gpx.EqualOptions{CoordinateEpsilon: 1},
+			expected: false,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, other := newGPX(), newGPX()
+			tc.edit(other)
+			assert.Equal(t, tc.expected, g.Equal(other, tc.options))
+			assert.Equal(t, tc.expected, other.Equal(g, tc.options))
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	read := func() *gpx.GPX {
+		f, err := os.Open("testdata/mystic_basin_trail.gpx")
+		assert.NoError(t, err)
+		defer f.Close()
+		g, err := gpx.Read(f)
+		assert.NoError(t, err)
+		return g
+	}
+
+	g, other := read(), read()
+	assert.Nil(t, g.Diff(other))
+
+	other.Metadata.Name = "Renamed"
+	other.Wpt[0].Ele = 1234
+	other.Wpt[0].Time = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ts := other.Trk[0].TrkSeg[0]
+	ts.TrkPt = append(ts.TrkPt[:10:10], ts.TrkPt[12:]...)
+	other.Trk = append(other.Trk, &gpx.TrkType{})
+	assert.Equal(t, []string{
+		`metadata: name changed from "` + g.Metadata.Name + `" to "Renamed"`,
+		"wpt[0]: ele changed from " + strconv.FormatFloat(g.Wpt[0].Ele, 'f', -1, 64) + " to 1234",
+		"wpt[0]: time changed from 2002-03-12T18:36:28Z to 2024-05-01T10:00:00Z",
+		"trk[0].trkseg[0].trkpt: 0 points inserted, 2 points deleted",
+		"trk[3]: added with 0 segments",
+	}, g.Diff(other))
+}
@@ -0,0 +1,148 @@
+package gpx
+
+import (
+	"encoding/csv"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+// An ExportActivity is an activity from a bulk export, such as those produced
+// by Strava and RideWithGPS.
+type ExportActivity struct {
+	// Name is the path of the activity's file within the export.
+	Name string
+	// Metadata is the activity's row from the export's activities.csv, keyed
+	// by column header, or nil if there is no such row.
+	Metadata map[string]string
+	// GPX is the activity's track, or nil if the activity's file is in a
+	// format other than GPX, such as TCX or FIT.
+	GPX *GPX
+}
+
+// exportActivityExts are the lowercase extensions of activity files in bulk
+// exports, and whether they are GPX files.
+var exportActivityExts = []struct {
+	ext   string
+	isGPX bool
+}{
+	{".gpx", true},
+	{".gpx.gz", true},
+	{".tcx", false},
+	{".tcx.gz", false},
+	{".fit", false},
+	{".fit.gz", false},
+}
+
+// WalkExport calls fn for each activity file in the bulk export fsys, in
+// lexical order. GPX files are parsed, decompressing them if needed, and
+// metadata is taken from the activities.csv file at the root of fsys, if
+// present, matching rows by their Filename column. If fn returns an error then
+// the walk stops and the error is returned.
+func WalkExport(fsys fs.FS, fn func(*ExportActivity) error) error {
+	metadata, err := readExportMetadata(fsys)
+	if err != nil {
+		return err
+	}
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		lowerName := strings.ToLower(name)
+		for _, e := range exportActivityExts {
+			if !strings.HasSuffix(lowerName, e.ext) {
+				continue
+			}
+			activity := &ExportActivity{
+				Name:     name,
+				Metadata: metadata[name],
+			}
+			if e.isGPX {
+				if activity.GPX, err = readFS(fsys, name); err != nil {
+					return err
+				}
+			}
+			return fn(activity)
+		}
+		return nil
+	})
+}
+
+// readExportMetadata reads activities.csv from fsys and returns its rows keyed
+// by their Filename column.
+func readExportMetadata(fsys fs.FS) (map[string]map[string]string, error) {
+	rows, err := readExportCSV(fsys)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil //nolint:nilnil
+	case err != nil:
+		return nil, err
+	}
+
+	var metadata map[string]map[string]string
+	for _, row := range rows {
+		filename := exportFilename(row)
+		if filename == "" {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]map[string]string, len(rows))
+		}
+		metadata[filename] = row
+	}
+	return metadata, nil
+}
+
+// exportFilename returns the value of row's Filename column, matching the
+// column header case-insensitively.
+func exportFilename(row map[string]string) string {
+	if value, ok := row["Filename"]; ok {
+		return value
+	}
+	for column, value := range row {
+		if strings.EqualFold(column, "Filename") {
+			return value
+		}
+	}
+	return ""
+}
+
+// readExportCSV reads activities.csv from fsys and returns its rows, in order,
+// keyed by column header. Where a column header is repeated, as in Strava
+// exports, the first column is used.
+func readExportCSV(fsys fs.FS) ([]map[string]string, error) {
+	f, err := fsys.Open("activities.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			if _, ok := row[header[i]]; !ok {
+				row[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
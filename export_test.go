@@ -0,0 +1,93 @@
+package gpx_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestWalkExport(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "StravaGPX",
+		Trk: []*gpx.TrkType{
+			{
+				Name: "Morning Ride",
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 1, Lon: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, g.Write(buf))
+	gzBuf := &bytes.Buffer{}
+	zw := gzip.NewWriter(gzBuf)
+	assert.NoError(t, g.Write(zw))
+	assert.NoError(t, zw.Close())
+
+	fsys := fstest.MapFS{
+		"activities.csv": &fstest.MapFile{
+			Data: []byte("Activity ID,Activity Name,Activity Type,Filename\n" +
+				"1,Morning Ride,Ride,activities/1.gpx\n" +
+				"2,Evening Ride,Ride,activities/2.gpx.gz\n" +
+				"3,Lunch Run,Run,activities/3.fit.gz\n"),
+		},
+		"activities/1.gpx":    &fstest.MapFile{Data: buf.Bytes()},
+		"activities/2.gpx.gz": &fstest.MapFile{Data: gzBuf.Bytes()},
+		"activities/3.fit.gz": &fstest.MapFile{Data: []byte{0x1f, 0x8b}},
+		"media/photo.jpg":     &fstest.MapFile{},
+	}
+
+	var got []*gpx.ExportActivity
+	assert.NoError(t, gpx.WalkExport(fsys, func(activity *gpx.ExportActivity) error {
+		got = append(got, activity)
+		return nil
+	}))
+	assert.Equal(t, []*gpx.ExportActivity{
+		{
+			Name: "activities/1.gpx",
+			Metadata: map[string]string{
+				"Activity ID":   "1",
+				"Activity Name": "Morning Ride",
+				"Activity Type": "Ride",
+				"Filename":      "activities/1.gpx",
+			},
+			GPX: g,
+		},
+		{
+			Name: "activities/2.gpx.gz",
+			Metadata: map[string]string{
+				"Activity ID":   "2",
+				"Activity Name": "Evening Ride",
+				"Activity Type": "Ride",
+				"Filename":      "activities/2.gpx.gz",
+			},
+			GPX: g,
+		},
+		{
+			Name: "activities/3.fit.gz",
+			Metadata: map[string]string{
+				"Activity ID":   "3",
+				"Activity Name": "Lunch Run",
+				"Activity Type": "Run",
+				"Filename":      "activities/3.fit.gz",
+			},
+		},
+	}, got)
+
+	errStop := errors.New("stop")
+	assert.ErrorIs(t, gpx.WalkExport(fsys, func(*gpx.ExportActivity) error {
+		return errStop
+	}), errStop)
+}
@@ -0,0 +1,287 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// trackPointExtensionV2Namespace is the namespace of Garmin's
+// TrackPointExtension v2, which includes speed and course elements.
+const trackPointExtensionV2Namespace = "http://www.garmin.com/xmlschemas/TrackPointExtension/v2"
+
+// speedCourseExtensionEncoders maps the xml.Encoders that are writing GPX 1.1
+// documents, in which WptType speeds and courses are written as extensions,
+// to the Garmin TrackPointExtension v2 namespaces of the document, see
+// trackPointExtensionSpacesFor.
+var speedCourseExtensionEncoders sync.Map
+
+// trackPointExtensionSpaces are the namespaces of Garmin TrackPointExtension
+// v2 elements in documents that do not declare the conventional prefix.
+var trackPointExtensionSpaces = trackPointExtensionSpacesFor(nil)
+
+// trackPointExtensionSpacesFor returns the namespaces of Garmin
+// TrackPointExtension v2 elements in a document whose gpx element has the
+// attributes xmlAttrs, as in GPX.XMLAttrs. As extensions are parsed
+// separately from the gpx element, the prefixes that it binds to the
+// namespace are included, as is the conventional prefix if it is not bound to
+// another namespace.
+func trackPointExtensionSpacesFor(xmlAttrs map[string]string) []string {
+	spaces := []string{trackPointExtensionV2Namespace}
+	for key, value := range xmlAttrs {
+		if prefix, ok := strings.CutPrefix(key, "xmlns:"); ok && value == trackPointExtensionV2Namespace {
+			spaces = append(spaces, prefix)
+		}
+	}
+	if _, ok := xmlAttrs["xmlns:gpxtpx"]; !ok {
+		spaces = append(spaces, "gpxtpx")
+	}
+	return spaces
+}
+
+// setSpeedCourseFromExtensions sets w's speed and course from the children of
+// its Garmin TrackPointExtension v2 element, with a namespace in spaces, or
+// from its OsmAnd extensions, if they are not already set.
+func (w *WptType) setSpeedCourseFromExtensions(spaces []string) {
+	if w.Extensions == nil {
+		return
+	}
+	if !bytes.Contains(w.Extensions.XML, []byte("speed")) && !bytes.Contains(w.Extensions.XML, []byte("course")) {
+		return
+	}
+	var trackPointExtension struct {
+		Speed  *string `xml:"speed"`
+		Course *string `xml:"course"`
+	}
+	w.Extensions.decodeNamespacedExtension("TrackPointExtension", spaces, &trackPointExtension)
+	osmAnd := w.Extensions.namespacedExtensions(osmAndSpaces...)
+	for _, field := range []struct {
+		field WptFields
+		value *float64
+		tpx   *string
+		local string
+	}{
+		{WptSpeed, &w.Speed, trackPointExtension.Speed, "speed"},
+		{WptCourse, &w.Course, trackPointExtension.Course, "course"},
+	} {
+		if w.Has(field.field) {
+			continue
+		}
+		text, ok := osmAnd[field.local]
+		if field.tpx != nil {
+			text, ok = *field.tpx, true
+		}
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			continue
+		}
+		*field.value = value
+		if value == 0 {
+			w.ZeroFields |= field.field
+		}
+	}
+}
+
+// speedCourseExtensions returns w's extensions with the speed and course
+// children of its Garmin TrackPointExtension v2 element, with a namespace in
+// spaces, replaced by w's speed and course, adding the element with its own
+// namespace declaration if needed. The element's other children are
+// preserved, as are TrackPointExtension elements in other namespaces, such as
+// v1.
+func (w *WptType) speedCourseExtensions(spaces []string) *ExtensionsType {
+	speed, course := w.Has(WptSpeed), w.Has(WptCourse)
+	start, inner, ok := w.Extensions.trackPointExtension(spaces)
+	if !ok {
+		if !speed && !course {
+			return w.Extensions
+		}
+		start = []byte(`<gpxtpx:TrackPointExtension xmlns:gpxtpx="` + trackPointExtensionV2Namespace + `">`)
+	}
+	// Write new children with the same prefix as the element.
+	prefix := string(start[1:bytes.Index(start, []byte("TrackPointExtension"))])
+	var children bytes.Buffer
+	if others := (&ExtensionsType{XML: inner}).replaceExtensions(func(name xml.Name) bool {
+		return name.Local == "speed" || name.Local == "course"
+	}, nil); others != nil {
+		children.Write(others.XML)
+	}
+	if speed {
+		children.WriteString("<" + prefix + "speed>" + strconv.FormatFloat(w.Speed, 'f', -1, 64) + "</" + prefix + "speed>")
+	}
+	if course {
+		children.WriteString("<" + prefix + "course>" + strconv.FormatFloat(w.Course, 'f', -1, 64) + "</" + prefix + "course>")
+	}
+	var b bytes.Buffer
+	if len(bytes.TrimSpace(children.Bytes())) > 0 {
+		b.Write(start)
+		b.Write(children.Bytes())
+		b.WriteString("</" + prefix + "TrackPointExtension>")
+	}
+	return w.Extensions.replaceExtensions(func(name xml.Name) bool {
+		return isTrackPointExtension(name, spaces)
+	}, b.Bytes())
+}
+
+// trackPointExtension returns the start tag and the inner XML of the first
+// top-level Garmin TrackPointExtension v2 element in e with a namespace in
+// spaces, and whether there is such an element.
+func (e *ExtensionsType) trackPointExtension(spaces []string) (start, inner []byte, ok bool) {
+	if e == nil || len(e.XML) == 0 {
+		return nil, nil, false
+	}
+	d := xml.NewDecoder(bytes.NewReader(e.XML))
+	for {
+		offset := d.InputOffset()
+		token, err := d.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		startElement, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		innerOffset := d.InputOffset()
+		if err := d.Skip(); err != nil {
+			return nil, nil, false
+		}
+		if !isTrackPointExtension(startElement.Name, spaces) {
+			continue
+		}
+		start = e.XML[offset:innerOffset]
+		if selfClosing, found := bytes.CutSuffix(start, []byte("/>")); found {
+			return append(selfClosing[:len(selfClosing):len(selfClosing)], '>'), nil, true
+		}
+		inner = e.XML[innerOffset:d.InputOffset()]
+		// Remove the end element.
+		if i := bytes.LastIndexByte(inner, '<'); i >= 0 {
+			inner = inner[:i]
+		}
+		return start, inner, true
+	}
+}
+
+// isTrackPointExtension returns whether name is the name of a Garmin
+// TrackPointExtension v2 element with a namespace in spaces.
+func isTrackPointExtension(name xml.Name, spaces []string) bool {
+	return name.Local == "TrackPointExtension" && slices.Contains(spaces, name.Space)
+}
+
+// namespacedExtensions returns the text content of the top-level elements in
+// e whose namespace is one of spaces, by local name. Undeclared prefixes are
+// treated as namespaces, so spaces should include conventional prefixes.
+func (e *ExtensionsType) namespacedExtensions(spaces ...string) map[string]string {
+	values := make(map[string]string)
+	if e == nil || len(e.XML) == 0 {
+		return values
+	}
+	d := xml.NewDecoder(bytes.NewReader(e.XML))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return values
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(spaces, start.Name.Space) {
+			if err := d.Skip(); err != nil {
+				return values
+			}
+			continue
+		}
+		var value string
+		if err := d.DecodeElement(&value, &start); err != nil {
+			return values
+		}
+		if _, ok := values[start.Name.Local]; !ok {
+			values[start.Name.Local] = strings.TrimSpace(value)
+		}
+	}
+}
+
+// withNamespacedExtensions returns a copy of e with the top-level elements
+// whose namespace is one of spaces replaced by elements with the given local
+// names and values, in order, in namespace with the given prefix. Empty
+// values are omitted. It returns nil if the result is empty.
+func (e *ExtensionsType) withNamespacedExtensions(spaces []string, prefix, namespace string, values [][2]string) *ExtensionsType {
+	var b bytes.Buffer
+	for _, value := range values {
+		if value[1] == "" {
+			continue
+		}
+		b.WriteString("<" + prefix + ":" + value[0] + ` xmlns:` + prefix + `="` + namespace + `">`)
+		_ = xml.EscapeText(&b, []byte(value[1]))
+		b.WriteString("</" + prefix + ":" + value[0] + ">")
+	}
+	return e.replaceExtensions(func(name xml.Name) bool {
+		return slices.Contains(spaces, name.Space)
+	}, b.Bytes())
+}
+
+// replaceExtensions returns a copy of e with the top-level elements whose
+// names match removed and with data appended. It returns nil if the result
+// is empty.
+func (e *ExtensionsType) replaceExtensions(remove func(xml.Name) bool, data []byte) *ExtensionsType {
+	var b bytes.Buffer
+	if e != nil {
+		d := xml.NewDecoder(bytes.NewReader(e.XML))
+		var copied int64
+		for {
+			offset := d.InputOffset()
+			token, err := d.Token()
+			if err != nil {
+				break
+			}
+			if start, ok := token.(xml.StartElement); ok {
+				if err := d.Skip(); err != nil {
+					break
+				}
+				if remove(start.Name) {
+					b.Write(e.XML[copied:offset])
+					copied = d.InputOffset()
+				}
+			}
+		}
+		b.Write(e.XML[copied:])
+	}
+	b.Write(data)
+	if len(bytes.TrimSpace(b.Bytes())) == 0 {
+		return nil
+	}
+	return &ExtensionsType{
+		XML: b.Bytes(),
+	}
+}
+
+// decodeNamespacedExtension decodes the first top-level element in e with the
+// given local name and a namespace in spaces into v, and returns whether
+// there was such an element.
+func (e *ExtensionsType) decodeNamespacedExtension(localName string, spaces []string, v any) bool {
+	if e == nil || len(e.XML) == 0 {
+		return false
+	}
+	d := xml.NewDecoder(bytes.NewReader(e.XML))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return false
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == localName && slices.Contains(spaces, start.Name.Space) {
+			return d.DecodeElement(v, &start) == nil
+		}
+		if err := d.Skip(); err != nil {
+			return false
+		}
+	}
+}
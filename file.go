@@ -0,0 +1,179 @@
+package gpx
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// ReadFile reads a new GPX from the file called name. Gzip-compressed files
+// and zip archives, such as those produced by bulk exports, are detected
+// automatically and decompressed. From a zip archive, the first file with a
+// .gpx extension is read.
+func ReadFile(name string) (*GPX, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(len(zipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		return readZipFile(name)
+	default:
+		return readMaybeGzipped(br)
+	}
+}
+
+// WriteFileGZ writes g to the file called name, compressed with gzip.
+func (g *GPX) WriteFileGZ(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(f)
+	zw.Name = strings.TrimSuffix(path.Base(name), ".gz")
+	if err := g.Write(zw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readMaybeGzipped reads a new GPX from r, decompressing it first if it is
+// gzip-compressed.
+func readMaybeGzipped(r io.Reader) (*GPX, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.HasPrefix(magic, gzipMagic) {
+		return Read(br)
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return Read(zr)
+}
+
+// readZipFile reads the first GPX file in the zip archive called name.
+func readZipFile(name string) (*GPX, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		if !strings.EqualFold(path.Ext(zf.Name), ".gpx") {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return Read(rc)
+	}
+	return nil, fmt.Errorf("%s: no GPX file in archive", name)
+}
+
+// ReadDir reads all files in fsys matching the glob pattern, in lexical
+// order. Gzip-compressed files are decompressed automatically.
+func ReadDir(fsys fs.FS, pattern string) ([]*GPX, error) {
+	var gpxs []*GPX
+	if err := ReadDirFunc(fsys, pattern, func(_ string, g *GPX) error {
+		gpxs = append(gpxs, g)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return gpxs, nil
+}
+
+// ReadDirFunc reads each file in fsys matching the glob pattern, in lexical
+// order, and calls fn with its name and contents. Gzip-compressed files are
+// decompressed automatically. If reading a file fails or fn returns an error
+// then iteration stops and the error is returned.
+func ReadDirFunc(fsys fs.FS, pattern string, fn func(string, *GPX) error) error {
+	for file, err := range ReadDirSeq(fsys, pattern) {
+		if err != nil {
+			return err
+		}
+		if err := fn(file.Name, file.GPX); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A DirFile is a file read by ReadDirSeq.
+type DirFile struct {
+	Name string
+	// GPX is the file's contents, or nil if it could not be read.
+	GPX *GPX
+}
+
+// ReadDirSeq returns an iterator over the files in fsys matching the glob
+// pattern, in lexical order. Gzip-compressed files are decompressed
+// automatically. For each file it yields the file and a nil error, or, if the
+// file could not be read, the file with a nil GPX and the error, after which
+// iteration may continue with the next file. If pattern is malformed then it
+// yields a nil file and the error only.
+func ReadDirSeq(fsys fs.FS, pattern string) iter.Seq2[*DirFile, error] {
+	return func(yield func(*DirFile, error) bool) {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, name := range names {
+			g, err := readFS(fsys, name)
+			if !yield(&DirFile{Name: name, GPX: g}, err) {
+				return
+			}
+		}
+	}
+}
+
+// readFS reads the file called name in fsys, decompressing it if needed.
+func readFS(fsys fs.FS, name string) (*GPX, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g, err := readMaybeGzipped(f)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return g, nil
+}
@@ -0,0 +1,105 @@
+package gpx_test
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestReadFile(t *testing.T) {
+	expected, err := gpx.ReadFile("testdata/fells_loop.gpx")
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+
+	gzName := filepath.Join(dir, "fells_loop.gpx.gz")
+	assert.NoError(t, expected.WriteFileGZ(gzName))
+	got, err := gpx.ReadFile(gzName)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	zipName := filepath.Join(dir, "export.zip")
+	f, err := os.Create(zipName)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("README.txt")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("readme"))
+	assert.NoError(t, err)
+	w, err = zw.Create("activities/fells_loop.GPX")
+	assert.NoError(t, err)
+	assert.NoError(t, expected.Write(w))
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+	got, err = gpx.ReadFile(zipName)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	_, err = gpx.ReadFile(filepath.Join(dir, "missing.gpx"))
+	assert.Error(t, err)
+}
+
+func TestReadDir(t *testing.T) {
+	fsys := os.DirFS("testdata")
+
+	gpxs, err := gpx.ReadDir(fsys, "*.gpx")
+	assert.NoError(t, err)
+	assert.Len(t, gpxs, 3)
+
+	var names []string
+	assert.NoError(t, gpx.ReadDirFunc(fsys, "f*.gpx", func(name string, g *gpx.GPX) error {
+		names = append(names, name)
+		assert.Equal(t, "1.0", g.Version)
+		return nil
+	}))
+	assert.Equal(t, []string{"fells_loop.gpx"}, names)
+
+	_, err = gpx.ReadDir(fsys, "[")
+	assert.Error(t, err)
+}
+
+func TestReadDirSeq(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.gpx": &fstest.MapFile{Data: []byte(`<gpx version="1.1" creator="a"></gpx>`)},
+		"b.gpx": &fstest.MapFile{Data: []byte(`<gpx`)},
+		"c.gpx": &fstest.MapFile{Data: []byte(`<gpx version="1.1" creator="c"></gpx>`)},
+		"d.txt": &fstest.MapFile{},
+	}
+
+	var names, creators []string
+	var errs []error
+	for file, err := range gpx.ReadDirSeq(fsys, "*.gpx") {
+		names = append(names, file.Name)
+		if err != nil {
+			assert.Nil(t, file.GPX)
+			errs = append(errs, err)
+			continue
+		}
+		creators = append(creators, file.GPX.Creator)
+	}
+	assert.Equal(t, []string{"a.gpx", "b.gpx", "c.gpx"}, names)
+	assert.Equal(t, []string{"a", "c"}, creators)
+	assert.Len(t, errs, 1)
+	var pathErr *fs.PathError
+	assert.ErrorAs(t, errs[0], &pathErr)
+	assert.Equal(t, "b.gpx", pathErr.Path)
+
+	// Stopping early stops reading.
+	for file := range gpx.ReadDirSeq(fsys, "*.gpx") {
+		assert.Equal(t, "a.gpx", file.Name)
+		break
+	}
+
+	for file, err := range gpx.ReadDirSeq(fsys, "[") {
+		assert.Nil(t, file)
+		assert.ErrorIs(t, err, path.ErrBadPattern)
+	}
+}
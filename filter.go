@@ -0,0 +1,208 @@
+package gpx
+
+import "math"
+
+// FilterOptions control the smoothing and outlier filters.
+type FilterOptions struct {
+	// Window is the number of neighboring points on each side of a point that
+	// are used to filter it. If zero, two points are used.
+	Window int
+	// HorizontalWeight returns the weight of a point's latitude and
+	// longitude. If nil, all points have equal weight. HDOPWeight weights
+	// points by their accuracy.
+	HorizontalWeight func(*WptType) float64
+	// VerticalWeight returns the weight of a point's elevation. If nil, all
+	// points have equal weight. VDOPWeight weights points by their accuracy.
+	VerticalWeight func(*WptType) float64
+}
+
+// HDOPWeight returns a weight for wpt's horizontal position that is inversely
+// proportional to the square of its HDOP. Points without an HDOP have weight
+// one. The weight is further reduced if wpt was fixed with fewer than four
+// satellites.
+func HDOPWeight(wpt *WptType) float64 {
+	return dopWeight(wpt.HDOP, wpt.Sat)
+}
+
+// VDOPWeight returns a weight for wpt's elevation that is inversely
+// proportional to the square of its VDOP. Points without a VDOP have weight
+// one. The weight is further reduced if wpt was fixed with fewer than four
+// satellites.
+func VDOPWeight(wpt *WptType) float64 {
+	return dopWeight(wpt.VDOP, wpt.Sat)
+}
+
+// Smooth returns a copy of ts in which each point's position and elevation
+// are replaced by the weighted average of the points in the window around it.
+// Points closer to the center of the window have more weight, so points with
+// high weights are mostly preserved and points with low weights are moved
+// towards their neighbors. Points without elevations do not contribute to the
+// smoothed elevations of their neighbors and are left without elevations. The
+// window is shrunk symmetrically near the ends of ts, so the first and last
+// points are not moved. ts is not modified.
+func Smooth(ts *TrkSegType, options FilterOptions) *TrkSegType {
+	trkPts := make([]*WptType, len(ts.TrkPt))
+	for i, tp := range ts.TrkPt {
+		window := min(options.window(), i, len(ts.TrkPt)-1-i)
+		var sumHorizontalWeights, sumLat, sumLon float64
+		var sumVerticalWeights, sumEle float64
+		for j := i - window; j <= i+window; j++ {
+			other := ts.TrkPt[j]
+			kernel := float64(window + 1 - abs(i-j))
+			horizontalWeight := kernel * options.horizontalWeight(other)
+			sumHorizontalWeights += horizontalWeight
+			sumLat += horizontalWeight * (other.Lat - tp.Lat)
+			sumLon += horizontalWeight * normalizeLon(other.Lon-tp.Lon)
+			if other.Has(WptEle) {
+				verticalWeight := kernel * options.verticalWeight(other)
+				sumVerticalWeights += verticalWeight
+				sumEle += verticalWeight * (other.Ele - tp.Ele)
+			}
+		}
+		smoothedTrkPt := *tp
+		if sumHorizontalWeights > 0 {
+			smoothedTrkPt.Lat += sumLat / sumHorizontalWeights
+			smoothedTrkPt.Lon = normalizeLon(tp.Lon + sumLon/sumHorizontalWeights)
+		}
+		if tp.Has(WptEle) && sumVerticalWeights > 0 {
+			smoothedTrkPt.setEle(tp.Ele + sumEle/sumVerticalWeights)
+		}
+		trkPts[i] = &smoothedTrkPt
+	}
+	return &TrkSegType{
+		TrkPt:      trkPts,
+		Extensions: ts.Extensions,
+	}
+}
+
+// SmoothPreservingDistance returns a copy of ts smoothed like Smooth but with
+// each point's position moved back towards its original position, by the
+// same fraction for all points, until the length of the result is at least
+// target minus tolerance meters. Points are moved back as little as
+// possible. Naive smoothing cuts corners and removes jitter, which
+// systematically shortens tracks. If target is zero then the length of ts is
+// used. If target cannot be reached then the original positions are
+// returned. Elevations are smoothed as by Smooth. ts is not modified.
+func SmoothPreservingDistance(ts *TrkSegType, target, tolerance float64, options FilterOptions) *TrkSegType {
+	smoothed := Smooth(ts, options)
+	if target == 0 {
+		target = ts.Length(nil)
+	}
+	if smoothed.Length(nil) >= target-tolerance {
+		return smoothed
+	}
+
+	blend := func(f float64) *TrkSegType {
+		trkPts := make([]*WptType, len(smoothed.TrkPt))
+		for i, tp := range smoothed.TrkPt {
+			blendedTrkPt := *tp
+			blendedTrkPt.Lat += f * (ts.TrkPt[i].Lat - tp.Lat)
+			blendedTrkPt.Lon = normalizeLon(tp.Lon + f*normalizeLon(ts.TrkPt[i].Lon-tp.Lon))
+			trkPts[i] = &blendedTrkPt
+		}
+		return &TrkSegType{
+			TrkPt:      trkPts,
+			Extensions: ts.Extensions,
+		}
+	}
+
+	// Find the smallest fraction that reaches the target by bisection, as the
+	// length increases with it.
+	lo, hi := 0.0, 1.0
+	result := blend(hi)
+	if result.Length(nil) < target-tolerance {
+		return result
+	}
+	for range 32 {
+		f := (lo + hi) / 2
+		if blended := blend(f); blended.Length(nil) >= target-tolerance {
+			hi, result = f, blended
+		} else {
+			lo = f
+		}
+	}
+	return result
+}
+
+// RemoveOutliers returns a copy of ts without the points that are more than
+// maxDeviation meters from the weighted average position of their neighbors
+// in the window. If options.HorizontalWeight is set then the maximum
+// deviation of each point is scaled by the square root of its weight, so
+// points with low weights are removed more aggressively. The returned
+// TrkSegType shares its points with ts. ts is not modified.
+func RemoveOutliers(ts *TrkSegType, maxDeviation float64, options FilterOptions) *TrkSegType {
+	window := options.window()
+	trkPts := make([]*WptType, 0, len(ts.TrkPt))
+	for i, tp := range ts.TrkPt {
+		var sumWeights, sumLat, sumLon float64
+		for j := max(0, i-window); j <= min(len(ts.TrkPt)-1, i+window); j++ {
+			if j == i {
+				continue
+			}
+			other := ts.TrkPt[j]
+			weight := float64(window+1-abs(i-j)) * options.horizontalWeight(other)
+			sumWeights += weight
+			sumLat += weight * (other.Lat - tp.Lat)
+			sumLon += weight * normalizeLon(other.Lon-tp.Lon)
+		}
+		if sumWeights > 0 {
+			lat := tp.Lat + sumLat/sumWeights
+			lon := tp.Lon + sumLon/sumWeights
+			deviation := HaversineDistance(tp.Lat, tp.Lon, lat, lon)
+			if deviation > maxDeviation*math.Sqrt(options.horizontalWeight(tp)) {
+				continue
+			}
+		}
+		trkPts = append(trkPts, tp)
+	}
+	return &TrkSegType{
+		TrkPt:      trkPts,
+		Extensions: ts.Extensions,
+	}
+}
+
+func (o *FilterOptions) window() int {
+	if o.Window <= 0 {
+		return 2
+	}
+	return o.Window
+}
+
+func (o *FilterOptions) horizontalWeight(wpt *WptType) float64 {
+	if o.HorizontalWeight == nil {
+		return 1
+	}
+	return o.HorizontalWeight(wpt)
+}
+
+func (o *FilterOptions) verticalWeight(wpt *WptType) float64 {
+	if o.VerticalWeight == nil {
+		return 1
+	}
+	return o.VerticalWeight(wpt)
+}
+
+// dopWeight returns the weight of a fix with the given dilution of precision
+// and number of satellites.
+func dopWeight(dop float64, sat int) float64 {
+	weight := 1.0
+	if dop > 0 {
+		weight = 1 / (dop * dop)
+	}
+	if sat > 0 && sat < 4 {
+		weight /= 4
+	}
+	return weight
+}
+
+// normalizeLon returns lon normalized to [-180, 180).
+func normalizeLon(lon float64) float64 {
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
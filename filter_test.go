@@ -0,0 +1,138 @@
+package gpx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestSmooth(t *testing.T) {
+	ts := newTestTrkSeg(9, func(i int) *gpx.WptType {
+		return &gpx.WptType{Lat: 46, Lon: 7 + float64(i)*1e-4, Ele: 1000, HDOP: 1, VDOP: 1}
+	})
+	// A poor fix 100m north of the track.
+	ts.TrkPt[4] = &gpx.WptType{Lat: 46 + 100/111195.0, Lon: 7.0004, Ele: 1100, HDOP: 10, VDOP: 10}
+	original := ts.Clone()
+	unweighted := gpx.Smooth(ts, gpx.FilterOptions{})
+	weighted := gpx.Smooth(ts, gpx.FilterOptions{
+		HorizontalWeight: gpx.HDOPWeight,
+		VerticalWeight:   gpx.VDOPWeight,
+	})
+	assert.Len(t, unweighted.TrkPt, len(ts.TrkPt))
+	assert.Len(t, weighted.TrkPt, len(ts.TrkPt))
+
+	// The input is not modified.
+	assert.Equal(t, original, ts)
+
+	// The poor fix is moved more when weighted.
+	unweightedError := gpx.HaversineDistance(46, 7.0004, unweighted.TrkPt[4].Lat, unweighted.TrkPt[4].Lon)
+	weightedError := gpx.HaversineDistance(46, 7.0004, weighted.TrkPt[4].Lat, weighted.TrkPt[4].Lon)
+	assert.InDelta(t, 100*3/9.0, unweightedError, 0.1)
+	assert.Less(t, weightedError, 1.0)
+	assert.InDelta(t, 1000+100*3/9.0, unweighted.TrkPt[4].Ele, 1e-9)
+	assert.Less(t, weighted.TrkPt[4].Ele, 1001.0)
+
+	// Good fixes are disturbed less when weighted.
+	assert.Less(t, weighted.TrkPt[3].Lat-46, unweighted.TrkPt[3].Lat-46)
+
+	assert.Equal(t, ts.TrkPt[0].Lon, weighted.TrkPt[0].Lon)
+}
+
+func TestSmoothMissingElevations(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0, Ele: 100},
+			{Lat: 0, Lon: 1e-4},
+			{Lat: 0, Lon: 2e-4, Ele: 100},
+		},
+	}
+	got := gpx.Smooth(ts, gpx.FilterOptions{Window: 1})
+	assert.False(t, got.TrkPt[1].Has(gpx.WptEle))
+	assert.Equal(t, 100.0, got.TrkPt[0].Ele)
+	assert.Equal(t, 100.0, got.TrkPt[2].Ele)
+
+	ts.TrkPt[1].SetZero(gpx.WptEle)
+	got = gpx.Smooth(ts, gpx.FilterOptions{Window: 1})
+	assert.InDelta(t, 50, got.TrkPt[1].Ele, 1e-9)
+}
+
+func TestSmoothAntimeridian(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 179.9999},
+			{Lat: 0, Lon: -180},
+			{Lat: 0, Lon: -179.9999},
+		},
+	}
+	got := gpx.Smooth(ts, gpx.FilterOptions{Window: 1})
+	assert.InDelta(t, -180, got.TrkPt[1].Lon, 1e-9)
+}
+
+func TestRemoveOutliers(t *testing.T) {
+	ts := newTestTrkSeg(9, func(i int) *gpx.WptType {
+		return &gpx.WptType{Lat: 46, Lon: 7 + float64(i)*1e-4, HDOP: 1}
+	})
+	// A poor fix 30m north of the track.
+	ts.TrkPt[4] = &gpx.WptType{Lat: 46 + 30/111195.0, Lon: 7.0004, HDOP: 10}
+
+	// A 30m deviation is within 50m unweighted but not when the poor fix's
+	// allowance is scaled by its weight.
+	assert.Len(t, gpx.RemoveOutliers(ts, 50, gpx.FilterOptions{}).TrkPt, 9)
+	got := gpx.RemoveOutliers(ts, 50, gpx.FilterOptions{
+		HorizontalWeight: gpx.HDOPWeight,
+	})
+	assert.Len(t, got.TrkPt, 8)
+	assert.NotContains(t, got.TrkPt, ts.TrkPt[4])
+	assert.Len(t, ts.TrkPt, 9)
+}
+
+func TestDOPWeight(t *testing.T) {
+	assert.Equal(t, 1.0, gpx.HDOPWeight(&gpx.WptType{}))
+	assert.Equal(t, 0.25, gpx.HDOPWeight(&gpx.WptType{HDOP: 2}))
+	assert.Equal(t, 0.0625, gpx.HDOPWeight(&gpx.WptType{HDOP: 2, Sat: 3}))
+	assert.Equal(t, 0.04, gpx.VDOPWeight(&gpx.WptType{HDOP: 2, VDOP: 5, Sat: 8}))
+}
+
+func TestSmoothPreservingDistance(t *testing.T) {
+	// A zigzag track whose corners are cut by smoothing.
+	ts := &gpx.TrkSegType{}
+	for i := 0; i < 50; i++ {
+		ts.TrkPt = append(ts.TrkPt, &gpx.WptType{
+			Lat: 46 + float64(i%2)*2e-4,
+			Lon: 7 + float64(i)*1e-4,
+			Ele: 1000 + float64(i%2)*10,
+		})
+	}
+	raw := ts.Length(nil)
+	smoothed := gpx.Smooth(ts, gpx.FilterOptions{})
+	assert.Less(t, smoothed.Length(nil), 0.8*raw)
+
+	got := gpx.SmoothPreservingDistance(ts, 0, 1, gpx.FilterOptions{})
+	assert.Len(t, got.TrkPt, len(ts.TrkPt))
+	assert.InDelta(t, raw, got.Length(nil), 1)
+	assert.GreaterOrEqual(t, got.Length(nil), raw-1)
+	// Positions are still smoothed.
+	assert.Less(t, got.TrkPt[1].Lat, ts.TrkPt[1].Lat)
+	// Elevations are smoothed regardless.
+	for i := range got.TrkPt {
+		assert.Equal(t, smoothed.TrkPt[i].Ele, got.TrkPt[i].Ele)
+	}
+
+	corrected := 0.9 * raw
+	got = gpx.SmoothPreservingDistance(ts, corrected, 1, gpx.FilterOptions{})
+	assert.InDelta(t, corrected, got.Length(nil), 1)
+	assert.GreaterOrEqual(t, got.Length(nil), corrected-1)
+
+	// Targets shorter than the smoothed length give the smoothed track.
+	got = gpx.SmoothPreservingDistance(ts, 1, 1, gpx.FilterOptions{})
+	assert.Equal(t, smoothed, got)
+
+	// Targets longer than the raw length give the raw positions.
+	got = gpx.SmoothPreservingDistance(ts, 2*raw, 1, gpx.FilterOptions{})
+	assert.InDelta(t, raw, got.Length(nil), 1e-6)
+
+	// The input is not modified.
+	assert.Equal(t, 46.0002, ts.TrkPt[1].Lat)
+}
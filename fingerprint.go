@@ -0,0 +1,118 @@
+package gpx
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"time"
+)
+
+// Default fingerprint options.
+const (
+	DefaultFingerprintCoordinatePrecision = 5
+	DefaultFingerprintTimeRounding        = time.Second
+)
+
+// FingerprintOptions control GPX.Fingerprint. The zero value uses the
+// defaults.
+type FingerprintOptions struct {
+	// CoordinatePrecision is the number of decimal places to which latitudes
+	// and longitudes are rounded. If zero, DefaultFingerprintCoordinatePrecision
+	// is used, which is about one meter.
+	CoordinatePrecision int
+	// TimeRounding is the duration to which times are rounded. If zero,
+	// DefaultFingerprintTimeRounding is used.
+	TimeRounding time.Duration
+	// IncludeEle includes elevations, rounded to whole meters. Elevations are
+	// excluded by default because many services replace them with elevations
+	// from a terrain model.
+	IncludeEle bool
+	// IgnoreTimes excludes times.
+	IgnoreTimes bool
+	// AllPoints includes waypoints and route points, and the boundaries
+	// between routes, tracks, and track segments.
+	AllPoints bool
+	// Exact includes positions, elevations, and times without rounding, and
+	// hashes consecutive equal points separately, so that only identical
+	// points have the same fingerprint. CoordinatePrecision, TimeRounding,
+	// and IncludeEle are ignored.
+	Exact bool
+}
+
+// exactFingerprintOptions are the options of fingerprints that identify
+// documents with identical points.
+var exactFingerprintOptions = FingerprintOptions{
+	AllPoints: true,
+	Exact:     true,
+}
+
+// Fingerprint returns a hex-encoded hash of the normalized track points of g,
+// for detecting the same activity uploaded from different services. Track
+// points are hashed in order, ignoring track and segment boundaries, names,
+// and extensions. Positions and times are rounded according to options, and
+// consecutive points that are equal after rounding are hashed once. With
+// options.AllPoints and options.Exact, documents have the same fingerprint
+// only if they have the same points in the same routes, tracks, and track
+// segments.
+func (g *GPX) Fingerprint(options FingerprintOptions) string {
+	precision := options.CoordinatePrecision
+	if precision == 0 {
+		precision = DefaultFingerprintCoordinatePrecision
+	}
+	scale := math.Pow10(precision)
+	timeRounding := options.TimeRounding
+	if timeRounding == 0 {
+		timeRounding = DefaultFingerprintTimeRounding
+	}
+
+	h := sha256.New()
+	var buf, prev [32]byte
+	first := true
+	writeWpts := func(tag byte, wpts []*WptType) {
+		if options.AllPoints {
+			h.Write([]byte{tag})
+		}
+		for _, wpt := range wpts {
+			buf = [32]byte{}
+			if options.Exact {
+				binary.LittleEndian.PutUint64(buf[0:8], math.Float64bits(wpt.Lat))
+				binary.LittleEndian.PutUint64(buf[8:16], math.Float64bits(wpt.Lon))
+				binary.LittleEndian.PutUint64(buf[16:24], math.Float64bits(wpt.Ele))
+				if !options.IgnoreTimes && !wpt.Time.IsZero() {
+					binary.LittleEndian.PutUint64(buf[24:32], uint64(wpt.Time.UnixNano())) //nolint:gosec
+				}
+			} else {
+				binary.LittleEndian.PutUint64(buf[0:8], uint64(int64(math.Round(wpt.Lat*scale))))  //nolint:gosec
+				binary.LittleEndian.PutUint64(buf[8:16], uint64(int64(math.Round(wpt.Lon*scale)))) //nolint:gosec
+				if options.IncludeEle {
+					binary.LittleEndian.PutUint64(buf[16:24], uint64(int64(math.Round(wpt.Ele)))) //nolint:gosec
+				}
+				if !options.IgnoreTimes && !wpt.Time.IsZero() {
+					binary.LittleEndian.PutUint64(buf[24:32], uint64(wpt.Time.Round(timeRounding).UnixNano())) //nolint:gosec
+				}
+				if !first && buf == prev {
+					continue
+				}
+			}
+			h.Write(buf[:])
+			prev, first = buf, false
+		}
+	}
+
+	if options.AllPoints {
+		writeWpts('w', g.Wpt)
+		for _, rte := range g.Rte {
+			writeWpts('r', rte.RtePt)
+		}
+	}
+	for _, trk := range g.Trk {
+		if options.AllPoints {
+			h.Write([]byte{'t'})
+		}
+		for _, ts := range trk.TrkSeg {
+			writeWpts('s', ts.TrkPt)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,138 @@
+package gpx_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFingerprint(t *testing.T) {
+	read := func() *gpx.GPX {
+		f, err := os.Open("testdata/mystic_basin_trail.gpx")
+		assert.NoError(t, err)
+		defer f.Close()
+		g, err := gpx.Read(f)
+		assert.NoError(t, err)
+		return g
+	}
+	expected := read().Fingerprint(gpx.FingerprintOptions{})
+	assert.Len(t, expected, 64)
+
+	for i, tc := range []struct {
+		edit     func(*gpx.GPX)
+		options  gpx.FingerprintOptions
+		expected bool
+	}{
+		{
+			edit: func(g *gpx.GPX) {
+				g.Creator = "other"
+				g.Metadata = nil
+				g.Wpt = nil
+				g.Trk[0].Name = "other"
+				g.Trk[0].TrkSeg[0].TrkPt[0].Extensions = &gpx.ExtensionsType{XML: []byte("<hr>120</hr>")}
+			},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				ts := g.Trk[0].TrkSeg[0]
+				g.Trk[0].TrkSeg = append(g.Trk[0].TrkSeg[:1:1], append([]*gpx.TrkSegType{{TrkPt: ts.TrkPt[10:]}}, g.Trk[0].TrkSeg[1:]...)...)
+				ts.TrkPt = ts.TrkPt[:10]
+			},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				ts := g.Trk[0].TrkSeg[0]
+				ts.TrkPt[0].Lat += 1e-7
+				ts.TrkPt[1].Ele += 10
+				ts.TrkPt[2].Time = ts.TrkPt[2].Time.Add(time.Millisecond).In(time.FixedZone("", 3600))
+			},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				ts := g.Trk[0].TrkSeg[0]
+				ts.TrkPt = append(ts.TrkPt[:1], append([]*gpx.WptType{ts.TrkPt[0]}, ts.TrkPt[1:]...)...)
+			},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk[0].TrkSeg[0].TrkPt[0].Lat += 1e-4
+			},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk[0].TrkSeg[0].TrkPt[1].Ele += 10
+			},
+			options:  gpx.FingerprintOptions{IncludeEle: true},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk[0].TrkSeg[0].TrkPt[2].Time = g.Trk[0].TrkSeg[0].TrkPt[2].Time.Add(time.Minute)
+			},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk[0].TrkSeg[0].TrkPt[2].Time = g.Trk[0].TrkSeg[0].TrkPt[2].Time.Add(time.Minute)
+			},
+			options:  gpx.FingerprintOptions{IgnoreTimes: true},
+			expected: true,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt = nil
+			},
+			options:  gpx.FingerprintOptions{AllPoints: true},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				ts := g.Trk[0].TrkSeg[0]
+				g.Trk[0].TrkSeg = append(g.Trk[0].TrkSeg[:1:1], append([]*gpx.TrkSegType{{TrkPt: ts.TrkPt[10:]}}, g.Trk[0].TrkSeg[1:]...)...)
+				ts.TrkPt = ts.TrkPt[:10]
+			},
+			options:  gpx.FingerprintOptions{AllPoints: true},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk[0].TrkSeg[0].TrkPt[0].Lat += 1e-7
+			},
+			options:  gpx.FingerprintOptions{Exact: true},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				ts := g.Trk[0].TrkSeg[0]
+				ts.TrkPt = append(ts.TrkPt[:1], append([]*gpx.WptType{ts.TrkPt[0]}, ts.TrkPt[1:]...)...)
+			},
+			options:  gpx.FingerprintOptions{Exact: true},
+			expected: false,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Creator = "other"
+				g.Trk[0].Name = "other"
+			},
+			options:  gpx.FingerprintOptions{AllPoints: true, Exact: true},
+			expected: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, other := read(), read()
+			tc.edit(other)
+			assert.Equal(t, tc.expected, g.Fingerprint(tc.options) == other.Fingerprint(tc.options))
+		})
+	}
+	assert.Equal(t, expected, read().Fingerprint(gpx.FingerprintOptions{}))
+}
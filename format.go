@@ -0,0 +1,122 @@
+package gpx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownFormat is returned when a format is not registered, or cannot be
+// read or written.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// A FormatReader reads a GPX document from a format.
+type FormatReader func(r io.Reader) (*GPX, error)
+
+// A FormatWriter writes a GPX document in a format.
+type FormatWriter func(w io.Writer, g *GPX) error
+
+type format struct {
+	reader FormatReader
+	writer FormatWriter
+}
+
+var (
+	formatsMutex sync.RWMutex
+	formats      = make(map[string]format)
+)
+
+func init() {
+	RegisterFormat("gpx", Read, func(w io.Writer, g *GPX) error {
+		return g.Write(w)
+	})
+	RegisterFormat("csv", func(r io.Reader) (*GPX, error) {
+		return ReadCSV(r, DefaultCSVMapping)
+	}, func(w io.Writer, g *GPX) error {
+		return g.WriteCSV(w, CSVOptions{
+			Columns: []string{"kind", "lat", "lon", "ele", "time", "name"},
+		})
+	})
+	RegisterFormat("komoot", FromKomootTour, nil)
+}
+
+// RegisterFormat registers a format called name, so that it can be used by
+// Convert, ReadFormat, and WriteFormat. Either reader or writer may be nil if
+// the format can only be written or read. The formats gpx, csv, and komoot
+// (read only) are registered by default. RegisterFormat panics if both reader
+// and writer are nil or if name is already registered.
+func RegisterFormat(name string, reader FormatReader, writer FormatWriter) {
+	if reader == nil && writer == nil {
+		panic("gpx: RegisterFormat " + name + ": no reader or writer")
+	}
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	if _, ok := formats[name]; ok {
+		panic("gpx: RegisterFormat " + name + ": already registered")
+	}
+	formats[name] = format{
+		reader: reader,
+		writer: writer,
+	}
+}
+
+// Formats returns the names of the registered formats, sorted.
+func Formats() []string {
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadFormat reads a GPX document in the registered format name from r. It
+// returns an error wrapping ErrUnknownFormat if name is not registered or
+// cannot be read.
+func ReadFormat(name string, r io.Reader) (*GPX, error) {
+	formatsMutex.RLock()
+	reader := formats[name].reader
+	formatsMutex.RUnlock()
+	if reader == nil {
+		return nil, fmt.Errorf("%s: %w: cannot read", name, ErrUnknownFormat)
+	}
+	return reader(r)
+}
+
+// WriteFormat writes g to w in the registered format name. It returns an
+// error wrapping ErrUnknownFormat if name is not registered or cannot be
+// written.
+func (g *GPX) WriteFormat(name string, w io.Writer) error {
+	formatsMutex.RLock()
+	writer := formats[name].writer
+	formatsMutex.RUnlock()
+	if writer == nil {
+		return fmt.Errorf("%s: %w: cannot write", name, ErrUnknownFormat)
+	}
+	return writer(w, g)
+}
+
+// Convert reads a document in the registered format from from src and writes
+// it to dst in the registered format to. It returns an error wrapping
+// ErrUnknownFormat before reading src if either format is not registered or
+// does not support the conversion.
+func Convert(src io.Reader, from, to string, dst io.Writer) error {
+	formatsMutex.RLock()
+	reader, writer := formats[from].reader, formats[to].writer
+	formatsMutex.RUnlock()
+	switch {
+	case reader == nil:
+		return fmt.Errorf("%s: %w: cannot read", from, ErrUnknownFormat)
+	case writer == nil:
+		return fmt.Errorf("%s: %w: cannot write", to, ErrUnknownFormat)
+	}
+	g, err := reader(src)
+	if err != nil {
+		return err
+	}
+	return writer(dst, g)
+}
@@ -0,0 +1,57 @@
+package gpx_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestConvert(t *testing.T) {
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="47.5" lon="7.5"><name>a</name></wpt>
+  <trk><trkseg><trkpt lat="47.6" lon="7.6"><ele>300</ele></trkpt></trkseg></trk>
+</gpx>`
+
+	var csv bytes.Buffer
+	assert.NoError(t, gpx.Convert(strings.NewReader(src), "gpx", "csv", &csv))
+	assert.Equal(t, "kind,lat,lon,ele,time,name\nwpt,47.5,7.5,,,a\ntrkpt,47.6,7.6,300,,\n", csv.String())
+
+	var dst bytes.Buffer
+	assert.NoError(t, gpx.Convert(&csv, "csv", "gpx", &dst))
+	g, err := gpx.Read(&dst)
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.WptType{{Lat: 47.5, Lon: 7.5, Name: "a"}}, g.Wpt)
+	assert.Equal(t, []*gpx.WptType{{Lat: 47.6, Lon: 7.6, Ele: 300}}, g.Trk[0].TrkSeg[0].TrkPt)
+
+	assert.ErrorIs(t, gpx.Convert(strings.NewReader(src), "gpx", "komoot", io.Discard), gpx.ErrUnknownFormat)
+	assert.ErrorIs(t, gpx.Convert(strings.NewReader(src), "kml", "gpx", io.Discard), gpx.ErrUnknownFormat)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	gpx.RegisterFormat("test-names", nil, func(w io.Writer, g *gpx.GPX) error {
+		for _, wpt := range g.Wpt {
+			if _, err := io.WriteString(w, wpt.Name+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Contains(t, gpx.Formats(), "test-names")
+	assert.Panics(t, func() {
+		gpx.RegisterFormat("test-names", gpx.Read, nil)
+	})
+
+	var b strings.Builder
+	g := &gpx.GPX{Wpt: []*gpx.WptType{{Name: "a"}, {Name: "b"}}}
+	assert.NoError(t, g.WriteFormat("test-names", &b))
+	assert.Equal(t, "a\nb\n", b.String())
+
+	_, err := gpx.ReadFormat("test-names", strings.NewReader(""))
+	assert.ErrorIs(t, err, gpx.ErrUnknownFormat)
+}
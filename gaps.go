@@ -0,0 +1,40 @@
+package gpx
+
+import (
+	"fmt"
+	"time"
+)
+
+// GapWptType is the type of the waypoints added by AnnotateGaps.
+const GapWptType = "gap"
+
+// AnnotateGaps adds a waypoint to g for each pair of consecutive track points
+// in the same segment whose times are at least minGap apart. Each waypoint is
+// placed halfway between the two track points, has the time of the first, is
+// named after the duration of the gap, and has type GapWptType. It returns the
+// number of waypoints added.
+func AnnotateGaps(g *GPX, minGap time.Duration) int {
+	n := 0
+	for _, trk := range g.Trk {
+		for _, ts := range trk.TrkSeg {
+			for i := 1; i < len(ts.TrkPt); i++ {
+				a, b := ts.TrkPt[i-1], ts.TrkPt[i]
+				if a.Time.IsZero() || b.Time.IsZero() {
+					continue
+				}
+				gap := b.Time.Sub(a.Time)
+				if gap < minGap {
+					continue
+				}
+				wpt := interpolate(a, b, 0.5)
+				wpt.Time = a.Time
+				wpt.Name = "Gap " + gap.String()
+				wpt.Desc = fmt.Sprintf("No data recorded from %s to %s", a.Time.Format(time.RFC3339), b.Time.Format(time.RFC3339))
+				wpt.Type = GapWptType
+				g.Wpt = append(g.Wpt, wpt)
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,48 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestAnnotateGaps(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 1, Lon: 1, Name: "Start"},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 0, Lon: 0, Ele: 100, Time: t0},
+							{Lat: 0, Lon: 0.001, Ele: 100, Time: t0.Add(time.Minute)},
+							{Lat: 0, Lon: 0.003, Ele: 200, Time: t0.Add(91 * time.Minute)},
+							{Lat: 0, Lon: 0.004},
+							{Lat: 0, Lon: 0.005, Time: t0.Add(200 * time.Minute)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, 0, gpx.AnnotateGaps(g, 24*time.Hour))
+	assert.Len(t, g.Wpt, 1)
+
+	assert.Equal(t, 1, gpx.AnnotateGaps(g, 5*time.Minute))
+	assert.Len(t, g.Wpt, 2)
+	gap := g.Wpt[1]
+	assert.InDelta(t, 0, gap.Lat, 1e-9)
+	assert.InDelta(t, 0.002, gap.Lon, 1e-9)
+	assert.Equal(t, 150.0, gap.Ele)
+	assert.Equal(t, t0.Add(time.Minute), gap.Time)
+	assert.Equal(t, "Gap 1h30m0s", gap.Name)
+	assert.Equal(t, "No data recorded from 2024-05-01T10:01:00Z to 2024-05-01T11:31:00Z", gap.Desc)
+	assert.Equal(t, gpx.GapWptType, gap.Type)
+}
@@ -0,0 +1,188 @@
+package gpx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// garminConnectActivityURL is the URL of an activity on Garmin Connect, given
+// its ID.
+const garminConnectActivityURL = "https://connect.garmin.com/modern/activity/"
+
+// A garminActivitySummary is an activity in a Garmin Connect export's
+// summarizedActivities.json file.
+type garminActivitySummary struct {
+	ActivityID   json.Number     `json:"activityId"`
+	Name         string          `json:"name"`
+	ActivityType json.RawMessage `json:"activityType"`
+	StartTimeGMT float64         `json:"startTimeGmt"` // Milliseconds since the epoch.
+	Duration     float64         `json:"duration"`     // Milliseconds.
+	Distance     float64         `json:"distance"`     // Centimeters.
+	Calories     float64         `json:"calories"`
+	AvgHR        float64         `json:"avgHr"`
+	MaxHR        float64         `json:"maxHr"`
+}
+
+// ImportGarminExport reads the GPX activity files in the Garmin Connect data
+// export zip archive called zipPath, including those in zip archives nested
+// within it, in archive order. Each document whose file name ends with the ID
+// of an activity in the export's summarizedActivities.json files is enriched
+// with the activity's summary: its name and start time fill in the
+// document's metadata, a link to the activity is added to the metadata, its
+// type fills in the types of the document's tracks, and its totals are added
+// as a Cluetrust gpxdata lap in the document's extensions. Activities without
+// GPX files, for example those recorded as FIT, are not returned.
+func ImportGarminExport(zipPath string) ([]*GPX, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	summaries := make(map[string]*garminActivitySummary)
+	var gpxs []*GPX
+	var ids []string
+	if err := walkZip(&zr.Reader, func(name string, r io.Reader) error {
+		base := path.Base(name)
+		switch {
+		case strings.HasSuffix(base, "summarizedActivities.json"):
+			var exports []struct {
+				SummarizedActivitiesExport []*garminActivitySummary `json:"summarizedActivitiesExport"`
+			}
+			if err := json.NewDecoder(r).Decode(&exports); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			for _, export := range exports {
+				for _, summary := range export.SummarizedActivitiesExport {
+					summaries[summary.ActivityID.String()] = summary
+				}
+			}
+		case strings.EqualFold(path.Ext(strings.TrimSuffix(strings.ToLower(base), ".gz")), ".gpx"):
+			g, err := readMaybeGzipped(r)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			gpxs = append(gpxs, g)
+			ids = append(ids, garminActivityID(base))
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("%s: %w", zipPath, err)
+	}
+
+	for i, g := range gpxs {
+		if summary, ok := summaries[ids[i]]; ok {
+			if err := summary.mergeInto(g); err != nil {
+				return nil, fmt.Errorf("%s: activity %s: %w", zipPath, ids[i], err)
+			}
+		}
+	}
+	return gpxs, nil
+}
+
+// walkZip calls fn with the name and contents of each file in zr, recursing
+// into nested zip archives. Names of files in nested archives are prefixed
+// with the name of the archive.
+func walkZip(zr *zip.Reader, fn func(string, io.Reader) error) error {
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		if err := func() error {
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			if !strings.EqualFold(path.Ext(zf.Name), ".zip") {
+				return fn(zf.Name, rc)
+			}
+			data, err := io.ReadAll(rc)
+			if err != nil {
+				return err
+			}
+			nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				return fmt.Errorf("%s: %w", zf.Name, err)
+			}
+			return walkZip(nested, func(name string, r io.Reader) error {
+				return fn(zf.Name+"/"+name, r)
+			})
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// garminActivityID returns the activity ID at the end of the file name base,
+// for example 12345 from user@example.com_12345.gpx.gz.
+func garminActivityID(base string) string {
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if strings.EqualFold(path.Ext(base), ".gpx") {
+		base = strings.TrimSuffix(base, path.Ext(base))
+	}
+	i := strings.LastIndexFunc(base, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	return base[i+1:]
+}
+
+// activityType returns the type of s, which Garmin writes either as a string
+// or as an object with a typeKey.
+func (s *garminActivitySummary) activityType() string {
+	var typeString string
+	if err := json.Unmarshal(s.ActivityType, &typeString); err == nil {
+		return typeString
+	}
+	var typeObject struct {
+		TypeKey string `json:"typeKey"`
+	}
+	if err := json.Unmarshal(s.ActivityType, &typeObject); err == nil {
+		return typeObject.TypeKey
+	}
+	return ""
+}
+
+// mergeInto adds s to g's metadata, track types, and extensions.
+func (s *garminActivitySummary) mergeInto(g *GPX) error {
+	startTime := time.Time{}
+	if s.StartTimeGMT != 0 {
+		startTime = time.UnixMilli(int64(s.StartTimeGMT)).UTC()
+	}
+	metadata := g.metadata()
+	if metadata.Name == "" {
+		metadata.Name = s.Name
+	}
+	if metadata.Time.IsZero() {
+		metadata.Time = startTime
+	}
+	metadata.Link = append(metadata.Link, &LinkType{
+		HREF: garminConnectActivityURL + s.ActivityID.String(),
+		Text: "Garmin Connect",
+	})
+	if activityType := s.activityType(); activityType != "" {
+		for _, trk := range g.Trk {
+			if trk.Type == "" {
+				trk.Type = activityType
+			}
+		}
+	}
+	return g.AddLaps([]Lap{
+		{
+			StartTime:    startTime,
+			Duration:     time.Duration(s.Duration * float64(time.Millisecond)),
+			Distance:     s.Distance / 100,
+			Calories:     int(s.Calories),
+			AvgHeartRate: s.AvgHR,
+			MaxHeartRate: s.MaxHR,
+		},
+	})
+}
@@ -0,0 +1,85 @@
+package gpx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestImportGarminExport(t *testing.T) {
+	g, err := gpx.ReadFile("testdata/mystic_basin_trail.gpx")
+	assert.NoError(t, err)
+
+	// Garmin puts uploaded files in nested zip archives.
+	var nested bytes.Buffer
+	nzw := zip.NewWriter(&nested)
+	w, err := nzw.Create("user@example.com_12345.gpx")
+	assert.NoError(t, err)
+	assert.NoError(t, g.Write(w))
+	w, err = nzw.Create("user@example.com_67890.gpx.gz")
+	assert.NoError(t, err)
+	gw := gzip.NewWriter(w)
+	assert.NoError(t, g.Write(gw))
+	assert.NoError(t, gw.Close())
+	w, err = nzw.Create("user@example.com_13579.fit")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("not gpx"))
+	assert.NoError(t, err)
+	assert.NoError(t, nzw.Close())
+
+	zipName := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(zipName)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err = zw.Create("DI_CONNECT/DI-Connect-Fitness/user@example.com_0_summarizedActivities.json")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(`[{"summarizedActivitiesExport":[` +
+		`{"activityId":12345,"name":"Trail Run","activityType":"trail_running","startTimeGmt":1704179732000,"duration":3600000,"distance":1050000,"calories":650,"avgHr":145,"maxHr":172},` +
+		`{"activityId":13579,"name":"Pool Swim","activityType":{"typeKey":"lap_swimming"}}` +
+		`]}]`))
+	assert.NoError(t, err)
+	w, err = zw.Create("DI_CONNECT/DI-Connect-Uploaded-Files/UploadedFiles_0-_Part1.zip")
+	assert.NoError(t, err)
+	_, err = w.Write(nested.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+
+	got, err := gpx.ImportGarminExport(zipName)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	run := got[0]
+	assert.Equal(t, g.Metadata.Name, run.Metadata.Name)
+	assert.Equal(t, "https://connect.garmin.com/modern/activity/12345", run.Metadata.Link[len(run.Metadata.Link)-1].HREF)
+	for _, trk := range run.Trk {
+		assert.Equal(t, "trail_running", trk.Type)
+	}
+	laps, err := run.Laps()
+	assert.NoError(t, err)
+	assert.Equal(t, []gpx.Lap{
+		{
+			StartTime:    time.Date(2024, 1, 2, 7, 15, 32, 0, time.UTC),
+			Duration:     time.Hour,
+			Distance:     10500,
+			Calories:     650,
+			AvgHeartRate: 145,
+			MaxHeartRate: 172,
+		},
+	}, laps)
+
+	unmatched := got[1]
+	assert.Equal(t, g.Metadata, unmatched.Metadata)
+	assert.Equal(t, g.Extensions, unmatched.Extensions)
+
+	_, err = gpx.ImportGarminExport(filepath.Join(t.TempDir(), "missing.zip"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package gpx
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// A Geocoder returns the names of places.
+type Geocoder interface {
+	// ReverseGeocode returns the name of the place at each of wpts, or an
+	// empty string where the place is not known.
+	ReverseGeocode(ctx context.Context, wpts []*WptType) ([]string, error)
+}
+
+// ReverseGeocodeOptions are options for GPX.ReverseGeocode.
+type ReverseGeocodeOptions struct {
+	// BatchSize is the maximum number of points passed to the Geocoder at
+	// once. If zero, all points are passed at once.
+	BatchSize int
+	// Interval is the minimum time between the starts of consecutive calls
+	// to the Geocoder.
+	Interval time.Duration
+	// Waypoints sets the names of waypoints without names.
+	Waypoints bool
+	// Tracks sets the descriptions of tracks without descriptions to where
+	// they started and ended.
+	Tracks bool
+	// Metadata sets the description of the document's metadata, if it has
+	// none, to where its first track started and its last track ended.
+	Metadata bool
+}
+
+// ReverseGeocode annotates g with place names from geocoder, as selected by
+// options.
+func (g *GPX) ReverseGeocode(ctx context.Context, geocoder Geocoder, options ReverseGeocodeOptions) error {
+	var wpts []*WptType
+	var trks []*TrkType
+	if options.Waypoints {
+		for _, wpt := range g.Wpt {
+			if wpt.Name == "" {
+				wpts = append(wpts, wpt)
+			}
+		}
+	}
+	for _, trk := range g.Trk {
+		if len(trk.trkPts()) != 0 {
+			trks = append(trks, trk)
+		}
+	}
+	switch {
+	case options.Tracks:
+	case options.Metadata && (g.Metadata == nil || g.Metadata.Desc == "") && len(trks) > 2:
+		trks = []*TrkType{trks[0], trks[len(trks)-1]}
+	case options.Metadata && (g.Metadata == nil || g.Metadata.Desc == ""):
+	default:
+		trks = nil
+	}
+	// Look up waypoints, then the first and last points of each track.
+	queries := append([]*WptType(nil), wpts...)
+	for _, trk := range trks {
+		trkPts := trk.trkPts()
+		queries = append(queries, trkPts[0], trkPts[len(trkPts)-1])
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	names, err := reverseGeocode(ctx, geocoder, queries, options)
+	if err != nil {
+		return err
+	}
+	for i, wpt := range wpts {
+		wpt.Name = names[i]
+	}
+	names = names[len(wpts):]
+	if options.Tracks {
+		for i, trk := range trks {
+			if trk.Desc == "" {
+				trk.Desc = startedEnded(names[2*i], names[2*i+1])
+			}
+		}
+	}
+	if options.Metadata && len(trks) != 0 {
+		if metadata := g.metadata(); metadata.Desc == "" {
+			metadata.Desc = startedEnded(names[0], names[len(names)-1])
+		}
+	}
+	return nil
+}
+
+// reverseGeocode returns the names of the places at wpts from geocoder, in
+// batches of at most options.BatchSize at least options.Interval apart.
+func reverseGeocode(ctx context.Context, geocoder Geocoder, wpts []*WptType, options ReverseGeocodeOptions) ([]string, error) {
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(wpts)
+	}
+	names := make([]string, 0, len(wpts))
+	var last time.Time
+	for start := 0; start < len(wpts); start += batchSize {
+		if wait := options.Interval - time.Since(last); start != 0 && wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		last = time.Now()
+		batch := wpts[start:min(start+batchSize, len(wpts))]
+		batchNames, err := geocoder.ReverseGeocode(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(batchNames) != len(batch) {
+			return nil, fmt.Errorf("got %d names, want %d", len(batchNames), len(batch))
+		}
+		names = append(names, batchNames...)
+	}
+	return names, nil
+}
+
+// startedEnded returns a description of a journey from start to end, either
+// of which may be unknown.
+func startedEnded(start, end string) string {
+	switch {
+	case start == "" && end == "":
+		return ""
+	case start == end:
+		return "Started and ended in " + start
+	case end == "":
+		return "Started in " + start
+	case start == "":
+		return "Ended in " + end
+	default:
+		return "Started in " + start + ", ended in " + end
+	}
+}
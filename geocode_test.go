@@ -0,0 +1,105 @@
+package gpx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+type testGeocoder struct {
+	places  map[float64]string
+	batches [][]*gpx.WptType
+}
+
+func (g *testGeocoder) ReverseGeocode(_ context.Context, wpts []*gpx.WptType) ([]string, error) {
+	g.batches = append(g.batches, wpts)
+	names := make([]string, len(wpts))
+	for i, wpt := range wpts {
+		names[i] = g.places[wpt.Lat]
+	}
+	return names, nil
+}
+
+func TestReverseGeocode(t *testing.T) {
+	newGPX := func() *gpx.GPX {
+		return &gpx.GPX{
+			Wpt: []*gpx.WptType{
+				{Lat: 1},
+				{Lat: 2, Name: "Summit"},
+				{Lat: 9},
+			},
+			Trk: []*gpx.TrkType{
+				{
+					TrkSeg: []*gpx.TrkSegType{
+						{TrkPt: []*gpx.WptType{{Lat: 3}, {Lat: 5}}},
+						{TrkPt: []*gpx.WptType{{Lat: 4}}},
+					},
+				},
+				{
+					Desc: "Existing",
+					TrkSeg: []*gpx.TrkSegType{
+						{TrkPt: []*gpx.WptType{{Lat: 4}, {Lat: 4}}},
+					},
+				},
+				{
+					TrkSeg: []*gpx.TrkSegType{
+						{TrkPt: []*gpx.WptType{{Lat: 4}, {Lat: 9}}},
+					},
+				},
+			},
+		}
+	}
+	geocoder := &testGeocoder{
+		places: map[float64]string{
+			1: "Freiburg",
+			2: "Feldberg",
+			3: "Freiburg",
+			4: "Basel",
+		},
+	}
+
+	g := newGPX()
+	assert.NoError(t, g.ReverseGeocode(context.Background(), geocoder, gpx.ReverseGeocodeOptions{
+		BatchSize: 4,
+		Interval:  time.Millisecond,
+		Waypoints: true,
+		Tracks:    true,
+		Metadata:  true,
+	}))
+	assert.Len(t, geocoder.batches, 2)
+	assert.Equal(t, "Freiburg", g.Wpt[0].Name)
+	assert.Equal(t, "Summit", g.Wpt[1].Name)
+	assert.Equal(t, "", g.Wpt[2].Name)
+	assert.Equal(t, "Started in Freiburg, ended in Basel", g.Trk[0].Desc)
+	assert.Equal(t, "Existing", g.Trk[1].Desc)
+	assert.Equal(t, "Started in Basel", g.Trk[2].Desc)
+	assert.Equal(t, "Started in Freiburg", g.Metadata.Desc)
+
+	geocoder.batches = nil
+	g = newGPX()
+	assert.NoError(t, g.ReverseGeocode(context.Background(), geocoder, gpx.ReverseGeocodeOptions{
+		Metadata: true,
+	}))
+	assert.Len(t, geocoder.batches, 1)
+	assert.Equal(t, "", g.Wpt[0].Name)
+	assert.Equal(t, "", g.Trk[0].Desc)
+	assert.Equal(t, "Started in Freiburg", g.Metadata.Desc)
+
+	geocoder.batches = nil
+	g = newGPX()
+	assert.NoError(t, g.ReverseGeocode(context.Background(), geocoder, gpx.ReverseGeocodeOptions{}))
+	assert.Empty(t, geocoder.batches)
+	assert.Nil(t, g.Metadata)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, newGPX().ReverseGeocode(ctx, geocoder, gpx.ReverseGeocodeOptions{
+		BatchSize: 1,
+		Interval:  time.Hour,
+		Waypoints: true,
+	}), context.Canceled)
+}
@@ -0,0 +1,192 @@
+package gpx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// A DistanceFunc returns the distance in meters between two points given by
+// their latitudes and longitudes in degrees.
+type DistanceFunc func(lat1, lon1, lat2, lon2 float64) float64
+
+// HaversineDistance returns the great-circle distance in meters between two
+// points.
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	sinDPhi := math.Sin((phi2 - phi1) / 2)
+	sinDLambda := math.Sin((lon2 - lon1) * math.Pi / 360)
+	a := sinDPhi*sinDPhi + math.Cos(phi1)*math.Cos(phi2)*sinDLambda*sinDLambda
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// EquirectangularDistance returns the approximate distance in meters between
+// two points using an equirectangular projection. It is several times faster
+// than HaversineDistance and is accurate for the short distances between
+// consecutive points of dense tracks, but its error grows with distance and
+// latitude.
+func EquirectangularDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	x := normalizeLon(lon2-lon1) * math.Pi / 180 * math.Cos((lat1+lat2)*math.Pi/360)
+	y := (lat2 - lat1) * math.Pi / 180
+	return earthRadius * math.Sqrt(x*x+y*y)
+}
+
+// Length returns the length of r in meters using distance, or
+// HaversineDistance if distance is nil.
+func (r *RteType) Length(distance DistanceFunc) float64 {
+	return length(r.RtePt, distance)
+}
+
+// Length returns the length of t in meters using distance, or
+// HaversineDistance if distance is nil.
+func (t *TrkType) Length(distance DistanceFunc) float64 {
+	result := 0.0
+	for _, ts := range t.TrkSeg {
+		result += ts.Length(distance)
+	}
+	return result
+}
+
+// Length returns the length of ts in meters using distance, or
+// HaversineDistance if distance is nil.
+func (ts *TrkSegType) Length(distance DistanceFunc) float64 {
+	return length(ts.TrkPt, distance)
+}
+
+// length returns the length of the path through wpts.
+func length(wpts []*WptType, distance DistanceFunc) float64 {
+	if distance == nil {
+		distance = HaversineDistance
+	}
+	result := 0.0
+	for i := 1; i < len(wpts); i++ {
+		result += distance(wpts[i-1].Lat, wpts[i-1].Lon, wpts[i].Lat, wpts[i].Lon)
+	}
+	return result
+}
+
+// CumulativeDistances returns the distance in meters along r to each of its
+// points, using HaversineDistance.
+func (r *RteType) CumulativeDistances() []float64 {
+	return cumulativeDistances(r.RtePt)
+}
+
+// CumulativeDistances returns the distance in meters along ts to each of its
+// points, using HaversineDistance.
+func (ts *TrkSegType) CumulativeDistances() []float64 {
+	return cumulativeDistances(ts.TrkPt)
+}
+
+// PointAtDistance returns a new point meters along ts, interpolated between
+// its neighboring points, or nil if meters is negative or greater than the
+// length of ts.
+func (ts *TrkSegType) PointAtDistance(meters float64) *WptType {
+	return pointAtDistance(ts.TrkPt, cumulativeDistances(ts.TrkPt), meters)
+}
+
+// PointAtDistance returns a new point meters along t, interpolated between its
+// neighboring points, or nil if meters is negative or greater than the length
+// of t. The gaps between segments are not included in the distance.
+func (t *TrkType) PointAtDistance(meters float64) *WptType {
+	if meters < 0 {
+		return nil
+	}
+	for _, ts := range t.TrkSeg {
+		distances := cumulativeDistances(ts.TrkPt)
+		if len(distances) == 0 {
+			continue
+		}
+		if total := distances[len(distances)-1]; meters > total {
+			meters -= total
+			continue
+		}
+		return pointAtDistance(ts.TrkPt, distances, meters)
+	}
+	return nil
+}
+
+// cumulativeDistances returns the distance along wpts to each point.
+func cumulativeDistances(wpts []*WptType) []float64 {
+	if len(wpts) == 0 {
+		return nil
+	}
+	distances := make([]float64, len(wpts))
+	for i := 1; i < len(wpts); i++ {
+		distances[i] = distances[i-1] + HaversineDistance(wpts[i-1].Lat, wpts[i-1].Lon, wpts[i].Lat, wpts[i].Lon)
+	}
+	return distances
+}
+
+// pointAtDistance returns the point meters along wpts, given the cumulative
+// distances to each point.
+func pointAtDistance(wpts []*WptType, distances []float64, meters float64) *WptType {
+	if len(wpts) == 0 || meters < 0 || meters > distances[len(distances)-1] {
+		return nil
+	}
+	i := sort.SearchFloat64s(distances, meters)
+	if distances[i] == meters {
+		wpt := *wpts[i]
+		return &wpt
+	}
+	f := (meters - distances[i-1]) / (distances[i] - distances[i-1])
+	return interpolate(wpts[i-1], wpts[i], f)
+}
+
+// interpolate returns a new point a fraction f of the way from a to b. Its
+// position, elevation, and time are linearly interpolated. It has an
+// elevation and a time only if both a and b do.
+func interpolate(a, b *WptType, f float64) *WptType {
+	wpt := &WptType{
+		Lat: a.Lat + f*(b.Lat-a.Lat),
+		Lon: normalizeLon(a.Lon + f*normalizeLon(b.Lon-a.Lon)),
+	}
+	if a.Has(WptEle) && b.Has(WptEle) {
+		wpt.setEle(a.Ele + f*(b.Ele-a.Ele))
+	}
+	if !a.Time.IsZero() && !b.Time.IsZero() {
+		wpt.Time = a.Time.Add(time.Duration(f * float64(b.Time.Sub(a.Time))))
+	}
+	return wpt
+}
+
+// bearing returns the initial bearing in degrees clockwise from north of the
+// great-circle path from lat1, lon1 to lat2, lon2, in the range [0, 360).
+func bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	theta := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(theta+360, 360)
+}
+
+// offset returns the position north meters north and east meters east of
+// lat, lon. Positions beyond a pole are reflected across it and longitudes are
+// normalized to [-180, 180). At the poles, east is ignored.
+func offset(lat, lon, north, east float64) (float64, float64) {
+	if cosLat := math.Cos(lat * math.Pi / 180); cosLat > 1e-12 {
+		lon += east / (earthRadius * cosLat) * 180 / math.Pi
+	}
+	lat += north / earthRadius * 180 / math.Pi
+	if lat < -90 || lat > 90 {
+		// Reflect lat across the pole it passed, onto the opposite meridian.
+		lat = math.Mod(lat+90, 360)
+		if lat < 0 {
+			lat += 360
+		}
+		if lat > 180 {
+			lat = 360 - lat
+			lon += 180
+		}
+		lat -= 90
+	}
+	if lon < -180 || lon >= 180 {
+		lon = normalizeLon(lon)
+	}
+	return lat, lon
+}
@@ -0,0 +1,129 @@
+package gpx_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestDistance(t *testing.T) {
+	for i, tc := range []struct {
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+		delta                  float64
+	}{
+		{
+			lat1:     0,
+			lon1:     0,
+			lat2:     0,
+			lon2:     0,
+			expected: 0,
+			delta:    1e-9,
+		},
+		{
+			lat1:     0,
+			lon1:     0,
+			lat2:     0,
+			lon2:     1,
+			expected: 111195,
+			delta:    1,
+		},
+		{
+			lat1:     42.43095,
+			lon1:     -71.107628,
+			lat2:     42.43124,
+			lon2:     -71.109236,
+			expected: 135.85,
+			delta:    0.01,
+		},
+		{
+			lat1:     0,
+			lon1:     179.5,
+			lat2:     0,
+			lon2:     -179.5,
+			expected: 111195,
+			delta:    1,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.InDelta(t, tc.expected, gpx.HaversineDistance(tc.lat1, tc.lon1, tc.lat2, tc.lon2), tc.delta)
+			assert.InDelta(t, tc.expected, gpx.EquirectangularDistance(tc.lat1, tc.lon1, tc.lat2, tc.lon2), tc.delta)
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	f, err := os.Open("testdata/ashland.gpx")
+	assert.NoError(t, err)
+	defer f.Close()
+	g, err := gpx.Read(f)
+	assert.NoError(t, err)
+
+	haversineLength := g.Trk[0].Length(nil)
+	assert.Greater(t, haversineLength, 0.0)
+	assert.InDelta(t, haversineLength, g.Trk[0].Length(gpx.EquirectangularDistance), haversineLength*1e-4)
+	assert.Equal(t, 0.0, (&gpx.RteType{}).Length(nil))
+}
+
+func TestCumulativeDistances(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.001},
+			{Lat: 0, Lon: 0.003},
+		},
+	}
+	d := gpx.HaversineDistance(0, 0, 0, 0.001)
+	got := ts.CumulativeDistances()
+	assert.Len(t, got, 3)
+	assert.Equal(t, 0.0, got[0])
+	assert.InDelta(t, d, got[1], 1e-6)
+	assert.InDelta(t, 3*d, got[2], 1e-6)
+	assert.Equal(t, got, (&gpx.RteType{RtePt: ts.TrkPt}).CumulativeDistances())
+	assert.Nil(t, (&gpx.TrkSegType{}).CumulativeDistances())
+}
+
+func TestPointAtDistance(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	d := gpx.HaversineDistance(0, 0, 0, 0.001)
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 0, Lon: 0, Ele: 100, Time: t0},
+					{Lat: 0, Lon: 0.001, Ele: 200, Time: t0.Add(10 * time.Second), Name: "a"},
+				},
+			},
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 1, Lon: 0},
+					{Lat: 1, Lon: 0.001},
+				},
+			},
+		},
+	}
+
+	got := trk.TrkSeg[0].PointAtDistance(d / 4)
+	assert.InDelta(t, 0.00025, got.Lon, 1e-9)
+	assert.InDelta(t, 125, got.Ele, 1e-6)
+	assert.Equal(t, t0.Add(2500*time.Millisecond), got.Time)
+
+	got = trk.PointAtDistance(d)
+	assert.Equal(t, "a", got.Name)
+	assert.NotSame(t, trk.TrkSeg[0].TrkPt[1], got)
+
+	got = trk.PointAtDistance(d + gpx.HaversineDistance(1, 0, 1, 0.001)/2)
+	assert.InDelta(t, 1, got.Lat, 1e-9)
+	assert.InDelta(t, 0.0005, got.Lon, 1e-9)
+	assert.True(t, got.Time.IsZero())
+
+	assert.Nil(t, trk.PointAtDistance(-1))
+	assert.Nil(t, trk.PointAtDistance(3*d))
+	assert.Nil(t, trk.TrkSeg[0].PointAtDistance(2*d))
+	assert.Nil(t, (&gpx.TrkSegType{}).PointAtDistance(0))
+}
@@ -0,0 +1,243 @@
+package gpx
+
+import (
+	"fmt"
+	"time"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+// FromGeom returns a new GPX 1.1 document containing g. Points and the points
+// of MultiPoints become waypoints, LineStrings become single-segment tracks,
+// MultiLineStrings become tracks, and GeometryCollections are added
+// recursively. Z and M coordinates become elevations and times respectively.
+func FromGeom(g geom.T) (*GPX, error) {
+	result := &GPX{
+		Version: "1.1",
+	}
+	if err := result.addGeom(g); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NewGPXFromGeoms returns a new GPX 1.1 document containing gs, as described
+// in FromGeom.
+func NewGPXFromGeoms(gs []geom.T) (*GPX, error) {
+	result := &GPX{
+		Version: "1.1",
+	}
+	for i, g := range gs {
+		if err := result.addGeom(g); err != nil {
+			return nil, fmt.Errorf("geometry %d: %w", i, err)
+		}
+	}
+	return result, nil
+}
+
+// GeomData contains non-coordinate data for constructing GPX types from
+// geometries. Per-point slices are indexed by point in coordinate order and
+// may be shorter than the number of points. Non-zero times override times
+// from M coordinates.
+type GeomData struct {
+	Name            string
+	PointNames      []string
+	PointTimes      []time.Time
+	PointExtensions []*ExtensionsType
+}
+
+// NewWptTypeWithData returns a new WptType with geometry g and name, time,
+// and extensions from data. The waypoint's name is data.Name. It returns nil
+// if g is empty.
+func NewWptTypeWithData(g *geom.Point, data *GeomData) *WptType {
+	wpt := NewWptType(g)
+	if wpt != nil && data != nil {
+		wpt.Name = data.Name
+		data.setPoint(wpt, 0)
+	}
+	return wpt
+}
+
+// NewRteTypeWithData returns a new RteType with geometry g and names, times,
+// and extensions from data.
+func NewRteTypeWithData(g *geom.LineString, data *GeomData) *RteType {
+	rte := NewRteType(g)
+	if data != nil {
+		rte.Name = data.Name
+		for i, rtePt := range rte.RtePt {
+			data.setPoint(rtePt, i)
+		}
+	}
+	return rte
+}
+
+// NewTrkTypeWithData returns a new TrkType with geometry g and names, times,
+// and extensions from data. Points are indexed across all of g's LineStrings.
+func NewTrkTypeWithData(g *geom.MultiLineString, data *GeomData) *TrkType {
+	trk := NewTrkType(g)
+	if data != nil {
+		trk.Name = data.Name
+		i := 0
+		for _, trkSeg := range trk.TrkSeg {
+			for _, trkPt := range trkSeg.TrkPt {
+				data.setPoint(trkPt, i)
+				i++
+			}
+		}
+	}
+	return trk
+}
+
+// setPoint sets the name, time, and extensions of wpt from the ith point of
+// d.
+func (d *GeomData) setPoint(wpt *WptType, i int) {
+	if i < len(d.PointNames) && d.PointNames[i] != "" {
+		wpt.Name = d.PointNames[i]
+	}
+	if i < len(d.PointTimes) && !d.PointTimes[i].IsZero() {
+		wpt.Time = d.PointTimes[i]
+	}
+	if i < len(d.PointExtensions) && d.PointExtensions[i] != nil {
+		wpt.Extensions = d.PointExtensions[i]
+	}
+}
+
+// addGeom adds t to g.
+func (g *GPX) addGeom(t geom.T) error {
+	switch t := t.(type) {
+	case *geom.Point:
+		if t.Empty() {
+			return fmt.Errorf("%T: empty point", t)
+		}
+		g.Wpt = append(g.Wpt, NewWptType(t))
+	case *geom.MultiPoint:
+		for i := 0; i < t.NumPoints(); i++ {
+			point := t.Point(i)
+			if point.Empty() {
+				return fmt.Errorf("%T: point %d: empty point", t, i)
+			}
+			g.Wpt = append(g.Wpt, NewWptType(point))
+		}
+	case *geom.LineString:
+		g.Trk = append(g.Trk, &TrkType{
+			TrkSeg: []*TrkSegType{
+				NewTrkSegType(t),
+			},
+		})
+	case *geom.MultiLineString:
+		g.Trk = append(g.Trk, NewTrkType(t))
+	case *geom.GeometryCollection:
+		for _, child := range t.Geoms() {
+			if err := g.addGeom(child); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%T: unsupported geometry type", t)
+	}
+	return nil
+}
+
+// GeomAuto returns w's geometry with the smallest layout that includes its
+// elevation and time, if set.
+func (w *WptType) GeomAuto() *geom.Point {
+	return w.Geom(autoLayout(w))
+}
+
+// GeomStrict returns w's geometry, or an error if layout includes elevations
+// or times and w does not have them.
+func (w *WptType) GeomStrict(layout geom.Layout) (*geom.Point, error) {
+	if err := checkLayout(layout, w); err != nil {
+		return nil, err
+	}
+	return w.Geom(layout), nil
+}
+
+// GeomAuto returns r's geometry with the smallest layout that includes the
+// elevations and times of its points, if any are set.
+func (r *RteType) GeomAuto() *geom.LineString {
+	return r.Geom(autoLayout(r.RtePt...))
+}
+
+// GeomStrict returns r's geometry, or an error if layout includes elevations
+// or times and any of r's points do not have them.
+func (r *RteType) GeomStrict(layout geom.Layout) (*geom.LineString, error) {
+	if err := checkLayout(layout, r.RtePt...); err != nil {
+		return nil, err
+	}
+	return r.Geom(layout), nil
+}
+
+// GeomAuto returns ts's geometry with the smallest layout that includes the
+// elevations and times of its points, if any are set.
+func (ts *TrkSegType) GeomAuto() *geom.LineString {
+	return ts.Geom(autoLayout(ts.TrkPt...))
+}
+
+// GeomStrict returns ts's geometry, or an error if layout includes elevations
+// or times and any of ts's points do not have them.
+func (ts *TrkSegType) GeomStrict(layout geom.Layout) (*geom.LineString, error) {
+	if err := checkLayout(layout, ts.TrkPt...); err != nil {
+		return nil, err
+	}
+	return ts.Geom(layout), nil
+}
+
+// GeomAuto returns t's geometry with the smallest layout that includes the
+// elevations and times of its points, if any are set.
+func (t *TrkType) GeomAuto() *geom.MultiLineString {
+	return t.Geom(autoLayout(t.trkPts()...))
+}
+
+// GeomStrict returns t's geometry, or an error if layout includes elevations
+// or times and any of t's points do not have them.
+func (t *TrkType) GeomStrict(layout geom.Layout) (*geom.MultiLineString, error) {
+	if err := checkLayout(layout, t.trkPts()...); err != nil {
+		return nil, err
+	}
+	return t.Geom(layout), nil
+}
+
+// trkPts returns all of t's points.
+func (t *TrkType) trkPts() []*WptType {
+	var trkPts []*WptType
+	for _, ts := range t.TrkSeg {
+		trkPts = append(trkPts, ts.TrkPt...)
+	}
+	return trkPts
+}
+
+// autoLayout returns the smallest layout that includes the elevations and
+// times of wpts.
+func autoLayout(wpts ...*WptType) geom.Layout {
+	hasEle, hasTime := false, false
+	for _, wpt := range wpts {
+		hasEle = hasEle || wpt.Has(WptEle)
+		hasTime = hasTime || !wpt.Time.IsZero()
+	}
+	switch {
+	case hasEle && hasTime:
+		return geom.XYZM
+	case hasEle:
+		return geom.XYZ
+	case hasTime:
+		return geom.XYM
+	default:
+		return geom.XY
+	}
+}
+
+// checkLayout returns an error if layout includes elevations or times and any
+// of wpts do not have them.
+func checkLayout(layout geom.Layout, wpts ...*WptType) error {
+	zIndex, mIndex := layout.ZIndex(), layout.MIndex()
+	for i, wpt := range wpts {
+		if zIndex != -1 && !wpt.Has(WptEle) {
+			return fmt.Errorf("point %d: no elevation", i)
+		}
+		if mIndex != -1 && wpt.Time.IsZero() {
+			return fmt.Errorf("point %d: no time", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	geom "github.com/twpayne/go-geom"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFromGeom(t *testing.T) {
+	for i, tc := range []struct {
+		g           geom.T
+		expected    *gpx.GPX
+		expectedErr string
+	}{
+		{
+			g: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+			expected: &gpx.GPX{
+				Version: "1.1",
+				Wpt: []*gpx.WptType{
+					{Lat: 2, Lon: 1, Ele: 3},
+				},
+			},
+		},
+		{
+			g: geom.NewMultiPoint(geom.XYM).MustSetCoords([]geom.Coord{{1, 2, 946684800}, {3, 4, 946684801}}),
+			expected: &gpx.GPX{
+				Version: "1.1",
+				Wpt: []*gpx.WptType{
+					{Lat: 2, Lon: 1, Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+					{Lat: 4, Lon: 3, Time: time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)},
+				},
+			},
+		},
+		{
+			g: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			expected: &gpx.GPX{
+				Version: "1.1",
+				Trk: []*gpx.TrkType{
+					{
+						TrkSeg: []*gpx.TrkSegType{
+							{
+								TrkPt: []*gpx.WptType{
+									{Lat: 2, Lon: 1},
+									{Lat: 4, Lon: 3},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{3, 4}}, {{5, 6}}}),
+			),
+			expected: &gpx.GPX{
+				Version: "1.1",
+				Wpt: []*gpx.WptType{
+					{Lat: 2, Lon: 1},
+				},
+				Trk: []*gpx.TrkType{
+					{
+						TrkSeg: []*gpx.TrkSegType{
+							{
+								TrkPt: []*gpx.WptType{
+									{Lat: 4, Lon: 3},
+								},
+							},
+							{
+								TrkPt: []*gpx.WptType{
+									{Lat: 6, Lon: 5},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			g:           geom.NewPolygon(geom.XY),
+			expectedErr: "*geom.Polygon: unsupported geometry type",
+		},
+		{
+			g:           geom.NewPointEmpty(geom.XY),
+			expectedErr: "*geom.Point: empty point",
+		},
+		{
+			g: geom.NewLineString(geom.NoLayout),
+			expected: &gpx.GPX{
+				Version: "1.1",
+				Trk: []*gpx.TrkType{
+					{
+						TrkSeg: []*gpx.TrkSegType{
+							{
+								TrkPt: []*gpx.WptType{},
+							},
+						},
+					},
+				},
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := gpx.FromGeom(tc.g)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	assert.True(t, gpx.NewWptType(geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 0})).Has(gpx.WptEle))
+	assert.Nil(t, gpx.NewWptType(geom.NewPointEmpty(geom.XY)))
+	assert.Nil(t, gpx.NewPtType(geom.NewPointEmpty(geom.XY)))
+}
+
+func TestNewGPXFromGeoms(t *testing.T) {
+	g, err := gpx.NewGPXFromGeoms([]geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{3, 4}, {5, 6}}),
+		geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{7, 8}}, {{9, 10}}}),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &gpx.GPX{
+		Version: "1.1",
+		Wpt: []*gpx.WptType{
+			{Lat: 2, Lon: 1},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{{Lat: 4, Lon: 3}, {Lat: 6, Lon: 5}}},
+				},
+			},
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{{Lat: 8, Lon: 7}}},
+					{TrkPt: []*gpx.WptType{{Lat: 10, Lon: 9}}},
+				},
+			},
+		},
+	}, g)
+
+	_, err = gpx.NewGPXFromGeoms([]geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewPolygon(geom.XY),
+	})
+	assert.EqualError(t, err, "geometry 1: *geom.Polygon: unsupported geometry type")
+}
+
+func TestNewTypesWithData(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	extensions := &gpx.ExtensionsType{XML: []byte("<hr>120</hr>")}
+
+	assert.Equal(t, &gpx.WptType{
+		Lat:        2,
+		Lon:        1,
+		Name:       "summit",
+		Time:       t0,
+		Extensions: extensions,
+	}, gpx.NewWptTypeWithData(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}), &gpx.GeomData{
+		Name:            "summit",
+		PointTimes:      []time.Time{t0},
+		PointExtensions: []*gpx.ExtensionsType{extensions},
+	}))
+
+	assert.Equal(t, &gpx.RteType{
+		Name: "route",
+		RtePt: []*gpx.WptType{
+			{Lat: 2, Lon: 1, Name: "a", Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Lat: 4, Lon: 3, Time: time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)},
+		},
+	}, gpx.NewRteTypeWithData(geom.NewLineString(geom.XYM).MustSetCoords([]geom.Coord{{1, 2, 946684800}, {3, 4, 946684801}}), &gpx.GeomData{
+		Name:       "route",
+		PointNames: []string{"a"},
+	}))
+
+	assert.Equal(t, &gpx.TrkType{
+		Name: "track",
+		TrkSeg: []*gpx.TrkSegType{
+			{TrkPt: []*gpx.WptType{{Lat: 2, Lon: 1, Time: t0}}},
+			{TrkPt: []*gpx.WptType{{Lat: 4, Lon: 3, Time: t0.Add(time.Second), Extensions: extensions}}},
+		},
+	}, gpx.NewTrkTypeWithData(geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}}, {{3, 4}}}), &gpx.GeomData{
+		Name:            "track",
+		PointTimes:      []time.Time{t0, t0.Add(time.Second)},
+		PointExtensions: []*gpx.ExtensionsType{nil, extensions},
+	}))
+
+	assert.Equal(t, &gpx.WptType{Lat: 2, Lon: 1}, gpx.NewWptTypeWithData(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}), nil))
+}
+
+func TestGeomAuto(t *testing.T) {
+	t0 := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, tc := range []struct {
+		trkPts         []*gpx.WptType
+		expectedLayout geom.Layout
+	}{
+		{
+			trkPts:         []*gpx.WptType{{Lat: 1, Lon: 2}},
+			expectedLayout: geom.XY,
+		},
+		{
+			trkPts:         []*gpx.WptType{{Lat: 1, Lon: 2}, {Lat: 3, Lon: 4, Ele: 5}},
+			expectedLayout: geom.XYZ,
+		},
+		{
+			trkPts:         []*gpx.WptType{{Lat: 1, Lon: 2, Time: t0}},
+			expectedLayout: geom.XYM,
+		},
+		{
+			trkPts:         []*gpx.WptType{{Lat: 1, Lon: 2, Time: t0}, {Lat: 3, Lon: 4, Ele: 5}},
+			expectedLayout: geom.XYZM,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ts := &gpx.TrkSegType{TrkPt: tc.trkPts}
+			assert.Equal(t, tc.expectedLayout, ts.GeomAuto().Layout())
+			assert.Equal(t, tc.expectedLayout, (&gpx.RteType{RtePt: tc.trkPts}).GeomAuto().Layout())
+			assert.Equal(t, tc.expectedLayout, (&gpx.TrkType{TrkSeg: []*gpx.TrkSegType{ts}}).GeomAuto().Layout())
+			assert.Equal(t, ts.Geom(tc.expectedLayout), ts.GeomAuto())
+		})
+	}
+	assert.Equal(t, geom.XYZ, (&gpx.WptType{Ele: 1}).GeomAuto().Layout())
+}
+
+func TestGeomStrict(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 1, Lon: 2, Ele: 3, Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Lat: 4, Lon: 5, Ele: 6},
+		},
+	}
+
+	got, err := ts.GeomStrict(geom.XYZ)
+	assert.NoError(t, err)
+	assert.Equal(t, ts.Geom(geom.XYZ), got)
+
+	_, err = ts.GeomStrict(geom.XYM)
+	assert.EqualError(t, err, "point 1: no time")
+
+	_, err = (&gpx.TrkType{TrkSeg: []*gpx.TrkSegType{ts}}).GeomStrict(geom.XYZM)
+	assert.EqualError(t, err, "point 1: no time")
+
+	_, err = (&gpx.RteType{RtePt: []*gpx.WptType{{}}}).GeomStrict(geom.XYZ)
+	assert.EqualError(t, err, "point 0: no elevation")
+
+	_, err = (&gpx.WptType{}).GeomStrict(geom.XY)
+	assert.NoError(t, err)
+}
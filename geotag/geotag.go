@@ -0,0 +1,130 @@
+// Package geotag locates photos on GPX tracks by their times, for example by
+// the DateTimeOriginal EXIF tags of photos taken while the track was
+// recorded.
+package geotag
+
+import (
+	"fmt"
+	"time"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+// A Photo is a photo to be located.
+type Photo struct {
+	// Name identifies the photo, for example its file name. It is used as the
+	// name of its waypoint.
+	Name string
+	// Time is the time the photo was taken according to the camera's clock,
+	// see gpx.ParseEXIFTime.
+	Time time.Time
+}
+
+// Options control Locate.
+type Options struct {
+	// CameraClockOffset is how far the camera's clock is ahead of the clock
+	// that recorded the track, see gpx.CameraClockOffset. It is subtracted
+	// from the times of photos.
+	CameraClockOffset time.Duration
+	// MaxGap, if positive, is the longest time between two track points
+	// between which photos are located. Photos taken in longer gaps, for
+	// example while the receiver had no fix, are not located.
+	MaxGap time.Duration
+	// MaxExtrapolation is the longest time before the first point or after the
+	// last point of a track segment at which photos are located at that
+	// point. Photos are only extrapolated if they were not taken during any
+	// track segment, and then from the nearest point in time.
+	MaxExtrapolation time.Duration
+	// AddWaypoints adds a waypoint to the document for each located photo.
+	AddWaypoints bool
+}
+
+// A Location is the location of a photo.
+type Location struct {
+	Photo Photo
+	// Wpt is the position of the photo, interpolated between the track points
+	// before and after it, with the corrected time of the photo. It is nil if
+	// the photo could not be located.
+	Wpt *gpx.WptType
+}
+
+// Locate returns the locations of photos on the tracks of g, in the same
+// order as photos. If options.AddWaypoints is set then a waypoint is appended
+// to g for each located photo. It returns an error if the times of any track
+// segment of g are not valid, see gpx.TrkSegType.ValidateTimes.
+func Locate(g *gpx.GPX, photos []Photo, options Options) ([]Location, error) {
+	var trkSegs []*gpx.TrkSegType
+	for i, trk := range g.Trk {
+		for j, ts := range trk.TrkSeg {
+			if err := ts.ValidateTimes(); err != nil {
+				return nil, fmt.Errorf("trk %d: trkseg %d: %w", i, j, err)
+			}
+			if len(ts.TrkPt) > 0 {
+				trkSegs = append(trkSegs, ts)
+			}
+		}
+	}
+
+	locations := make([]Location, 0, len(photos))
+	for _, photo := range photos {
+		t := photo.Time.Add(-options.CameraClockOffset)
+		location := Location{
+			Photo: photo,
+			Wpt:   locate(trkSegs, t, options),
+		}
+		if location.Wpt != nil && options.AddWaypoints {
+			wpt := *location.Wpt
+			wpt.Name = photo.Name
+			g.Wpt = append(g.Wpt, &wpt)
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// locate returns the position on trkSegs at t, or nil if there is none. If
+// t is during a track segment then the position is interpolated within it.
+// Otherwise, the position is extrapolated from the first or last point of a
+// track segment nearest in time to t.
+func locate(trkSegs []*gpx.TrkSegType, t time.Time, options Options) *gpx.WptType {
+	var nearest *gpx.WptType
+	var nearestDuration time.Duration
+	during := false
+	for _, ts := range trkSegs {
+		first, last := ts.TrkPt[0], ts.TrkPt[len(ts.TrkPt)-1]
+		switch {
+		case t.Before(first.Time):
+			if d := first.Time.Sub(t); nearest == nil || d < nearestDuration {
+				nearest, nearestDuration = first, d
+			}
+			continue
+		case t.After(last.Time):
+			if d := t.Sub(last.Time); nearest == nil || d < nearestDuration {
+				nearest, nearestDuration = last, d
+			}
+			continue
+		}
+		during = true
+		if options.MaxGap > 0 {
+			if i := ts.IndexAtTime(t); i < len(ts.TrkPt)-1 && ts.TrkPt[i+1].Time.Sub(ts.TrkPt[i].Time) > options.MaxGap {
+				continue
+			}
+		}
+		return newWpt(ts.PointAtTime(t), t)
+	}
+	if during || nearest == nil || nearestDuration > options.MaxExtrapolation {
+		return nil
+	}
+	return newWpt(nearest, t)
+}
+
+// newWpt returns a new point at the position of wpt at time t.
+func newWpt(wpt *gpx.WptType, t time.Time) *gpx.WptType {
+	return &gpx.WptType{
+		Lat:        wpt.Lat,
+		Lon:        wpt.Lon,
+		Ele:        wpt.Ele,
+		Time:       t,
+		ZeroFields: wpt.ZeroFields & gpx.WptEle,
+	}
+}
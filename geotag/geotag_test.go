@@ -0,0 +1,100 @@
+package geotag_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+	"github.com/twpayne/go-gpx/geotag"
+)
+
+func TestLocate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 46, Lon: 7, Ele: 100, Time: start},
+							{Lat: 46.5, Lon: 7, Ele: 200, Time: start.Add(10 * time.Minute)},
+							{Lat: 47, Lon: 7, Ele: 300, Time: start.Add(time.Hour)},
+						},
+					},
+				},
+			},
+		},
+	}
+	photos := []geotag.Photo{
+		{Name: "a.jpg", Time: start.Add(5 * time.Minute)},
+		{Name: "b.jpg", Time: start.Add(-30 * time.Second)},
+		{Name: "c.jpg", Time: start.Add(-5 * time.Minute)},
+		{Name: "d.jpg", Time: start.Add(30 * time.Minute)},
+		{Name: "e.jpg", Time: start.Add(time.Hour + 30*time.Second)},
+	}
+
+	locations, err := geotag.Locate(g, photos, geotag.Options{
+		MaxGap:           15 * time.Minute,
+		MaxExtrapolation: time.Minute,
+		AddWaypoints:     true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []geotag.Location{
+		{Photo: photos[0], Wpt: &gpx.WptType{Lat: 46.25, Lon: 7, Ele: 150, Time: start.Add(5 * time.Minute)}},
+		{Photo: photos[1], Wpt: &gpx.WptType{Lat: 46, Lon: 7, Ele: 100, Time: start.Add(-30 * time.Second)}},
+		{Photo: photos[2]},
+		{Photo: photos[3]},
+		{Photo: photos[4], Wpt: &gpx.WptType{Lat: 47, Lon: 7, Ele: 300, Time: start.Add(time.Hour + 30*time.Second)}},
+	}, locations)
+	assert.Len(t, g.Wpt, 3)
+	assert.Equal(t, "a.jpg", g.Wpt[0].Name)
+	assert.Equal(t, "e.jpg", g.Wpt[2].Name)
+	assert.Empty(t, locations[0].Wpt.Name)
+
+	// The camera's clock is two minutes fast.
+	locations, err = geotag.Locate(g, photos[:1], geotag.Options{
+		CameraClockOffset: 2 * time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(3*time.Minute), locations[0].Wpt.Time)
+	assert.InDelta(t, 46.15, locations[0].Wpt.Lat, 1e-9)
+
+	// Photos during a later track segment are not extrapolated from an
+	// earlier one, and photos between track segments are extrapolated from
+	// the nearest.
+	g2 := &gpx.GPX{
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 0, Lon: 0, Time: start},
+							{Lat: 0, Lon: 1, Time: start.Add(time.Minute)},
+						},
+					},
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 1, Lon: 0, Time: start.Add(2 * time.Minute)},
+							{Lat: 1, Lon: 2, Time: start.Add(4 * time.Minute)},
+						},
+					},
+				},
+			},
+		},
+	}
+	locations, err = geotag.Locate(g2, []geotag.Photo{
+		{Time: start.Add(3 * time.Minute)},
+		{Time: start.Add(100 * time.Second)},
+	}, geotag.Options{
+		MaxExtrapolation: 5 * time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &gpx.WptType{Lat: 1, Lon: 1, Time: start.Add(3 * time.Minute)}, locations[0].Wpt)
+	assert.Equal(t, &gpx.WptType{Lat: 1, Lon: 0, Time: start.Add(100 * time.Second)}, locations[1].Wpt)
+
+	g.Trk[0].TrkSeg[0].TrkPt[1].Time = time.Time{}
+	_, err = geotag.Locate(g, photos, geotag.Options{})
+	assert.ErrorIs(t, err, gpx.ErrNonMonotonicTime)
+}
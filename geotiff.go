@@ -0,0 +1,359 @@
+package gpx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TIFF tags.
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPredictor       = 317
+	tiffTileWidth       = 322
+	tiffTileLength      = 323
+	tiffTileOffsets     = 324
+	tiffTileByteCounts  = 325
+	tiffSampleFormat    = 339
+	tiffModelPixelScale = 33550
+	tiffModelTiepoint   = 33922
+	tiffGeoKeyDirectory = 34735
+	tiffGDALNoData      = 42113
+)
+
+// GeoTIFF keys.
+const (
+	geoKeyModelType  = 1024
+	geoKeyRasterType = 1025
+)
+
+// A GeoTIFF is an ElevationProvider that uses a single-band GeoTIFF
+// elevation raster in geographic coordinates, such as a Copernicus DEM or
+// SRTM GeoTIFF tile. Uncompressed and Deflate-compressed rasters with strips
+// or tiles of integer or floating-point samples are supported. Elevations
+// are bilinearly interpolated. Points outside the raster or near no-data
+// samples have unknown elevations.
+type GeoTIFF struct {
+	width   int
+	height  int
+	samples []float32
+	// lon0 and lat0 are the position of the center of the north-west
+	// sample, and dLon and dLat are the distances between samples.
+	lon0   float64
+	lat0   float64
+	dLon   float64
+	dLat   float64
+	noData float32
+}
+
+// A tiffField is a field of a TIFF image file directory.
+type tiffField struct {
+	typ   uint16
+	count int
+	data  []byte
+}
+
+// ReadGeoTIFF reads a GeoTIFF from r.
+func ReadGeoTIFF(r io.Reader) (*GeoTIFF, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8 {
+		return nil, errors.New("invalid TIFF header")
+	}
+	var byteOrder binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		byteOrder = binary.LittleEndian
+	case "MM":
+		byteOrder = binary.BigEndian
+	default:
+		return nil, errors.New("invalid TIFF header")
+	}
+	if magic := byteOrder.Uint16(data[2:]); magic != 42 {
+		return nil, fmt.Errorf("%d: unsupported TIFF version", magic)
+	}
+
+	// Read the first image file directory.
+	fields := make(map[uint16]tiffField)
+	offset := int(byteOrder.Uint32(data[4:]))
+	if offset+2 > len(data) {
+		return nil, errors.New("invalid TIFF image file directory offset")
+	}
+	n := int(byteOrder.Uint16(data[offset:]))
+	if offset+2+12*n > len(data) {
+		return nil, errors.New("truncated TIFF image file directory")
+	}
+	for i := range n {
+		entry := data[offset+2+12*i:]
+		field := tiffField{
+			typ:   byteOrder.Uint16(entry[2:]),
+			count: int(byteOrder.Uint32(entry[4:])),
+		}
+		size := field.count * tiffTypeSize(field.typ)
+		if size <= 4 {
+			field.data = entry[8 : 8+size]
+		} else {
+			valueOffset := int(byteOrder.Uint32(entry[8:]))
+			if valueOffset+size > len(data) {
+				return nil, fmt.Errorf("%d: truncated TIFF field", byteOrder.Uint16(entry))
+			}
+			field.data = data[valueOffset : valueOffset+size]
+		}
+		fields[byteOrder.Uint16(entry)] = field
+	}
+	uints := func(tag uint16) []int {
+		field := fields[tag]
+		result := make([]int, field.count)
+		for i := range result {
+			switch field.typ {
+			case 1:
+				result[i] = int(field.data[i])
+			case 3:
+				result[i] = int(byteOrder.Uint16(field.data[2*i:]))
+			case 4:
+				result[i] = int(byteOrder.Uint32(field.data[4*i:]))
+			default:
+				return nil
+			}
+		}
+		return result
+	}
+	uintValue := func(tag uint16, defaultValue int) int {
+		if values := uints(tag); len(values) > 0 {
+			return values[0]
+		}
+		return defaultValue
+	}
+	doubles := func(tag uint16) []float64 {
+		field := fields[tag]
+		if field.typ != 12 {
+			return nil
+		}
+		result := make([]float64, field.count)
+		for i := range result {
+			result[i] = math.Float64frombits(byteOrder.Uint64(field.data[8*i:]))
+		}
+		return result
+	}
+
+	g := &GeoTIFF{
+		width:  uintValue(tiffImageWidth, 0),
+		height: uintValue(tiffImageLength, 0),
+		noData: float32(math.NaN()),
+	}
+	if g.width == 0 || g.height == 0 {
+		return nil, errors.New("empty TIFF image")
+	}
+	if samplesPerPixel := uintValue(tiffSamplesPerPixel, 1); samplesPerPixel != 1 {
+		return nil, fmt.Errorf("%d: unsupported TIFF samples per pixel", samplesPerPixel)
+	}
+	compression := uintValue(tiffCompression, 1)
+	if compression != 1 && compression != 8 && compression != 32946 {
+		return nil, fmt.Errorf("%d: unsupported TIFF compression", compression)
+	}
+	bitsPerSample := uintValue(tiffBitsPerSample, 1)
+	sampleFormat := uintValue(tiffSampleFormat, 1)
+	sample, err := tiffSampleDecoder(byteOrder, sampleFormat, bitsPerSample)
+	if err != nil {
+		return nil, err
+	}
+	predictor := uintValue(tiffPredictor, 1)
+	if predictor != 1 && (predictor != 2 || sampleFormat == 3) {
+		return nil, fmt.Errorf("%d: unsupported TIFF predictor", predictor)
+	}
+
+	// Read the georeferencing. Tie points are at the north-west corners of
+	// samples, unless the raster is pixel-is-point.
+	center := 0.5
+	if keys := uints(tiffGeoKeyDirectory); len(keys) >= 4 {
+		for i := 4; i+3 < len(keys) && i < 4+4*keys[3]; i += 4 {
+			switch {
+			case keys[i] == geoKeyModelType && keys[i+1] == 0 && keys[i+3] != 2:
+				return nil, errors.New("not a geographic raster")
+			case keys[i] == geoKeyRasterType && keys[i+1] == 0 && keys[i+3] == 2:
+				center = 0
+			}
+		}
+	}
+	scale, tiepoint := doubles(tiffModelPixelScale), doubles(tiffModelTiepoint)
+	if len(scale) < 2 || len(tiepoint) < 6 || scale[0] <= 0 || scale[1] <= 0 {
+		return nil, errors.New("missing GeoTIFF pixel scale or tie point")
+	}
+	g.dLon, g.dLat = scale[0], scale[1]
+	g.lon0 = tiepoint[3] + (center-tiepoint[0])*g.dLon
+	g.lat0 = tiepoint[4] - (center-tiepoint[1])*g.dLat
+	if field, ok := fields[tiffGDALNoData]; ok {
+		s := strings.TrimSpace(strings.TrimRight(string(field.data), "\x00"))
+		noData, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid GDAL no-data value", s)
+		}
+		g.noData = float32(noData)
+	}
+
+	// Decode the strips or tiles.
+	chunkWidth, chunkHeight := g.width, uintValue(tiffRowsPerStrip, g.height)
+	offsets, byteCounts := uints(tiffStripOffsets), uints(tiffStripByteCounts)
+	if _, ok := fields[tiffTileWidth]; ok {
+		chunkWidth, chunkHeight = uintValue(tiffTileWidth, 0), uintValue(tiffTileLength, 0)
+		offsets, byteCounts = uints(tiffTileOffsets), uints(tiffTileByteCounts)
+	}
+	if chunkWidth <= 0 || chunkHeight <= 0 {
+		return nil, errors.New("invalid TIFF strip or tile size")
+	}
+	chunksAcross := (g.width + chunkWidth - 1) / chunkWidth
+	chunksDown := (g.height + chunkHeight - 1) / chunkHeight
+	if len(offsets) != chunksAcross*chunksDown || len(byteCounts) != len(offsets) {
+		return nil, errors.New("invalid TIFF strip or tile offsets")
+	}
+	bytesPerSample := bitsPerSample / 8
+	g.samples = make([]float32, g.width*g.height)
+	for i, offset := range offsets {
+		if offset+byteCounts[i] > len(data) {
+			return nil, fmt.Errorf("%d: truncated TIFF strip or tile", i)
+		}
+		chunk := data[offset : offset+byteCounts[i]]
+		if compression != 1 {
+			zr, err := zlib.NewReader(bytes.NewReader(chunk))
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			chunk, err = io.ReadAll(zr)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+		}
+		x0, y0 := chunkWidth*(i%chunksAcross), chunkHeight*(i/chunksAcross)
+		rows := min(chunkHeight, g.height-y0)
+		if len(chunk) < bytesPerSample*chunkWidth*rows {
+			return nil, fmt.Errorf("%d: truncated TIFF strip or tile", i)
+		}
+		for row := range rows {
+			var prev uint64
+			for col := range chunkWidth {
+				value := sample(chunk[bytesPerSample*(row*chunkWidth+col):])
+				if predictor == 2 {
+					value += prev
+					value &= 1<<bitsPerSample - 1
+					prev = value
+				}
+				if x0+col < g.width {
+					g.samples[(y0+row)*g.width+x0+col] = tiffSampleValue(value, sampleFormat, bitsPerSample)
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// Elevations implements ElevationProvider.
+func (g *GeoTIFF) Elevations(_ context.Context, wpts []*WptType) ([]float64, error) {
+	elevations := make([]float64, len(wpts))
+	for i, wpt := range wpts {
+		elevations[i] = g.elevation(wpt.Lat, normalizeLon(wpt.Lon))
+	}
+	return elevations, nil
+}
+
+// elevation returns the elevation at lat, lon.
+func (g *GeoTIFF) elevation(lat, lon float64) float64 {
+	// x and y are the position in samples from the center of the north-west
+	// sample. Positions in the outer half of the edge samples are clamped.
+	x := (lon - g.lon0) / g.dLon
+	y := (g.lat0 - lat) / g.dLat
+	if x < -0.5 || x > float64(g.width)-0.5 || y < -0.5 || y > float64(g.height)-0.5 {
+		return math.NaN()
+	}
+	x = math.Max(0, math.Min(x, float64(g.width-1)))
+	y = math.Max(0, math.Min(y, float64(g.height-1)))
+	row, col := min(int(y), max(g.height-2, 0)), min(int(x), max(g.width-2, 0))
+	fy, fx := y-float64(row), x-float64(col)
+	result := 0.0
+	for _, corner := range []struct {
+		row, col int
+		weight   float64
+	}{
+		{row, col, (1 - fy) * (1 - fx)},
+		{row, col + 1, (1 - fy) * fx},
+		{row + 1, col, fy * (1 - fx)},
+		{row + 1, col + 1, fy * fx},
+	} {
+		if corner.weight == 0 {
+			continue
+		}
+		sample := g.samples[corner.row*g.width+corner.col]
+		if math.IsNaN(float64(sample)) || sample == g.noData {
+			return math.NaN()
+		}
+		result += corner.weight * float64(sample)
+	}
+	return result
+}
+
+// tiffTypeSize returns the size in bytes of values of the TIFF field type
+// typ.
+func tiffTypeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// tiffSampleDecoder returns a function that returns the bits of the sample at
+// the start of its argument.
+func tiffSampleDecoder(byteOrder binary.ByteOrder, sampleFormat, bitsPerSample int) (func([]byte) uint64, error) {
+	if sampleFormat < 1 || sampleFormat > 3 || sampleFormat == 3 && bitsPerSample < 32 {
+		return nil, fmt.Errorf("%d: unsupported TIFF sample format", sampleFormat)
+	}
+	switch bitsPerSample {
+	case 8:
+		return func(b []byte) uint64 { return uint64(b[0]) }, nil
+	case 16:
+		return func(b []byte) uint64 { return uint64(byteOrder.Uint16(b)) }, nil
+	case 32:
+		return func(b []byte) uint64 { return uint64(byteOrder.Uint32(b)) }, nil
+	case 64:
+		return byteOrder.Uint64, nil
+	default:
+		return nil, fmt.Errorf("%d: unsupported TIFF bits per sample", bitsPerSample)
+	}
+}
+
+// tiffSampleValue returns the value of the sample with the given bits.
+func tiffSampleValue(bits uint64, sampleFormat, bitsPerSample int) float32 {
+	switch {
+	case sampleFormat == 3 && bitsPerSample == 32:
+		return math.Float32frombits(uint32(bits)) //nolint:gosec
+	case sampleFormat == 3:
+		return float32(math.Float64frombits(bits))
+	case sampleFormat == 2:
+		shift := 64 - bitsPerSample
+		return float32(int64(bits<<shift) >> shift) //nolint:gosec
+	default:
+		return float32(bits)
+	}
+}
@@ -0,0 +1,220 @@
+package gpx_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+// A geoTIFFTestField is a field of a test GeoTIFF.
+type geoTIFFTestField struct {
+	typ    uint16
+	values any
+}
+
+// newGeoTIFFTestData returns a TIFF file with fields and the given strips or
+// tiles, whose offsets and byte counts are set in offsetsTag and
+// byteCountsTag.
+func newGeoTIFFTestData(t *testing.T, byteOrder binary.ByteOrder, fields map[uint16]geoTIFFTestField, offsetsTag, byteCountsTag uint16, chunks ...[]byte) []byte {
+	t.Helper()
+	encode := func(values any) []byte {
+		var b bytes.Buffer
+		assert.NoError(t, binary.Write(&b, byteOrder, values))
+		return b.Bytes()
+	}
+	tags := []int{int(offsetsTag), int(byteCountsTag)}
+	for tag := range fields {
+		tags = append(tags, int(tag))
+	}
+	sort.Ints(tags)
+
+	// The file is the header, the image file directory, the values that do
+	// not fit in the directory, and then the chunks.
+	offsets := make([]uint32, len(chunks))
+	byteCounts := make([]uint32, len(chunks))
+	fields[offsetsTag] = geoTIFFTestField{typ: 4, values: offsets}
+	fields[byteCountsTag] = geoTIFFTestField{typ: 4, values: byteCounts}
+	offset := 8 + 2 + 12*len(tags) + 4
+	for _, tag := range tags {
+		if data := encode(fields[uint16(tag)].values); len(data) > 4 {
+			offset += len(data)
+		}
+	}
+	for i, chunk := range chunks {
+		offsets[i] = uint32(offset)
+		byteCounts[i] = uint32(len(chunk))
+		offset += len(chunk)
+	}
+
+	var b, values bytes.Buffer
+	if byteOrder == binary.LittleEndian {
+		b.WriteString("II")
+	} else {
+		b.WriteString("MM")
+	}
+	b.Write(encode(uint16(42)))
+	b.Write(encode(uint32(8)))
+	b.Write(encode(uint16(len(tags))))
+	valuesOffset := 8 + 2 + 12*len(tags) + 4
+	for _, tag := range tags {
+		field := fields[uint16(tag)]
+		data := encode(field.values)
+		size := map[uint16]int{2: 1, 3: 2, 4: 4, 12: 8}[field.typ]
+		b.Write(encode(uint16(tag)))
+		b.Write(encode(field.typ))
+		b.Write(encode(uint32(len(data) / size)))
+		if len(data) <= 4 {
+			b.Write(append(data, make([]byte, 4-len(data))...))
+		} else {
+			b.Write(encode(uint32(valuesOffset + values.Len())))
+			values.Write(data)
+		}
+	}
+	b.Write(encode(uint32(0)))
+	b.Write(values.Bytes())
+	for _, chunk := range chunks {
+		b.Write(chunk)
+	}
+	return b.Bytes()
+}
+
+func TestGeoTIFF(t *testing.T) {
+	// An uncompressed little-endian pixel-is-area raster in two strips.
+	encode := func(byteOrder binary.ByteOrder, values any) []byte {
+		var b bytes.Buffer
+		assert.NoError(t, binary.Write(&b, byteOrder, values))
+		return b.Bytes()
+	}
+	strips := newGeoTIFFTestData(t, binary.LittleEndian, map[uint16]geoTIFFTestField{
+		256:   {typ: 3, values: uint16(3)},
+		257:   {typ: 3, values: uint16(3)},
+		258:   {typ: 3, values: uint16(16)},
+		278:   {typ: 3, values: uint16(2)},
+		339:   {typ: 3, values: uint16(2)},
+		33550: {typ: 12, values: []float64{0.5, 0.5, 0}},
+		33922: {typ: 12, values: []float64{0, 0, 0, 7, 47.5, 0}},
+		34735: {typ: 3, values: []uint16{1, 1, 0, 1, 1024, 0, 1, 2}},
+		42113: {typ: 2, values: []byte("-9999\x00")},
+	}, 273, 279,
+		encode(binary.LittleEndian, []int16{100, 200, 300, 0, 100, -9999}),
+		encode(binary.LittleEndian, []int16{0, 0, 0}),
+	)
+	provider, err := gpx.ReadGeoTIFF(bytes.NewReader(strips))
+	assert.NoError(t, err)
+	got, err := provider.Elevations(context.Background(), []*gpx.WptType{
+		{Lat: 47.25, Lon: 7.25},
+		{Lat: 47, Lon: 7.5},
+		{Lat: 46.75, Lon: 7.25},
+		{Lat: 46.5, Lon: 8},
+		{Lat: 47.4, Lon: 7.1},
+		{Lat: 46.25, Lon: 8.25},
+		{Lat: 48, Lon: 7},
+	})
+	assert.NoError(t, err)
+	assertElevations(t, []float64{100, 100, 0, math.NaN(), 100, 0, math.NaN()}, got)
+
+	// Sea-level elevations are filled as present.
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{{Lat: 46.75, Lon: 7.25}},
+	}
+	n, err := g.FillElevations(context.Background(), provider)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, g.Wpt[0].Has(gpx.WptEle))
+
+	// A Deflate-compressed big-endian pixel-is-point raster of floats in
+	// four tiles.
+	deflate := func(data []byte) []byte {
+		var b bytes.Buffer
+		zw := zlib.NewWriter(&b)
+		_, err := zw.Write(data)
+		assert.NoError(t, err)
+		assert.NoError(t, zw.Close())
+		return b.Bytes()
+	}
+	tiles := newGeoTIFFTestData(t, binary.BigEndian, map[uint16]geoTIFFTestField{
+		256:   {typ: 3, values: uint16(3)},
+		257:   {typ: 3, values: uint16(3)},
+		258:   {typ: 3, values: uint16(32)},
+		259:   {typ: 3, values: uint16(8)},
+		322:   {typ: 3, values: uint16(2)},
+		323:   {typ: 3, values: uint16(2)},
+		339:   {typ: 3, values: uint16(3)},
+		33550: {typ: 12, values: []float64{1, 1, 0}},
+		33922: {typ: 12, values: []float64{0, 0, 0, 7, 47, 0}},
+		34735: {typ: 3, values: []uint16{1, 1, 0, 2, 1024, 0, 1, 2, 1025, 0, 1, 2}},
+	}, 324, 325,
+		deflate(encode(binary.BigEndian, []float32{0.5, 1.5, 10.5, 11.5})),
+		deflate(encode(binary.BigEndian, []float32{2.5, 0, 12.5, 0})),
+		deflate(encode(binary.BigEndian, []float32{20.5, 21.5, 0, 0})),
+		deflate(encode(binary.BigEndian, []float32{22.5, 0, 0, 0})),
+	)
+	provider, err = gpx.ReadGeoTIFF(bytes.NewReader(tiles))
+	assert.NoError(t, err)
+	got, err = provider.Elevations(context.Background(), []*gpx.WptType{
+		{Lat: 46, Lon: 8},
+		{Lat: 46.5, Lon: 7.5},
+		{Lat: 45, Lon: 9},
+		{Lat: 45.5, Lon: 8.5},
+	})
+	assert.NoError(t, err)
+	assertElevations(t, []float64{11.5, 6, 22.5, 17}, got)
+
+	// A Deflate-compressed raster with horizontal differencing.
+	predicted := newGeoTIFFTestData(t, binary.LittleEndian, map[uint16]geoTIFFTestField{
+		256:   {typ: 3, values: uint16(2)},
+		257:   {typ: 3, values: uint16(2)},
+		258:   {typ: 3, values: uint16(16)},
+		259:   {typ: 3, values: uint16(8)},
+		317:   {typ: 3, values: uint16(2)},
+		33550: {typ: 12, values: []float64{1, 1, 0}},
+		33922: {typ: 12, values: []float64{0, 0, 0, 7, 47, 0}},
+	}, 273, 279,
+		deflate(encode(binary.LittleEndian, []uint16{1000, 10, 990, 0xffff})),
+	)
+	provider, err = gpx.ReadGeoTIFF(bytes.NewReader(predicted))
+	assert.NoError(t, err)
+	got, err = provider.Elevations(context.Background(), []*gpx.WptType{
+		{Lat: 46.5, Lon: 7.5},
+		{Lat: 46.5, Lon: 8.5},
+		{Lat: 46, Lon: 8},
+	})
+	assert.NoError(t, err)
+	assertElevations(t, []float64{1000, 1010, 997.25}, got)
+
+	projected := newGeoTIFFTestData(t, binary.LittleEndian, map[uint16]geoTIFFTestField{
+		256:   {typ: 3, values: uint16(1)},
+		257:   {typ: 3, values: uint16(1)},
+		258:   {typ: 3, values: uint16(8)},
+		33550: {typ: 12, values: []float64{30, 30, 0}},
+		33922: {typ: 12, values: []float64{0, 0, 0, 500000, 5000000, 0}},
+		34735: {typ: 3, values: []uint16{1, 1, 0, 1, 1024, 0, 1, 1}},
+	}, 273, 279, []byte{0})
+	_, err = gpx.ReadGeoTIFF(bytes.NewReader(projected))
+	assert.Error(t, err)
+
+	_, err = gpx.ReadGeoTIFF(bytes.NewReader([]byte("not a TIFF")))
+	assert.Error(t, err)
+}
+
+// assertElevations asserts that actual equals expected, where NaNs are
+// unknown elevations.
+func assertElevations(t *testing.T, expected, actual []float64) {
+	t.Helper()
+	assert.Len(t, actual, len(expected))
+	for i := range min(len(expected), len(actual)) {
+		if math.IsNaN(expected[i]) {
+			assert.True(t, math.IsNaN(actual[i]), "point %d", i)
+		} else {
+			assert.InDelta(t, expected[i], actual[i], 1e-9, "point %d", i)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/twpayne/go-gpx
 
-go 1.19
+go 1.23
 
 require (
 	github.com/kr/pretty v0.3.1
@@ -0,0 +1,136 @@
+package gpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// GoogleLocationHistoryOptions are options for FromGoogleLocationHistory.
+type GoogleLocationHistoryOptions struct {
+	// MaxAccuracy is the largest accuracy radius in meters of records to
+	// include. If zero, records are included regardless of their accuracy.
+	MaxAccuracy float64
+	// Location is the time zone in which records are split into days. If nil,
+	// UTC is used.
+	Location *time.Location
+}
+
+// A googleLocationRecord is a record in a Google Takeout location history
+// Records.json file. Older exports use TimestampMs and newer exports use
+// Timestamp.
+type googleLocationRecord struct {
+	LatitudeE7  int64     `json:"latitudeE7"`
+	LongitudeE7 int64     `json:"longitudeE7"`
+	Accuracy    *float64  `json:"accuracy"`
+	Altitude    float64   `json:"altitude"`
+	Velocity    float64   `json:"velocity"`
+	Heading     float64   `json:"heading"`
+	Timestamp   time.Time `json:"timestamp"`
+	TimestampMs string    `json:"timestampMs"`
+}
+
+// FromGoogleLocationHistory returns a new GPX 1.1 document containing the
+// records in the Google Takeout location history Records.json read from r,
+// with one track per day, named by date in the form YYYY-MM-DD, in order.
+// Records without times are ignored.
+func FromGoogleLocationHistory(r io.Reader, opts GoogleLocationHistoryOptions) (*GPX, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var trkPts []*WptType
+	d := json.NewDecoder(r)
+	if err := expectJSONDelim(d, '{'); err != nil {
+		return nil, err
+	}
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := token.(string); !ok || key != "locations" {
+			var value json.RawMessage
+			if err := d.Decode(&value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := expectJSONDelim(d, '['); err != nil {
+			return nil, err
+		}
+		for d.More() {
+			var record googleLocationRecord
+			if err := d.Decode(&record); err != nil {
+				return nil, err
+			}
+			if opts.MaxAccuracy != 0 && record.Accuracy != nil && *record.Accuracy > opts.MaxAccuracy {
+				continue
+			}
+			if trkPt := record.wpt(); !trkPt.Time.IsZero() {
+				trkPts = append(trkPts, trkPt)
+			}
+		}
+		if err := expectJSONDelim(d, ']'); err != nil {
+			return nil, err
+		}
+	}
+	if err := expectJSONDelim(d, '}'); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(trkPts, func(i, j int) bool {
+		return trkPts[i].Time.Before(trkPts[j].Time)
+	})
+	g := &GPX{
+		Version: "1.1",
+	}
+	var ts *TrkSegType
+	for _, trkPt := range trkPts {
+		day := trkPt.Time.In(loc).Format(dateLayout)
+		if len(g.Trk) == 0 || g.Trk[len(g.Trk)-1].Name != day {
+			ts = &TrkSegType{}
+			g.Trk = append(g.Trk, &TrkType{
+				Name:   day,
+				TrkSeg: []*TrkSegType{ts},
+			})
+		}
+		ts.TrkPt = append(ts.TrkPt, trkPt)
+	}
+	return g, nil
+}
+
+// wpt returns r as a WptType.
+func (r *googleLocationRecord) wpt() *WptType {
+	wpt := &WptType{
+		Lat:    float64(r.LatitudeE7) / 1e7,
+		Lon:    float64(r.LongitudeE7) / 1e7,
+		Ele:    r.Altitude,
+		Time:   r.Timestamp,
+		Speed:  r.Velocity,
+		Course: r.Heading,
+	}
+	if wpt.Time.IsZero() && r.TimestampMs != "" {
+		if ms, err := strconv.ParseInt(r.TimestampMs, 10, 64); err == nil {
+			wpt.Time = time.UnixMilli(ms).UTC()
+		}
+	}
+	return wpt
+}
+
+// expectJSONDelim reads the next token from d and returns an error if it is
+// not delim.
+func expectJSONDelim(d *json.Decoder, delim json.Delim) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("%v: expected %v", token, delim)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gpx_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFromGoogleLocationHistory(t *testing.T) {
+	records := `{
+  "locations": [{
+    "latitudeE7": 475596000,
+    "longitudeE7": 75886000,
+    "accuracy": 15,
+    "altitude": 260,
+    "velocity": 3,
+    "heading": 90,
+    "timestamp": "2024-01-02T23:30:00.000Z"
+  }, {
+    "latitudeE7": 479990000,
+    "longitudeE7": 78420000,
+    "accuracy": 10,
+    "timestampMs": "1704220200000"
+  }, {
+    "latitudeE7": 479000000,
+    "longitudeE7": 78000000,
+    "accuracy": 2000,
+    "timestamp": "2024-01-02T21:00:00Z"
+  }, {
+    "latitudeE7": 478000000,
+    "longitudeE7": 77000000,
+    "timestamp": "2024-01-03T08:00:00Z",
+    "activity": [{"activity": [{"type": "STILL", "confidence": 100}]}]
+  }, {
+    "latitudeE7": 470000000,
+    "longitudeE7": 70000000
+  }],
+  "other": {"ignored": true}
+}`
+
+	got, err := gpx.FromGoogleLocationHistory(strings.NewReader(records), gpx.GoogleLocationHistoryOptions{
+		MaxAccuracy: 100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1", got.Version)
+	assert.Len(t, got.Trk, 2)
+	assert.Equal(t, "2024-01-02", got.Trk[0].Name)
+	assert.Equal(t, []*gpx.WptType{
+		{Lat: 47.999, Lon: 7.842, Time: time.Date(2024, 1, 2, 18, 30, 0, 0, time.UTC)},
+		{Lat: 47.5596, Lon: 7.5886, Ele: 260, Time: time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC), Speed: 3, Course: 90},
+	}, got.Trk[0].TrkSeg[0].TrkPt)
+	assert.Equal(t, "2024-01-03", got.Trk[1].Name)
+	assert.Len(t, got.Trk[1].TrkSeg[0].TrkPt, 1)
+
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	assert.NoError(t, err)
+	got, err = gpx.FromGoogleLocationHistory(strings.NewReader(records), gpx.GoogleLocationHistoryOptions{
+		Location: zurich,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, got.Trk, 2)
+	assert.Equal(t, "2024-01-02", got.Trk[0].Name)
+	assert.Len(t, got.Trk[0].TrkSeg[0].TrkPt, 2)
+	assert.Equal(t, "2024-01-03", got.Trk[1].Name)
+	assert.Len(t, got.Trk[1].TrkSeg[0].TrkPt, 2)
+
+	_, err = gpx.FromGoogleLocationHistory(strings.NewReader(`[]`), gpx.GoogleLocationHistoryOptions{})
+	assert.Error(t, err)
+}
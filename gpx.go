@@ -4,9 +4,13 @@
 package gpx
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,7 +19,10 @@ import (
 	"golang.org/x/net/html/charset"
 )
 
-const timeLayout = time.RFC3339Nano
+const (
+	timeLayout   = time.RFC3339Nano
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+)
 
 // StartElement is the XML start element for GPX files.
 var StartElement = xml.StartElement{
@@ -30,128 +37,221 @@ var copyrightYearLayouts = []string{
 
 // A BoundsType is a boundsType.
 type BoundsType struct {
-	MinLat float64 `xml:"minlat,attr"`
-	MinLon float64 `xml:"minlon,attr"`
-	MaxLat float64 `xml:"maxlat,attr"`
-	MaxLon float64 `xml:"maxlon,attr"`
+	MinLat float64 `xml:"minlat,attr" json:"minlat"`
+	MinLon float64 `xml:"minlon,attr" json:"minlon"`
+	MaxLat float64 `xml:"maxlat,attr" json:"maxlat"`
+	MaxLon float64 `xml:"maxlon,attr" json:"maxlon"`
 }
 
 // A CopyrightType is a copyrightType.
 type CopyrightType struct {
-	Author  string `xml:"author,attr"`
-	Year    int    `xml:"year,omitempty"`
-	License string `xml:"license,omitempty"`
+	Author  string `xml:"author,attr" json:"author,omitempty"`
+	Year    int    `xml:"year,omitempty" json:"year,omitempty"`
+	License string `xml:"license,omitempty" json:"license,omitempty"`
 }
 
 // An ExtensionsType contains elements from another schema.
 type ExtensionsType struct {
-	XML []byte `xml:",innerxml"`
+	XML []byte `xml:",innerxml" json:"-"`
 }
 
 // A GPX is a gpxType.
 type GPX struct {
-	XMLName            string            `xml:"gpx"`
-	XMLSchemaLocations []string          `xml:"xsi:schemaLocation,attr"`
-	XMLAttrs           map[string]string `xml:"-"`
-	Version            string            `xml:"version,attr"`
-	Creator            string            `xml:"creator,attr"`
-	Metadata           *MetadataType     `xml:"metadata,omitempty"`
-	Wpt                []*WptType        `xml:"wpt,omitempty"`
-	Rte                []*RteType        `xml:"rte,omitempty"`
-	Trk                []*TrkType        `xml:"trk,omitempty"`
-	Extensions         *ExtensionsType   `xml:"extensions"`
+	XMLName            string            `xml:"gpx" json:"-"`
+	XMLSchemaLocations []string          `xml:"xsi:schemaLocation,attr" json:"schemaLocations,omitempty"`
+	XMLAttrs           map[string]string `xml:"-" json:"attrs,omitempty"`
+	Version            string            `xml:"version,attr" json:"version,omitempty"`
+	Creator            string            `xml:"creator,attr" json:"creator,omitempty"`
+	Metadata           *MetadataType     `xml:"metadata,omitempty" json:"metadata,omitempty"`
+	Wpt                []*WptType        `xml:"wpt,omitempty" json:"wpt,omitempty"`
+	Rte                []*RteType        `xml:"rte,omitempty" json:"rte,omitempty"`
+	Trk                []*TrkType        `xml:"trk,omitempty" json:"trk,omitempty"`
+	Extensions         *ExtensionsType   `xml:"extensions" json:"extensions,omitempty"`
 }
 
 // A LinkType is a linkType.
 type LinkType struct {
-	HREF string `xml:"href,attr"`
-	Text string `xml:"text,omitempty"`
-	Type string `xml:"type,omitempty"`
+	HREF string `xml:"href,attr" json:"href,omitempty"`
+	Text string `xml:"text,omitempty" json:"text,omitempty"`
+	Type string `xml:"type,omitempty" json:"type,omitempty"`
 }
 
 // A PersonType is a personType.
 type PersonType struct {
-	Name  string     `xml:"name,omitempty"`
-	Email *EmailType `xml:"email,omitempty"`
-	Link  *LinkType  `xml:"link,omitempty"`
+	Name  string     `xml:"name,omitempty" json:"name,omitempty"`
+	Email *EmailType `xml:"email,omitempty" json:"email,omitempty"`
+	Link  *LinkType  `xml:"link,omitempty" json:"link,omitempty"`
 }
 
 // An EmailType is an emailType.
 type EmailType struct {
-	Name   string `xml:"id,attr"`
-	Domain string `xml:"domain,attr"`
+	Name   string `xml:"id,attr" json:"id,omitempty"`
+	Domain string `xml:"domain,attr" json:"domain,omitempty"`
 }
 
 // A MetadataType is a metadataType.
 type MetadataType struct {
-	Name       string          `xml:"name,omitempty"`
-	Desc       string          `xml:"desc,omitempty"`
-	Author     *PersonType     `xml:"author,omitempty"`
-	Copyright  *CopyrightType  `xml:"copyright,omitempty"`
-	Link       []*LinkType     `xml:"link,omitempty"`
-	Time       time.Time       `xml:"time,omitempty"`
-	Keywords   string          `xml:"keywords,omitempty"`
-	Bounds     *BoundsType     `xml:"bounds,omitempty"`
-	Extensions *ExtensionsType `xml:"extensions"`
+	Name       string          `xml:"name,omitempty" json:"name,omitempty"`
+	Desc       string          `xml:"desc,omitempty" json:"desc,omitempty"`
+	Author     *PersonType     `xml:"author,omitempty" json:"author,omitempty"`
+	Copyright  *CopyrightType  `xml:"copyright,omitempty" json:"copyright,omitempty"`
+	Link       []*LinkType     `xml:"link,omitempty" json:"link,omitempty"`
+	Time       time.Time       `xml:"time,omitempty" json:"time,omitempty"`
+	Keywords   string          `xml:"keywords,omitempty" json:"keywords,omitempty"`
+	Bounds     *BoundsType     `xml:"bounds,omitempty" json:"bounds,omitempty"`
+	Extensions *ExtensionsType `xml:"extensions" json:"extensions,omitempty"`
 }
 
 // A RteType is a rteType.
 type RteType struct {
-	Name       string          `xml:"name,omitempty"`
-	Cmt        string          `xml:"cmt,omitempty"`
-	Desc       string          `xml:"desc,omitempty"`
-	Src        string          `xml:"src,omitempty"`
-	Link       []*LinkType     `xml:"link,omitempty"`
-	Number     int             `xml:"number,omitempty"`
-	Type       string          `xml:"type,omitempty"`
-	Extensions *ExtensionsType `xml:"extensions"`
-	RtePt      []*WptType      `xml:"rtept,omitempty"`
+	Name       string          `xml:"name,omitempty" json:"name,omitempty"`
+	Cmt        string          `xml:"cmt,omitempty" json:"cmt,omitempty"`
+	Desc       string          `xml:"desc,omitempty" json:"desc,omitempty"`
+	Src        string          `xml:"src,omitempty" json:"src,omitempty"`
+	Link       []*LinkType     `xml:"link,omitempty" json:"link,omitempty"`
+	Number     int             `xml:"number,omitempty" json:"number,omitempty"`
+	Type       string          `xml:"type,omitempty" json:"type,omitempty"`
+	Extensions *ExtensionsType `xml:"extensions" json:"extensions,omitempty"`
+	RtePt      []*WptType      `xml:"rtept,omitempty" json:"rtept,omitempty"`
 }
 
 // A TrkSegType is a trkSegType.
 type TrkSegType struct {
-	TrkPt      []*WptType      `xml:"trkpt,omitempty"`
-	Extensions *ExtensionsType `xml:"extensions"`
+	TrkPt      []*WptType      `xml:"trkpt,omitempty" json:"trkpt,omitempty"`
+	Extensions *ExtensionsType `xml:"extensions" json:"extensions,omitempty"`
 }
 
 // A TrkType is a trkType.
 type TrkType struct {
-	Name       string          `xml:"name,omitempty"`
-	Cmt        string          `xml:"cmt,omitempty"`
-	Desc       string          `xml:"desc,omitempty"`
-	Src        string          `xml:"src,omitempty"`
-	Link       []*LinkType     `xml:"link,omitempty"`
-	Number     int             `xml:"number,omitempty"`
-	Type       string          `xml:"type,omitempty"`
-	Extensions *ExtensionsType `xml:"extensions"`
-	TrkSeg     []*TrkSegType   `xml:"trkseg,omitempty"`
+	Name       string          `xml:"name,omitempty" json:"name,omitempty"`
+	Cmt        string          `xml:"cmt,omitempty" json:"cmt,omitempty"`
+	Desc       string          `xml:"desc,omitempty" json:"desc,omitempty"`
+	Src        string          `xml:"src,omitempty" json:"src,omitempty"`
+	Link       []*LinkType     `xml:"link,omitempty" json:"link,omitempty"`
+	Number     int             `xml:"number,omitempty" json:"number,omitempty"`
+	Type       string          `xml:"type,omitempty" json:"type,omitempty"`
+	Extensions *ExtensionsType `xml:"extensions" json:"extensions,omitempty"`
+	TrkSeg     []*TrkSegType   `xml:"trkseg,omitempty" json:"trkseg,omitempty"`
 }
 
 // A WptType is a wptType.
 type WptType struct {
-	Lat           float64         `xml:"lat,omitempty"`
-	Lon           float64         `xml:"lon,omitempty"`
-	Ele           float64         `xml:"ele,omitempty"`
-	Speed         float64         `xml:"speed,omitempty"`
-	Course        float64         `xml:"course,omitempty"`
-	Time          time.Time       `xml:"time,omitempty"`
-	MagVar        float64         `xml:"magvar,omitempty"`
-	GeoidHeight   float64         `xml:"geoidheight,omitempty"`
-	Name          string          `xml:"name,omitempty"`
-	Cmt           string          `xml:"cmt,omitempty"`
-	Desc          string          `xml:"desc,omitempty"`
-	Src           string          `xml:"src,omitempty"`
-	Link          []*LinkType     `xml:"link,omitempty"`
-	Sym           string          `xml:"sym,omitempty"`
-	Type          string          `xml:"type,omitempty"`
-	Fix           string          `xml:"fix,omitempty"`
-	Sat           int             `xml:"sat,omitempty"`
-	HDOP          float64         `xml:"hdop,omitempty"`
-	VDOP          float64         `xml:"vdop,omitempty"`
-	PDOP          float64         `xml:"pdop,omitempty"`
-	AgeOfDGPSData float64         `xml:"ageofdgpsdata,omitempty"`
-	DGPSID        []int           `xml:"dgpsid,omitempty"`
-	Extensions    *ExtensionsType `xml:"extensions,omitempty"`
+	Lat           float64         `xml:"lat,omitempty" json:"lat"`
+	Lon           float64         `xml:"lon,omitempty" json:"lon"`
+	Ele           float64         `xml:"ele,omitempty" json:"ele,omitempty"`
+	Speed         float64         `xml:"speed,omitempty" json:"speed,omitempty"`
+	Course        float64         `xml:"course,omitempty" json:"course,omitempty"`
+	Time          time.Time       `xml:"time,omitempty" json:"time,omitempty"`
+	MagVar        DegreesType     `xml:"magvar,omitempty" json:"magvar,omitempty"`
+	GeoidHeight   float64         `xml:"geoidheight,omitempty" json:"geoidheight,omitempty"`
+	Name          string          `xml:"name,omitempty" json:"name,omitempty"`
+	Cmt           string          `xml:"cmt,omitempty" json:"cmt,omitempty"`
+	Desc          string          `xml:"desc,omitempty" json:"desc,omitempty"`
+	Src           string          `xml:"src,omitempty" json:"src,omitempty"`
+	Link          []*LinkType     `xml:"link,omitempty" json:"link,omitempty"`
+	Sym           string          `xml:"sym,omitempty" json:"sym,omitempty"`
+	Type          string          `xml:"type,omitempty" json:"type,omitempty"`
+	Fix           FixType         `xml:"fix,omitempty" json:"fix,omitempty"`
+	Sat           int             `xml:"sat,omitempty" json:"sat,omitempty"`
+	HDOP          float64         `xml:"hdop,omitempty" json:"hdop,omitempty"`
+	VDOP          float64         `xml:"vdop,omitempty" json:"vdop,omitempty"`
+	PDOP          float64         `xml:"pdop,omitempty" json:"pdop,omitempty"`
+	AgeOfDGPSData float64         `xml:"ageofdgpsdata,omitempty" json:"ageofdgpsdata,omitempty"`
+	DGPSID        DGPSStationType `xml:"dgpsid,omitempty" json:"dgpsid,omitempty"`
+	Extensions    *ExtensionsType `xml:"extensions,omitempty" json:"extensions,omitempty"`
+	// ZeroFields are the optional numeric fields that are present with zero
+	// values, see WptFields.
+	ZeroFields WptFields `xml:"-" json:"-"`
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML.
+func (e *ExtensionsType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if tr, ok := tokenReaders.Load(d); ok {
+		if err := d.Skip(); err != nil {
+			return err
+		}
+		e.XML = tr.(*tokenReader).extensionsXML //nolint:forcetypeassert
+		return nil
+	}
+	alias := struct {
+		XML []byte `xml:",innerxml"`
+	}{}
+	if err := d.DecodeElement(&alias, &start); err != nil {
+		return err
+	}
+	e.XML = alias.XML
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. b is replaced, not
+// merged, if it was already set by an earlier element. It returns an error if
+// any latitude or longitude is out of range.
+func (b *BoundsType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias BoundsType
+	var a alias
+	if err := d.DecodeElement(&a, &start); err != nil {
+		return err
+	}
+	for _, err := range []error{
+		LatitudeType(a.MinLat).Validate(),
+		LongitudeType(a.MinLon).Validate(),
+		LatitudeType(a.MaxLat).Validate(),
+		LongitudeType(a.MaxLon).Validate(),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	*b = BoundsType(a)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. e is replaced, not
+// merged, if it was already set by an earlier element.
+func (e *EmailType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias EmailType
+	var a alias
+	if err := d.DecodeElement(&a, &start); err != nil {
+		return err
+	}
+	*e = EmailType(a)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. l is replaced, not
+// merged, if it was already set by an earlier element.
+func (l *LinkType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias LinkType
+	var a alias
+	if err := d.DecodeElement(&a, &start); err != nil {
+		return err
+	}
+	*l = LinkType(a)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. m is replaced, not
+// merged, if it was already set by an earlier element.
+func (m *MetadataType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias MetadataType
+	var a alias
+	if err := d.DecodeElement(&a, &start); err != nil {
+		return err
+	}
+	*m = MetadataType(a)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. p is replaced, not
+// merged, if it was already set by an earlier element.
+func (p *PersonType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias PersonType
+	var a alias
+	if err := d.DecodeElement(&a, &start); err != nil {
+		return err
+	}
+	*p = PersonType(a)
+	return nil
 }
 
 // UnmarshalXML implements xml.Unmarshaler.UnmarshalXML.
@@ -168,11 +268,16 @@ func (c *CopyrightType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) err
 	}
 
 	c.Author = alias.Author
-	c.License = alias.License
+	c.License = strings.TrimSpace(alias.License)
 
+	year := strings.TrimSpace(alias.Year)
+	if year == "" {
+		c.Year = 0
+		return nil
+	}
 	for _, layout := range copyrightYearLayouts {
 		var date time.Time
-		date, err = time.Parse(layout, alias.Year)
+		date, err = time.Parse(layout, year)
 		if err == nil {
 			c.Year = date.Year()
 			return nil
@@ -182,6 +287,120 @@ func (c *CopyrightType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) err
 	return fmt.Errorf("couldn't parse Copyright year: %s", alias.Year)
 }
 
+// MarshalXML implements xml.Marshaler.MarshalXML. The year is written as a
+// plain gYear without a time zone and is omitted if it is zero. It returns an
+// error if c is not valid.
+func (c *CopyrightType) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	start.Attr = append(start.Attr, xml.Attr{
+		Name:  xml.Name{Local: "author"},
+		Value: c.Author,
+	})
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if c.Year != 0 {
+		if err := emitStringElement(e, "year", fmt.Sprintf("%04d", c.Year)); err != nil {
+			return err
+		}
+	}
+	if err := maybeEmitStringElement(e, "license", c.License); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// Validate returns an error if c's year is outside the range of a four digit
+// gYear or if its license is set but is not an absolute URI.
+func (c *CopyrightType) Validate() error {
+	if c.Year < 0 || c.Year > 9999 {
+		return fmt.Errorf("%d: invalid copyright year", c.Year)
+	}
+	if c.License == "" {
+		return nil
+	}
+	u, err := url.Parse(c.License)
+	if err != nil {
+		return fmt.Errorf("%s: invalid license URI: %w", c.License, err)
+	}
+	if !u.IsAbs() || (u.Host == "" && u.Opaque == "") {
+		return fmt.Errorf("%s: license URI is not absolute", c.License)
+	}
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. Namespace
+// declarations and schema locations other than the GPX defaults are preserved
+// in g.XMLAttrs and g.XMLSchemaLocations so that they survive a round trip.
+func (g *GPX) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// Embedded fields must be exported for encoding/xml to decode into them.
+	type (
+		GPXAlias      GPX
+		GPX10Metadata gpx10Metadata
+	)
+	var alias struct {
+		GPXAlias
+		GPX10Metadata
+	}
+	if err := d.DecodeElement(&alias, &start); err != nil {
+		return err
+	}
+	*g = GPX(alias.GPXAlias)
+	if g.Metadata == nil {
+		m10 := gpx10Metadata(alias.GPX10Metadata)
+		metadata, err := m10.metadataType()
+		if err != nil {
+			return err
+		}
+		g.Metadata = metadata
+	}
+
+	prefixes := make(map[string]string)
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" {
+			prefixes[attr.Value] = attr.Name.Local
+		}
+	}
+
+	namespace := gpxNamespace(g.Version)
+	for _, attr := range start.Attr {
+		switch {
+		case attr.Name.Space == "" && (attr.Name.Local == "version" || attr.Name.Local == "creator"):
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			if attr.Value != namespace {
+				g.setXMLAttr("xmlns", attr.Value)
+			}
+		case attr.Name.Space == "xmlns":
+			if attr.Name.Local != "xsi" || attr.Value != xsiNamespace {
+				g.setXMLAttr("xmlns:"+attr.Name.Local, attr.Value)
+			}
+		case (attr.Name.Space == xsiNamespace || attr.Name.Space == "xsi") && attr.Name.Local == "schemaLocation":
+			g.XMLSchemaLocations = parseSchemaLocations(attr.Value, namespace)
+		case attr.Name.Space == "":
+			g.setXMLAttr(attr.Name.Local, attr.Value)
+		default:
+			if prefix, ok := prefixes[attr.Name.Space]; ok {
+				g.setXMLAttr(prefix+":"+attr.Name.Local, attr.Value)
+			} else {
+				g.setXMLAttr(attr.Name.Local, attr.Value)
+			}
+		}
+	}
+
+	// Points' speeds and courses were read from extensions assuming the
+	// conventional Garmin TrackPointExtension prefix, so read them again if
+	// g binds prefixes differently.
+	if spaces := trackPointExtensionSpacesFor(g.XMLAttrs); !slices.Equal(spaces, trackPointExtensionSpaces) {
+		for wpt := range g.Points() {
+			wpt.setSpeedCourseFromExtensions(spaces)
+		}
+	}
+
+	return nil
+}
+
 // Read reads a new GPX from r.
 func Read(r io.Reader) (*GPX, error) {
 	gpx := &GPX{}
@@ -190,13 +409,30 @@ func Read(r io.Reader) (*GPX, error) {
 	return gpx, d.Decode(gpx)
 }
 
+// ReadContext reads a new GPX from r, checking ctx between XML tokens so that
+// reading can be cancelled. If ctx is done then ctx.Err() is returned.
+func ReadContext(ctx context.Context, r io.Reader) (*GPX, error) {
+	return ReadWithOptions(ctx, r, nil)
+}
+
 // MarshalXML implements xml.Marshaler.MarshalXML.
 func (g *GPX) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
-	baseURL := "http://www.topografix.com/GPX/" + strings.Join(strings.Split(g.Version, "."), "/")
-	xmlSchemaLocations := append([]string{
-		baseURL,
-		baseURL + "/gpx.xsd",
-	}, g.XMLSchemaLocations...)
+	namespace := gpxNamespace(g.Version)
+	xmlSchemaLocations := g.XMLSchemaLocations
+	if !hasSchemaLocation(xmlSchemaLocations, namespace) {
+		xmlSchemaLocations = append([]string{
+			namespace,
+			namespace + "/gpx.xsd",
+		}, xmlSchemaLocations...)
+	}
+	xmlnsXSI := xsiNamespace
+	if value, ok := g.XMLAttrs["xmlns:xsi"]; ok {
+		xmlnsXSI = value
+	}
+	xmlns := namespace
+	if value, ok := g.XMLAttrs["xmlns"]; ok {
+		xmlns = value
+	}
 	attr := []xml.Attr{
 		{
 			Name:  xml.Name{Local: "version"},
@@ -208,22 +444,39 @@ func (g *GPX) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
 		},
 		{
 			Name:  xml.Name{Local: "xmlns:xsi"},
-			Value: "http://www.w3.org/2001/XMLSchema-instance",
+			Value: xmlnsXSI,
 		},
 		{
 			Name:  xml.Name{Local: "xmlns"},
-			Value: baseURL,
-		},
-		{
-			Name:  xml.Name{Local: "xsi:schemaLocation"},
-			Value: strings.Join(xmlSchemaLocations, " "),
+			Value: xmlns,
 		},
 	}
-	for k, v := range g.XMLAttrs {
-		attr = append(attr, xml.Attr{
-			Name:  xml.Name{Local: k},
-			Value: v,
-		})
+	keys := make([]string, 0, len(g.XMLAttrs))
+	for k := range g.XMLAttrs {
+		if k != "xmlns" && k != "xmlns:xsi" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasPrefix(k, "xmlns:") {
+			attr = append(attr, xml.Attr{
+				Name:  xml.Name{Local: k},
+				Value: g.XMLAttrs[k],
+			})
+		}
+	}
+	attr = append(attr, xml.Attr{
+		Name:  xml.Name{Local: "xsi:schemaLocation"},
+		Value: strings.Join(xmlSchemaLocations, " "),
+	})
+	for _, k := range keys {
+		if !strings.HasPrefix(k, "xmlns:") {
+			attr = append(attr, xml.Attr{
+				Name:  xml.Name{Local: k},
+				Value: g.XMLAttrs[k],
+			})
+		}
 	}
 	start := xml.StartElement{
 		Name: xml.Name{Local: "gpx"},
@@ -232,7 +485,19 @@ func (g *GPX) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
-	if err := e.EncodeElement(g.Metadata, xml.StartElement{Name: xml.Name{Local: "metadata"}}); err != nil {
+	if g.Version != "1.0" {
+		// GPX 1.1 has no speed and course elements, so write them as
+		// extensions.
+		speedCourseExtensionEncoders.Store(e, trackPointExtensionSpacesFor(g.XMLAttrs))
+		defer speedCourseExtensionEncoders.Delete(e)
+	}
+	if g.Version == "1.0" {
+		if g.Metadata != nil {
+			if err := g.Metadata.marshalGPX10(e); err != nil {
+				return err
+			}
+		}
+	} else if err := e.EncodeElement(g.Metadata, xml.StartElement{Name: xml.Name{Local: "metadata"}}); err != nil {
 		return err
 	}
 	if err := e.EncodeElement(g.Wpt, xml.StartElement{Name: xml.Name{Local: "wpt"}}); err != nil {
@@ -244,25 +509,32 @@ func (g *GPX) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
 	if err := e.EncodeElement(g.Trk, xml.StartElement{Name: xml.Name{Local: "trk"}}); err != nil {
 		return err
 	}
+	if g.Extensions != nil {
+		if err := e.EncodeElement(g.Extensions, xml.StartElement{Name: xml.Name{Local: "extensions"}}); err != nil {
+			return err
+		}
+	}
 	return e.EncodeToken(start.End())
 }
 
-// Write writes g to w.
+// Write writes g to w, preceded by an XML declaration.
 func (g *GPX) Write(w io.Writer) error {
-	return xml.NewEncoder(w).EncodeElement(g, StartElement)
+	return g.WriteWithOptions(w, nil)
 }
 
-// WriteIndent writes g to w.
+// WriteIndent writes g to w, preceded by an XML declaration, with each line
+// starting with prefix and indented by indent for each level of nesting.
 func (g *GPX) WriteIndent(w io.Writer, prefix, indent string) error {
-	e := xml.NewEncoder(w)
-	e.Indent(prefix, indent)
-	return e.EncodeElement(g, StartElement)
+	return g.WriteWithOptions(w, &WriteOptions{
+		Prefix: prefix,
+		Indent: indent,
+	})
 }
 
 // NewRteType returns a new RteType with geometry g.
 func NewRteType(g *geom.LineString) *RteType {
 	return &RteType{
-		RtePt: newWptTypes(g),
+		RtePt: newWptTypes(g, MToTime),
 	}
 }
 
@@ -306,7 +578,7 @@ func (t *TrkType) Geom(layout geom.Layout) *geom.MultiLineString {
 // NewTrkSegType returns a new TrkSegType with geometry g.
 func NewTrkSegType(g *geom.LineString) *TrkSegType {
 	return &TrkSegType{
-		TrkPt: newWptTypes(g),
+		TrkPt: newWptTypes(g, MToTime),
 	}
 }
 
@@ -319,8 +591,16 @@ func (ts *TrkSegType) Geom(layout geom.Layout) *geom.LineString {
 	return geom.NewLineStringFlat(layout, flatCoords)
 }
 
-// NewWptType returns a new WptType with geometry g.
+// NewWptType returns a new WptType with geometry g. It returns nil if g is
+// empty.
 func NewWptType(g *geom.Point) *WptType {
+	return newWptType(g, MToTime)
+}
+
+func newWptType(g *geom.Point, mToTime func(float64) time.Time) *WptType {
+	if g.Empty() {
+		return nil
+	}
 	flatCoords := g.FlatCoords()
 	layout := g.Layout()
 	w := &WptType{
@@ -328,10 +608,10 @@ func NewWptType(g *geom.Point) *WptType {
 		Lon: flatCoords[0],
 	}
 	if zIndex := layout.ZIndex(); zIndex != -1 {
-		w.Ele = flatCoords[zIndex]
+		w.setEle(flatCoords[zIndex])
 	}
 	if mIndex := layout.MIndex(); mIndex != -1 {
-		w.Time = MToTime(flatCoords[mIndex])
+		w.Time = mToTime(flatCoords[mIndex])
 	}
 	return w
 }
@@ -345,34 +625,39 @@ func (w *WptType) Geom(layout geom.Layout) *geom.Point {
 func (w *WptType) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	latAttr := xml.Attr{
 		Name:  xml.Name{Local: "lat"},
-		Value: strconv.FormatFloat(w.Lat, 'f', -1, 64),
+		Value: formatLatLon(e, w.Lat),
 	}
 	lonAttr := xml.Attr{
 		Name:  xml.Name{Local: "lon"},
-		Value: strconv.FormatFloat(w.Lon, 'f', -1, 64),
+		Value: formatLatLon(e, w.Lon),
 	}
 	start.Attr = append(start.Attr, latAttr, lonAttr)
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
-	if err := maybeEmitFloatElement(e, "ele", w.Ele); err != nil {
-		return err
-	}
-	if err := maybeEmitFloatElement(e, "speed", w.Speed); err != nil {
-		return err
+	if w.Has(WptEle) {
+		if err := emitEleElement(e, w.Ele); err != nil {
+			return err
+		}
 	}
-	if err := maybeEmitFloatElement(e, "course", w.Course); err != nil {
-		return err
+	spaces, speedCourseExtensions := speedCourseExtensionEncoders.Load(e)
+	if !speedCourseExtensions {
+		if err := w.maybeEmitFloatField(e, WptSpeed, "speed", w.Speed); err != nil {
+			return err
+		}
+		if err := w.maybeEmitFloatField(e, WptCourse, "course", w.Course); err != nil {
+			return err
+		}
 	}
 	if !w.Time.IsZero() {
 		if err := maybeEmitStringElement(e, "time", w.Time.UTC().Format(timeLayout)); err != nil {
 			return err
 		}
 	}
-	if err := maybeEmitFloatElement(e, "magvar", w.MagVar); err != nil {
+	if err := w.maybeEmitFloatField(e, WptMagVar, "magvar", float64(w.MagVar)); err != nil {
 		return err
 	}
-	if err := maybeEmitFloatElement(e, "geoidheight", w.GeoidHeight); err != nil {
+	if err := w.maybeEmitFloatField(e, WptGeoidHeight, "geoidheight", w.GeoidHeight); err != nil {
 		return err
 	}
 	if err := maybeEmitStringElement(e, "name", w.Name); err != nil {
@@ -398,100 +683,258 @@ func (w *WptType) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	if err := maybeEmitStringElement(e, "type", w.Type); err != nil {
 		return err
 	}
-	if err := maybeEmitStringElement(e, "fix", w.Fix); err != nil {
+	if err := maybeEmitStringElement(e, "fix", string(w.Fix)); err != nil {
+		return err
+	}
+	if err := w.maybeEmitIntField(e, WptSat, "sat", w.Sat); err != nil {
 		return err
 	}
-	if err := maybeEmitIntElement(e, "sat", w.Sat); err != nil {
+	if err := w.maybeEmitFloatField(e, WptHDOP, "hdop", w.HDOP); err != nil {
 		return err
 	}
-	if err := maybeEmitFloatElement(e, "hdop", w.HDOP); err != nil {
+	if err := w.maybeEmitFloatField(e, WptVDOP, "vdop", w.VDOP); err != nil {
 		return err
 	}
-	if err := maybeEmitFloatElement(e, "vdop", w.VDOP); err != nil {
+	if err := w.maybeEmitFloatField(e, WptPDOP, "pdop", w.PDOP); err != nil {
 		return err
 	}
-	if err := maybeEmitFloatElement(e, "pdop", w.PDOP); err != nil {
+	if err := w.maybeEmitFloatField(e, WptAgeOfDGPSData, "ageofdgpsdata", w.AgeOfDGPSData); err != nil {
 		return err
 	}
-	if err := maybeEmitFloatElement(e, "ageofdgpsdata", w.AgeOfDGPSData); err != nil {
+	if err := w.maybeEmitIntField(e, WptDGPSID, "dgpsid", int(w.DGPSID)); err != nil {
 		return err
 	}
-	for _, dgpsid := range w.DGPSID {
-		if err := emitIntElement(e, "dgpsid", dgpsid); err != nil {
+	extensions := w.Extensions
+	if speedCourseExtensions {
+		extensions = w.speedCourseExtensions(spaces.([]string)) //nolint:forcetypeassert
+	}
+	if extensions != nil {
+		if err := e.EncodeElement(extensions, xml.StartElement{Name: xml.Name{Local: "extensions"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. If ts is being read
+// by ReadWithOptions with ParseOptions.PreviewPoints set then only a subset
+// of its points is retained.
+func (ts *TrkSegType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var tr *tokenReader
+	if value, ok := tokenReaders.Load(d); ok {
+		tr = value.(*tokenReader) //nolint:forcetypeassert
+	}
+	if tr == nil || tr.options.PreviewPoints <= 0 {
+		type alias TrkSegType
+		var a alias
+		if err := d.DecodeElement(&a, &start); err != nil {
 			return err
 		}
+		*ts = TrkSegType(a)
+		if tr != nil {
+			tr.addPreviewTrkSeg(len(ts.TrkPt))
+		}
+		return nil
 	}
-	if w.Extensions != nil {
-		if err := e.EncodeElement(w.Extensions, xml.StartElement{Name: xml.Name{Local: "extensions"}}); err != nil {
+
+	// Retain every stride-th point, doubling stride and discarding every
+	// other retained point whenever there are too many.
+	limit := tr.options.PreviewPoints
+	var result TrkSegType
+	var last *WptType
+	n, stride := 0, 1
+	for {
+		token, err := d.Token()
+		if err != nil {
 			return err
 		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "trkpt":
+				trkPt := &WptType{}
+				if err := d.DecodeElement(trkPt, &token); err != nil {
+					return err
+				}
+				if n%stride == 0 {
+					result.TrkPt = append(result.TrkPt, trkPt)
+					if len(result.TrkPt) > limit {
+						for i := range (len(result.TrkPt) + 1) / 2 {
+							result.TrkPt[i] = result.TrkPt[2*i]
+						}
+						clear(result.TrkPt[(len(result.TrkPt)+1)/2:])
+						result.TrkPt = result.TrkPt[:(len(result.TrkPt)+1)/2]
+						stride *= 2
+					}
+				}
+				last = trkPt
+				n++
+			case "extensions":
+				result.Extensions = &ExtensionsType{}
+				if err := d.DecodeElement(result.Extensions, &token); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if last != nil && result.TrkPt[len(result.TrkPt)-1] != last {
+				if len(result.TrkPt) < limit {
+					result.TrkPt = append(result.TrkPt, last)
+				} else {
+					result.TrkPt[len(result.TrkPt)-1] = last
+				}
+			}
+			tr.addPreviewTrkSeg(n)
+			*ts = result
+			return nil
+		}
 	}
-	return e.EncodeToken(start.End())
 }
 
-// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML.
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML. For speed, it scans
+// tokens directly rather than using reflection. It returns an error if the
+// latitude, longitude, magnetic variation, or DGPS station id is out of range
+// or if the fix is invalid.
 func (w *WptType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	var e struct {
-		Lat           float64         `xml:"lat,attr"`
-		Lon           float64         `xml:"lon,attr"`
-		Ele           float64         `xml:"ele"`
-		Speed         float64         `xml:"speed"`
-		Course        float64         `xml:"course"`
-		Time          string          `xml:"time"`
-		MagVar        float64         `xml:"magvar"`
-		GeoidHeight   float64         `xml:"geoidheight"`
-		Name          string          `xml:"name"`
-		Cmt           string          `xml:"cmt"`
-		Desc          string          `xml:"desc"`
-		Src           string          `xml:"src"`
-		Link          []*LinkType     `xml:"link"`
-		Sym           string          `xml:"sym"`
-		Type          string          `xml:"type"`
-		Fix           string          `xml:"fix"`
-		Sat           int             `xml:"sat"`
-		HDOP          float64         `xml:"hdop"`
-		VDOP          float64         `xml:"vdop"`
-		PDOP          float64         `xml:"pdop"`
-		AgeOfDGPSData float64         `xml:"ageofdgpsdata"`
-		DGPSID        []int           `xml:"dgpsid"`
-		Extensions    *ExtensionsType `xml:"extensions"`
-	}
-	if err := d.DecodeElement(&e, &start); err != nil {
-		return err
-	}
-	wt := WptType{
-		Lat:           e.Lat,
-		Lon:           e.Lon,
-		Ele:           e.Ele,
-		Speed:         e.Speed,
-		Course:        e.Course,
-		MagVar:        e.MagVar,
-		GeoidHeight:   e.GeoidHeight,
-		Name:          e.Name,
-		Cmt:           e.Cmt,
-		Desc:          e.Desc,
-		Src:           e.Src,
-		Link:          e.Link,
-		Sym:           e.Sym,
-		Type:          e.Type,
-		Fix:           e.Fix,
-		Sat:           e.Sat,
-		HDOP:          e.HDOP,
-		VDOP:          e.VDOP,
-		PDOP:          e.PDOP,
-		AgeOfDGPSData: e.AgeOfDGPSData,
-		DGPSID:        e.DGPSID,
-		Extensions:    e.Extensions,
-	}
-	if e.Time != "" {
-		t, err := time.ParseInLocation(timeLayout, e.Time, time.UTC)
+	var wt WptType
+	for _, attr := range start.Attr {
+		var err error
+		switch attr.Name.Local {
+		case "lat":
+			if wt.Lat, err = parseFloat(attr.Value); err == nil {
+				err = LatitudeType(wt.Lat).Validate()
+			}
+		case "lon":
+			if wt.Lon, err = parseFloat(attr.Value); err == nil {
+				err = LongitudeType(wt.Lon).Validate()
+			}
+		}
 		if err != nil {
 			return err
 		}
-		wt.Time = t
 	}
-	*w = wt
-	return nil
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if err := wt.unmarshalChild(d, token); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			wt.setSpeedCourseFromExtensions(trackPointExtensionSpaces)
+			*w = wt
+			return nil
+		}
+	}
+}
+
+// unmarshalChild unmarshals the child element start of w from d.
+func (w *WptType) unmarshalChild(d *xml.Decoder, start xml.StartElement) error {
+	switch start.Name.Local {
+	case "link":
+		var link LinkType
+		if err := d.DecodeElement(&link, &start); err != nil {
+			return err
+		}
+		w.Link = append(w.Link, &link)
+		return nil
+	case "extensions":
+		var extensions ExtensionsType
+		if err := d.DecodeElement(&extensions, &start); err != nil {
+			return err
+		}
+		w.Extensions = &extensions
+		return nil
+	}
+
+	text, err := readText(d)
+	if err != nil {
+		return err
+	}
+	switch start.Name.Local {
+	case "ele":
+		w.Ele, err = parseFloat(text)
+	case "speed":
+		w.Speed, err = parseFloat(text)
+	case "course":
+		w.Course, err = parseFloat(text)
+	case "time":
+		w.Time = time.Time{}
+		if text != "" {
+			w.Time, err = time.ParseInLocation(timeLayout, text, time.UTC)
+		}
+	case "magvar":
+		var magVar float64
+		if magVar, err = parseFloat(text); err == nil {
+			w.MagVar = DegreesType(magVar)
+			err = w.MagVar.Validate()
+		}
+	case "geoidheight":
+		w.GeoidHeight, err = parseFloat(text)
+	case "name":
+		w.Name = text
+	case "cmt":
+		w.Cmt = text
+	case "desc":
+		w.Desc = text
+	case "src":
+		w.Src = text
+	case "sym":
+		w.Sym = text
+	case "type":
+		w.Type = text
+	case "fix":
+		w.Fix = FixType(text)
+		err = w.Fix.Validate()
+	case "sat":
+		w.Sat, err = parseInt(text)
+	case "hdop":
+		w.HDOP, err = parseFloat(text)
+	case "vdop":
+		w.VDOP, err = parseFloat(text)
+	case "pdop":
+		w.PDOP, err = parseFloat(text)
+	case "ageofdgpsdata":
+		w.AgeOfDGPSData, err = parseFloat(text)
+	case "dgpsid":
+		var dgpsid int
+		if dgpsid, err = parseInt(text); err == nil {
+			w.DGPSID = DGPSStationType(dgpsid)
+			err = w.DGPSID.Validate()
+		}
+	}
+	if field, ok := wptElementFields[start.Name.Local]; ok && err == nil && text != "" {
+		if w.isZero(field) {
+			w.ZeroFields |= field
+		} else {
+			w.ZeroFields &^= field
+		}
+	}
+	return err
+}
+
+// maybeEmitFloatField emits an element with the given local name and value
+// if field is present in w.
+func (w *WptType) maybeEmitFloatField(e *xml.Encoder, field WptFields, localName string, value float64) error {
+	if !w.Has(field) {
+		return nil
+	}
+	return emitStringElement(e, localName, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// maybeEmitIntField emits an element with the given local name and value if
+// field is present in w.
+func (w *WptType) maybeEmitIntField(e *xml.Encoder, field WptFields, localName string, value int) error {
+	if !w.Has(field) {
+		return nil
+	}
+	return emitIntElement(e, localName, value)
 }
 
 func (w *WptType) appendFlatCoords(flatCoords []float64, layout geom.Layout) []float64 {
@@ -513,18 +956,109 @@ func (w *WptType) appendFlatCoords(flatCoords []float64, layout geom.Layout) []f
 	}
 }
 
+// MToTime converts an M coordinate in seconds since the Unix epoch to a time.
 func MToTime(m float64) time.Time {
-	if m == 0 {
-		return time.Unix(0, 0)
-	}
-	return time.Unix(int64(m), int64(m*float64(time.Second))%int64(time.Second)).UTC()
+	return MTimeConverter{}.MToTime(m)
 }
 
+// TimeToM converts a time to an M coordinate in seconds since the Unix epoch.
 func TimeToM(t time.Time) float64 {
-	if t.IsZero() {
-		return 0
+	return MTimeConverter{}.TimeToM(t)
+}
+
+func (g *GPX) setXMLAttr(key, value string) {
+	if g.XMLAttrs == nil {
+		g.XMLAttrs = make(map[string]string)
+	}
+	g.XMLAttrs[key] = value
+}
+
+// gpxNamespace returns the GPX namespace for version.
+func gpxNamespace(version string) string {
+	return "http://www.topografix.com/GPX/" + strings.Join(strings.Split(version, "."), "/")
+}
+
+// hasSchemaLocation returns whether xmlSchemaLocations contains a location for
+// namespace.
+func hasSchemaLocation(xmlSchemaLocations []string, namespace string) bool {
+	for i := 0; i < len(xmlSchemaLocations); i += 2 {
+		if xmlSchemaLocations[i] == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSchemaLocations parses the value of an xsi:schemaLocation attribute,
+// omitting the default location for namespace.
+func parseSchemaLocations(value, namespace string) []string {
+	fields := strings.Fields(value)
+	var xmlSchemaLocations []string
+	for i := 0; i < len(fields); i += 2 {
+		if i+1 == len(fields) {
+			xmlSchemaLocations = append(xmlSchemaLocations, fields[i])
+			break
+		}
+		if fields[i] == namespace && fields[i+1] == namespace+"/gpx.xsd" {
+			continue
+		}
+		xmlSchemaLocations = append(xmlSchemaLocations, fields[i], fields[i+1])
+	}
+	return xmlSchemaLocations
+}
+
+// parseFloat parses s as a float in the same way as encoding/xml.
+func parseFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseInt parses s as an int in the same way as encoding/xml.
+func parseInt(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	return int(i), err
+}
+
+// readText reads the character data of the current element from d, up to and
+// including its end element. Child elements are skipped.
+func readText(d *xml.Decoder) (string, error) {
+	// Most elements contain a single CharData token, so only allocate a
+	// buffer when there is more than one.
+	var text string
+	var buf []byte
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			switch {
+			case buf != nil:
+				buf = append(buf, token...)
+			case text == "":
+				text = string(token)
+			default:
+				buf = append([]byte(text), token...)
+			}
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			if buf != nil {
+				return string(buf), nil
+			}
+			return text, nil
+		}
 	}
-	return float64(t.UnixNano()) / float64(time.Second)
 }
 
 func emitIntElement(e *xml.Encoder, localName string, value int) error {
@@ -556,7 +1090,10 @@ func maybeEmitStringElement(e *xml.Encoder, localName, value string) error {
 	return emitStringElement(e, localName, value)
 }
 
-func newWptTypes(g *geom.LineString) []*WptType {
+func newWptTypes(g *geom.LineString, mToTime func(float64) time.Time) []*WptType {
+	if g.Empty() {
+		return []*WptType{}
+	}
 	flatCoords := g.FlatCoords()
 	layout := g.Layout()
 	mIndex := layout.MIndex()
@@ -570,10 +1107,10 @@ func newWptTypes(g *geom.LineString) []*WptType {
 			Lon: flatCoords[start],
 		}
 		if zIndex != -1 {
-			wpt.Ele = flatCoords[start+zIndex]
+			wpt.setEle(flatCoords[start+zIndex])
 		}
 		if mIndex != -1 {
-			wpt.Time = MToTime(flatCoords[start+mIndex])
+			wpt.Time = mToTime(flatCoords[start+mIndex])
 		}
 		start += stride
 		wpts[i] = wpt
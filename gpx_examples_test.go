@@ -2,7 +2,6 @@ package gpx_test
 
 import (
 	"bytes"
-	"encoding/xml"
 	"fmt"
 	"os"
 	"time"
@@ -32,7 +31,7 @@ func ExampleRead() {
 	}
 	fmt.Printf("t.Wpt[0] == %+v", t.Wpt[0])
 	// Output:
-	// t.Wpt[0] == &{Lat:42.438878 Lon:-71.119277 Ele:44.586548 Speed:9.16 Course:0 Time:2001-11-28 21:05:28 +0000 UTC MagVar:0 GeoidHeight:0 Name:5066 Cmt: Desc:5066 Src: Link:[] Sym:Crossing Type:Crossing Fix: Sat:0 HDOP:0 VDOP:0 PDOP:0 AgeOfDGPSData:0 DGPSID:[] Extensions:<nil>}
+	// t.Wpt[0] == &{Lat:42.438878 Lon:-71.119277 Ele:44.586548 Speed:9.16 Course:0 Time:2001-11-28 21:05:28 +0000 UTC MagVar:0 GeoidHeight:0 Name:5066 Cmt: Desc:5066 Src: Link:[] Sym:Crossing Type:Crossing Fix: Sat:0 HDOP:0 VDOP:0 PDOP:0 AgeOfDGPSData:0 DGPSID:0 Extensions:<nil> ZeroFields:0}
 }
 
 func ExampleGPX_WriteIndent() {
@@ -52,9 +51,6 @@ func ExampleGPX_WriteIndent() {
 			},
 		},
 	}
-	if _, err := os.Stdout.WriteString(xml.Header); err != nil {
-		fmt.Printf("err == %v", err)
-	}
 	if err := g.WriteIndent(os.Stdout, "", "  "); err != nil {
 		fmt.Printf("err == %v", err)
 	}
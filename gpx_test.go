@@ -2,6 +2,7 @@ package gpx_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"io/fs"
 	"os"
@@ -133,7 +134,7 @@ func TestWpt(t *testing.T) {
 				VDOP:          5.5,
 				PDOP:          6.6,
 				AgeOfDGPSData: 7.7,
-				DGPSID:        []int{8},
+				DGPSID:        8,
 			},
 			layout:    geom.XYZM,
 			g:         geom.NewPoint(geom.XYZM).MustSetCoords([]float64{-71.119277, 42.438878, 44.586548, 1006981528}),
@@ -161,6 +162,190 @@ func TestWpt(t *testing.T) {
 	}
 }
 
+func TestWptUnmarshalXML(t *testing.T) {
+	data := "<wpt lat=\" 42.438878\" lon=\"-71.119277\">" +
+		"<ele> 44.586548 </ele>" +
+		"<name>50<![CDATA[66]]></name>" +
+		"<desc>a<unknown>b</unknown>c</desc>" +
+		"<unknown><name>ignored</name></unknown>" +
+		"<link href=\"http://example.com/1\"></link>" +
+		"<link href=\"http://example.com/2\"><text>2</text></link>" +
+		"<sat></sat>" +
+		"<dgpsid>1</dgpsid>" +
+		"<dgpsid>2</dgpsid>" +
+		"<extensions><foo:bar>baz</foo:bar></extensions>" +
+		"</wpt>"
+	var got gpx.WptType
+	assert.NoError(t, xml.Unmarshal([]byte(data), &got))
+	assert.Equal(t, gpx.WptType{
+		Lat:  42.438878,
+		Lon:  -71.119277,
+		Ele:  44.586548,
+		Name: "5066",
+		Desc: "ac",
+		Link: []*gpx.LinkType{
+			{HREF: "http://example.com/1"},
+			{HREF: "http://example.com/2", Text: "2"},
+		},
+		DGPSID: 2,
+		Extensions: &gpx.ExtensionsType{
+			XML: []byte("<foo:bar>baz</foo:bar>"),
+		},
+	}, got)
+
+	assert.Error(t, xml.Unmarshal([]byte("<wpt lat=\"x\" lon=\"0\"></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt><ele>x</ele></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt><time>x</time></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt lat=\"90.5\" lon=\"0\"></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt lat=\"0\" lon=\"180\"></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt><magvar>360</magvar></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt><dgpsid>1024</dgpsid></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<wpt><fix>4d</fix></wpt>"), &got))
+	assert.Error(t, xml.Unmarshal([]byte("<bounds minlat=\"-91\" minlon=\"0\" maxlat=\"0\" maxlon=\"0\"></bounds>"), &gpx.BoundsType{}))
+}
+
+func TestWptSpeedCourse(t *testing.T) {
+	newGPX := func(version string) *gpx.GPX {
+		return &gpx.GPX{
+			Version: version,
+			Creator: "test",
+			Trk: []*gpx.TrkType{
+				{
+					TrkSeg: []*gpx.TrkSegType{
+						{
+							TrkPt: []*gpx.WptType{
+								{Lat: 1, Lon: 2, Speed: 3.5, Course: 90},
+								{Lat: 3, Lon: 4, Speed: 1},
+								{Lat: 5, Lon: 6},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	for i, tc := range []struct {
+		version  string
+		expected []string
+	}{
+		{
+			version: "1.0",
+			expected: []string{
+				`<trkpt lat="1" lon="2"><speed>3.5</speed><course>90</course></trkpt>`,
+				`<trkpt lat="3" lon="4"><speed>1</speed></trkpt>`,
+				`<trkpt lat="5" lon="6"></trkpt>`,
+			},
+		},
+		{
+			version: "1.1",
+			expected: []string{
+				`<trkpt lat="1" lon="2"><extensions><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:speed>3.5</gpxtpx:speed><gpxtpx:course>90</gpxtpx:course></gpxtpx:TrackPointExtension></extensions></trkpt>`,
+				`<trkpt lat="3" lon="4"><extensions><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:speed>1</gpxtpx:speed></gpxtpx:TrackPointExtension></extensions></trkpt>`,
+				`<trkpt lat="5" lon="6"></trkpt>`,
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := newGPX(tc.version)
+			b := &bytes.Buffer{}
+			assert.NoError(t, g.Write(b))
+			for _, expected := range tc.expected {
+				assert.Contains(t, b.String(), expected)
+			}
+
+			got, err := gpx.Read(bytes.NewReader(b.Bytes()))
+			assert.NoError(t, err)
+			for j, trkPt := range got.Trk[0].TrkSeg[0].TrkPt {
+				assert.Equal(t, g.Trk[0].TrkSeg[0].TrkPt[j].Speed, trkPt.Speed)
+				assert.Equal(t, g.Trk[0].TrkSeg[0].TrkPt[j].Course, trkPt.Course)
+			}
+
+			// Writing again must not duplicate the extensions.
+			b2 := &bytes.Buffer{}
+			assert.NoError(t, got.Write(b2))
+			assert.Equal(t, b.String(), b2.String())
+		})
+	}
+}
+
+func TestWptSpeedCourseExtensions(t *testing.T) {
+	for i, tc := range []struct {
+		xmlns           string
+		extensions      string
+		edit            func(*gpx.WptType)
+		expectedSpeed   float64
+		expectedHas     bool
+		expectedWritten string
+	}{
+		{
+			extensions:      `<gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:hr>120</gpxtpx:hr><gpxtpx:speed>3.5</gpxtpx:speed></gpxtpx:TrackPointExtension>`,
+			edit:            func(w *gpx.WptType) { w.Speed = 5 },
+			expectedSpeed:   3.5,
+			expectedHas:     true,
+			expectedWritten: `<extensions><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:hr>120</gpxtpx:hr><gpxtpx:speed>5</gpxtpx:speed></gpxtpx:TrackPointExtension></extensions>`,
+		},
+		{
+			extensions:      `<gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:speed>3.5</gpxtpx:speed></gpxtpx:TrackPointExtension>`,
+			edit:            func(w *gpx.WptType) { w.Clear(gpx.WptSpeed) },
+			expectedSpeed:   3.5,
+			expectedHas:     true,
+			expectedWritten: `<trkpt lat="1" lon="2"></trkpt>`,
+		},
+		{
+			extensions:      `<foo:bar xmlns:foo="http://example.com/foo"><foo:speed>9</foo:speed></foo:bar><speed>8</speed>`,
+			edit:            func(w *gpx.WptType) {},
+			expectedWritten: `<extensions><foo:bar xmlns:foo="http://example.com/foo"><foo:speed>9</foo:speed></foo:bar><speed>8</speed></extensions>`,
+		},
+		{
+			extensions:      `<osmand:speed xmlns:osmand="https://osmand.net">2.5</osmand:speed>`,
+			edit:            func(w *gpx.WptType) {},
+			expectedSpeed:   2.5,
+			expectedHas:     true,
+			expectedWritten: `<gpxtpx:speed>2.5</gpxtpx:speed>`,
+		},
+		{
+			xmlns:           ` xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1"`,
+			extensions:      `<gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension>`,
+			edit:            func(w *gpx.WptType) { w.Speed = 5 },
+			expectedWritten: `<extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:speed>5</gpxtpx:speed></gpxtpx:TrackPointExtension></extensions>`,
+		},
+		{
+			xmlns:           ` xmlns:tpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"`,
+			extensions:      `<tpx:TrackPointExtension><tpx:speed>3.5</tpx:speed></tpx:TrackPointExtension>`,
+			edit:            func(w *gpx.WptType) { w.Speed = 5 },
+			expectedSpeed:   3.5,
+			expectedHas:     true,
+			expectedWritten: `<extensions><tpx:TrackPointExtension><tpx:speed>5</tpx:speed></tpx:TrackPointExtension></extensions>`,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			data := `<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1"` + tc.xmlns + `><trk><trkseg>` +
+				`<trkpt lat="1" lon="2"><extensions>` + tc.extensions + `</extensions></trkpt>` +
+				`</trkseg></trk></gpx>`
+			g, err := gpx.Read(strings.NewReader(data))
+			assert.NoError(t, err)
+			trkPt := g.Trk[0].TrkSeg[0].TrkPt[0]
+			assert.Equal(t, tc.expectedSpeed, trkPt.Speed)
+			assert.Equal(t, tc.expectedHas, trkPt.Has(gpx.WptSpeed))
+
+			tc.edit(trkPt)
+			b := &bytes.Buffer{}
+			assert.NoError(t, g.Write(b))
+			assert.Contains(t, b.String(), tc.expectedWritten)
+
+			// The written speed is read back.
+			reread, err := gpx.Read(b)
+			assert.NoError(t, err)
+			rereadTrkPt := reread.Trk[0].TrkSeg[0].TrkPt[0]
+			assert.Equal(t, trkPt.Has(gpx.WptSpeed), rereadTrkPt.Has(gpx.WptSpeed))
+			if trkPt.Has(gpx.WptSpeed) {
+				assert.Equal(t, trkPt.Speed, rereadTrkPt.Speed)
+			}
+		})
+	}
+}
+
 func TestRte(t *testing.T) {
 	for i, tc := range []struct {
 		data          string
@@ -575,6 +760,29 @@ func TestRoundTrip(t *testing.T) {
 				},
 			},
 		},
+		{
+			data: "<gpx" +
+				" version=\"1.1\"" +
+				" creator=\"Garmin Desktop App\"" +
+				" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\"" +
+				" xmlns=\"http://www.topografix.com/GPX/1/1\"" +
+				" xmlns:gpxtpx=\"http://www.garmin.com/xmlschemas/TrackPointExtension/v1\"" +
+				" xmlns:gpxx=\"http://www.garmin.com/xmlschemas/GpxExtensions/v3\"" +
+				" xsi:schemaLocation=\"http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd http://www.garmin.com/xmlschemas/GpxExtensions/v3 http://www8.garmin.com/xmlschemas/GpxExtensionsv3.xsd\">" +
+				"</gpx>",
+			gpx: &gpx.GPX{
+				XMLSchemaLocations: []string{
+					"http://www.garmin.com/xmlschemas/GpxExtensions/v3",
+					"http://www8.garmin.com/xmlschemas/GpxExtensionsv3.xsd",
+				},
+				XMLAttrs: map[string]string{
+					"xmlns:gpxtpx": "http://www.garmin.com/xmlschemas/TrackPointExtension/v1",
+					"xmlns:gpxx":   "http://www.garmin.com/xmlschemas/GpxExtensions/v3",
+				},
+				Version: "1.1",
+				Creator: "Garmin Desktop App",
+			},
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			got, err := gpx.Read(bytes.NewBufferString(tc.data))
@@ -582,7 +790,7 @@ func TestRoundTrip(t *testing.T) {
 			assert.Equal(t, tc.gpx, got)
 			sb := &strings.Builder{}
 			assert.NoError(t, tc.gpx.WriteIndent(sb, "", "\t"))
-			assert.Equal(t, strings.Split(tc.data, "\n"), strings.Split(sb.String(), "\n"))
+			assert.Equal(t, strings.Split(xml.Header+tc.data, "\n"), strings.Split(sb.String(), "\n"))
 		})
 	}
 }
@@ -639,6 +847,38 @@ func TestParseExamples(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestReadContext(t *testing.T) {
+	data, err := os.ReadFile("testdata/ashland.gpx")
+	assert.NoError(t, err)
+
+	for i, tc := range [][]byte{
+		data,
+		[]byte(`<?xml version="1.0" encoding="UTF-8"?>` +
+			`<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1" xmlns:foo="http://example.com/foo">` +
+			`<wpt lat="1" lon="2"><extensions><foo:bar a="b">baz<foo:qux/></foo:bar>` + "\n" + `</extensions></wpt>` +
+			`<trk><extensions/><trkseg><trkpt lat="3" lon="4"><extensions>  </extensions></trkpt></trkseg></trk>` +
+			`<extensions><foo:bar>&amp;</foo:bar ></extensions>` +
+			`</gpx>`),
+		[]byte(`<?xml version="1.0" encoding="ISO-8859-1"?>` +
+			`<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">` +
+			`<wpt lat="1" lon="2"><name>caf\xe9</name><extensions><name>caf\xe9</name></extensions></wpt>` +
+			`</gpx>`),
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			expected, err := gpx.Read(bytes.NewReader(tc))
+			assert.NoError(t, err)
+			got, err := gpx.ReadContext(context.Background(), bytes.NewReader(tc))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, got)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = gpx.ReadContext(ctx, bytes.NewReader(data))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestCopyrightTypeYear(t *testing.T) {
 	for i, tc := range []struct {
 		data []byte
@@ -660,6 +900,13 @@ func TestCopyrightTypeYear(t *testing.T) {
 			data: []byte("<copyright><year>2010-07:00</year></copyright>"),
 			year: 2010,
 		},
+		{
+			data: []byte("<copyright><year> 2009 </year></copyright>"),
+			year: 2009,
+		},
+		{
+			data: []byte("<copyright></copyright>"),
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			var gotCopyright gpx.CopyrightType
@@ -668,3 +915,74 @@ func TestCopyrightTypeYear(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyrightTypeMarshal(t *testing.T) {
+	for i, tc := range []struct {
+		copyright   *gpx.CopyrightType
+		expected    string
+		expectedErr bool
+	}{
+		{
+			copyright: &gpx.CopyrightType{Author: "author"},
+			expected:  `<copyright author="author"></copyright>`,
+		},
+		{
+			copyright: &gpx.CopyrightType{Author: "author", Year: 987, License: "https://creativecommons.org/licenses/by/4.0/"},
+			expected:  `<copyright author="author"><year>0987</year><license>https://creativecommons.org/licenses/by/4.0/</license></copyright>`,
+		},
+		{
+			copyright: &gpx.CopyrightType{Author: "author", License: "urn:example:license"},
+			expected:  `<copyright author="author"><license>urn:example:license</license></copyright>`,
+		},
+		{
+			copyright:   &gpx.CopyrightType{Author: "author", License: "CC BY 4.0"},
+			expectedErr: true,
+		},
+		{
+			copyright:   &gpx.CopyrightType{Author: "author", License: "/licenses/by/4.0/"},
+			expectedErr: true,
+		},
+		{
+			copyright:   &gpx.CopyrightType{Author: "author", Year: 10000},
+			expectedErr: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var b bytes.Buffer
+			err := xml.NewEncoder(&b).EncodeElement(tc.copyright, xml.StartElement{Name: xml.Name{Local: "copyright"}})
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, b.String())
+			var roundTripped gpx.CopyrightType
+			assert.NoError(t, xml.Unmarshal(b.Bytes(), &roundTripped))
+			assert.Equal(t, tc.copyright, &roundTripped)
+		})
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	data, err := os.ReadFile("testdata/ashland.gpx")
+	assert.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gpx.Read(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// newTestTrkSeg returns a track segment of n points, where f returns the ith
+// point.
+func newTestTrkSeg(n int, f func(i int) *gpx.WptType) *gpx.TrkSegType {
+	ts := &gpx.TrkSegType{
+		TrkPt: make([]*gpx.WptType, n),
+	}
+	for i := range n {
+		ts.TrkPt[i] = f(i)
+	}
+	return ts
+}
@@ -0,0 +1,307 @@
+package gpx
+
+import (
+	"math"
+	"sort"
+)
+
+// metersPerDegree is the length of one degree of latitude in meters.
+const metersPerDegree = earthRadius * math.Pi / 180
+
+// An IndexEntry is a point in an Index.
+type IndexEntry struct {
+	Kind PointKind
+	Wpt  *WptType
+	// Trk and TrkSeg are the indexes of the track and track segment
+	// containing the point, for track points.
+	Trk    int
+	TrkSeg int
+	// Index is the index of the point in the waypoints or its track segment.
+	Index int
+	// Distance is the distance in meters from the query position, for radius
+	// and nearest queries.
+	Distance float64
+}
+
+// An Index is a grid spatial index over the waypoints and track points of a
+// GPX document. It is not updated if the document is modified.
+type Index struct {
+	entries  []IndexEntry
+	cells    map[[2]int][]int
+	minLat   float64
+	minLon   float64
+	cellSize float64
+	maxCell  [2]int
+	// trkPts are the track points of each track segment, indexed by track
+	// and track segment, and maxSegmentLength is the length in meters of
+	// the longest line segment between consecutive track points.
+	trkPts           [][][]*WptType
+	maxSegmentLength float64
+}
+
+// NewIndex returns a new Index of the waypoints and track points in g.
+func NewIndex(g *GPX) *Index {
+	index := &Index{
+		cells: make(map[[2]int][]int),
+	}
+	for i, wpt := range g.Wpt {
+		index.entries = append(index.entries, IndexEntry{
+			Kind:  PointKindWpt,
+			Wpt:   wpt,
+			Index: i,
+		})
+	}
+	index.trkPts = make([][][]*WptType, len(g.Trk))
+	for i, trk := range g.Trk {
+		index.trkPts[i] = make([][]*WptType, len(trk.TrkSeg))
+		for j, ts := range trk.TrkSeg {
+			index.trkPts[i][j] = ts.TrkPt
+			for k, trkPt := range ts.TrkPt {
+				index.entries = append(index.entries, IndexEntry{
+					Kind:   PointKindTrkPt,
+					Wpt:    trkPt,
+					Trk:    i,
+					TrkSeg: j,
+					Index:  k,
+				})
+				if k > 0 {
+					prev := ts.TrkPt[k-1]
+					index.maxSegmentLength = math.Max(index.maxSegmentLength, HaversineDistance(prev.Lat, prev.Lon, trkPt.Lat, trkPt.Lon))
+				}
+			}
+		}
+	}
+	if len(index.entries) == 0 {
+		index.cellSize = 1
+		return index
+	}
+
+	// Choose a cell size that gives about 16 points per cell.
+	index.minLat, index.minLon = math.Inf(1), math.Inf(1)
+	maxLat, maxLon := math.Inf(-1), math.Inf(-1)
+	for _, entry := range index.entries {
+		index.minLat = math.Min(index.minLat, entry.Wpt.Lat)
+		index.minLon = math.Min(index.minLon, entry.Wpt.Lon)
+		maxLat = math.Max(maxLat, entry.Wpt.Lat)
+		maxLon = math.Max(maxLon, entry.Wpt.Lon)
+	}
+	area := math.Max(maxLat-index.minLat, 1e-4) * math.Max(maxLon-index.minLon, 1e-4)
+	index.cellSize = math.Sqrt(area * 16 / float64(len(index.entries)))
+
+	index.maxCell = [2]int{
+		int(math.Floor((maxLat - index.minLat) / index.cellSize)),
+		int(math.Floor((maxLon - index.minLon) / index.cellSize)),
+	}
+	for i, entry := range index.entries {
+		cell := index.cell(entry.Wpt.Lat, entry.Wpt.Lon)
+		index.cells[cell] = append(index.cells[cell], i)
+	}
+	return index
+}
+
+// Len returns the number of points in i.
+func (i *Index) Len() int {
+	return len(i.entries)
+}
+
+// Bbox returns the points in the bounding box, in no particular order.
+func (i *Index) Bbox(minLat, minLon, maxLat, maxLon float64) []IndexEntry {
+	var result []IndexEntry
+	i.visit(minLat, minLon, maxLat, maxLon, func(entry IndexEntry) {
+		if minLat <= entry.Wpt.Lat && entry.Wpt.Lat <= maxLat && minLon <= entry.Wpt.Lon && entry.Wpt.Lon <= maxLon {
+			result = append(result, entry)
+		}
+	})
+	return result
+}
+
+// Radius returns the points within meters of lat and lon, nearest first.
+func (i *Index) Radius(lat, lon, meters float64) []IndexEntry {
+	var result []IndexEntry
+	i.radius(lat, lon, meters, func(entry IndexEntry) {
+		result = append(result, entry)
+	})
+	sortByDistance(result)
+	return result
+}
+
+// Nearest returns the k points nearest to lat and lon, nearest first.
+func (i *Index) Nearest(lat, lon float64, k int) []IndexEntry {
+	if k <= 0 || len(i.entries) == 0 {
+		return nil
+	}
+	k = min(k, len(i.entries))
+	for meters := i.cellSize * metersPerDegree; ; meters *= 2 {
+		if result := i.Radius(lat, lon, meters); len(result) >= k {
+			return result[:k]
+		}
+		if meters > math.Pi*earthRadius {
+			result := i.Radius(lat, lon, math.Inf(1))
+			return result[:k]
+		}
+	}
+}
+
+// NearestWpt returns the waypoint in i nearest to lat and lon, or nil if i has
+// no waypoints.
+func (i *Index) NearestWpt(lat, lon float64) *PointMatch {
+	return i.nearestOfKind(lat, lon, PointKindWpt)
+}
+
+// NearestTrkPt returns the track point in i nearest to lat and lon, or nil if
+// i has no track points.
+func (i *Index) NearestTrkPt(lat, lon float64) *PointMatch {
+	return i.nearestOfKind(lat, lon, PointKindTrkPt)
+}
+
+// SnapTrkPt returns a new point on the tracks in i nearest to lat and lon,
+// interpolated between consecutive track points, or nil if i has no track
+// points.
+func (i *Index) SnapTrkPt(lat, lon float64) *PointMatch {
+	nearest := i.NearestTrkPt(lat, lon)
+	if nearest == nil {
+		return nil
+	}
+	// A line segment that passes nearer than the nearest track point has an
+	// end within that distance plus the line segment's length.
+	var result *PointMatch
+	i.radius(lat, lon, nearest.Distance+i.maxSegmentLength, func(entry IndexEntry) {
+		if entry.Kind != PointKindTrkPt {
+			return
+		}
+		trkPts := i.trkPts[entry.Trk][entry.TrkSeg]
+		for _, j := range []int{entry.Index - 1, entry.Index} {
+			if j < 0 || j >= max(len(trkPts)-1, 1) {
+				continue
+			}
+			wpt, distance := snap(trkPts, j, lat, lon)
+			match := &PointMatch{
+				Wpt:      wpt,
+				Distance: distance,
+				Trk:      entry.Trk,
+				TrkSeg:   entry.TrkSeg,
+				Index:    j,
+			}
+			if result == nil || match.before(result) {
+				result = match
+			}
+		}
+	})
+	return result
+}
+
+// radius calls f for each point within meters of lat and lon, in no
+// particular order.
+func (i *Index) radius(lat, lon, meters float64, f func(IndexEntry)) {
+	// The circle's longitude extent is widest poleward of lat, where its
+	// half-width is asin(sin(d)/cos(lat)) for an angular radius d. If the
+	// circle contains a pole then it spans all longitudes.
+	d := meters / earthRadius
+	dLat := d * 180 / math.Pi
+	dLon := 360.0
+	if d < math.Pi/2 {
+		if s := math.Sin(d) / math.Cos(lat*math.Pi/180); s < 1 {
+			dLon = math.Asin(s) * 180 / math.Pi
+		}
+	}
+	visit := func(entry IndexEntry) {
+		entry.Distance = HaversineDistance(lat, lon, entry.Wpt.Lat, entry.Wpt.Lon)
+		if entry.Distance <= meters {
+			f(entry)
+		}
+	}
+	if dLon >= 180 {
+		for _, entry := range i.entries {
+			visit(entry)
+		}
+	} else {
+		i.visit(lat-dLat, lon-dLon, lat+dLat, lon+dLon, visit)
+		// Also visit the points on the other side of the antimeridian.
+		if lon-dLon < -180 {
+			i.visit(lat-dLat, lon-dLon+360, lat+dLat, 180, visit)
+		}
+		if lon+dLon > 180 {
+			i.visit(lat-dLat, -180, lat+dLat, lon+dLon-360, visit)
+		}
+	}
+}
+
+// nearestOfKind returns the point of kind nearest to lat and lon, or nil if i
+// has no points of kind. Of equally near points, the first in document order
+// is returned.
+func (i *Index) nearestOfKind(lat, lon float64, kind PointKind) *PointMatch {
+	var result *PointMatch
+	for meters := i.cellSize * metersPerDegree; ; meters *= 2 {
+		if meters > math.Pi*earthRadius {
+			meters = math.Inf(1)
+		}
+		i.radius(lat, lon, meters, func(entry IndexEntry) {
+			if entry.Kind != kind {
+				return
+			}
+			match := &PointMatch{
+				Wpt:      entry.Wpt,
+				Distance: entry.Distance,
+				Trk:      entry.Trk,
+				TrkSeg:   entry.TrkSeg,
+				Index:    entry.Index,
+			}
+			if result == nil || match.before(result) {
+				result = match
+			}
+		})
+		if result != nil || math.IsInf(meters, 1) {
+			return result
+		}
+	}
+}
+
+// cell returns the cell containing lat and lon, clamped to the cells
+// containing points.
+func (i *Index) cell(lat, lon float64) [2]int {
+	clamp := func(x float64, maxCell int) int {
+		return int(math.Max(0, math.Min(math.Floor(x), float64(maxCell))))
+	}
+	return [2]int{
+		clamp((lat-i.minLat)/i.cellSize, i.maxCell[0]),
+		clamp((lon-i.minLon)/i.cellSize, i.maxCell[1]),
+	}
+}
+
+// visit calls f for each point in the cells overlapping the bounding box.
+func (i *Index) visit(minLat, minLon, maxLat, maxLon float64, f func(IndexEntry)) {
+	if len(i.entries) == 0 || minLat > maxLat || minLon > maxLon {
+		return
+	}
+	if maxLat < i.minLat || maxLon < i.minLon {
+		return
+	}
+	minCell := i.cell(minLat, minLon)
+	maxCell := i.cell(maxLat, maxLon)
+	if cells := (maxCell[0] - minCell[0] + 1) * (maxCell[1] - minCell[1] + 1); cells > len(i.cells) {
+		// The bounding box covers more cells than are populated, so visit
+		// the populated cells instead.
+		for cell, entries := range i.cells {
+			if minCell[0] <= cell[0] && cell[0] <= maxCell[0] && minCell[1] <= cell[1] && cell[1] <= maxCell[1] {
+				for _, entry := range entries {
+					f(i.entries[entry])
+				}
+			}
+		}
+		return
+	}
+	for latCell := minCell[0]; latCell <= maxCell[0]; latCell++ {
+		for lonCell := minCell[1]; lonCell <= maxCell[1]; lonCell++ {
+			for _, entry := range i.cells[[2]int{latCell, lonCell}] {
+				f(i.entries[entry])
+			}
+		}
+	}
+}
+
+// sortByDistance sorts entries by distance.
+func sortByDistance(entries []IndexEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Distance < entries[j].Distance
+	})
+}
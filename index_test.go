@@ -0,0 +1,145 @@
+package gpx_test
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestIndex(t *testing.T) {
+	r := rand.New(rand.NewSource(1)) //nolint:gosec
+	randomWpt := func(int) *gpx.WptType {
+		return &gpx.WptType{Lat: 46 + r.Float64(), Lon: 7 + r.Float64()}
+	}
+	g := &gpx.GPX{
+		Wpt: newTestTrkSeg(50, randomWpt).TrkPt,
+		Trk: []*gpx.TrkType{
+			{TrkSeg: []*gpx.TrkSegType{newTestTrkSeg(500, randomWpt), newTestTrkSeg(500, randomWpt)}},
+			{TrkSeg: []*gpx.TrkSegType{newTestTrkSeg(500, randomWpt), newTestTrkSeg(500, randomWpt)}},
+		},
+	}
+	index := gpx.NewIndex(g)
+	assert.Equal(t, 2050, index.Len())
+
+	all := index.Bbox(-90, -180, 90, 180)
+	for _, entry := range all {
+		switch entry.Kind {
+		case gpx.PointKindWpt:
+			assert.Same(t, g.Wpt[entry.Index], entry.Wpt)
+		case gpx.PointKindTrkPt:
+			assert.Same(t, g.Trk[entry.Trk].TrkSeg[entry.TrkSeg].TrkPt[entry.Index], entry.Wpt)
+		}
+	}
+	assert.Len(t, all, 2050)
+
+	bruteForce := func(lat, lon float64) []gpx.IndexEntry {
+		result := make([]gpx.IndexEntry, len(all))
+		for i, entry := range all {
+			entry.Distance = gpx.HaversineDistance(lat, lon, entry.Wpt.Lat, entry.Wpt.Lon)
+			result[i] = entry
+		}
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Distance < result[j].Distance
+		})
+		return result
+	}
+
+	for _, query := range [][2]float64{{46.5, 7.5}, {46.01, 7.99}, {40, 0}, {47.5, 7.5}} {
+		expected := bruteForce(query[0], query[1])
+
+		got := index.Nearest(query[0], query[1], 10)
+		assert.Len(t, got, 10)
+		for i := range got {
+			assert.Equal(t, expected[i].Distance, got[i].Distance)
+		}
+
+		radius := expected[25].Distance
+		got = index.Radius(query[0], query[1], radius)
+		assert.Len(t, got, 26)
+		for _, entry := range got {
+			assert.LessOrEqual(t, entry.Distance, radius)
+		}
+	}
+
+	got := index.Bbox(46.2, 7.2, 46.4, 7.3)
+	var expected int
+	for _, entry := range all {
+		if 46.2 <= entry.Wpt.Lat && entry.Wpt.Lat <= 46.4 && 7.2 <= entry.Wpt.Lon && entry.Wpt.Lon <= 7.3 {
+			expected++
+		}
+	}
+	assert.Len(t, got, expected)
+	assert.NotZero(t, expected)
+
+	assert.Empty(t, index.Bbox(0, 0, 1, 1))
+	assert.Len(t, index.Nearest(0, 0, 3000), 2050)
+
+	// Nearest point queries match linear scans.
+	for i, query := range [][2]float64{{46.5, 7.5}, {46.01, 7.99}, {40, 0}, {47.5, 7.5}} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			lat, lon := query[0], query[1]
+			assert.Equal(t, g.NearestWpt(lat, lon), index.NearestWpt(lat, lon))
+
+			var nearest, snapped *gpx.PointMatch
+			for j, trk := range g.Trk {
+				if match := trk.NearestPoint(lat, lon); nearest == nil || match.Distance < nearest.Distance {
+					nearest = match
+					nearest.Trk = j
+				}
+				if match := trk.SnapPoint(lat, lon); snapped == nil || match.Distance < snapped.Distance {
+					snapped = match
+					snapped.Trk = j
+				}
+			}
+			assert.Equal(t, nearest, index.NearestTrkPt(lat, lon))
+			assert.Equal(t, snapped, index.SnapTrkPt(lat, lon))
+		})
+	}
+
+	single := gpx.NewIndex(&gpx.GPX{
+		Trk: []*gpx.TrkType{{TrkSeg: []*gpx.TrkSegType{{TrkPt: []*gpx.WptType{{Lat: 1, Lon: 2}}}}}},
+	})
+	assert.Equal(t, &gpx.WptType{Lat: 1, Lon: 2}, single.SnapTrkPt(0, 0).Wpt)
+	assert.Nil(t, single.NearestWpt(0, 0))
+
+	empty := gpx.NewIndex(&gpx.GPX{})
+	assert.Nil(t, empty.NearestWpt(0, 0))
+	assert.Nil(t, empty.NearestTrkPt(0, 0))
+	assert.Nil(t, empty.SnapTrkPt(0, 0))
+}
+
+func TestIndexAntimeridian(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 0, Lon: 179.9999, Name: "east"},
+			{Lat: 0, Lon: -179.9999, Name: "west"},
+			{Lat: 0, Lon: 0, Name: "origin"},
+		},
+	}
+	index := gpx.NewIndex(g)
+	got := index.Radius(0, 179.99995, 100)
+	assert.Len(t, got, 2)
+
+	assert.Empty(t, gpx.NewIndex(&gpx.GPX{}).Nearest(0, 0, 1))
+	assert.Empty(t, gpx.NewIndex(&gpx.GPX{}).Radius(0, 0, 1))
+}
+
+func TestIndexRadiusHighLatitude(t *testing.T) {
+	g := &gpx.GPX{}
+	for lat := range 81 {
+		for lon := range 61 {
+			g.Wpt = append(g.Wpt, &gpx.WptType{Lat: float64(lat), Lon: float64(lon)})
+		}
+	}
+	index := gpx.NewIndex(g)
+	var got []*gpx.WptType
+	for _, entry := range index.Radius(70, 0, 1700000) {
+		got = append(got, entry.Wpt)
+	}
+	assert.Contains(t, got, &gpx.WptType{Lat: 77, Lon: 48})
+}
@@ -0,0 +1,91 @@
+package gpx
+
+import "iter"
+
+// A TrkPtIndex identifies a track point within a GPX document.
+type TrkPtIndex struct {
+	Trk    int
+	TrkSeg int
+	TrkPt  int
+}
+
+// Points returns an iterator over all the points in g: its waypoints, then
+// its route points, then its track points.
+func (g *GPX) Points() iter.Seq[*WptType] {
+	return func(yield func(*WptType) bool) {
+		for _, wpt := range g.Wpt {
+			if !yield(wpt) {
+				return
+			}
+		}
+		for _, rte := range g.Rte {
+			for _, rtePt := range rte.RtePt {
+				if !yield(rtePt) {
+					return
+				}
+			}
+		}
+		for _, trk := range g.Trk {
+			for _, trkSeg := range trk.TrkSeg {
+				for _, trkPt := range trkSeg.TrkPt {
+					if !yield(trkPt) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// TrkPts returns an iterator over the track points in g and their indexes.
+func (g *GPX) TrkPts() iter.Seq2[TrkPtIndex, *WptType] {
+	return func(yield func(TrkPtIndex, *WptType) bool) {
+		for i, trk := range g.Trk {
+			for j, trkSeg := range trk.TrkSeg {
+				for k, trkPt := range trkSeg.TrkPt {
+					if !yield(TrkPtIndex{Trk: i, TrkSeg: j, TrkPt: k}, trkPt) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Points returns an iterator over the points in r.
+func (r *RteType) Points() iter.Seq[*WptType] {
+	return func(yield func(*WptType) bool) {
+		for _, rtePt := range r.RtePt {
+			if !yield(rtePt) {
+				return
+			}
+		}
+	}
+}
+
+// Points returns an iterator over the points in all segments of t.
+func (t *TrkType) Points() iter.Seq[*WptType] {
+	return func(yield func(*WptType) bool) {
+		for _, trkSeg := range t.TrkSeg {
+			for _, trkPt := range trkSeg.TrkPt {
+				if !yield(trkPt) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SegmentPoints returns an iterator over the points in all segments of t and
+// the indexes of their segments.
+func (t *TrkType) SegmentPoints() iter.Seq2[int, *WptType] {
+	return func(yield func(int, *WptType) bool) {
+		for i, trkSeg := range t.TrkSeg {
+			for _, trkPt := range trkSeg.TrkPt {
+				if !yield(i, trkPt) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gpx_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPoints(t *testing.T) {
+	wpt := &gpx.WptType{Name: "wpt"}
+	rtePt := &gpx.WptType{Name: "rtept"}
+	trkPt1 := &gpx.WptType{Name: "trkpt1"}
+	trkPt2 := &gpx.WptType{Name: "trkpt2"}
+	trkPt3 := &gpx.WptType{Name: "trkpt3"}
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{wpt},
+		Rte: []*gpx.RteType{
+			{RtePt: []*gpx.WptType{rtePt}},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{trkPt1}},
+					{TrkPt: []*gpx.WptType{trkPt2}},
+				},
+			},
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{trkPt3}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []*gpx.WptType{wpt, rtePt, trkPt1, trkPt2, trkPt3}, slices.Collect(g.Points()))
+	assert.Equal(t, []*gpx.WptType{rtePt}, slices.Collect(g.Rte[0].Points()))
+	assert.Equal(t, []*gpx.WptType{trkPt1, trkPt2}, slices.Collect(g.Trk[0].Points()))
+
+	var indexes []gpx.TrkPtIndex
+	for index := range g.TrkPts() {
+		indexes = append(indexes, index)
+	}
+	assert.Equal(t, []gpx.TrkPtIndex{
+		{Trk: 0, TrkSeg: 0, TrkPt: 0},
+		{Trk: 0, TrkSeg: 1, TrkPt: 0},
+		{Trk: 1, TrkSeg: 0, TrkPt: 0},
+	}, indexes)
+
+	var segmentIndexes []int
+	for i := range g.Trk[0].SegmentPoints() {
+		segmentIndexes = append(segmentIndexes, i)
+	}
+	assert.Equal(t, []int{0, 1}, segmentIndexes)
+
+	for wpt := range g.Points() {
+		assert.Equal(t, "wpt", wpt.Name)
+		break
+	}
+}
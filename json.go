@@ -0,0 +1,146 @@
+package gpx
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// wptJSON is the JSON representation of a WptType. Its fields shadow the
+// optional fields of WptType so that absent fields are omitted and present
+// zero fields are not.
+type wptJSON struct {
+	*wptAlias
+	Ele           *float64         `json:"ele,omitempty"`
+	Speed         *float64         `json:"speed,omitempty"`
+	Course        *float64         `json:"course,omitempty"`
+	Time          *time.Time       `json:"time,omitempty"`
+	MagVar        *DegreesType     `json:"magvar,omitempty"`
+	GeoidHeight   *float64         `json:"geoidheight,omitempty"`
+	Sat           *int             `json:"sat,omitempty"`
+	HDOP          *float64         `json:"hdop,omitempty"`
+	VDOP          *float64         `json:"vdop,omitempty"`
+	PDOP          *float64         `json:"pdop,omitempty"`
+	AgeOfDGPSData *float64         `json:"ageofdgpsdata,omitempty"`
+	DGPSID        *DGPSStationType `json:"dgpsid,omitempty"`
+}
+
+type wptAlias WptType
+
+// MarshalJSON implements json.Marshaler.MarshalJSON. Extensions are written
+// as a string containing their XML.
+func (e *ExtensionsType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(e.XML))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON.
+func (e *ExtensionsType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	e.XML = []byte(s)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON. A zero time is omitted.
+func (m *MetadataType) MarshalJSON() ([]byte, error) {
+	type alias MetadataType
+	return json.Marshal(struct {
+		*alias
+		Time *time.Time `json:"time,omitempty"`
+	}{
+		alias: (*alias)(m),
+		Time:  optionalTime(m.Time),
+	})
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON. A zero time is omitted.
+func (p *PtType) MarshalJSON() ([]byte, error) {
+	type alias PtType
+	return json.Marshal(struct {
+		*alias
+		Time *time.Time `json:"time,omitempty"`
+	}{
+		alias: (*alias)(p),
+		Time:  optionalTime(p.Time),
+	})
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON. Absent optional fields
+// are omitted and present zero fields are written, see WptFields. A zero time
+// is omitted.
+func (w *WptType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&wptJSON{
+		wptAlias:      (*wptAlias)(w),
+		Ele:           optionalField(w, WptEle, w.Ele),
+		Speed:         optionalField(w, WptSpeed, w.Speed),
+		Course:        optionalField(w, WptCourse, w.Course),
+		Time:          optionalTime(w.Time),
+		MagVar:        optionalField(w, WptMagVar, w.MagVar),
+		GeoidHeight:   optionalField(w, WptGeoidHeight, w.GeoidHeight),
+		Sat:           optionalField(w, WptSat, w.Sat),
+		HDOP:          optionalField(w, WptHDOP, w.HDOP),
+		VDOP:          optionalField(w, WptVDOP, w.VDOP),
+		PDOP:          optionalField(w, WptPDOP, w.PDOP),
+		AgeOfDGPSData: optionalField(w, WptAgeOfDGPSData, w.AgeOfDGPSData),
+		DGPSID:        optionalField(w, WptDGPSID, w.DGPSID),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON. Optional fields
+// that are present with zero values are added to w.ZeroFields.
+func (w *WptType) UnmarshalJSON(data []byte) error {
+	var wt WptType
+	wj := wptJSON{
+		wptAlias: (*wptAlias)(&wt),
+	}
+	if err := json.Unmarshal(data, &wj); err != nil {
+		return err
+	}
+	setOptionalField(&wt, WptEle, &wt.Ele, wj.Ele)
+	setOptionalField(&wt, WptSpeed, &wt.Speed, wj.Speed)
+	setOptionalField(&wt, WptCourse, &wt.Course, wj.Course)
+	if wj.Time != nil {
+		wt.Time = *wj.Time
+	}
+	setOptionalField(&wt, WptMagVar, &wt.MagVar, wj.MagVar)
+	setOptionalField(&wt, WptGeoidHeight, &wt.GeoidHeight, wj.GeoidHeight)
+	setOptionalField(&wt, WptSat, &wt.Sat, wj.Sat)
+	setOptionalField(&wt, WptHDOP, &wt.HDOP, wj.HDOP)
+	setOptionalField(&wt, WptVDOP, &wt.VDOP, wj.VDOP)
+	setOptionalField(&wt, WptPDOP, &wt.PDOP, wj.PDOP)
+	setOptionalField(&wt, WptAgeOfDGPSData, &wt.AgeOfDGPSData, wj.AgeOfDGPSData)
+	setOptionalField(&wt, WptDGPSID, &wt.DGPSID, wj.DGPSID)
+	*w = wt
+	return nil
+}
+
+// optionalField returns a pointer to value if field is present in w, or nil
+// otherwise.
+func optionalField[T any](w *WptType, field WptFields, value T) *T {
+	if !w.Has(field) {
+		return nil
+	}
+	return &value
+}
+
+// setOptionalField sets *dst to *src and records field as present in w, if
+// src is not nil.
+func setOptionalField[T comparable](w *WptType, field WptFields, dst, src *T) {
+	if src == nil {
+		return
+	}
+	*dst = *src
+	var zero T
+	if *src == zero {
+		w.ZeroFields |= field
+	}
+}
+
+// optionalTime returns a pointer to t, or nil if t is zero.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
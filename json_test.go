@@ -0,0 +1,67 @@
+package gpx_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestJSON(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Metadata: &gpx.MetadataType{
+			Name: "name",
+		},
+		Wpt: []*gpx.WptType{
+			{
+				Lat:        1,
+				Lon:        2,
+				Time:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Fix:        gpx.Fix3D,
+				ZeroFields: gpx.WptEle | gpx.WptHDOP,
+				Extensions: &gpx.ExtensionsType{XML: []byte("<hr>120</hr>")},
+			},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				Name: "trk",
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{{Lat: 3, Lon: 4, Ele: 5, Sat: 6}}},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(g)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": "1.1",
+		"creator": "test",
+		"metadata": {"name": "name"},
+		"wpt": [{"lat": 1, "lon": 2, "ele": 0, "time": "2024-05-01T10:00:00Z", "fix": "3d", "hdop": 0, "extensions": "<hr>120</hr>"}],
+		"trk": [{"name": "trk", "trkseg": [{"trkpt": [{"lat": 3, "lon": 4, "ele": 5, "sat": 6}]}]}]
+	}`, string(data))
+
+	var got gpx.GPX
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, g, &got)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	f, err := os.Open("testdata/ashland.gpx")
+	assert.NoError(t, err)
+	defer f.Close()
+	g, err := gpx.Read(f)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(g)
+	assert.NoError(t, err)
+	var got gpx.GPX
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, g, &got)
+}
@@ -0,0 +1,44 @@
+package gpx
+
+// keywordsNamespace is the namespace of the keywords extension. The GPX
+// schema only has keywords in the document metadata, so this package stores
+// the keywords of tracks and waypoints as extensions in this namespace, which
+// other tools can adopt.
+const keywordsNamespace = "https://github.com/twpayne/go-gpx/xmlschemas/KeywordsExtension/v1"
+
+// keywordsSpaces are the namespaces of keywords extension elements,
+// including the conventional prefix for when it is declared on the gpx
+// element.
+var keywordsSpaces = []string{keywordsNamespace, "gpxkw"}
+
+// Keywords returns t's comma-separated keywords from its extensions, or the
+// empty string if it has none.
+func (t *TrkType) Keywords() string {
+	return t.Extensions.namespacedExtensions(keywordsSpaces...)["keywords"]
+}
+
+// SetKeywords sets t's comma-separated keywords in its extensions, preserving
+// its other extensions. If keywords is empty then the keywords are removed.
+func (t *TrkType) SetKeywords(keywords string) {
+	t.Extensions = t.Extensions.withKeywords(keywords)
+}
+
+// Keywords returns w's comma-separated keywords from its extensions, or the
+// empty string if it has none.
+func (w *WptType) Keywords() string {
+	return w.Extensions.namespacedExtensions(keywordsSpaces...)["keywords"]
+}
+
+// SetKeywords sets w's comma-separated keywords in its extensions, preserving
+// its other extensions. If keywords is empty then the keywords are removed.
+func (w *WptType) SetKeywords(keywords string) {
+	w.Extensions = w.Extensions.withKeywords(keywords)
+}
+
+// withKeywords returns a copy of e with its keywords extension replaced by
+// keywords.
+func (e *ExtensionsType) withKeywords(keywords string) *ExtensionsType {
+	return e.withNamespacedExtensions(keywordsSpaces, "gpxkw", keywordsNamespace, [][2]string{
+		{"keywords", keywords},
+	})
+}
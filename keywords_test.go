@@ -0,0 +1,40 @@
+package gpx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestKeywords(t *testing.T) {
+	trk := &gpx.TrkType{
+		Extensions: &gpx.ExtensionsType{XML: []byte("<other/>")},
+	}
+	assert.Equal(t, "", trk.Keywords())
+	trk.SetKeywords("hiking, alps")
+	assert.Equal(t, "hiking, alps", trk.Keywords())
+
+	wpt := &gpx.WptType{Lat: 1, Lon: 2}
+	wpt.SetKeywords("hut")
+
+	g := &gpx.GPX{
+		Version: "1.1",
+		Wpt:     []*gpx.WptType{wpt},
+		Trk:     []*gpx.TrkType{trk},
+	}
+	var b bytes.Buffer
+	assert.NoError(t, g.Write(&b))
+	g2, err := gpx.Read(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, "hiking, alps", g2.Trk[0].Keywords())
+	assert.Equal(t, "hut", g2.Wpt[0].Keywords())
+	assert.Empty(t, g2.Trk[0].Type)
+
+	g2.Trk[0].SetKeywords("")
+	assert.Equal(t, "<other/>", string(g2.Trk[0].Extensions.XML))
+	g2.Wpt[0].SetKeywords("")
+	assert.Nil(t, g2.Wpt[0].Extensions)
+}
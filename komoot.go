@@ -0,0 +1,88 @@
+package gpx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// A komootTour is a Komoot tour API response, or the coordinates of a tour.
+type komootTour struct {
+	Name  string    `json:"name"`
+	Type  string    `json:"type"`
+	Sport string    `json:"sport"`
+	Date  time.Time `json:"date"`
+	// Items is set when the response is the coordinates of a tour alone.
+	Items    []komootCoordinate `json:"items"`
+	Embedded struct {
+		Coordinates struct {
+			Items []komootCoordinate `json:"items"`
+		} `json:"coordinates"`
+	} `json:"_embedded"`
+}
+
+// A komootCoordinate is a point of a Komoot tour. T is the time in
+// milliseconds since the start of the tour.
+type komootCoordinate struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+	Alt float64 `json:"alt"`
+	T   int64   `json:"t"`
+}
+
+// FromKomootTour returns a new GPX 1.1 document built from the Komoot tour
+// JSON read from r, which is either a tour with embedded coordinates or the
+// coordinates of a tour alone. Recorded tours become a track and planned tours
+// become a route, named by the tour's name and typed by its sport. Track
+// points have times only if the tour's date is known.
+func FromKomootTour(r io.Reader) (*GPX, error) {
+	var tour komootTour
+	if err := json.NewDecoder(r).Decode(&tour); err != nil {
+		return nil, err
+	}
+	coordinates := tour.Items
+	if coordinates == nil {
+		coordinates = tour.Embedded.Coordinates.Items
+	}
+	if coordinates == nil {
+		return nil, errors.New("missing coordinates")
+	}
+
+	wpts := make([]*WptType, 0, len(coordinates))
+	for _, coordinate := range coordinates {
+		wpt := &WptType{
+			Lat: coordinate.Lat,
+			Lon: coordinate.Lng,
+			Ele: coordinate.Alt,
+		}
+		if tour.Type != "tour_planned" && !tour.Date.IsZero() {
+			wpt.Time = tour.Date.Add(time.Duration(coordinate.T) * time.Millisecond).UTC()
+		}
+		wpts = append(wpts, wpt)
+	}
+
+	g := &GPX{
+		Version: "1.1",
+	}
+	if tour.Type == "tour_planned" {
+		g.Rte = []*RteType{
+			{
+				Name:  tour.Name,
+				Type:  tour.Sport,
+				RtePt: wpts,
+			},
+		}
+	} else {
+		g.Trk = []*TrkType{
+			{
+				Name: tour.Name,
+				Type: tour.Sport,
+				TrkSeg: []*TrkSegType{
+					{TrkPt: wpts},
+				},
+			},
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,78 @@
+package gpx_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFromKomootTour(t *testing.T) {
+	recorded := `{
+  "id": 123,
+  "type": "tour_recorded",
+  "name": "Feldberg loop",
+  "sport": "hike",
+  "date": "2024-05-01T08:00:00.000+02:00",
+  "_embedded": {
+    "coordinates": {
+      "items": [
+        {"lat": 47.874, "lng": 8.004, "alt": 1277.5, "t": 0},
+        {"lat": 47.875, "lng": 8.005, "alt": 1280, "t": 61500}
+      ]
+    }
+  }
+}`
+	got, err := gpx.FromKomootTour(strings.NewReader(recorded))
+	assert.NoError(t, err)
+	assert.Equal(t, &gpx.GPX{
+		Version: "1.1",
+		Trk: []*gpx.TrkType{
+			{
+				Name: "Feldberg loop",
+				Type: "hike",
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 47.874, Lon: 8.004, Ele: 1277.5, Time: time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)},
+							{Lat: 47.875, Lon: 8.005, Ele: 1280, Time: time.Date(2024, 5, 1, 6, 1, 1, 500000000, time.UTC)},
+						},
+					},
+				},
+			},
+		},
+	}, got)
+
+	planned := `{
+  "type": "tour_planned",
+  "name": "Feldberg plan",
+  "sport": "hike",
+  "date": "2024-04-20T10:00:00.000Z",
+  "_embedded": {"coordinates": {"items": [{"lat": 47.874, "lng": 8.004, "alt": 1277.5, "t": 0}]}}
+}`
+	got, err = gpx.FromKomootTour(strings.NewReader(planned))
+	assert.NoError(t, err)
+	assert.Empty(t, got.Trk)
+	assert.Equal(t, []*gpx.RteType{
+		{
+			Name: "Feldberg plan",
+			Type: "hike",
+			RtePt: []*gpx.WptType{
+				{Lat: 47.874, Lon: 8.004, Ele: 1277.5},
+			},
+		},
+	}, got.Rte)
+
+	coordinates := `{"items": [{"lat": 1, "lng": 2, "alt": 3, "t": 1000}]}`
+	got, err = gpx.FromKomootTour(strings.NewReader(coordinates))
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.WptType{{Lat: 1, Lon: 2, Ele: 3}}, got.Trk[0].TrkSeg[0].TrkPt)
+
+	_, err = gpx.FromKomootTour(strings.NewReader(`{"name": "empty"}`))
+	assert.Error(t, err)
+	_, err = gpx.FromKomootTour(strings.NewReader(`[`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// gpxDataNamespace is the namespace of the Cluetrust gpxdata extensions.
+const gpxDataNamespace = "http://www.cluetrust.com/XML/GPXDATA/1/0"
+
+// A Lap is a summary of a lap of a structured workout.
+type Lap struct {
+	StartTime    time.Time
+	Duration     time.Duration
+	Distance     float64 // Meters.
+	Calories     int
+	AvgHeartRate float64 // Beats per minute.
+	MaxHeartRate float64 // Beats per minute.
+	Start        *WptType
+	End          *WptType
+	Trigger      string // For example, "manual", "distance", or "time".
+	Intensity    string // For example, "active" or "resting".
+}
+
+// gpxDataLap is the XML representation of a Lap as a Cluetrust gpxdata lap
+// element.
+type gpxDataLap struct {
+	XMLName     xml.Name         `xml:"gpxdata:lap"`
+	XMLNS       string           `xml:"xmlns:gpxdata,attr,omitempty"`
+	Index       int              `xml:"gpxdata:index"`
+	StartPoint  *gpxDataPoint    `xml:"gpxdata:startPoint,omitempty"`
+	EndPoint    *gpxDataPoint    `xml:"gpxdata:endPoint,omitempty"`
+	StartTime   string           `xml:"gpxdata:startTime,omitempty"`
+	ElapsedTime float64          `xml:"gpxdata:elapsedTime"`
+	Calories    int              `xml:"gpxdata:calories,omitempty"`
+	Distance    float64          `xml:"gpxdata:distance"`
+	Summary     []gpxDataSummary `xml:"gpxdata:summary,omitempty"`
+	Trigger     *gpxDataTrigger  `xml:"gpxdata:trigger,omitempty"`
+	Intensity   string           `xml:"gpxdata:intensity,omitempty"`
+}
+
+type gpxDataPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type gpxDataSummary struct {
+	Name  string  `xml:"name,attr"`
+	Kind  string  `xml:"kind,attr"`
+	Value float64 `xml:",chardata"`
+}
+
+type gpxDataTrigger struct {
+	Kind string `xml:"kind,attr"`
+}
+
+// gpxDataLapDecoder is the decoding equivalent of gpxDataLap, which matches
+// elements by local name only.
+type gpxDataLapDecoder struct {
+	StartPoint  *gpxDataPoint    `xml:"startPoint"`
+	EndPoint    *gpxDataPoint    `xml:"endPoint"`
+	StartTime   string           `xml:"startTime"`
+	ElapsedTime float64          `xml:"elapsedTime"`
+	Calories    int              `xml:"calories"`
+	Distance    float64          `xml:"distance"`
+	Summary     []gpxDataSummary `xml:"summary"`
+	Trigger     *gpxDataTrigger  `xml:"trigger"`
+	Intensity   string           `xml:"intensity"`
+}
+
+// NewLap returns a Lap summarizing ts. The distance is calculated with
+// HaversineDistance and heart rates are read with WptType.Sensors.
+func NewLap(ts *TrkSegType) Lap {
+	var lap Lap
+	if len(ts.TrkPt) == 0 {
+		return lap
+	}
+	first, last := ts.TrkPt[0], ts.TrkPt[len(ts.TrkPt)-1]
+	lap.StartTime = first.Time
+	if !first.Time.IsZero() && !last.Time.IsZero() {
+		lap.Duration = last.Time.Sub(first.Time)
+	}
+	lap.Distance = ts.Length(nil)
+	lap.Start = &WptType{Lat: first.Lat, Lon: first.Lon}
+	lap.End = &WptType{Lat: last.Lat, Lon: last.Lon}
+	var sumHeartRates float64
+	var heartRates int
+	for _, trkPt := range ts.TrkPt {
+		if heartRate := trkPt.Sensors().HeartRate; !math.IsNaN(heartRate) {
+			sumHeartRates += heartRate
+			heartRates++
+			lap.MaxHeartRate = math.Max(lap.MaxHeartRate, heartRate)
+		}
+	}
+	if heartRates > 0 {
+		lap.AvgHeartRate = sumHeartRates / float64(heartRates)
+	}
+	return lap
+}
+
+// AddLaps adds laps to g's extensions as Cluetrust gpxdata lap elements,
+// numbered after any laps already in g.
+func (g *GPX) AddLaps(laps []Lap) error {
+	existingLaps, err := g.Laps()
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if g.Extensions != nil {
+		b.Write(g.Extensions.XML)
+	}
+	e := xml.NewEncoder(&b)
+	for i, lap := range laps {
+		if err := e.Encode(lap.gpxDataLap(len(existingLaps) + i)); err != nil {
+			return err
+		}
+	}
+	if err := e.Close(); err != nil {
+		return err
+	}
+	g.Extensions = &ExtensionsType{
+		XML: b.Bytes(),
+	}
+	return nil
+}
+
+// Laps returns the laps in g's extensions.
+func (g *GPX) Laps() ([]Lap, error) {
+	if g.Extensions == nil {
+		return nil, nil
+	}
+	var laps []Lap
+	d := xml.NewDecoder(bytes.NewReader(g.Extensions.XML))
+	for {
+		token, err := d.Token()
+		switch {
+		case errors.Is(err, io.EOF):
+			return laps, nil
+		case err != nil:
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "lap" {
+			continue
+		}
+		var lapDecoder gpxDataLapDecoder
+		if err := d.DecodeElement(&lapDecoder, &start); err != nil {
+			return nil, err
+		}
+		lap, err := lapDecoder.lap()
+		if err != nil {
+			return nil, err
+		}
+		laps = append(laps, lap)
+	}
+}
+
+// gpxDataLap returns the gpxdata representation of l with the given index.
+func (l *Lap) gpxDataLap(index int) *gpxDataLap {
+	lap := &gpxDataLap{
+		XMLNS:       gpxDataNamespace,
+		Index:       index,
+		ElapsedTime: l.Duration.Seconds(),
+		Calories:    l.Calories,
+		Distance:    l.Distance,
+		Intensity:   l.Intensity,
+	}
+	if l.Start != nil {
+		lap.StartPoint = &gpxDataPoint{Lat: l.Start.Lat, Lon: l.Start.Lon}
+	}
+	if l.End != nil {
+		lap.EndPoint = &gpxDataPoint{Lat: l.End.Lat, Lon: l.End.Lon}
+	}
+	if !l.StartTime.IsZero() {
+		lap.StartTime = l.StartTime.UTC().Format(timeLayout)
+	}
+	if l.AvgHeartRate != 0 {
+		lap.Summary = append(lap.Summary, gpxDataSummary{Name: "AverageHeartRateBpm", Kind: "avg", Value: l.AvgHeartRate})
+	}
+	if l.MaxHeartRate != 0 {
+		lap.Summary = append(lap.Summary, gpxDataSummary{Name: "MaximumHeartRateBpm", Kind: "max", Value: l.MaxHeartRate})
+	}
+	if l.Trigger != "" {
+		lap.Trigger = &gpxDataTrigger{Kind: l.Trigger}
+	}
+	return lap
+}
+
+// lap returns the Lap represented by d.
+func (d *gpxDataLapDecoder) lap() (Lap, error) {
+	lap := Lap{
+		Duration:  time.Duration(d.ElapsedTime * float64(time.Second)),
+		Distance:  d.Distance,
+		Calories:  d.Calories,
+		Intensity: d.Intensity,
+	}
+	if d.StartPoint != nil {
+		lap.Start = &WptType{Lat: d.StartPoint.Lat, Lon: d.StartPoint.Lon}
+	}
+	if d.EndPoint != nil {
+		lap.End = &WptType{Lat: d.EndPoint.Lat, Lon: d.EndPoint.Lon}
+	}
+	if d.StartTime != "" {
+		startTime, err := time.ParseInLocation(timeLayout, d.StartTime, time.UTC)
+		if err != nil {
+			return Lap{}, err
+		}
+		lap.StartTime = startTime
+	}
+	for _, summary := range d.Summary {
+		switch summary.Name {
+		case "AverageHeartRateBpm":
+			lap.AvgHeartRate = summary.Value
+		case "MaximumHeartRateBpm":
+			lap.MaxHeartRate = summary.Value
+		}
+	}
+	if d.Trigger != nil {
+		lap.Trigger = d.Trigger.Kind
+	}
+	return lap, nil
+}
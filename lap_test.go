@@ -0,0 +1,83 @@
+package gpx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestNewLap(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 46, Lon: 7, Time: t0, Extensions: &gpx.ExtensionsType{XML: []byte("<gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension>")}},
+			{Lat: 46, Lon: 7.001, Time: t0.Add(30 * time.Second)},
+			{Lat: 46, Lon: 7.002, Time: t0.Add(time.Minute), Extensions: &gpx.ExtensionsType{XML: []byte("<gpxtpx:TrackPointExtension><gpxtpx:hr>140</gpxtpx:hr></gpxtpx:TrackPointExtension>")}},
+		},
+	}
+	lap := gpx.NewLap(ts)
+	assert.Equal(t, t0, lap.StartTime)
+	assert.Equal(t, time.Minute, lap.Duration)
+	assert.InDelta(t, ts.Length(nil), lap.Distance, 1e-9)
+	assert.Equal(t, 130.0, lap.AvgHeartRate)
+	assert.Equal(t, 140.0, lap.MaxHeartRate)
+	assert.Equal(t, &gpx.WptType{Lat: 46, Lon: 7}, lap.Start)
+	assert.Equal(t, &gpx.WptType{Lat: 46, Lon: 7.002}, lap.End)
+
+	assert.Equal(t, gpx.Lap{}, gpx.NewLap(&gpx.TrkSegType{}))
+}
+
+func TestLaps(t *testing.T) {
+	laps := []gpx.Lap{
+		{
+			StartTime:    time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+			Duration:     5 * time.Minute,
+			Distance:     1000,
+			Calories:     60,
+			AvgHeartRate: 130,
+			MaxHeartRate: 150,
+			Start:        &gpx.WptType{Lat: 46, Lon: 7},
+			End:          &gpx.WptType{Lat: 46.01, Lon: 7},
+			Trigger:      "distance",
+			Intensity:    "active",
+		},
+		{
+			Duration:  90 * time.Second,
+			Distance:  200.5,
+			Intensity: "resting",
+		},
+	}
+
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Extensions: &gpx.ExtensionsType{
+			XML: []byte("<foo:bar>baz</foo:bar>"),
+		},
+	}
+	assert.NoError(t, g.AddLaps(laps))
+	assert.Contains(t, string(g.Extensions.XML), `<foo:bar>baz</foo:bar><gpxdata:lap xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0"><gpxdata:index>0</gpxdata:index>`)
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, g.Write(b))
+	got, err := gpx.Read(b)
+	assert.NoError(t, err)
+	gotLaps, err := got.Laps()
+	assert.NoError(t, err)
+	assert.Equal(t, laps, gotLaps)
+
+	// Added laps are numbered after the existing laps.
+	assert.NoError(t, got.AddLaps(laps[:1]))
+	assert.Contains(t, string(got.Extensions.XML), `<gpxdata:index>2</gpxdata:index>`)
+	gotLaps, err = got.Laps()
+	assert.NoError(t, err)
+	assert.Len(t, gotLaps, 3)
+
+	gotLaps, err = (&gpx.GPX{}).Laps()
+	assert.NoError(t, err)
+	assert.Nil(t, gotLaps)
+}
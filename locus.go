@@ -0,0 +1,64 @@
+package gpx
+
+// locusNamespace is the namespace of Locus Map's GPX extensions.
+const locusNamespace = "https://www.locusmap.app"
+
+// locusSpaces are the namespaces of Locus Map extension elements, including
+// the namespace used by older versions and the conventional prefix for when
+// it is declared on the gpx element.
+var locusSpaces = []string{locusNamespace, "http://www.locusmap.eu", "locus"}
+
+// A Locus contains the properties that the Locus Map Android app stores in
+// waypoint and track extensions. Empty fields are absent. Locus Map stores
+// track colors and widths in gpx_style line elements, which are preserved
+// as other extensions.
+type Locus struct {
+	// Icon is the URL or name of a waypoint's icon.
+	Icon string
+	// Activity is a track's activity, for example "cycling" or "walking".
+	Activity string
+	// RteComputeType is the routing profile used to plan a track, as a
+	// Locus Map routing type number.
+	RteComputeType string
+}
+
+// Locus returns w's Locus Map extensions.
+func (w *WptType) Locus() Locus {
+	return newLocus(w.Extensions)
+}
+
+// SetLocus replaces w's Locus Map extensions with l, preserving its other
+// extensions.
+func (w *WptType) SetLocus(l Locus) {
+	w.Extensions = l.extensions(w.Extensions)
+}
+
+// Locus returns t's Locus Map extensions.
+func (t *TrkType) Locus() Locus {
+	return newLocus(t.Extensions)
+}
+
+// SetLocus replaces t's Locus Map extensions with l, preserving its other
+// extensions.
+func (t *TrkType) SetLocus(l Locus) {
+	t.Extensions = l.extensions(t.Extensions)
+}
+
+// newLocus returns the Locus Map extensions in e.
+func newLocus(e *ExtensionsType) Locus {
+	values := e.namespacedExtensions(locusSpaces...)
+	return Locus{
+		Icon:           values["icon"],
+		Activity:       values["activity"],
+		RteComputeType: values["rteComputeType"],
+	}
+}
+
+// extensions returns e with its Locus Map extensions replaced by l.
+func (l *Locus) extensions(e *ExtensionsType) *ExtensionsType {
+	return e.withNamespacedExtensions(locusSpaces, "locus", locusNamespace, [][2]string{
+		{"icon", l.Icon},
+		{"activity", l.Activity},
+		{"rteComputeType", l.RteComputeType},
+	})
+}
@@ -0,0 +1,53 @@
+package gpx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestLocus(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="Locus Map" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpx_style="http://www.topografix.com/GPX/gpx_style/0/2" xmlns:locus="http://www.locusmap.eu">
+  <wpt lat="1" lon="2">
+    <extensions>
+      <locus:icon>file:Locus/z-ico01.png</locus:icon>
+    </extensions>
+  </wpt>
+  <trk>
+    <extensions>
+      <line xmlns="http://www.topografix.com/GPX/gpx_style/0/2"><color>FF0000</color><width>6.0</width></line>
+      <locus:activity>cycling</locus:activity>
+      <locus:rteComputeType>8</locus:rteComputeType>
+    </extensions>
+    <trkseg>
+      <trkpt lat="1" lon="2"/>
+    </trkseg>
+  </trk>
+</gpx>`
+
+	g, err := gpx.Read(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, gpx.Locus{Icon: "file:Locus/z-ico01.png"}, g.Wpt[0].Locus())
+	assert.Equal(t, gpx.Locus{Activity: "cycling", RteComputeType: "8"}, g.Trk[0].Locus())
+
+	g.Trk[0].SetLocus(gpx.Locus{Activity: "walking"})
+	assert.Equal(t, gpx.Locus{Activity: "walking"}, g.Trk[0].Locus())
+	assert.Contains(t, string(g.Trk[0].Extensions.XML), "<color>FF0000</color>")
+	assert.NotContains(t, string(g.Trk[0].Extensions.XML), "rteComputeType")
+
+	var b bytes.Buffer
+	assert.NoError(t, g.Write(&b))
+	g2, err := gpx.Read(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, gpx.Locus{Icon: "file:Locus/z-ico01.png"}, g2.Wpt[0].Locus())
+	assert.Equal(t, gpx.Locus{Activity: "walking"}, g2.Trk[0].Locus())
+
+	wpt := &gpx.WptType{}
+	wpt.SetLocus(gpx.Locus{Icon: "star"})
+	assert.Equal(t, gpx.Locus{Icon: "star"}, wpt.Locus())
+}
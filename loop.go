@@ -0,0 +1,216 @@
+package gpx
+
+import (
+	"math"
+	"sort"
+)
+
+// An Intersection is a point where a track segment crosses itself.
+type Intersection struct {
+	Lat float64
+	Lon float64
+	// I and J are the indexes of the first points of the two crossing line
+	// segments, with I < J.
+	I int
+	J int
+}
+
+// ClosureDistance returns the distance in meters between the first and last
+// points of ts, or zero if ts has fewer than two points.
+func (ts *TrkSegType) ClosureDistance() float64 {
+	if len(ts.TrkPt) < 2 {
+		return 0
+	}
+	first, last := ts.TrkPt[0], ts.TrkPt[len(ts.TrkPt)-1]
+	return HaversineDistance(first.Lat, first.Lon, last.Lat, last.Lon)
+}
+
+// IsLoop returns whether ts ends within tolerance meters of its start and is
+// longer than twice tolerance, so that stationary recordings are not loops.
+func (ts *TrkSegType) IsLoop(tolerance float64) bool {
+	return len(ts.TrkPt) >= 2 && ts.ClosureDistance() <= tolerance && ts.Length(nil) > 2*tolerance
+}
+
+// SelfIntersections returns the points where ts crosses itself, ordered by
+// I and then J. Crossings at a vertex, for example where a route snapped to
+// road nodes passes through the same node twice, are included once, with I
+// and J the indexes of the line segments leaving the vertex. Line segments
+// that only touch, including adjacent line segments and the first and last
+// line segments of a closed loop, are not considered to intersect.
+func (ts *TrkSegType) SelfIntersections() []Intersection {
+	n := len(ts.TrkPt) - 1
+	if n < 2 {
+		return nil
+	}
+
+	// Project the points onto a local plane.
+	lat0 := ts.TrkPt[0].Lat
+	lon0 := ts.TrkPt[0].Lon
+	cosLat0 := math.Cos(lat0 * math.Pi / 180)
+	xs := make([]float64, len(ts.TrkPt))
+	ys := make([]float64, len(ts.TrkPt))
+	for i, trkPt := range ts.TrkPt {
+		xs[i] = normalizeLon(trkPt.Lon-lon0) * cosLat0
+		ys[i] = trkPt.Lat - lat0
+	}
+
+	// Find the runs of repeated points, which are a single vertex.
+	runStarts := make([]int, len(ts.TrkPt))
+	runEnds := make([]int, len(ts.TrkPt))
+	for i := range ts.TrkPt {
+		if i > 0 && xs[i] == xs[i-1] && ys[i] == ys[i-1] {
+			runStarts[i] = runStarts[i-1]
+		} else {
+			runStarts[i] = i
+		}
+	}
+	for i := n; i >= 0; i-- {
+		if i < n && runStarts[i+1] == runStarts[i] {
+			runEnds[i] = runEnds[i+1]
+		} else {
+			runEnds[i] = i
+		}
+	}
+
+	// locate returns the location of the point at fraction f along the line
+	// segment i, the index of the line segment leaving it, and the directions
+	// in which ts arrives at and leaves it. ok is false if the point is the
+	// start or end of ts.
+	locate := func(i int, f float64) (location loopLocation, segment int, directions [2]float64, ok bool) {
+		switch f {
+		case 0, 1:
+			vertex := i + int(f)
+			start, end := runStarts[vertex], runEnds[vertex]
+			if start == 0 || end == n {
+				return loopLocation{}, 0, directions, false
+			}
+			directions[0] = math.Atan2(ys[start-1]-ys[vertex], xs[start-1]-xs[vertex])
+			directions[1] = math.Atan2(ys[end+1]-ys[vertex], xs[end+1]-xs[vertex])
+			return loopLocation{index: start, vertex: true}, end, directions, true
+		default:
+			directions[0] = math.Atan2(ys[i]-ys[i+1], xs[i]-xs[i+1])
+			directions[1] = math.Atan2(ys[i+1]-ys[i], xs[i+1]-xs[i])
+			return loopLocation{index: i}, i, directions, true
+		}
+	}
+
+	// Sweep line segments ordered by their minimum x coordinate.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return math.Min(xs[order[i]], xs[order[i]+1]) < math.Min(xs[order[j]], xs[order[j]+1])
+	})
+	var result []Intersection
+	seen := make(map[[2]loopLocation]bool)
+	for k, i := range order {
+		maxX := math.Max(xs[i], xs[i+1])
+		minY, maxY := math.Min(ys[i], ys[i+1]), math.Max(ys[i], ys[i+1])
+		for _, j := range order[k+1:] {
+			if math.Min(xs[j], xs[j+1]) > maxX {
+				break
+			}
+			if j == i-1 || j == i+1 || math.Max(ys[j], ys[j+1]) < minY || math.Min(ys[j], ys[j+1]) > maxY {
+				continue
+			}
+			t, u, ok := segmentIntersection(xs[i], ys[i], xs[i+1], ys[i+1], xs[j], ys[j], xs[j+1], ys[j+1])
+			if !ok {
+				continue
+			}
+			locationI, segmentI, directionsI, okI := locate(i, t)
+			locationJ, segmentJ, directionsJ, okJ := locate(j, u)
+			if !okI || !okJ || locationI == locationJ || !crosses(directionsI, directionsJ) {
+				continue
+			}
+			if segmentI > segmentJ {
+				locationI, locationJ = locationJ, locationI
+				segmentI, segmentJ = segmentJ, segmentI
+			}
+			key := [2]loopLocation{locationI, locationJ}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			intersection := Intersection{
+				I: segmentI,
+				J: segmentJ,
+			}
+			switch a, b := ts.TrkPt[i], ts.TrkPt[i+1]; t {
+			case 0:
+				intersection.Lat, intersection.Lon = a.Lat, a.Lon
+			case 1:
+				intersection.Lat, intersection.Lon = b.Lat, b.Lon
+			default:
+				intersection.Lat = a.Lat + t*(b.Lat-a.Lat)
+				intersection.Lon = normalizeLon(a.Lon + t*normalizeLon(b.Lon-a.Lon))
+			}
+			result = append(result, intersection)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].I != result[b].I {
+			return result[a].I < result[b].I
+		}
+		return result[a].J < result[b].J
+	})
+	return result
+}
+
+// A loopLocation is a location on a track segment, either the vertex
+// starting at a point or the interior of the line segment starting at a
+// point.
+type loopLocation struct {
+	index  int
+	vertex bool
+}
+
+// crosses returns whether a path leaving a point in directions b crosses a
+// path leaving the same point in directions a, where directions are angles
+// in radians. Paths that share a direction only touch.
+func crosses(a, b [2]float64) bool {
+	if b[0] == a[0] || b[0] == a[1] || b[1] == a[0] || b[1] == a[1] {
+		return false
+	}
+	// between returns whether direction d is strictly between a[0] and a[1]
+	// counterclockwise.
+	between := func(d float64) bool {
+		return math.Mod(d-a[0]+4*math.Pi, 2*math.Pi) < math.Mod(a[1]-a[0]+4*math.Pi, 2*math.Pi)
+	}
+	return between(b[0]) != between(b[1])
+}
+
+// segmentIntersection returns the fractions along the line segment from x1,
+// y1 to x2, y2 and along the line segment from x3, y3 to x4, y4 at which they
+// intersect, and whether they intersect. Fractions within
+// segmentIntersectionEpsilon of either end of a line segment are snapped to
+// it. Collinear line segments do not intersect.
+func segmentIntersection(x1, y1, x2, y2, x3, y3, x4, y4 float64) (float64, float64, bool) {
+	denominator := (x2-x1)*(y4-y3) - (y2-y1)*(x4-x3)
+	if denominator == 0 {
+		return 0, 0, false
+	}
+	t := snapFraction(((x3-x1)*(y4-y3) - (y3-y1)*(x4-x3)) / denominator)
+	u := snapFraction(((x3-x1)*(y2-y1) - (y3-y1)*(x2-x1)) / denominator)
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, 0, false
+	}
+	return t, u, true
+}
+
+// segmentIntersectionEpsilon is the tolerance within which fractions along
+// line segments are snapped to their ends.
+const segmentIntersectionEpsilon = 1e-12
+
+// snapFraction returns f snapped to 0 or 1 if it is within
+// segmentIntersectionEpsilon of them.
+func snapFraction(f float64) float64 {
+	switch {
+	case math.Abs(f) < segmentIntersectionEpsilon:
+		return 0
+	case math.Abs(f-1) < segmentIntersectionEpsilon:
+		return 1
+	default:
+		return f
+	}
+}
@@ -0,0 +1,149 @@
+package gpx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestLoop(t *testing.T) {
+	square := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.01},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0.01, Lon: 0},
+			{Lat: 0.00001, Lon: 0},
+		},
+	}
+	assert.InDelta(t, gpx.HaversineDistance(0, 0, 0.00001, 0), square.ClosureDistance(), 1e-9)
+	assert.True(t, square.IsLoop(10))
+	assert.False(t, square.IsLoop(1))
+	assert.Empty(t, square.SelfIntersections())
+
+	outAndBack := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.01},
+		},
+	}
+	assert.False(t, outAndBack.IsLoop(10))
+
+	stationary := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.00001},
+			{Lat: 0, Lon: 0},
+		},
+	}
+	assert.False(t, stationary.IsLoop(10))
+
+	assert.Equal(t, 0.0, (&gpx.TrkSegType{}).ClosureDistance())
+	assert.False(t, (&gpx.TrkSegType{}).IsLoop(10))
+}
+
+func TestSelfIntersections(t *testing.T) {
+	// An open figure of eight crosses itself once.
+	figureOfEight := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0.01, Lon: 0},
+			{Lat: 0, Lon: 0.01},
+		},
+	}
+	got := figureOfEight.SelfIntersections()
+	assert.Len(t, got, 1)
+	assert.InDelta(t, 0.005, got[0].Lat, 1e-6)
+	assert.InDelta(t, 0.005, got[0].Lon, 1e-6)
+	assert.Equal(t, 0, got[0].I)
+	assert.Equal(t, 2, got[0].J)
+
+	// A figure of eight snapped to road nodes crosses itself once at a shared
+	// node, even if the node is repeated.
+	for i, trkPts := range [][]*gpx.WptType{
+		{
+			{Lat: 0, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0.01, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0, Lon: 0.01},
+		},
+		{
+			{Lat: 0, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0.01, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0, Lon: 0.01},
+		},
+	} {
+		got := (&gpx.TrkSegType{TrkPt: trkPts}).SelfIntersections()
+		assert.Equal(t, []gpx.Intersection{
+			{Lat: 0.005, Lon: 0.005, I: 1, J: len(trkPts) - 2},
+		}, got, i)
+	}
+
+	// A node on the interior of a line segment is a crossing.
+	got = (&gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0.01, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0, Lon: 0.01},
+		},
+	}).SelfIntersections()
+	assert.Len(t, got, 1)
+	assert.InDelta(t, 0.005, got[0].Lat, 1e-12)
+	assert.InDelta(t, 0.005, got[0].Lon, 1e-12)
+	assert.Equal(t, 0, got[0].I)
+	assert.Equal(t, 3, got[0].J)
+
+	// Returning to a node from the same side only touches.
+	touching := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0.01, Lon: 0},
+			{Lat: 0.005, Lon: 0.005},
+			{Lat: 0.008, Lon: 0},
+		},
+	}
+	assert.Empty(t, touching.SelfIntersections())
+
+	// A closed loop only touches its start, which is not an intersection.
+	closed := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.01},
+			{Lat: 0.01, Lon: 0.01},
+			{Lat: 0, Lon: 0},
+		},
+	}
+	assert.Empty(t, closed.SelfIntersections())
+
+	// Neither are repeated points, which make zero-length line segments.
+	repeated := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.01},
+			{Lat: 0, Lon: 0.01},
+			{Lat: 0.01, Lon: 0.01},
+		},
+	}
+	assert.Empty(t, repeated.SelfIntersections())
+
+	line := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 1},
+		},
+	}
+	assert.Nil(t, line.SelfIntersections())
+}
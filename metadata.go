@@ -0,0 +1,312 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// gpx10Metadata contains the top-level elements of a GPX 1.0 document that
+// are grouped into a metadata element in GPX 1.1.
+type gpx10Metadata struct {
+	Name     string      `xml:"name"`
+	Desc     string      `xml:"desc"`
+	Author   string      `xml:"author"`
+	Email    string      `xml:"email"`
+	URL      string      `xml:"url"`
+	URLName  string      `xml:"urlname"`
+	Time     string      `xml:"time"`
+	Keywords string      `xml:"keywords"`
+	Bounds   *BoundsType `xml:"bounds"`
+}
+
+// A CreativeCommonsLicense is a Creative Commons license.
+type CreativeCommonsLicense string
+
+// Creative Commons licenses.
+const (
+	CCBy     CreativeCommonsLicense = "by"
+	CCBySA   CreativeCommonsLicense = "by-sa"
+	CCByND   CreativeCommonsLicense = "by-nd"
+	CCByNC   CreativeCommonsLicense = "by-nc"
+	CCByNCSA CreativeCommonsLicense = "by-nc-sa"
+	CCByNCND CreativeCommonsLicense = "by-nc-nd"
+	CC0      CreativeCommonsLicense = "zero"
+)
+
+// URL returns the URL of version 4.0 of l, or of version 1.0 for CC0.
+func (l CreativeCommonsLicense) URL() string {
+	if l == CC0 {
+		return "https://creativecommons.org/publicdomain/zero/1.0/"
+	}
+	return "https://creativecommons.org/licenses/" + string(l) + "/4.0/"
+}
+
+// NewMetadataType returns a new, empty MetadataType.
+func NewMetadataType() *MetadataType {
+	return &MetadataType{}
+}
+
+// WithName sets m's name and returns m.
+func (m *MetadataType) WithName(name string) *MetadataType {
+	m.Name = name
+	return m
+}
+
+// WithDesc sets m's description and returns m.
+func (m *MetadataType) WithDesc(desc string) *MetadataType {
+	m.Desc = desc
+	return m
+}
+
+// WithAuthor sets m's author and returns m.
+func (m *MetadataType) WithAuthor(author *PersonType) *MetadataType {
+	m.Author = author
+	return m
+}
+
+// WithCopyright sets m's copyright and returns m.
+func (m *MetadataType) WithCopyright(author string, year int, license string) *MetadataType {
+	m.Copyright = &CopyrightType{
+		Author:  author,
+		Year:    year,
+		License: license,
+	}
+	return m
+}
+
+// WithCreativeCommons sets m's copyright to the Creative Commons license and
+// returns m.
+func (m *MetadataType) WithCreativeCommons(author string, year int, license CreativeCommonsLicense) *MetadataType {
+	return m.WithCopyright(author, year, license.URL())
+}
+
+// WithLink adds a link to m and returns m.
+func (m *MetadataType) WithLink(href, text string) *MetadataType {
+	m.Link = append(m.Link, &LinkType{
+		HREF: href,
+		Text: text,
+	})
+	return m
+}
+
+// WithTime sets m's time and returns m.
+func (m *MetadataType) WithTime(t time.Time) *MetadataType {
+	m.Time = t
+	return m
+}
+
+// WithKeywords sets m's keywords and returns m.
+func (m *MetadataType) WithKeywords(keywords ...string) *MetadataType {
+	m.Keywords = strings.Join(keywords, ", ")
+	return m
+}
+
+// WithBounds sets m's bounds and returns m.
+func (m *MetadataType) WithBounds(minLat, minLon, maxLat, maxLon float64) *MetadataType {
+	m.Bounds = &BoundsType{
+		MinLat: minLat,
+		MinLon: minLon,
+		MaxLat: maxLat,
+		MaxLon: maxLon,
+	}
+	return m
+}
+
+// NewPersonType returns a new PersonType with the given name.
+func NewPersonType(name string) *PersonType {
+	return &PersonType{
+		Name: name,
+	}
+}
+
+// WithEmail sets p's email address and returns p. Use NewEmailType or
+// ParseEmail to create a validated email.
+func (p *PersonType) WithEmail(email *EmailType) *PersonType {
+	p.Email = email
+	return p
+}
+
+// WithLink sets p's link and returns p.
+func (p *PersonType) WithLink(href, text string) *PersonType {
+	p.Link = &LinkType{
+		HREF: href,
+		Text: text,
+	}
+	return p
+}
+
+// SetEmail sets p's email address from address, in the form id@domain. If
+// address is empty then p's email address is removed.
+func (p *PersonType) SetEmail(address string) error {
+	if address == "" {
+		p.Email = nil
+		return nil
+	}
+	email, err := ParseEmail(address)
+	if err != nil {
+		return err
+	}
+	p.Email = email
+	return nil
+}
+
+// EmailAddress returns p's email address in the form id@domain, or the empty
+// string if p has no email address.
+func (p *PersonType) EmailAddress() string {
+	if p.Email == nil {
+		return ""
+	}
+	return p.Email.String()
+}
+
+// NewEmailType returns a new EmailType for the address id@domain. It returns
+// an error if the address is invalid.
+func NewEmailType(id, domain string) (*EmailType, error) {
+	email := &EmailType{
+		Name:   id,
+		Domain: domain,
+	}
+	if err := email.Validate(); err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+// ParseEmail parses address, in the form id@domain, into an EmailType. The
+// address is split at its last @, as ids may contain quoted @ characters.
+func ParseEmail(address string) (*EmailType, error) {
+	id, domain := splitEmail(address)
+	return NewEmailType(id, domain)
+}
+
+// String returns e's address in the form id@domain.
+func (e *EmailType) String() string {
+	if e.Name == "" && e.Domain == "" {
+		return ""
+	}
+	return e.Name + "@" + e.Domain
+}
+
+// Validate returns an error if e's id or domain is empty, or contains
+// whitespace, or if its domain contains an @ or is not made of non-empty
+// dot-separated labels.
+func (e *EmailType) Validate() error {
+	switch {
+	case e.Name == "":
+		return fmt.Errorf("%s: missing email id", e)
+	case e.Domain == "":
+		return fmt.Errorf("%s: missing email domain", e)
+	case strings.ContainsFunc(e.Name+e.Domain, unicode.IsSpace):
+		return fmt.Errorf("%q: invalid whitespace in email address", e.String())
+	case strings.Contains(e.Domain, "@"):
+		return fmt.Errorf("%s: invalid email domain", e)
+	}
+	for _, label := range strings.Split(e.Domain, ".") {
+		if label == "" {
+			return fmt.Errorf("%s: invalid email domain", e)
+		}
+	}
+	return nil
+}
+
+// splitEmail splits address at its last @ into an id and a domain.
+func splitEmail(address string) (string, string) {
+	i := strings.LastIndexByte(address, '@')
+	if i == -1 {
+		return address, ""
+	}
+	return address[:i], address[i+1:]
+}
+
+// SetMetadataTime sets the time in g's metadata, creating the metadata if
+// needed.
+func (g *GPX) SetMetadataTime(t time.Time) {
+	g.metadata().Time = t
+}
+
+// metadataType returns the MetadataType equivalent of m10, or nil if m10 is
+// empty.
+func (m10 *gpx10Metadata) metadataType() (*MetadataType, error) {
+	if *m10 == (gpx10Metadata{}) {
+		return nil, nil //nolint:nilnil
+	}
+	m := &MetadataType{
+		Name:     m10.Name,
+		Desc:     m10.Desc,
+		Keywords: m10.Keywords,
+		Bounds:   m10.Bounds,
+	}
+	if m10.Author != "" || m10.Email != "" {
+		m.Author = &PersonType{
+			Name: m10.Author,
+		}
+		if m10.Email != "" {
+			id, domain := splitEmail(m10.Email)
+			m.Author.Email = &EmailType{
+				Name:   id,
+				Domain: domain,
+			}
+		}
+	}
+	if m10.URL != "" {
+		m.Link = []*LinkType{
+			{
+				HREF: m10.URL,
+				Text: m10.URLName,
+			},
+		}
+	}
+	if m10.Time != "" {
+		t, err := time.ParseInLocation(timeLayout, m10.Time, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		m.Time = t
+	}
+	return m, nil
+}
+
+// marshalGPX10 encodes m as GPX 1.0 top-level elements.
+func (m *MetadataType) marshalGPX10(e *xml.Encoder) error {
+	if err := maybeEmitStringElement(e, "name", m.Name); err != nil {
+		return err
+	}
+	if err := maybeEmitStringElement(e, "desc", m.Desc); err != nil {
+		return err
+	}
+	if m.Author != nil {
+		if err := maybeEmitStringElement(e, "author", m.Author.Name); err != nil {
+			return err
+		}
+		if m.Author.Email != nil {
+			if err := emitStringElement(e, "email", m.Author.Email.String()); err != nil {
+				return err
+			}
+		}
+	}
+	if len(m.Link) > 0 {
+		if err := maybeEmitStringElement(e, "url", m.Link[0].HREF); err != nil {
+			return err
+		}
+		if err := maybeEmitStringElement(e, "urlname", m.Link[0].Text); err != nil {
+			return err
+		}
+	}
+	if !m.Time.IsZero() {
+		if err := emitStringElement(e, "time", m.Time.UTC().Format(timeLayout)); err != nil {
+			return err
+		}
+	}
+	if err := maybeEmitStringElement(e, "keywords", m.Keywords); err != nil {
+		return err
+	}
+	if m.Bounds != nil {
+		if err := e.EncodeElement(m.Bounds, xml.StartElement{Name: xml.Name{Local: "bounds"}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
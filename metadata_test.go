@@ -0,0 +1,190 @@
+package gpx_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestMetadataTypeBuilder(t *testing.T) {
+	email, err := gpx.NewEmailType("id", "example.com")
+	assert.NoError(t, err)
+	m := gpx.NewMetadataType().
+		WithName("name").
+		WithDesc("desc").
+		WithAuthor(gpx.NewPersonType("author").WithEmail(email).WithLink("https://example.com/", "example")).
+		WithCopyright("author", 2023, "https://creativecommons.org/licenses/by/4.0/").
+		WithLink("https://example.com/link", "link").
+		WithTime(time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)).
+		WithKeywords("a", "b").
+		WithBounds(1, 2, 3, 4)
+	assert.Equal(t, &gpx.MetadataType{
+		Name: "name",
+		Desc: "desc",
+		Author: &gpx.PersonType{
+			Name: "author",
+			Email: &gpx.EmailType{
+				Name:   "id",
+				Domain: "example.com",
+			},
+			Link: &gpx.LinkType{
+				HREF: "https://example.com/",
+				Text: "example",
+			},
+		},
+		Copyright: &gpx.CopyrightType{
+			Author:  "author",
+			Year:    2023,
+			License: "https://creativecommons.org/licenses/by/4.0/",
+		},
+		Link: []*gpx.LinkType{
+			{
+				HREF: "https://example.com/link",
+				Text: "link",
+			},
+		},
+		Time:     time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		Keywords: "a, b",
+		Bounds: &gpx.BoundsType{
+			MinLat: 1,
+			MinLon: 2,
+			MaxLat: 3,
+			MaxLon: 4,
+		},
+	}, m)
+}
+
+func TestMetadataTypeWithCreativeCommons(t *testing.T) {
+	for i, tc := range []struct {
+		license  gpx.CreativeCommonsLicense
+		expected string
+	}{
+		{license: gpx.CCBy, expected: "https://creativecommons.org/licenses/by/4.0/"},
+		{license: gpx.CCByNCSA, expected: "https://creativecommons.org/licenses/by-nc-sa/4.0/"},
+		{license: gpx.CC0, expected: "https://creativecommons.org/publicdomain/zero/1.0/"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			m := gpx.NewMetadataType().WithCreativeCommons("author", 2024, tc.license)
+			assert.Equal(t, &gpx.CopyrightType{
+				Author:  "author",
+				Year:    2024,
+				License: tc.expected,
+			}, m.Copyright)
+			assert.NoError(t, m.Copyright.Validate())
+		})
+	}
+}
+
+func TestSetMetadataTime(t *testing.T) {
+	g := &gpx.GPX{}
+	tm := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	g.SetMetadataTime(tm)
+	assert.Equal(t, &gpx.MetadataType{Time: tm}, g.Metadata)
+}
+
+func TestGPX10Metadata(t *testing.T) {
+	data := "<gpx" +
+		" version=\"1.0\"" +
+		" creator=\"ExpertGPS 1.1 - http://www.topografix.com\"" +
+		" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\"" +
+		" xmlns=\"http://www.topografix.com/GPX/1/0\"" +
+		" xsi:schemaLocation=\"http://www.topografix.com/GPX/1/0 http://www.topografix.com/GPX/1/0/gpx.xsd\">\n" +
+		"\t<name>Rockbuster Duathlon</name>\n" +
+		"\t<desc>Team TopoGrafix tracklogs</desc>\n" +
+		"\t<author>Vil and Dan</author>\n" +
+		"\t<email>trails@topografix.com</email>\n" +
+		"\t<url>http://www.topografix.com/team/photos.asp</url>\n" +
+		"\t<urlname>Team TopoGrafix Pics</urlname>\n" +
+		"\t<time>2002-04-23T15:35:23Z</time>\n" +
+		"\t<keywords>mountain biking, racing</keywords>\n" +
+		"\t<bounds minlat=\"42.223808\" minlon=\"-71.493169\" maxlat=\"42.26109\" maxlon=\"-71.4578\"></bounds>\n" +
+		"</gpx>"
+	expected := &gpx.GPX{
+		Version: "1.0",
+		Creator: "ExpertGPS 1.1 - http://www.topografix.com",
+		Metadata: &gpx.MetadataType{
+			Name: "Rockbuster Duathlon",
+			Desc: "Team TopoGrafix tracklogs",
+			Author: &gpx.PersonType{
+				Name: "Vil and Dan",
+				Email: &gpx.EmailType{
+					Name:   "trails",
+					Domain: "topografix.com",
+				},
+			},
+			Link: []*gpx.LinkType{
+				{
+					HREF: "http://www.topografix.com/team/photos.asp",
+					Text: "Team TopoGrafix Pics",
+				},
+			},
+			Time:     time.Date(2002, 4, 23, 15, 35, 23, 0, time.UTC),
+			Keywords: "mountain biking, racing",
+			Bounds: &gpx.BoundsType{
+				MinLat: 42.223808,
+				MinLon: -71.493169,
+				MaxLat: 42.26109,
+				MaxLon: -71.4578,
+			},
+		},
+	}
+	got, err := gpx.Read(bytes.NewBufferString(data))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	sb := &strings.Builder{}
+	assert.NoError(t, expected.WriteIndent(sb, "", "\t"))
+	assert.Equal(t, strings.Split(xml.Header+data, "\n"), strings.Split(sb.String(), "\n"))
+}
+
+func TestParseEmail(t *testing.T) {
+	for i, tc := range []struct {
+		address     string
+		expected    *gpx.EmailType
+		expectedErr bool
+	}{
+		{address: "alice@example.com", expected: &gpx.EmailType{Name: "alice", Domain: "example.com"}},
+		{address: `"a@b"@example.com`, expected: &gpx.EmailType{Name: `"a@b"`, Domain: "example.com"}},
+		{address: "alice+gpx@mail.example.co.uk", expected: &gpx.EmailType{Name: "alice+gpx", Domain: "mail.example.co.uk"}},
+		{address: "alice", expectedErr: true},
+		{address: "@example.com", expectedErr: true},
+		{address: "alice@", expectedErr: true},
+		{address: "alice smith@example.com", expectedErr: true},
+		{address: "alice@example..com", expectedErr: true},
+		{address: "alice@.com", expectedErr: true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := gpx.ParseEmail(tc.address)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+			assert.Equal(t, tc.address, got.String())
+		})
+	}
+
+	_, err := gpx.NewEmailType("alice", "example@com")
+	assert.Error(t, err)
+	assert.Equal(t, "", (&gpx.EmailType{}).String())
+}
+
+func TestPersonTypeSetEmail(t *testing.T) {
+	p := gpx.NewPersonType("Alice")
+	assert.Equal(t, "", p.EmailAddress())
+	assert.NoError(t, p.SetEmail("alice@example.com"))
+	assert.Equal(t, &gpx.EmailType{Name: "alice", Domain: "example.com"}, p.Email)
+	assert.Equal(t, "alice@example.com", p.EmailAddress())
+	assert.Error(t, p.SetEmail("not an address"))
+	assert.Equal(t, "alice@example.com", p.EmailAddress())
+	assert.NoError(t, p.SetEmail(""))
+	assert.Nil(t, p.Email)
+}
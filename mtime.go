@@ -0,0 +1,81 @@
+package gpx
+
+import (
+	"math"
+	"time"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+// An MTimeConverter converts between M coordinates, in seconds, and times. The
+// zero value converts using seconds since the Unix epoch without rounding.
+type MTimeConverter struct {
+	// Epoch is the time corresponding to an M coordinate of zero. If zero, the
+	// Unix epoch is used.
+	Epoch time.Time
+	// Precision is the duration to which times are rounded, absorbing small
+	// perturbations introduced by external geometry processing. If zero,
+	// times are not rounded.
+	Precision time.Duration
+}
+
+// MToTime converts m to a time.
+func (c MTimeConverter) MToTime(m float64) time.Time {
+	if m == 0 && c.Epoch.IsZero() {
+		return time.Unix(0, 0)
+	}
+	sec, frac := math.Modf(m)
+	nsec := math.Round(frac * float64(time.Second))
+	var t time.Time
+	if c.Epoch.IsZero() {
+		t = time.Unix(int64(sec), int64(nsec))
+	} else {
+		t = c.Epoch.Add(time.Duration(sec)*time.Second + time.Duration(nsec))
+	}
+	return t.Round(c.Precision).UTC()
+}
+
+// TimeToM converts t to an M coordinate.
+func (c MTimeConverter) TimeToM(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	if c.Epoch.IsZero() {
+		return float64(t.UnixNano()) / float64(time.Second)
+	}
+	return t.Sub(c.Epoch).Seconds()
+}
+
+// NewRteType returns a new RteType with geometry g, converting M coordinates
+// with c.
+func (c MTimeConverter) NewRteType(g *geom.LineString) *RteType {
+	return &RteType{
+		RtePt: newWptTypes(g, c.MToTime),
+	}
+}
+
+// NewTrkType returns a new TrkType with geometry g, converting M coordinates
+// with c.
+func (c MTimeConverter) NewTrkType(g *geom.MultiLineString) *TrkType {
+	trkSegs := make([]*TrkSegType, g.NumLineStrings())
+	for i := range trkSegs {
+		trkSegs[i] = c.NewTrkSegType(g.LineString(i))
+	}
+	return &TrkType{
+		TrkSeg: trkSegs,
+	}
+}
+
+// NewTrkSegType returns a new TrkSegType with geometry g, converting M
+// coordinates with c.
+func (c MTimeConverter) NewTrkSegType(g *geom.LineString) *TrkSegType {
+	return &TrkSegType{
+		TrkPt: newWptTypes(g, c.MToTime),
+	}
+}
+
+// NewWptType returns a new WptType with geometry g, converting M coordinates
+// with c. It returns nil if g is empty.
+func (c MTimeConverter) NewWptType(g *geom.Point) *WptType {
+	return newWptType(g, c.MToTime)
+}
@@ -0,0 +1,85 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	geom "github.com/twpayne/go-geom"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestMTimeConverter(t *testing.T) {
+	epoch := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	for i, tc := range []struct {
+		c        gpx.MTimeConverter
+		m        float64
+		expected time.Time
+	}{
+		{
+			m:        1136214245.5,
+			expected: time.Date(2006, 1, 2, 15, 4, 5, 500000000, time.UTC),
+		},
+		{
+			c: gpx.MTimeConverter{
+				Precision: time.Second,
+			},
+			m:        1136214244.9999997,
+			expected: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			c: gpx.MTimeConverter{
+				Precision: time.Millisecond,
+			},
+			m:        1136214245.1230001,
+			expected: time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC),
+		},
+		{
+			c: gpx.MTimeConverter{
+				Epoch: epoch,
+			},
+			m:        0,
+			expected: epoch,
+		},
+		{
+			c: gpx.MTimeConverter{
+				Epoch:     epoch,
+				Precision: time.Second,
+			},
+			m:        90.0000001,
+			expected: epoch.Add(90 * time.Second),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.c.MToTime(tc.m))
+		})
+	}
+}
+
+func TestMTimeConverterNewTrkType(t *testing.T) {
+	epoch := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	c := gpx.MTimeConverter{
+		Epoch:     epoch,
+		Precision: time.Second,
+	}
+	g := geom.NewMultiLineString(geom.XYM).MustSetCoords([][]geom.Coord{
+		{
+			{1, 2, 0.0000002},
+			{3, 4, 9.9999999},
+		},
+	})
+	trk := c.NewTrkType(g)
+	assert.Equal(t, &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 2, Lon: 1, Time: epoch},
+					{Lat: 4, Lon: 3, Time: epoch.Add(10 * time.Second)},
+				},
+			},
+		},
+	}, trk)
+	assert.Equal(t, 10.0, c.TimeToM(trk.TrkSeg[0].TrkPt[1].Time))
+}
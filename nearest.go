@@ -0,0 +1,133 @@
+package gpx
+
+import "math"
+
+// A PointMatch is the result of a nearest point query.
+type PointMatch struct {
+	// Wpt is the matched point.
+	Wpt *WptType
+	// Distance is the distance in meters to the matched point.
+	Distance float64
+	// Trk is the index of the track containing the matched point, for track
+	// points matched by an Index.
+	Trk int
+	// TrkSeg is the index of the track segment containing the matched point,
+	// for track points.
+	TrkSeg int
+	// Index is the index of the matched point. For snapped points, it is the
+	// index of the point at the start of the matched line segment.
+	Index int
+}
+
+// NearestWpt returns the waypoint in g nearest to lat and lon, or nil if g has
+// no waypoints. It considers every waypoint, so for repeated queries use
+// Index.NearestWpt instead.
+func (g *GPX) NearestWpt(lat, lon float64) *PointMatch {
+	index, distance := nearest(g.Wpt, lat, lon)
+	if index < 0 {
+		return nil
+	}
+	return &PointMatch{
+		Wpt:      g.Wpt[index],
+		Distance: distance,
+		Index:    index,
+	}
+}
+
+// NearestPoint returns the track point in t nearest to lat and lon, or nil if t
+// has no track points. It considers every track point, so for repeated queries
+// use Index.NearestTrkPt instead.
+func (t *TrkType) NearestPoint(lat, lon float64) *PointMatch {
+	var result *PointMatch
+	for i, ts := range t.TrkSeg {
+		index, distance := nearest(ts.TrkPt, lat, lon)
+		if index >= 0 && (result == nil || distance < result.Distance) {
+			result = &PointMatch{
+				Wpt:      ts.TrkPt[index],
+				Distance: distance,
+				TrkSeg:   i,
+				Index:    index,
+			}
+		}
+	}
+	return result
+}
+
+// SnapPoint returns a new point on t nearest to lat and lon, interpolated
+// between consecutive track points, or nil if t has no track points. It
+// considers every line segment, so for repeated queries use Index.SnapTrkPt
+// instead.
+func (t *TrkType) SnapPoint(lat, lon float64) *PointMatch {
+	var result *PointMatch
+	for i, ts := range t.TrkSeg {
+		for j := range max(len(ts.TrkPt)-1, min(len(ts.TrkPt), 1)) {
+			wpt, distance := snap(ts.TrkPt, j, lat, lon)
+			if result == nil || distance < result.Distance {
+				result = &PointMatch{
+					Wpt:      wpt,
+					Distance: distance,
+					TrkSeg:   i,
+					Index:    j,
+				}
+			}
+		}
+	}
+	return result
+}
+
+// before returns whether m is nearer than other or, if they are equally near,
+// whether m comes first in document order.
+func (m *PointMatch) before(other *PointMatch) bool {
+	switch {
+	case m.Distance != other.Distance:
+		return m.Distance < other.Distance
+	case m.Trk != other.Trk:
+		return m.Trk < other.Trk
+	case m.TrkSeg != other.TrkSeg:
+		return m.TrkSeg < other.TrkSeg
+	default:
+		return m.Index < other.Index
+	}
+}
+
+// nearest returns the index of and distance to the point in wpts nearest to
+// lat and lon, or -1 if wpts is empty.
+func nearest(wpts []*WptType, lat, lon float64) (int, float64) {
+	index := -1
+	minDistance := math.Inf(1)
+	for i, wpt := range wpts {
+		if distance := HaversineDistance(lat, lon, wpt.Lat, wpt.Lon); distance < minDistance {
+			index, minDistance = i, distance
+		}
+	}
+	return index, minDistance
+}
+
+// snap returns a new point on the line segment from trkPts[j] to trkPts[j+1]
+// nearest to lat and lon, and its distance in meters. If trkPts[j] is the last
+// point then it returns a copy of it.
+func snap(trkPts []*WptType, j int, lat, lon float64) (*WptType, float64) {
+	var wpt *WptType
+	if a := trkPts[j]; j+1 < len(trkPts) {
+		wpt = interpolate(a, trkPts[j+1], segmentFraction(a, trkPts[j+1], lat, lon))
+	} else {
+		wptCopy := *a
+		wpt = &wptCopy
+	}
+	return wpt, HaversineDistance(lat, lon, wpt.Lat, wpt.Lon)
+}
+
+// segmentFraction returns the fraction of the way from a to b of the point on
+// the line segment ab nearest to lat and lon, using a local equirectangular
+// projection.
+func segmentFraction(a, b *WptType, lat, lon float64) float64 {
+	cosLat := math.Cos(lat * math.Pi / 180)
+	ax, ay := normalizeLon(a.Lon-lon)*cosLat, a.Lat-lat
+	bx, by := normalizeLon(b.Lon-lon)*cosLat, b.Lat-lat
+	dx, dy := bx-ax, by-ay
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSquared))
+}
@@ -0,0 +1,65 @@
+package gpx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestNearestWpt(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 46, Lon: 7, Name: "a"},
+			{Lat: 46.01, Lon: 7, Name: "b"},
+		},
+	}
+	got := g.NearestWpt(46.008, 7)
+	assert.Equal(t, "b", got.Wpt.Name)
+	assert.Equal(t, 1, got.Index)
+	assert.InDelta(t, gpx.HaversineDistance(46.008, 7, 46.01, 7), got.Distance, 1e-9)
+
+	assert.Nil(t, (&gpx.GPX{}).NearestWpt(0, 0))
+}
+
+func TestNearestPoint(t *testing.T) {
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 46, Lon: 7},
+					{Lat: 46, Lon: 7.01},
+				},
+			},
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 46.01, Lon: 7},
+					{Lat: 46.01, Lon: 7.01},
+				},
+			},
+		},
+	}
+
+	got := trk.NearestPoint(46.009, 7.004)
+	assert.Same(t, trk.TrkSeg[1].TrkPt[0], got.Wpt)
+	assert.Equal(t, 1, got.TrkSeg)
+	assert.Equal(t, 0, got.Index)
+
+	got = trk.SnapPoint(46.009, 7.004)
+	assert.Equal(t, 1, got.TrkSeg)
+	assert.Equal(t, 0, got.Index)
+	assert.InDelta(t, 46.01, got.Wpt.Lat, 1e-9)
+	assert.InDelta(t, 7.004, got.Wpt.Lon, 1e-6)
+	assert.InDelta(t, gpx.HaversineDistance(46.009, 7.004, 46.01, 7.004), got.Distance, 0.01)
+
+	// Positions beyond the start of the track snap to the first point.
+	got = trk.SnapPoint(46, 6.99)
+	assert.Equal(t, &gpx.WptType{Lat: 46, Lon: 7}, got.Wpt)
+
+	single := &gpx.TrkType{TrkSeg: []*gpx.TrkSegType{{TrkPt: []*gpx.WptType{{Lat: 1, Lon: 2}}}}}
+	assert.Equal(t, &gpx.WptType{Lat: 1, Lon: 2}, single.SnapPoint(0, 0).Wpt)
+
+	assert.Nil(t, (&gpx.TrkType{}).NearestPoint(0, 0))
+	assert.Nil(t, (&gpx.TrkType{}).SnapPoint(0, 0))
+}
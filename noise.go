@@ -0,0 +1,70 @@
+package gpx
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// A NoiseModel describes GPS errors to apply to a track segment. All distances
+// are in meters.
+type NoiseModel struct {
+	// Sigma is the standard deviation of independent Gaussian noise added to
+	// each point.
+	Sigma float64
+	// RandomWalkSigma is the standard deviation of each step of a random walk
+	// that accumulates along the segment.
+	RandomWalkSigma float64
+	// UrbanCanyonBias is the magnitude of the systematic offset, in a random
+	// direction, applied while in an urban canyon.
+	UrbanCanyonBias float64
+	// UrbanCanyonProbability is the probability that an urban canyon starts or
+	// ends at each point.
+	UrbanCanyonProbability float64
+	// DropoutProbability is the probability that each point is dropped.
+	DropoutProbability float64
+	// Rand is the source of randomness. If nil, a source seeded from the
+	// current time is used.
+	Rand *rand.Rand
+}
+
+// AddNoise returns a copy of ts with the errors described by model applied.
+// ts is not modified.
+func AddNoise(ts *TrkSegType, model NoiseModel) *TrkSegType {
+	r := model.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	}
+	var walkNorth, walkEast float64
+	var biasNorth, biasEast float64
+	inUrbanCanyon := false
+	trkPts := make([]*WptType, 0, len(ts.TrkPt))
+	for _, tp := range ts.TrkPt {
+		walkNorth += model.RandomWalkSigma * r.NormFloat64()
+		walkEast += model.RandomWalkSigma * r.NormFloat64()
+		if model.UrbanCanyonProbability > 0 && r.Float64() < model.UrbanCanyonProbability {
+			inUrbanCanyon = !inUrbanCanyon
+			if inUrbanCanyon {
+				theta := 2 * math.Pi * r.Float64()
+				biasNorth = model.UrbanCanyonBias * math.Cos(theta)
+				biasEast = model.UrbanCanyonBias * math.Sin(theta)
+			}
+		}
+		if model.DropoutProbability > 0 && r.Float64() < model.DropoutProbability {
+			continue
+		}
+		north := walkNorth + model.Sigma*r.NormFloat64()
+		east := walkEast + model.Sigma*r.NormFloat64()
+		if inUrbanCanyon {
+			north += biasNorth
+			east += biasEast
+		}
+		noisyTrkPt := *tp
+		noisyTrkPt.Lat, noisyTrkPt.Lon = offset(tp.Lat, tp.Lon, north, east)
+		trkPts = append(trkPts, &noisyTrkPt)
+	}
+	return &TrkSegType{
+		TrkPt:      trkPts,
+		Extensions: ts.Extensions,
+	}
+}
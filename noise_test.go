@@ -0,0 +1,72 @@
+package gpx_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestAddNoise(t *testing.T) {
+	ts := &gpx.TrkSegType{}
+	for i := 0; i < 100; i++ {
+		ts.TrkPt = append(ts.TrkPt, &gpx.WptType{
+			Lat: 46,
+			Lon: 7 + float64(i)*1e-4,
+		})
+	}
+
+	got := gpx.AddNoise(ts, gpx.NoiseModel{})
+	assert.Equal(t, ts, got)
+
+	got = gpx.AddNoise(ts, gpx.NoiseModel{
+		Sigma: 5,
+		Rand:  rand.New(rand.NewSource(1)), //nolint:gosec
+	})
+	assert.Len(t, got.TrkPt, len(ts.TrkPt))
+	for i, tp := range got.TrkPt {
+		assert.NotEqual(t, ts.TrkPt[i], tp)
+		assert.InDelta(t, ts.TrkPt[i].Lat, tp.Lat, 1e-3)
+		assert.InDelta(t, ts.TrkPt[i].Lon, tp.Lon, 1e-3)
+	}
+	assert.Equal(t, 46.0, ts.TrkPt[0].Lat)
+
+	got = gpx.AddNoise(ts, gpx.NoiseModel{
+		DropoutProbability: 0.5,
+		Rand:               rand.New(rand.NewSource(1)), //nolint:gosec
+	})
+	assert.Less(t, len(got.TrkPt), len(ts.TrkPt))
+	assert.Greater(t, len(got.TrkPt), 0)
+}
+
+func TestAddNoiseBounds(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 89.9999, Lon: 179.9999},
+			{Lat: -89.9999, Lon: -179.9999},
+			{Lat: 0, Lon: 179.9999},
+			{Lat: 90, Lon: 0},
+		},
+	}
+	for seed := range int64(16) {
+		got := gpx.AddNoise(ts, gpx.NoiseModel{
+			Sigma: 1000,
+			Rand:  rand.New(rand.NewSource(seed)), //nolint:gosec
+		})
+		for _, tp := range got.TrkPt {
+			assert.NoError(t, gpx.LatitudeType(tp.Lat).Validate())
+			assert.NoError(t, gpx.LongitudeType(tp.Lon).Validate())
+		}
+		g := &gpx.GPX{
+			Version: "1.1",
+			Trk:     []*gpx.TrkType{{TrkSeg: []*gpx.TrkSegType{got}}},
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, g.Write(&buf))
+		_, err := gpx.Read(&buf)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,76 @@
+package gpx
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ObfuscateOptions control GPX.Obfuscate. All distances are in meters.
+type ObfuscateOptions struct {
+	// MaxOffset is the maximum distance of a single random offset, uniformly
+	// distributed over a disk, applied to every position. Because every
+	// position is moved by the same offset the shape of the document is
+	// preserved, but the true positions are only known to within MaxOffset.
+	MaxOffset float64
+	// GridSize is the size of the grid to which positions are snapped. Each
+	// position is moved to the center of its grid cell, by at most
+	// GridSize/√2. Nearby positions become indistinguishable.
+	GridSize float64
+	// Func, if not nil, is called with each position after the offset and
+	// snapping are applied and returns the obfuscated position. It allows
+	// custom obfuscation schemes.
+	Func func(lat, lon float64) (float64, float64)
+	// Rand is the source of randomness. If nil, a source seeded from the
+	// current time is used.
+	Rand *rand.Rand
+}
+
+// Obfuscate modifies the positions of all waypoints, route points, and track
+// points in g according to options so that g can be shared without revealing
+// precise locations. Without Func, each position is moved by at most
+// options.MaxOffset + options.GridSize/√2. Metadata bounds are removed as they
+// would reveal the original positions.
+func (g *GPX) Obfuscate(options ObfuscateOptions) {
+	var offsetNorth, offsetEast float64
+	if options.MaxOffset > 0 {
+		r := options.Rand
+		if r == nil {
+			r = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+		}
+		distance := options.MaxOffset * math.Sqrt(r.Float64())
+		theta := 2 * math.Pi * r.Float64()
+		offsetNorth = distance * math.Cos(theta)
+		offsetEast = distance * math.Sin(theta)
+	}
+	for wpt := range g.Points() {
+		lat, lon := wpt.Lat, wpt.Lon
+		if options.MaxOffset > 0 {
+			lat, lon = offset(lat, lon, offsetNorth, offsetEast)
+		}
+		if options.GridSize > 0 {
+			lat, lon = snapToGrid(lat, lon, options.GridSize)
+		}
+		if options.Func != nil {
+			lat, lon = options.Func(lat, lon)
+		}
+		if lon < -180 || lon >= 180 {
+			lon = normalizeLon(lon)
+		}
+		wpt.Lat, wpt.Lon = lat, lon
+	}
+	if g.Metadata != nil {
+		g.Metadata.Bounds = nil
+	}
+}
+
+// snapToGrid returns the center of the cell of a grid with cells of size
+// gridSize meters containing lat and lon.
+func snapToGrid(lat, lon, gridSize float64) (float64, float64) {
+	latStep := gridSize / metersPerDegree
+	lat = (math.Floor(lat/latStep) + 0.5) * latStep
+	lat = math.Max(-90, math.Min(90, lat))
+	lonStep := latStep / math.Max(math.Cos(lat*math.Pi/180), 1e-9)
+	lon = (math.Floor(lon/lonStep) + 0.5) * lonStep
+	return lat, lon
+}
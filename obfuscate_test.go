@@ -0,0 +1,108 @@
+package gpx_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestObfuscate(t *testing.T) {
+	original := &gpx.GPX{
+		Metadata: &gpx.MetadataType{
+			Bounds: &gpx.BoundsType{MinLat: 46, MinLon: 7, MaxLat: 46.01, MaxLon: 7.01},
+		},
+		Wpt: []*gpx.WptType{{Lat: 46.005, Lon: 7.005}},
+		Rte: []*gpx.RteType{{RtePt: []*gpx.WptType{{Lat: 46, Lon: 7}}}},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					newTestTrkSeg(10, func(i int) *gpx.WptType {
+						return &gpx.WptType{Lat: 46 + float64(i)*1e-3, Lon: 7 + float64(i)*1e-3}
+					}),
+				},
+			},
+		},
+	}
+
+	// An offset moves all points by the same distance, preserving the shape.
+	g := original.Clone()
+	g.Obfuscate(gpx.ObfuscateOptions{
+		MaxOffset: 500,
+		Rand:      rand.New(rand.NewSource(1)), //nolint:gosec
+	})
+	assert.Nil(t, g.Metadata.Bounds)
+
+	var distances []float64
+	for i, trkPt := range g.Trk[0].TrkSeg[0].TrkPt {
+		originalTrkPt := original.Trk[0].TrkSeg[0].TrkPt[i]
+		distance := gpx.HaversineDistance(originalTrkPt.Lat, originalTrkPt.Lon, trkPt.Lat, trkPt.Lon)
+		assert.LessOrEqual(t, distance, 500.0)
+		distances = append(distances, distance)
+	}
+	for _, distance := range distances {
+		assert.InDelta(t, distances[0], distance, 0.1)
+	}
+	assert.NotZero(t, distances[0])
+	assert.InDelta(t, original.Trk[0].TrkSeg[0].Length(nil), g.Trk[0].TrkSeg[0].Length(nil), 0.1)
+	assert.NotEqual(t, original.Wpt[0], g.Wpt[0])
+	assert.NotEqual(t, original.Rte[0].RtePt[0], g.Rte[0].RtePt[0])
+
+	// A grid snaps nearby points to the same cell.
+	g = original.Clone()
+	g.Obfuscate(gpx.ObfuscateOptions{
+		GridSize: 1000,
+	})
+	for i, trkPt := range g.Trk[0].TrkSeg[0].TrkPt {
+		originalTrkPt := original.Trk[0].TrkSeg[0].TrkPt[i]
+		assert.LessOrEqual(t, gpx.HaversineDistance(originalTrkPt.Lat, originalTrkPt.Lon, trkPt.Lat, trkPt.Lon), 1000/1.414)
+	}
+	positions := make(map[[2]float64]bool)
+	for _, trkPt := range g.Trk[0].TrkSeg[0].TrkPt {
+		positions[[2]float64{trkPt.Lat, trkPt.Lon}] = true
+	}
+	assert.Less(t, len(positions), 5)
+
+	// Snapping is idempotent.
+	snapped := *g.Wpt[0]
+	g.Obfuscate(gpx.ObfuscateOptions{GridSize: 1000})
+	assert.InDelta(t, snapped.Lat, g.Wpt[0].Lat, 1e-12)
+	assert.InDelta(t, snapped.Lon, g.Wpt[0].Lon, 1e-12)
+
+	g = original.Clone()
+	g.Obfuscate(gpx.ObfuscateOptions{
+		Func: func(lat, lon float64) (float64, float64) {
+			return lat + 1, lon + 1
+		},
+	})
+	assert.InDelta(t, 47.005, g.Wpt[0].Lat, 1e-12)
+	assert.InDelta(t, 8.005, g.Wpt[0].Lon, 1e-12)
+}
+
+func TestObfuscateBounds(t *testing.T) {
+	for seed := range int64(16) {
+		g := &gpx.GPX{
+			Version: "1.1",
+			Wpt: []*gpx.WptType{
+				{Lat: 89.9999, Lon: 179.9999},
+				{Lat: -89.9999, Lon: -179.9999},
+				{Lat: 0, Lon: 179.9999},
+			},
+		}
+		g.Obfuscate(gpx.ObfuscateOptions{
+			MaxOffset: 1000,
+			Rand:      rand.New(rand.NewSource(seed)), //nolint:gosec
+		})
+		for _, wpt := range g.Wpt {
+			assert.NoError(t, gpx.LatitudeType(wpt.Lat).Validate())
+			assert.NoError(t, gpx.LongitudeType(wpt.Lon).Validate())
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, g.Write(&buf))
+		_, err := gpx.Read(&buf)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,139 @@
+package gpx
+
+// WptFields is a set of WptType's optional numeric fields.
+//
+// A zero value in an optional numeric field usually means that the field is
+// absent. WptType.ZeroFields records the fields whose zero values are
+// present, for example the elevation of a point at sea level or an HDOP of
+// zero, so that they are distinguished from absent fields and written.
+type WptFields uint16
+
+// Optional numeric fields.
+const (
+	WptEle WptFields = 1 << iota
+	WptSpeed
+	WptCourse
+	WptMagVar
+	WptGeoidHeight
+	WptSat
+	WptHDOP
+	WptVDOP
+	WptPDOP
+	WptAgeOfDGPSData
+	WptDGPSID
+)
+
+// wptElementFields maps the local names of wpt elements to their fields.
+var wptElementFields = map[string]WptFields{
+	"ele":           WptEle,
+	"speed":         WptSpeed,
+	"course":        WptCourse,
+	"magvar":        WptMagVar,
+	"geoidheight":   WptGeoidHeight,
+	"sat":           WptSat,
+	"hdop":          WptHDOP,
+	"vdop":          WptVDOP,
+	"pdop":          WptPDOP,
+	"ageofdgpsdata": WptAgeOfDGPSData,
+	"dgpsid":        WptDGPSID,
+}
+
+// Has returns whether all of fields are present in w, either because they are
+// non-zero or because they are in w.ZeroFields.
+func (w *WptType) Has(fields WptFields) bool {
+	for field := WptFields(1); field != 0 && field <= fields; field <<= 1 {
+		if fields&field != 0 && w.isZero(field) && w.ZeroFields&field == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetZero sets fields to zero and marks them as present.
+func (w *WptType) SetZero(fields WptFields) {
+	w.setZero(fields)
+	w.ZeroFields |= fields
+}
+
+// Clear sets fields to zero and marks them as absent.
+func (w *WptType) Clear(fields WptFields) {
+	w.setZero(fields)
+	w.ZeroFields &^= fields
+}
+
+// setEle sets w's elevation to ele and marks it as present, even if it is
+// zero.
+func (w *WptType) setEle(ele float64) {
+	if ele == 0 {
+		w.SetZero(WptEle)
+		return
+	}
+	w.Ele = ele
+}
+
+// isZero returns whether field is zero in w.
+func (w *WptType) isZero(field WptFields) bool {
+	switch field {
+	case WptEle:
+		return w.Ele == 0
+	case WptSpeed:
+		return w.Speed == 0
+	case WptCourse:
+		return w.Course == 0
+	case WptMagVar:
+		return w.MagVar == 0
+	case WptGeoidHeight:
+		return w.GeoidHeight == 0
+	case WptSat:
+		return w.Sat == 0
+	case WptHDOP:
+		return w.HDOP == 0
+	case WptVDOP:
+		return w.VDOP == 0
+	case WptPDOP:
+		return w.PDOP == 0
+	case WptAgeOfDGPSData:
+		return w.AgeOfDGPSData == 0
+	case WptDGPSID:
+		return w.DGPSID == 0
+	default:
+		return true
+	}
+}
+
+// setZero sets fields to zero in w.
+func (w *WptType) setZero(fields WptFields) {
+	if fields&WptEle != 0 {
+		w.Ele = 0
+	}
+	if fields&WptSpeed != 0 {
+		w.Speed = 0
+	}
+	if fields&WptCourse != 0 {
+		w.Course = 0
+	}
+	if fields&WptMagVar != 0 {
+		w.MagVar = 0
+	}
+	if fields&WptGeoidHeight != 0 {
+		w.GeoidHeight = 0
+	}
+	if fields&WptSat != 0 {
+		w.Sat = 0
+	}
+	if fields&WptHDOP != 0 {
+		w.HDOP = 0
+	}
+	if fields&WptVDOP != 0 {
+		w.VDOP = 0
+	}
+	if fields&WptPDOP != 0 {
+		w.PDOP = 0
+	}
+	if fields&WptAgeOfDGPSData != 0 {
+		w.AgeOfDGPSData = 0
+	}
+	if fields&WptDGPSID != 0 {
+		w.DGPSID = 0
+	}
+}
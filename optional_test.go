@@ -0,0 +1,62 @@
+package gpx_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestWptZeroFields(t *testing.T) {
+	data := "<wpt lat=\"1\" lon=\"2\">" +
+		"<ele>0</ele>" +
+		"<magvar>0</magvar>" +
+		"<sat>0</sat>" +
+		"<hdop>0</hdop>" +
+		"<vdop>1.5</vdop>" +
+		"</wpt>"
+	var got gpx.WptType
+	assert.NoError(t, xml.Unmarshal([]byte(data), &got))
+	assert.Equal(t, gpx.WptType{
+		Lat:        1,
+		Lon:        2,
+		VDOP:       1.5,
+		ZeroFields: gpx.WptEle | gpx.WptMagVar | gpx.WptSat | gpx.WptHDOP,
+	}, got)
+	assert.True(t, got.Has(gpx.WptEle|gpx.WptHDOP|gpx.WptVDOP))
+	assert.False(t, got.Has(gpx.WptEle|gpx.WptPDOP))
+
+	b := &bytes.Buffer{}
+	assert.NoError(t, xml.NewEncoder(b).EncodeElement(&got, xml.StartElement{Name: xml.Name{Local: "wpt"}}))
+	assert.Equal(t, data, b.String())
+
+	got.Clear(gpx.WptEle | gpx.WptVDOP)
+	assert.False(t, got.Has(gpx.WptEle))
+	assert.False(t, got.Has(gpx.WptVDOP))
+	assert.Zero(t, got.VDOP)
+
+	seaLevel := &gpx.WptType{Lat: 1, Lon: 2, Ele: 3}
+	seaLevel.SetZero(gpx.WptEle)
+	b.Reset()
+	assert.NoError(t, xml.NewEncoder(b).EncodeElement(seaLevel, xml.StartElement{Name: xml.Name{Local: "wpt"}}))
+	assert.Equal(t, `<wpt lat="1" lon="2"><ele>0</ele></wpt>`, b.String())
+}
+
+func TestWptZeroSpeedExtension(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Wpt:     []*gpx.WptType{{Lat: 1, Lon: 2}},
+	}
+	g.Wpt[0].SetZero(gpx.WptSpeed)
+	b := &bytes.Buffer{}
+	assert.NoError(t, g.Write(b))
+	assert.Contains(t, b.String(), "<gpxtpx:speed>0</gpxtpx:speed>")
+
+	got, err := gpx.Read(b)
+	assert.NoError(t, err)
+	assert.True(t, got.Wpt[0].Has(gpx.WptSpeed))
+	assert.False(t, got.Wpt[0].Has(gpx.WptCourse))
+}
@@ -0,0 +1,83 @@
+package gpx
+
+import "strconv"
+
+// osmAndNamespace is the namespace of OsmAnd's GPX extensions.
+const osmAndNamespace = "https://osmand.net"
+
+// osmAndSpaces are the namespaces of OsmAnd extension elements, including
+// the conventional prefix for when it is declared on the gpx element.
+var osmAndSpaces = []string{osmAndNamespace, "osmand"}
+
+// An OsmAnd contains the styling that the OsmAnd Android app stores in
+// waypoint and track extensions. Empty fields are absent. Track point speeds
+// in osmand:speed elements are read into WptType.Speed.
+type OsmAnd struct {
+	// Color is the color of a waypoint or track, for example "#ff0000", or
+	// "#80ff0000" with alpha.
+	Color string
+	// Icon is the name of a waypoint's icon, for example "special_star".
+	Icon string
+	// Background is the shape of a waypoint icon's background, for example
+	// "circle", "octagon", or "square".
+	Background string
+	// Address is a waypoint's address.
+	Address string
+	// Width is the width of a track's line, for example "thin", "medium",
+	// "bold", or a number of pixels.
+	Width string
+	// ShowArrows is whether direction arrows are drawn along a track.
+	ShowArrows bool
+}
+
+// OsmAnd returns w's OsmAnd extensions.
+func (w *WptType) OsmAnd() OsmAnd {
+	return newOsmAnd(w.Extensions)
+}
+
+// SetOsmAnd replaces w's OsmAnd extensions with o, preserving its other
+// extensions.
+func (w *WptType) SetOsmAnd(o OsmAnd) {
+	w.Extensions = o.extensions(w.Extensions)
+}
+
+// OsmAnd returns t's OsmAnd extensions.
+func (t *TrkType) OsmAnd() OsmAnd {
+	return newOsmAnd(t.Extensions)
+}
+
+// SetOsmAnd replaces t's OsmAnd extensions with o, preserving its other
+// extensions.
+func (t *TrkType) SetOsmAnd(o OsmAnd) {
+	t.Extensions = o.extensions(t.Extensions)
+}
+
+// newOsmAnd returns the OsmAnd extensions in e.
+func newOsmAnd(e *ExtensionsType) OsmAnd {
+	values := e.namespacedExtensions(osmAndSpaces...)
+	showArrows, _ := strconv.ParseBool(values["show_arrows"])
+	return OsmAnd{
+		Color:      values["color"],
+		Icon:       values["icon"],
+		Background: values["background"],
+		Address:    values["address"],
+		Width:      values["width"],
+		ShowArrows: showArrows,
+	}
+}
+
+// extensions returns e with its OsmAnd extensions replaced by o.
+func (o *OsmAnd) extensions(e *ExtensionsType) *ExtensionsType {
+	var showArrows string
+	if o.ShowArrows {
+		showArrows = "true"
+	}
+	return e.withNamespacedExtensions(osmAndSpaces, "osmand", osmAndNamespace, [][2]string{
+		{"icon", o.Icon},
+		{"background", o.Background},
+		{"color", o.Color},
+		{"address", o.Address},
+		{"width", o.Width},
+		{"show_arrows", showArrows},
+	})
+}
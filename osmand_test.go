@@ -0,0 +1,78 @@
+package gpx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestOsmAnd(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="OsmAnd" xmlns="http://www.topografix.com/GPX/1/1" xmlns:osmand="https://osmand.net">
+  <wpt lat="1" lon="2">
+    <name>Home</name>
+    <extensions>
+      <osmand:icon>special_star</osmand:icon>
+      <osmand:background>circle</osmand:background>
+      <osmand:color>#ffeecc22</osmand:color>
+      <other>kept</other>
+    </extensions>
+  </wpt>
+  <trk>
+    <extensions>
+      <osmand:color>#ff0000</osmand:color>
+      <osmand:width>bold</osmand:width>
+      <osmand:show_arrows>true</osmand:show_arrows>
+    </extensions>
+    <trkseg>
+      <trkpt lat="1" lon="2">
+        <extensions><osmand:speed>3.5</osmand:speed></extensions>
+      </trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+	g, err := gpx.Read(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, gpx.OsmAnd{
+		Color:      "#ffeecc22",
+		Icon:       "special_star",
+		Background: "circle",
+	}, g.Wpt[0].OsmAnd())
+	assert.Equal(t, gpx.OsmAnd{
+		Color:      "#ff0000",
+		Width:      "bold",
+		ShowArrows: true,
+	}, g.Trk[0].OsmAnd())
+	assert.Equal(t, 3.5, g.Trk[0].TrkSeg[0].TrkPt[0].Speed)
+
+	g.Wpt[0].SetOsmAnd(gpx.OsmAnd{
+		Color:   "#00ff00",
+		Icon:    "special_flag",
+		Address: "1 <Main> St",
+	})
+	assert.Contains(t, string(g.Wpt[0].Extensions.XML), "<other>kept</other>")
+
+	var b bytes.Buffer
+	assert.NoError(t, g.Write(&b))
+	g2, err := gpx.Read(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, gpx.OsmAnd{
+		Color:   "#00ff00",
+		Icon:    "special_flag",
+		Address: "1 <Main> St",
+	}, g2.Wpt[0].OsmAnd())
+	assert.Equal(t, g.Trk[0].OsmAnd(), g2.Trk[0].OsmAnd())
+	assert.Equal(t, 3.5, g2.Trk[0].TrkSeg[0].TrkPt[0].Speed)
+
+	trk := &gpx.TrkType{}
+	assert.Equal(t, gpx.OsmAnd{}, trk.OsmAnd())
+	trk.SetOsmAnd(gpx.OsmAnd{Color: "#0000ff"})
+	assert.Equal(t, gpx.OsmAnd{Color: "#0000ff"}, trk.OsmAnd())
+	trk.SetOsmAnd(gpx.OsmAnd{})
+	assert.Nil(t, trk.Extensions)
+}
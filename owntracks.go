@@ -0,0 +1,121 @@
+package gpx
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// An OwnTracksOptions contains options for ingesting OwnTracks location
+// messages.
+type OwnTracksOptions struct {
+	// MaxAccuracy is the largest accuracy radius in meters of locations to
+	// append. If zero, locations are appended regardless of their accuracy.
+	MaxAccuracy float64
+	// SegmentGap is the minimum time between consecutive locations that
+	// starts a new track segment. If zero, all locations are appended to the
+	// current segment.
+	SegmentGap time.Duration
+	// TrackerID, if not empty, is the tracker ID of the only device whose
+	// locations are appended.
+	TrackerID string
+}
+
+// An MQTTSubscriber is the subset of an MQTT client needed to receive
+// OwnTracks messages.
+type MQTTSubscriber interface {
+	Subscribe(topic string, handler func(topic string, payload []byte)) error
+	Unsubscribe(topic string) error
+}
+
+// An ownTracksLocation is an OwnTracks location message.
+type ownTracksLocation struct {
+	Type      string  `json:"_type"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Alt       float64 `json:"alt"`
+	Acc       float64 `json:"acc"`
+	Vel       float64 `json:"vel"` // Kilometers per hour.
+	COG       float64 `json:"cog"`
+	TST       int64   `json:"tst"` // Seconds since the epoch.
+	TrackerID string  `json:"tid"`
+}
+
+// IngestOwnTracks appends the locations in the OwnTracks JSON messages
+// received from messages to a until messages is closed or ctx is done.
+// Messages that are not valid location messages, and locations excluded by
+// options, are ignored.
+func IngestOwnTracks(ctx context.Context, messages <-chan []byte, a *Appender, options OwnTracksOptions) error {
+	var last time.Time
+	for {
+		var message []byte
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			message = m
+		}
+		var location ownTracksLocation
+		if err := json.Unmarshal(message, &location); err != nil || location.Type != "location" {
+			continue
+		}
+		if options.MaxAccuracy != 0 && location.Acc > options.MaxAccuracy {
+			continue
+		}
+		if options.TrackerID != "" && location.TrackerID != options.TrackerID {
+			continue
+		}
+		trkPt := location.wpt()
+		var err error
+		if options.SegmentGap > 0 && !last.IsZero() && trkPt.Time.Sub(last) >= options.SegmentGap {
+			err = a.AppendTrkSeg(trkPt)
+		} else {
+			err = a.AppendTrkPts(trkPt)
+		}
+		if err != nil {
+			return err
+		}
+		last = trkPt.Time
+	}
+}
+
+// SubscribeOwnTracks subscribes to topic with client and appends the
+// locations in the OwnTracks messages received to a, as IngestOwnTracks, until
+// ctx is done.
+func SubscribeOwnTracks(ctx context.Context, client MQTTSubscriber, topic string, a *Appender, options OwnTracksOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages := make(chan []byte)
+	if err := client.Subscribe(topic, func(_ string, payload []byte) {
+		select {
+		case <-ctx.Done():
+		case messages <- payload:
+		}
+	}); err != nil {
+		return err
+	}
+	err := IngestOwnTracks(ctx, messages, a, options)
+	cancel()
+	if unsubscribeErr := client.Unsubscribe(topic); err == nil {
+		err = unsubscribeErr
+	}
+	return err
+}
+
+// wpt returns l as a WptType.
+func (l *ownTracksLocation) wpt() *WptType {
+	wpt := &WptType{
+		Lat:    l.Lat,
+		Lon:    l.Lon,
+		Ele:    l.Alt,
+		Speed:  l.Vel / 3.6,
+		Course: l.COG,
+	}
+	if l.TST != 0 {
+		wpt.Time = time.Unix(l.TST, 0).UTC()
+	}
+	return wpt
+}
@@ -0,0 +1,130 @@
+package gpx_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+type testMQTTSubscriber struct {
+	mutex    sync.Mutex
+	handlers map[string]func(string, []byte)
+}
+
+func (s *testMQTTSubscriber) Subscribe(topic string, handler func(string, []byte)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[topic] = handler
+	return nil
+}
+
+func (s *testMQTTSubscriber) Unsubscribe(topic string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.handlers, topic)
+	return nil
+}
+
+func (s *testMQTTSubscriber) handler(topic string) func(string, []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.handlers[topic]
+}
+
+func newOwnTracksTestAppender(t *testing.T) (string, *gpx.Appender) {
+	t.Helper()
+	g := &gpx.GPX{
+		Version: "1.1",
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: []*gpx.WptType{{Lat: 1, Lon: 2}}},
+				},
+			},
+		},
+	}
+	name := filepath.Join(t.TempDir(), "live.gpx")
+	f, err := os.Create(name)
+	assert.NoError(t, err)
+	assert.NoError(t, g.Write(f))
+	assert.NoError(t, f.Close())
+	a, err := gpx.OpenAppender(name)
+	assert.NoError(t, err)
+	return name, a
+}
+
+func TestIngestOwnTracks(t *testing.T) {
+	name, a := newOwnTracksTestAppender(t)
+	messages := make(chan []byte, 8)
+	messages <- []byte(`{"_type":"location","lat":47.5,"lon":7.5,"alt":260,"acc":10,"vel":36,"cog":90,"tst":1704179732,"tid":"ph"}`)
+	messages <- []byte(`{"_type":"transition","lat":47.6,"lon":7.6,"tst":1704179742,"tid":"ph"}`)
+	messages <- []byte(`not json`)
+	messages <- []byte(`{"_type":"location","lat":47.7,"lon":7.7,"acc":500,"tst":1704179752,"tid":"ph"}`)
+	messages <- []byte(`{"_type":"location","lat":47.8,"lon":7.8,"acc":5,"tst":1704179762,"tid":"xx"}`)
+	messages <- []byte(`{"_type":"location","lat":47.9,"lon":7.9,"acc":5,"tst":1704183332,"tid":"ph"}`)
+	close(messages)
+
+	assert.NoError(t, gpx.IngestOwnTracks(context.Background(), messages, a, gpx.OwnTracksOptions{
+		MaxAccuracy: 100,
+		SegmentGap:  time.Hour,
+		TrackerID:   "ph",
+	}))
+	assert.NoError(t, a.Close())
+
+	got, err := gpx.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.TrkSegType{
+		{
+			TrkPt: []*gpx.WptType{
+				{Lat: 1, Lon: 2},
+				{
+					Lat:    47.5,
+					Lon:    7.5,
+					Ele:    260,
+					Time:   time.Date(2024, 1, 2, 7, 15, 32, 0, time.UTC),
+					Speed:  10,
+					Course: 90,
+					Extensions: &gpx.ExtensionsType{
+						XML: []byte(`<gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:speed>10</gpxtpx:speed><gpxtpx:course>90</gpxtpx:course></gpxtpx:TrackPointExtension>`),
+					},
+				},
+			},
+		},
+		{
+			TrkPt: []*gpx.WptType{
+				{Lat: 47.9, Lon: 7.9, Time: time.Date(2024, 1, 2, 8, 15, 32, 0, time.UTC)},
+			},
+		},
+	}, got.Trk[0].TrkSeg)
+}
+
+func TestSubscribeOwnTracks(t *testing.T) {
+	name, a := newOwnTracksTestAppender(t)
+	client := &testMQTTSubscriber{
+		handlers: make(map[string]func(string, []byte)),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- gpx.SubscribeOwnTracks(ctx, client, "owntracks/user/ph", a, gpx.OwnTracksOptions{})
+	}()
+	assert.Eventually(t, func() bool {
+		return client.handler("owntracks/user/ph") != nil
+	}, time.Second, time.Millisecond)
+	client.handler("owntracks/user/ph")("owntracks/user/ph", []byte(`{"_type":"location","lat":47.5,"lon":7.5,"tst":1704179732}`))
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+	assert.Nil(t, client.handler("owntracks/user/ph"))
+	assert.NoError(t, a.Close())
+
+	got, err := gpx.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Len(t, got.Trk[0].TrkSeg[0].TrkPt, 2)
+}
@@ -0,0 +1,101 @@
+package gpx
+
+import "time"
+
+// historicalPacingTolerance is the largest distance in meters between a
+// route point and a historical track point for them to match.
+const historicalPacingTolerance = 50
+
+// A Split is the expected elapsed time at a point along a route.
+type Split struct {
+	// Distance is the distance in meters along the route.
+	Distance float64
+	// Elapsed is the expected time since the start of the route.
+	Elapsed time.Duration
+	// Samples is the number of historical efforts on the section of the route
+	// ending at the point. If zero, the section's time is estimated from the
+	// median pace over all sections.
+	Samples int
+}
+
+// HistoricalPacing returns the expected split at each of route's points,
+// projected from the times taken on each section between consecutive route
+// points in history. A track covers a section if it passes within 50 meters
+// of both route points, in order, with times. The expected time for each
+// section is the median of the times of the tracks that cover it. It returns
+// nil if no track covers any section.
+func HistoricalPacing(route *RteType, history []*TrkType) []Split {
+	n := len(route.RtePt)
+	if n == 0 {
+		return nil
+	}
+	distances := cumulativeDistances(route.RtePt)
+	sectionTimes := make([][]float64, n)
+	var paces []float64
+	for _, trk := range history {
+		var trkPts []*WptType
+		for _, trkPt := range trk.trkPts() {
+			if !trkPt.Time.IsZero() {
+				trkPts = append(trkPts, trkPt)
+			}
+		}
+		prev, start := -1, 0
+		for i, rtePt := range route.RtePt {
+			match := matchForward(trkPts, start, rtePt)
+			if match != -1 && prev != -1 {
+				seconds := trkPts[match].Time.Sub(trkPts[prev].Time).Seconds()
+				if length := distances[i] - distances[i-1]; seconds >= 0 {
+					sectionTimes[i] = append(sectionTimes[i], seconds)
+					if length > 0 {
+						paces = append(paces, seconds/length)
+					}
+				}
+			}
+			prev = match
+			if match != -1 {
+				start = match
+			}
+		}
+	}
+	if len(paces) == 0 {
+		return nil
+	}
+	pace := median(paces)
+
+	splits := make([]Split, n)
+	var elapsed float64
+	for i := 1; i < n; i++ {
+		seconds := pace * (distances[i] - distances[i-1])
+		if len(sectionTimes[i]) != 0 {
+			seconds = median(sectionTimes[i])
+		}
+		elapsed += seconds
+		splits[i] = Split{
+			Distance: distances[i],
+			Elapsed:  time.Duration(elapsed * float64(time.Second)),
+			Samples:  len(sectionTimes[i]),
+		}
+	}
+	return splits
+}
+
+// matchForward returns the index of the first local minimum of the distance
+// to wpt in trkPts, starting at start, that is within
+// historicalPacingTolerance, or -1 if there is none.
+func matchForward(trkPts []*WptType, start int, wpt *WptType) int {
+	for i := start; i < len(trkPts); i++ {
+		distance := HaversineDistance(wpt.Lat, wpt.Lon, trkPts[i].Lat, trkPts[i].Lon)
+		if distance > historicalPacingTolerance {
+			continue
+		}
+		for i+1 < len(trkPts) {
+			next := HaversineDistance(wpt.Lat, wpt.Lon, trkPts[i+1].Lat, trkPts[i+1].Lon)
+			if next > distance {
+				break
+			}
+			i, distance = i+1, next
+		}
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestHistoricalPacing(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	step := 1000 / gpx.HaversineDistance(0, 0, 0, 1)
+
+	// The route runs 3km east along the equator, with points every
+	// kilometer.
+	route := &gpx.RteType{}
+	for i := 0; i <= 3; i++ {
+		route.RtePt = append(route.RtePt, &gpx.WptType{Lon: float64(i) * step})
+	}
+
+	// newTrk returns a track along the start of the route, taking
+	// each kilometer's time in minutes from minutes, with points every 100m.
+	newTrk := func(minutes ...float64) *gpx.TrkType {
+		ts := &gpx.TrkSegType{}
+		elapsed := 0.0
+		for i, m := range minutes {
+			for j := 0; j < 10; j++ {
+				ts.TrkPt = append(ts.TrkPt, &gpx.WptType{
+					Lon:  (float64(i) + float64(j)/10) * step,
+					Time: t0.Add(time.Duration((elapsed + m*float64(j)/10) * float64(time.Minute))),
+				})
+			}
+			elapsed += m
+		}
+		ts.TrkPt = append(ts.TrkPt, &gpx.WptType{
+			Lon:  float64(len(minutes)) * step,
+			Time: t0.Add(time.Duration(elapsed * float64(time.Minute))),
+		})
+		return &gpx.TrkType{TrkSeg: []*gpx.TrkSegType{ts}}
+	}
+
+	got := gpx.HistoricalPacing(route, []*gpx.TrkType{
+		newTrk(5, 6),
+		newTrk(4, 8),
+		newTrk(6, 7),
+		{TrkSeg: []*gpx.TrkSegType{{TrkPt: []*gpx.WptType{{Lat: 10, Lon: 10, Time: t0}}}}},
+	})
+	assert.Len(t, got, 4)
+	assert.Equal(t, gpx.Split{}, got[0])
+	assert.InDelta(t, 1000, got[1].Distance, 1e-6)
+	assert.InDelta(t, (5 * time.Minute).Seconds(), got[1].Elapsed.Seconds(), 1e-3)
+	assert.Equal(t, 3, got[1].Samples)
+	assert.InDelta(t, (12 * time.Minute).Seconds(), got[2].Elapsed.Seconds(), 1e-3)
+	assert.Equal(t, 3, got[2].Samples)
+	// The last kilometer has no history, so it is estimated from the median
+	// pace of 6 minutes per kilometer.
+	assert.InDelta(t, (18 * time.Minute).Seconds(), got[3].Elapsed.Seconds(), 1e-3)
+	assert.Equal(t, 0, got[3].Samples)
+
+	assert.Nil(t, gpx.HistoricalPacing(route, nil))
+	assert.Nil(t, gpx.HistoricalPacing(&gpx.RteType{}, nil))
+}
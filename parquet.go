@@ -0,0 +1,354 @@
+package gpx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// defaultParquetRowGroupSize is the default number of rows in each Parquet
+// row group.
+const defaultParquetRowGroupSize = 1 << 20
+
+// parquetMagic starts and ends every Parquet file.
+const parquetMagic = "PAR1"
+
+// Parquet physical types.
+const (
+	parquetInt32  = 1
+	parquetInt64  = 2
+	parquetDouble = 5
+)
+
+// Parquet encodings.
+const (
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// Thrift compact protocol types.
+const (
+	thriftBoolTrue = 1
+	thriftI32      = 5
+	thriftI64      = 6
+	thriftBinary   = 8
+	thriftList     = 9
+	thriftStruct   = 12
+)
+
+// ParquetOptions are options for GPX.ToParquet.
+type ParquetOptions struct {
+	// RowGroupSize is the maximum number of rows in each row group. If zero,
+	// 1048576 is used.
+	RowGroupSize int
+}
+
+// A parquetColumn is a column of a Parquet file.
+type parquetColumn struct {
+	name         string
+	physicalType int32
+	optional     bool
+	timestamp    bool
+	// appendValue appends the PLAIN encoding of the value in row i to b, and
+	// returns whether the value is present.
+	appendValue func(b []byte, i int) ([]byte, bool)
+}
+
+// ToParquet writes the track points of g to w as an uncompressed Parquet file
+// with the columns of g.PointTable: track and segment, the INT32 indexes of
+// the track and track segment, lat, lon, and ele, DOUBLE coordinates in
+// degrees and meters, time, an INT64 UTC timestamp in microseconds, and hr and
+// cad, the DOUBLE heart rate and cadence. Missing elevations, times, heart
+// rates, and cadences are written as nulls.
+func (g *GPX) ToParquet(w io.Writer, options ParquetOptions) error {
+	rowGroupSize := options.RowGroupSize
+	if rowGroupSize == 0 {
+		rowGroupSize = defaultParquetRowGroupSize
+	}
+	if rowGroupSize < 0 {
+		return fmt.Errorf("%d: invalid row group size", rowGroupSize)
+	}
+
+	pt := g.PointTable()
+	appendInt32 := func(values []int) func([]byte, int) ([]byte, bool) {
+		return func(b []byte, i int) ([]byte, bool) {
+			return binary.LittleEndian.AppendUint32(b, uint32(int32(values[i]))), true //nolint:gosec
+		}
+	}
+	appendDouble := func(values []float64, present []bool) func([]byte, int) ([]byte, bool) {
+		return func(b []byte, i int) ([]byte, bool) {
+			if present != nil && !present[i] {
+				return b, false
+			}
+			return binary.LittleEndian.AppendUint64(b, math.Float64bits(values[i])), true
+		}
+	}
+	columns := []*parquetColumn{
+		{name: "track", physicalType: parquetInt32, appendValue: appendInt32(pt.TrackIndex)},
+		{name: "segment", physicalType: parquetInt32, appendValue: appendInt32(pt.SegmentIndex)},
+		{name: "lat", physicalType: parquetDouble, appendValue: appendDouble(pt.Lat, nil)},
+		{name: "lon", physicalType: parquetDouble, appendValue: appendDouble(pt.Lon, nil)},
+		{name: "ele", physicalType: parquetDouble, optional: true, appendValue: appendDouble(pt.Ele, pt.HasEle)},
+		{
+			name:         "time",
+			physicalType: parquetInt64,
+			optional:     true,
+			timestamp:    true,
+			appendValue: func(b []byte, i int) ([]byte, bool) {
+				if pt.Time[i].IsZero() {
+					return b, false
+				}
+				return binary.LittleEndian.AppendUint64(b, uint64(pt.Time[i].UnixMicro())), true //nolint:gosec
+			},
+		},
+		{name: "hr", physicalType: parquetDouble, optional: true, appendValue: appendDouble(pt.HeartRate, pt.HasHeartRate)},
+		{name: "cad", physicalType: parquetDouble, optional: true, appendValue: appendDouble(pt.Cadence, pt.HasCadence)},
+	}
+
+	// Write the column chunks of each row group, recording their metadata.
+	type columnChunk struct {
+		offset int64
+		size   int64
+		rows   int
+	}
+	var rowGroups [][]columnChunk
+	offset := int64(len(parquetMagic))
+	if _, err := io.WriteString(w, parquetMagic); err != nil {
+		return err
+	}
+	for start := 0; start < pt.Len(); start += rowGroupSize {
+		end := min(start+rowGroupSize, pt.Len())
+		chunks := make([]columnChunk, 0, len(columns))
+		for _, column := range columns {
+			page := column.page(start, end)
+			if _, err := w.Write(page); err != nil {
+				return err
+			}
+			chunks = append(chunks, columnChunk{
+				offset: offset,
+				size:   int64(len(page)),
+				rows:   end - start,
+			})
+			offset += int64(len(page))
+		}
+		rowGroups = append(rowGroups, chunks)
+	}
+
+	// Write the file metadata.
+	var tw thriftWriter
+	tw.beginStruct()
+	tw.i32Field(1, 1)
+	tw.listField(2, thriftStruct, len(columns)+1)
+	tw.beginStruct()
+	tw.binaryField(4, "schema")
+	tw.i32Field(5, int32(len(columns))) //nolint:gosec
+	tw.endStruct()
+	for _, column := range columns {
+		tw.beginStruct()
+		tw.i32Field(1, column.physicalType)
+		if column.optional {
+			tw.i32Field(3, 1)
+		} else {
+			tw.i32Field(3, 0)
+		}
+		tw.binaryField(4, column.name)
+		if column.timestamp {
+			tw.i32Field(6, 10) // TIMESTAMP_MICROS.
+			tw.structField(10)
+			tw.structField(8)
+			tw.boolField(1, true)
+			tw.structField(2)
+			tw.structField(2)
+			tw.endStruct()
+			tw.endStruct()
+			tw.endStruct()
+			tw.endStruct()
+		}
+		tw.endStruct()
+	}
+	tw.i64Field(3, int64(pt.Len()))
+	tw.listField(4, thriftStruct, len(rowGroups))
+	for _, chunks := range rowGroups {
+		var totalSize int64
+		tw.beginStruct()
+		tw.listField(1, thriftStruct, len(chunks))
+		for i, chunk := range chunks {
+			tw.beginStruct()
+			tw.i64Field(2, chunk.offset)
+			tw.structField(3)
+			tw.i32Field(1, columns[i].physicalType)
+			tw.listField(2, thriftI32, 2)
+			tw.appendVarint(zigzag(parquetPlain))
+			tw.appendVarint(zigzag(parquetRLE))
+			tw.listField(3, thriftBinary, 1)
+			tw.appendBinary(columns[i].name)
+			tw.i32Field(4, 0) // UNCOMPRESSED.
+			tw.i64Field(5, int64(chunk.rows))
+			tw.i64Field(6, chunk.size)
+			tw.i64Field(7, chunk.size)
+			tw.i64Field(9, chunk.offset)
+			tw.endStruct()
+			tw.endStruct()
+			totalSize += chunk.size
+		}
+		tw.i64Field(2, totalSize)
+		tw.i64Field(3, int64(chunks[0].rows))
+		tw.endStruct()
+	}
+	tw.binaryField(6, "github.com/twpayne/go-gpx")
+	tw.endStruct()
+	footer := binary.LittleEndian.AppendUint32(tw.b, uint32(len(tw.b))) //nolint:gosec
+	footer = append(footer, parquetMagic...)
+	_, err := w.Write(footer)
+	return err
+}
+
+// page returns the header and data of a PLAIN encoded data page containing
+// rows start to end of c.
+func (c *parquetColumn) page(start, end int) []byte {
+	var values []byte
+	var levels []byte
+	var run, level int
+	for i := start; i < end; i++ {
+		var present bool
+		values, present = c.appendValue(values, i)
+		if !c.optional {
+			continue
+		}
+		var thisLevel int
+		if present {
+			thisLevel = 1
+		}
+		if run > 0 && thisLevel != level {
+			levels = appendRLERun(levels, run, level)
+			run = 0
+		}
+		level = thisLevel
+		run++
+	}
+
+	var data []byte
+	if c.optional {
+		if run > 0 {
+			levels = appendRLERun(levels, run, level)
+		}
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(levels))) //nolint:gosec
+		data = append(data, levels...)
+	}
+	data = append(data, values...)
+
+	var tw thriftWriter
+	tw.beginStruct()
+	tw.i32Field(1, 0)                // DATA_PAGE.
+	tw.i32Field(2, int32(len(data))) //nolint:gosec
+	tw.i32Field(3, int32(len(data))) //nolint:gosec
+	tw.structField(5)
+	tw.i32Field(1, int32(end-start)) //nolint:gosec
+	tw.i32Field(2, parquetPlain)
+	tw.i32Field(3, parquetRLE)
+	tw.i32Field(4, parquetRLE)
+	tw.endStruct()
+	tw.endStruct()
+	return append(tw.b, data...)
+}
+
+// appendRLERun appends an RLE run of n definition levels of level, with a bit
+// width of one, to b.
+func appendRLERun(b []byte, n, level int) []byte {
+	b = binary.AppendUvarint(b, uint64(n)<<1) //nolint:gosec
+	return append(b, byte(level))
+}
+
+// A thriftWriter encodes Thrift structs with the compact protocol.
+type thriftWriter struct {
+	b []byte
+	// lastIDs are the IDs of the last fields written in each enclosing
+	// struct.
+	lastIDs []int
+}
+
+// beginStruct begins a struct.
+func (tw *thriftWriter) beginStruct() {
+	tw.lastIDs = append(tw.lastIDs, 0)
+}
+
+// endStruct ends the current struct.
+func (tw *thriftWriter) endStruct() {
+	tw.b = append(tw.b, 0)
+	tw.lastIDs = tw.lastIDs[:len(tw.lastIDs)-1]
+}
+
+// fieldHeader writes the header of the field id of type typ.
+func (tw *thriftWriter) fieldHeader(id int, typ byte) {
+	lastID := &tw.lastIDs[len(tw.lastIDs)-1]
+	if delta := id - *lastID; 0 < delta && delta <= 15 {
+		tw.b = append(tw.b, byte(delta)<<4|typ)
+	} else {
+		tw.b = append(tw.b, typ)
+		tw.appendVarint(zigzag(int64(id)))
+	}
+	*lastID = id
+}
+
+// boolField writes the bool field id.
+func (tw *thriftWriter) boolField(id int, value bool) {
+	if value {
+		tw.fieldHeader(id, thriftBoolTrue)
+	} else {
+		tw.fieldHeader(id, thriftBoolTrue+1)
+	}
+}
+
+// i32Field writes the i32 field id.
+func (tw *thriftWriter) i32Field(id int, value int32) {
+	tw.fieldHeader(id, thriftI32)
+	tw.appendVarint(zigzag(int64(value)))
+}
+
+// i64Field writes the i64 field id.
+func (tw *thriftWriter) i64Field(id int, value int64) {
+	tw.fieldHeader(id, thriftI64)
+	tw.appendVarint(zigzag(value))
+}
+
+// binaryField writes the binary field id.
+func (tw *thriftWriter) binaryField(id int, value string) {
+	tw.fieldHeader(id, thriftBinary)
+	tw.appendBinary(value)
+}
+
+// listField writes the header of the list field id of n elements of type
+// elemType. The caller writes the elements.
+func (tw *thriftWriter) listField(id int, elemType byte, n int) {
+	tw.fieldHeader(id, thriftList)
+	if n < 15 {
+		tw.b = append(tw.b, byte(n)<<4|elemType)
+	} else {
+		tw.b = append(tw.b, 0xf0|elemType)
+		tw.appendVarint(uint64(n)) //nolint:gosec
+	}
+}
+
+// structField begins the struct field id. The caller writes its fields and
+// calls endStruct.
+func (tw *thriftWriter) structField(id int) {
+	tw.fieldHeader(id, thriftStruct)
+	tw.beginStruct()
+}
+
+// appendBinary appends a length-prefixed string.
+func (tw *thriftWriter) appendBinary(value string) {
+	tw.appendVarint(uint64(len(value)))
+	tw.b = append(tw.b, value...)
+}
+
+// appendVarint appends an unsigned varint.
+func (tw *thriftWriter) appendVarint(value uint64) {
+	tw.b = binary.AppendUvarint(tw.b, value)
+}
+
+// zigzag returns the zigzag encoding of value.
+func zigzag(value int64) uint64 {
+	return uint64(value<<1 ^ value>>63) //nolint:gosec
+}
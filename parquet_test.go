@@ -0,0 +1,236 @@
+package gpx_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestToParquet(t *testing.T) {
+	t0 := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{
+								Lat:  1,
+								Lon:  2,
+								Ele:  3,
+								Time: t0,
+								Extensions: &gpx.ExtensionsType{
+									XML: []byte("<gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr><gpxtpx:cad>0</gpxtpx:cad></gpxtpx:TrackPointExtension>"),
+								},
+							},
+							{Lat: 4, Lon: 5},
+						},
+					},
+				},
+			},
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{},
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 6, Lon: 7, ZeroFields: gpx.WptEle},
+						},
+					},
+				},
+			},
+		},
+	}
+	expected := map[string][]any{
+		"track":   {int32(0), int32(0), int32(1)},
+		"segment": {int32(0), int32(0), int32(1)},
+		"lat":     {1.0, 4.0, 6.0},
+		"lon":     {2.0, 5.0, 7.0},
+		"ele":     {3.0, nil, 0.0},
+		"time":    {t0.UnixMicro(), nil, nil},
+		"hr":      {150.0, nil, nil},
+		"cad":     {0.0, nil, nil},
+	}
+
+	for _, rowGroupSize := range []int{0, 1, 2} {
+		t.Run(strconv.Itoa(rowGroupSize), func(t *testing.T) {
+			var b bytes.Buffer
+			assert.NoError(t, g.ToParquet(&b, gpx.ParquetOptions{RowGroupSize: rowGroupSize}))
+			numRows, columns := readTestParquet(t, b.Bytes())
+			assert.Equal(t, int64(3), numRows)
+			assert.Equal(t, expected, columns)
+		})
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, (&gpx.GPX{}).ToParquet(&b, gpx.ParquetOptions{}))
+	numRows, columns := readTestParquet(t, b.Bytes())
+	assert.Zero(t, numRows)
+	assert.Empty(t, columns)
+
+	assert.Error(t, g.ToParquet(&b, gpx.ParquetOptions{RowGroupSize: -1}))
+}
+
+// readTestParquet decodes the uncompressed, PLAIN encoded Parquet file data
+// and returns its number of rows and the values of its columns by name, with
+// nil for nulls.
+func readTestParquet(t *testing.T, data []byte) (int64, map[string][]any) {
+	t.Helper()
+	assert.Equal(t, "PAR1", string(data[:4]))
+	assert.Equal(t, "PAR1", string(data[len(data)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &testThriftReader{b: data[len(data)-8-footerLen : len(data)-8]}
+	metadata := footer.readStruct()
+	assert.Equal(t, footerLen, footer.pos)
+
+	// Parquet physical types and repetition types by column name.
+	type columnSchema struct {
+		physicalType int64
+		optional     bool
+	}
+	schemas := make(map[string]columnSchema)
+	for _, element := range metadata[2].([]any)[1:] {
+		element := element.(map[int]any)
+		schemas[string(element[4].([]byte))] = columnSchema{
+			physicalType: element[1].(int64),
+			optional:     element[3].(int64) == 1,
+		}
+	}
+
+	columns := make(map[string][]any)
+	for _, rowGroup := range metadata[4].([]any) {
+		rowGroup := rowGroup.(map[int]any)
+		numRows := int(rowGroup[3].(int64))
+		for _, columnChunk := range rowGroup[1].([]any) {
+			columnMetadata := columnChunk.(map[int]any)[3].(map[int]any)
+			name := string(columnMetadata[3].([]any)[0].([]byte))
+			schema := schemas[name]
+			assert.Equal(t, schema.physicalType, columnMetadata[1].(int64))
+			assert.Equal(t, int64(numRows), columnMetadata[5].(int64))
+
+			page := &testThriftReader{b: data[columnMetadata[9].(int64):]}
+			pageHeader := page.readStruct()
+			assert.Equal(t, int64(numRows), pageHeader[5].(map[int]any)[1].(int64))
+			pageData := page.b[page.pos : page.pos+int(pageHeader[2].(int64))]
+
+			present := make([]bool, 0, numRows)
+			if schema.optional {
+				levelsLen := int(binary.LittleEndian.Uint32(pageData))
+				levels := &testThriftReader{b: pageData[4 : 4+levelsLen]}
+				for levels.pos < len(levels.b) {
+					header := levels.readVarint()
+					assert.Zero(t, header&1, "bit-packed run")
+					level := levels.b[levels.pos]
+					levels.pos++
+					for range header >> 1 {
+						present = append(present, level == 1)
+					}
+				}
+				pageData = pageData[4+levelsLen:]
+			} else {
+				for range numRows {
+					present = append(present, true)
+				}
+			}
+			assert.Len(t, present, numRows)
+
+			for _, isPresent := range present {
+				if !isPresent {
+					columns[name] = append(columns[name], nil)
+					continue
+				}
+				switch schema.physicalType {
+				case 1: // INT32.
+					columns[name] = append(columns[name], int32(binary.LittleEndian.Uint32(pageData)))
+					pageData = pageData[4:]
+				case 2: // INT64.
+					columns[name] = append(columns[name], int64(binary.LittleEndian.Uint64(pageData)))
+					pageData = pageData[8:]
+				case 5: // DOUBLE.
+					columns[name] = append(columns[name], math.Float64frombits(binary.LittleEndian.Uint64(pageData)))
+					pageData = pageData[8:]
+				default:
+					t.Fatalf("%s: unexpected physical type %d", name, schema.physicalType)
+				}
+			}
+			assert.Empty(t, pageData)
+		}
+	}
+	return metadata[3].(int64), columns
+}
+
+// A testThriftReader decodes Thrift compact protocol structs into maps of
+// field ids to values. Integers are decoded as int64s, binaries as []bytes,
+// lists as []anys, and structs as map[int]anys.
+type testThriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *testThriftReader) readVarint() uint64 {
+	value, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *testThriftReader) readZigzag() int64 {
+	value := r.readVarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *testThriftReader) readStruct() map[int]any {
+	fields := make(map[int]any)
+	id := 0
+	for {
+		header := r.b[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		if delta := int(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int(r.readZigzag())
+		}
+		fields[id] = r.readValue(header & 0x0f)
+	}
+}
+
+func (r *testThriftReader) readValue(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 5, 6:
+		return r.readZigzag()
+	case 8:
+		n := int(r.readVarint())
+		value := r.b[r.pos : r.pos+n]
+		r.pos += n
+		return value
+	case 9:
+		header := r.b[r.pos]
+		r.pos++
+		n := int(header >> 4)
+		if n == 15 {
+			n = int(r.readVarint())
+		}
+		values := make([]any, 0, n)
+		for range n {
+			values = append(values, r.readValue(header&0x0f))
+		}
+		return values
+	case 12:
+		return r.readStruct()
+	default:
+		panic(fmt.Sprintf("unsupported Thrift type %d", typ))
+	}
+}
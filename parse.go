@@ -0,0 +1,640 @@
+package gpx
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrLimitExceeded is returned when a document exceeds a limit set in
+// ParseOptions.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// ErrDuplicateElement is returned when a document repeats an element that the
+// GPX schema allows at most once and ParseOptions.DuplicateElementPolicy is
+// DuplicateError.
+var ErrDuplicateElement = errors.New("duplicate element")
+
+// A DuplicateElementPolicy is how repeated elements that the GPX schema allows
+// at most once, such as two name elements in one wpt or two metadata
+// elements, are handled.
+type DuplicateElementPolicy int
+
+// Duplicate element policies.
+const (
+	// DuplicateLastWins uses the last element. Earlier elements are
+	// discarded, not merged.
+	DuplicateLastWins DuplicateElementPolicy = iota
+	// DuplicateFirstWins uses the first element and skips later elements.
+	DuplicateFirstWins
+	// DuplicateError returns an error wrapping ErrDuplicateElement.
+	DuplicateError
+)
+
+// ParseOptions control how GPX documents are read. Zero values mean no limit.
+type ParseOptions struct {
+	// MaxPoints is the maximum total number of wpt, rtept, and trkpt
+	// elements.
+	MaxPoints int
+	// MaxElements is the maximum total number of elements.
+	MaxElements int
+	// MaxDepth is the maximum nesting depth of elements. The gpx element has a
+	// depth of one.
+	MaxDepth int
+	// MaxBytes is the maximum number of bytes read from the input. As
+	// encoding/xml reads each text or attribute value in full before it can
+	// be checked against MaxTextLen or MaxAttrLen, MaxBytes is what bounds the
+	// memory used when reading untrusted input.
+	MaxBytes int64
+	// MaxAttrs is the maximum number of attributes of each element.
+	MaxAttrs int
+	// MaxAttrLen is the maximum length of an attribute value in bytes.
+	MaxAttrLen int
+	// MaxTextLen is the maximum length in bytes of each run of text or
+	// comment, and of the inner XML of each extensions element.
+	MaxTextLen int
+	// UnknownElementHandler, if not nil, is called for each element that is
+	// not part of the GPX schema and is not inside an extensions element.
+	// path is the slash-separated path of local element names from the root
+	// to the element, for example "/gpx/trk/trkseg/trkpt/speed". dec returns
+	// the tokens of the element after start up to and including its end
+	// element. Any tokens not consumed by UnknownElementHandler are skipped.
+	UnknownElementHandler func(path string, dec *xml.Decoder, start xml.StartElement) error
+	// DuplicateElementPolicy is how repeated elements that the GPX schema
+	// allows at most once are handled. Elements inside extensions and
+	// elements not part of the GPX schema are not checked.
+	DuplicateElementPolicy DuplicateElementPolicy
+	// PreviewPoints, if positive, is the maximum number of points retained in
+	// each track segment, for fast previews of large documents. The rest of
+	// the document is read in full. Retained points are spread evenly along
+	// each segment and always include its first and last points. Segments
+	// with more than PreviewPoints points retain between PreviewPoints/2 and
+	// PreviewPoints points.
+	PreviewPoints int
+	// PreviewStats, if not nil, is set to the point counts and bounds of the
+	// whole document, including the points not retained because of
+	// PreviewPoints. ParseOptions with PreviewStats set must not be used by
+	// concurrent reads.
+	PreviewStats *PreviewStats
+	// CharsetReader, if not nil, returns a reader that converts input in the
+	// character encoding label, from the XML declaration, to UTF-8. If nil,
+	// the encodings of the WHATWG Encoding Standard are supported, in which
+	// ISO-8859-1 and Latin-1 are decoded as Windows-1252, a superset that is
+	// often what Windows tools that declare ISO-8859-1 actually write.
+	CharsetReader func(label string, input io.Reader) (io.Reader, error)
+}
+
+// PreviewStats are the point counts and bounds of a document, recorded while
+// reading it.
+type PreviewStats struct {
+	// Points is the total number of wpt, rtept, and trkpt elements.
+	Points int
+	// TrkPts is the number of points in each segment of each track.
+	TrkPts [][]int
+	// Bounds is the bounds of all points, or nil if there are none.
+	Bounds *BoundsType
+}
+
+// String returns a description of p.
+func (p DuplicateElementPolicy) String() string {
+	switch p {
+	case DuplicateLastWins:
+		return "last-wins"
+	case DuplicateFirstWins:
+		return "first-wins"
+	case DuplicateError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// knownElements maps the local names of GPX elements to the local names of
+// their known children, including GPX 1.0 children.
+var knownElements = map[string]map[string]bool{
+	"gpx":       setOf("metadata", "wpt", "rte", "trk", "extensions", "name", "desc", "author", "email", "url", "urlname", "time", "keywords", "bounds"),
+	"metadata":  setOf("name", "desc", "author", "copyright", "link", "time", "keywords", "bounds", "extensions"),
+	"author":    setOf("name", "email", "link"),
+	"copyright": setOf("year", "license"),
+	"link":      setOf("text", "type"),
+	"rte":       setOf("name", "cmt", "desc", "src", "link", "number", "type", "extensions", "rtept"),
+	"trk":       setOf("name", "cmt", "desc", "src", "link", "number", "type", "extensions", "trkseg"),
+	"trkseg":    setOf("trkpt", "extensions"),
+	"wpt":       wptElements,
+	"rtept":     wptElements,
+	"trkpt":     wptElements,
+}
+
+var wptElements = setOf("ele", "speed", "course", "time", "magvar", "geoidheight", "name", "cmt", "desc", "src", "link", "sym", "type", "fix", "sat", "hdop", "vdop", "pdop", "ageofdgpsdata", "dgpsid", "extensions")
+
+// repeatableElements maps the local names of GPX elements to the local names
+// of their known children that may occur more than once. All other known
+// children may occur at most once.
+var repeatableElements = map[string]map[string]bool{
+	"gpx":      setOf("wpt", "rte", "trk"),
+	"metadata": setOf("link"),
+	"rte":      setOf("link", "rtept"),
+	"trk":      setOf("link", "trkseg"),
+	"trkseg":   setOf("trkpt"),
+	"wpt":      wptRepeatableElements,
+	"rtept":    wptRepeatableElements,
+	"trkpt":    wptRepeatableElements,
+}
+
+var wptRepeatableElements = setOf("link")
+
+// tokenReaders maps the xml.Decoders created by ReadWithOptions to their
+// tokenReaders so that ExtensionsType.UnmarshalXML can recover the raw inner
+// XML, which encoding/xml does not provide for decoders created with
+// xml.NewTokenDecoder.
+var tokenReaders sync.Map
+
+// A tokenReader is an xml.TokenReader that enforces a context and
+// ParseOptions.
+type tokenReader struct {
+	ctx      context.Context //nolint:containedctx
+	d        *xml.Decoder
+	recorder *byteRecorder
+	options  *ParseOptions
+	points   int
+	elements int
+	depth    int
+	path     []string
+	// children contains, for each element in path, the local names of its
+	// known children seen so far that may occur at most once. It is only
+	// maintained if the DuplicateElementPolicy is not DuplicateLastWins.
+	children [][]string
+	// extensionsDepth is the depth of the outermost enclosing extensions
+	// element, or zero if there is none.
+	extensionsDepth int
+	// extensionsXML is the inner XML of the most recent outermost extensions
+	// element.
+	extensionsXML []byte
+}
+
+// An elementTokenReader is an xml.TokenReader that returns the tokens of a
+// single element from a tokenReader.
+type elementTokenReader struct {
+	r     *tokenReader
+	start *xml.StartElement
+	depth int
+}
+
+// A byteRecorder is an io.ByteReader that optionally records the bytes read.
+type byteRecorder struct {
+	r         io.ByteReader
+	recording bool
+	buf       []byte
+	// n is the number of bytes read since recording started.
+	n int
+	// lastLT is the offset of the last '<' read since recording started.
+	lastLT int
+	// maxLen is the maximum number of bytes recorded before the last '<', or
+	// zero if there is no limit.
+	maxLen int
+}
+
+// A limitReader is an io.Reader that returns an error wrapping
+// ErrLimitExceeded if more than max bytes are read from r.
+type limitReader struct {
+	r         io.Reader
+	max       int64
+	remaining int64
+}
+
+// ReadWithOptions reads a new GPX from r using options, which may be nil. ctx
+// is checked between XML tokens so that reading can be cancelled. If ctx is
+// done then ctx.Err() is returned. If a limit in options is exceeded then an
+// error wrapping ErrLimitExceeded is returned.
+func ReadWithOptions(ctx context.Context, r io.Reader, options *ParseOptions) (*GPX, error) {
+	if options == nil {
+		options = &ParseOptions{}
+	}
+	if options.PreviewStats != nil {
+		*options.PreviewStats = PreviewStats{}
+	}
+	if options.MaxBytes > 0 {
+		r = &limitReader{
+			r:         r,
+			max:       options.MaxBytes,
+			remaining: options.MaxBytes,
+		}
+	}
+	tr := &tokenReader{
+		ctx:      ctx,
+		recorder: newByteRecorder(r),
+		options:  options,
+	}
+	tr.d = xml.NewDecoder(tr.recorder)
+	charsetReader := options.CharsetReader
+	if charsetReader == nil {
+		charsetReader = charset.NewReaderLabel
+	}
+	tr.d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		r, err := charsetReader(label, input)
+		if err != nil {
+			return nil, err
+		}
+		// Record the decoded bytes, as encoding/xml does for innerxml.
+		tr.recorder = newByteRecorder(r)
+		return tr.recorder, nil
+	}
+	td := xml.NewTokenDecoder(tr)
+	tokenReaders.Store(td, tr)
+	defer tokenReaders.Delete(td)
+	gpx := &GPX{}
+	if err := td.Decode(gpx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	return gpx, nil
+}
+
+// Token implements xml.TokenReader.Token.
+func (r *tokenReader) Token() (xml.Token, error) {
+	for {
+		token, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		switch {
+		case ok && r.isUnknown():
+			if err := r.handleUnknownElement(start); err != nil {
+				return nil, err
+			}
+			continue
+		case ok && r.isDuplicate():
+			if r.options.DuplicateElementPolicy == DuplicateError {
+				return nil, fmt.Errorf("%w: /%s", ErrDuplicateElement, strings.Join(r.path, "/"))
+			}
+			if err := r.skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return token, nil
+	}
+}
+
+// isDuplicate returns whether the most recently started element repeats an
+// earlier sibling that the GPX schema allows at most once, and records it
+// otherwise.
+func (r *tokenReader) isDuplicate() bool {
+	if r.children == nil || r.extensionsDepth != 0 && r.extensionsDepth < r.depth || len(r.path) < 2 {
+		return false
+	}
+	parent, name := r.path[len(r.path)-2], r.path[len(r.path)-1]
+	if !knownElements[parent][name] || repeatableElements[parent][name] {
+		return false
+	}
+	siblings := &r.children[len(r.children)-2]
+	if slices.Contains(*siblings, name) {
+		return true
+	}
+	*siblings = append(*siblings, name)
+	return false
+}
+
+// skip skips the remaining tokens of the most recently started element.
+func (r *tokenReader) skip() error {
+	depth := r.depth
+	for r.depth >= depth {
+		if _, err := r.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUnknown returns whether the most recently started element is not part of
+// the GPX schema and should be passed to the UnknownElementHandler.
+func (r *tokenReader) isUnknown() bool {
+	if r.options.UnknownElementHandler == nil || r.extensionsDepth != 0 || len(r.path) < 2 {
+		return false
+	}
+	return !knownElements[r.path[len(r.path)-2]][r.path[len(r.path)-1]]
+}
+
+// handleUnknownElement passes start and its remaining tokens to the
+// UnknownElementHandler and then skips any unconsumed tokens.
+func (r *tokenReader) handleUnknownElement(start xml.StartElement) error {
+	path := "/" + strings.Join(r.path, "/")
+	dec := xml.NewTokenDecoder(&elementTokenReader{
+		r:     r,
+		start: &start,
+	})
+	// Consume start so that dec matches it with its end element.
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if err := r.options.UnknownElementHandler(path, dec, start); err != nil {
+		return err
+	}
+	for {
+		switch _, err := dec.Token(); {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// next returns the next token, enforcing r's context and limits.
+func (r *tokenReader) next() (xml.Token, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	token, err := r.d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case xml.StartElement:
+		r.elements++
+		if r.options.MaxElements > 0 && r.elements > r.options.MaxElements {
+			return nil, fmt.Errorf("%w: more than %d elements", ErrLimitExceeded, r.options.MaxElements)
+		}
+		r.depth++
+		if r.options.MaxDepth > 0 && r.depth > r.options.MaxDepth {
+			return nil, fmt.Errorf("%w: nesting deeper than %d", ErrLimitExceeded, r.options.MaxDepth)
+		}
+		r.path = append(r.path, token.Name.Local)
+		if r.options.DuplicateElementPolicy != DuplicateLastWins {
+			// Reuse the previously allocated slices of names.
+			if n := len(r.children); n < cap(r.children) {
+				r.children = r.children[:n+1]
+				r.children[n] = r.children[n][:0]
+			} else {
+				r.children = append(r.children, nil)
+			}
+		}
+		if token.Name.Local == "extensions" && r.extensionsDepth == 0 {
+			r.extensionsDepth = r.depth
+			r.recorder.start(r.options.MaxTextLen)
+		}
+		switch token.Name.Local {
+		case "wpt", "rtept", "trkpt":
+			r.points++
+			if r.options.MaxPoints > 0 && r.points > r.options.MaxPoints {
+				return nil, fmt.Errorf("%w: more than %d points", ErrLimitExceeded, r.options.MaxPoints)
+			}
+			if r.options.PreviewStats != nil && r.extensionsDepth == 0 {
+				r.options.PreviewStats.addPoint(token)
+			}
+		case "trk":
+			if r.options.PreviewStats != nil && len(r.path) == 2 {
+				r.options.PreviewStats.TrkPts = append(r.options.PreviewStats.TrkPts, []int{})
+			}
+		}
+		if r.options.MaxAttrs > 0 && len(token.Attr) > r.options.MaxAttrs {
+			return nil, fmt.Errorf("%w: %s element with more than %d attributes", ErrLimitExceeded, token.Name.Local, r.options.MaxAttrs)
+		}
+		if r.options.MaxAttrLen > 0 {
+			for _, attr := range token.Attr {
+				if len(attr.Value) > r.options.MaxAttrLen {
+					return nil, fmt.Errorf("%w: %s attribute longer than %d bytes", ErrLimitExceeded, attr.Name.Local, r.options.MaxAttrLen)
+				}
+			}
+		}
+	case xml.EndElement:
+		if r.depth == r.extensionsDepth {
+			r.extensionsDepth = 0
+			// Remove the end element.
+			r.extensionsXML = r.recorder.stop()
+		}
+		r.depth--
+		if len(r.path) > 0 {
+			r.path = r.path[:len(r.path)-1]
+		}
+		if len(r.children) > 0 {
+			r.children = r.children[:len(r.children)-1]
+		}
+	case xml.CharData:
+		if r.options.MaxTextLen > 0 && len(token) > r.options.MaxTextLen {
+			return nil, fmt.Errorf("%w: text longer than %d bytes", ErrLimitExceeded, r.options.MaxTextLen)
+		}
+	case xml.Comment:
+		if r.options.MaxTextLen > 0 && len(token) > r.options.MaxTextLen {
+			return nil, fmt.Errorf("%w: comment longer than %d bytes", ErrLimitExceeded, r.options.MaxTextLen)
+		}
+	}
+	return token, nil
+}
+
+// addPreviewTrkSeg records a track segment with n points in r's
+// PreviewStats, if any.
+func (r *tokenReader) addPreviewTrkSeg(n int) {
+	if stats := r.options.PreviewStats; stats != nil && len(stats.TrkPts) > 0 {
+		stats.TrkPts[len(stats.TrkPts)-1] = append(stats.TrkPts[len(stats.TrkPts)-1], n)
+	}
+}
+
+// addPoint adds the point with the given start element to s.
+func (s *PreviewStats) addPoint(start xml.StartElement) {
+	s.Points++
+	var lat, lon float64
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "lat":
+			lat, _ = parseFloat(attr.Value)
+		case "lon":
+			lon, _ = parseFloat(attr.Value)
+		}
+	}
+	if s.Bounds == nil {
+		s.Bounds = &BoundsType{MinLat: lat, MinLon: lon, MaxLat: lat, MaxLon: lon}
+		return
+	}
+	s.Bounds.MinLat = min(s.Bounds.MinLat, lat)
+	s.Bounds.MinLon = min(s.Bounds.MinLon, lon)
+	s.Bounds.MaxLat = max(s.Bounds.MaxLat, lat)
+	s.Bounds.MaxLon = max(s.Bounds.MaxLon, lon)
+}
+
+// Token implements xml.TokenReader.Token.
+func (r *elementTokenReader) Token() (xml.Token, error) {
+	if r.start != nil {
+		start := *r.start
+		r.start = nil
+		r.depth = 1
+		return start, nil
+	}
+	if r.depth == 0 {
+		return nil, io.EOF
+	}
+	token, err := r.r.next()
+	if err != nil {
+		return nil, err
+	}
+	switch token.(type) {
+	case xml.StartElement:
+		r.depth++
+	case xml.EndElement:
+		r.depth--
+	}
+	return token, nil
+}
+
+// setOf returns a set containing elements.
+func setOf(elements ...string) map[string]bool {
+	set := make(map[string]bool, len(elements))
+	for _, element := range elements {
+		set[element] = true
+	}
+	return set
+}
+
+// newByteRecorder returns a new byteRecorder that reads from r.
+func newByteRecorder(r io.Reader) *byteRecorder {
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = bufio.NewReader(r)
+	}
+	return &byteRecorder{
+		r: byteReader,
+	}
+}
+
+// Read implements io.Reader.Read.
+func (r *byteRecorder) Read(p []byte) (int, error) {
+	for i := range p {
+		b, err := r.ReadByte()
+		if err != nil {
+			return i, err
+		}
+		p[i] = b
+	}
+	return len(p), nil
+}
+
+// ReadByte implements io.ByteReader.ReadByte.
+func (r *byteRecorder) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err != nil || !r.recording {
+		return b, err
+	}
+	if b == '<' {
+		if r.maxLen > 0 && r.n > r.maxLen {
+			return 0, fmt.Errorf("%w: extensions longer than %d bytes", ErrLimitExceeded, r.maxLen)
+		}
+		r.lastLT = r.n
+	}
+	// Bytes after maxLen can only be part of the end element, so they need
+	// not be recorded.
+	if r.maxLen == 0 || len(r.buf) < r.maxLen {
+		r.buf = append(r.buf, b)
+	}
+	r.n++
+	return b, nil
+}
+
+// start starts recording. If maxLen is positive then reading fails if more
+// than maxLen bytes are read before the last '<'.
+func (r *byteRecorder) start(maxLen int) {
+	r.recording = true
+	r.buf = nil
+	r.n = 0
+	r.lastLT = 0
+	r.maxLen = maxLen
+}
+
+// stop stops recording and returns the bytes read since start was called, up
+// to but not including the last '<'.
+func (r *byteRecorder) stop() []byte {
+	r.recording = false
+	buf := r.buf[:r.lastLT]
+	r.buf = nil
+	return buf
+}
+
+// Read implements io.Reader.Read.
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// Only fail if there is more input.
+		var b [1]byte
+		if n, err := r.r.Read(b[:]); n == 0 {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrLimitExceeded, r.max)
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// ReadAll reads a GPX from each of readers concurrently using up to workers
+// goroutines, or runtime.GOMAXPROCS(0) goroutines if workers is not positive.
+// The results are returned in the same order as readers. If any read fails
+// then the remaining reads are cancelled and the first error is returned.
+func ReadAll(ctx context.Context, readers []io.Reader, workers int) ([]*GPX, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(readers) {
+		workers = len(readers)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	gpxs := make([]*GPX, len(readers))
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				g, err := ReadContext(ctx, readers[index])
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("reader %d: %w", index, err)
+						cancel()
+					})
+					continue
+				}
+				gpxs[index] = g
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indexes)
+		for index := range readers {
+			select {
+			case <-ctx.Done():
+				return
+			case indexes <- index:
+			}
+		}
+	}()
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return gpxs, nil
+}
@@ -0,0 +1,357 @@
+package gpx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestReadWithOptionsLimits(t *testing.T) {
+	data, err := os.ReadFile("testdata/fells_loop.gpx")
+	assert.NoError(t, err)
+
+	for i, tc := range []struct {
+		options     *gpx.ParseOptions
+		expectedErr string
+	}{
+		{
+			options: nil,
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxPoints:   1000,
+				MaxElements: 10000,
+				MaxDepth:    4,
+				MaxAttrLen:  1024,
+			},
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxPoints: 10,
+			},
+			expectedErr: "limit exceeded: more than 10 points",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxElements: 10,
+			},
+			expectedErr: "limit exceeded: more than 10 elements",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxDepth: 2,
+			},
+			expectedErr: "limit exceeded: nesting deeper than 2",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxAttrLen: 8,
+			},
+			expectedErr: "limit exceeded: creator attribute longer than 8 bytes",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxAttrs: 2,
+			},
+			expectedErr: "limit exceeded: gpx element with more than 2 attributes",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxTextLen: 8,
+			},
+			expectedErr: "limit exceeded: text longer than 8 bytes",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxBytes: 1024,
+			},
+			expectedErr: "limit exceeded: more than 1024 bytes",
+		},
+		{
+			options: &gpx.ParseOptions{
+				MaxBytes: int64(len(data)),
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := gpx.ReadWithOptions(context.Background(), strings.NewReader(string(data)), tc.options)
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, gpx.ErrLimitExceeded)
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, g)
+		})
+	}
+}
+
+func TestReadWithOptionsMaxTextLen(t *testing.T) {
+	for i, tc := range []struct {
+		data        string
+		expectedErr string
+	}{
+		{
+			data: `<gpx><metadata><desc>` + strings.Repeat("x", 64) + `</desc></metadata></gpx>`,
+		},
+		{
+			data:        `<gpx><metadata><desc>` + strings.Repeat("x", 65) + `</desc></metadata></gpx>`,
+			expectedErr: "limit exceeded: text longer than 64 bytes",
+		},
+		{
+			data:        `<gpx><!--` + strings.Repeat("x", 65) + `--></gpx>`,
+			expectedErr: "limit exceeded: comment longer than 64 bytes",
+		},
+		{
+			data: `<gpx><extensions>` + strings.Repeat("<a>x</a>", 8) + `</extensions></gpx>`,
+		},
+		{
+			data:        `<gpx><extensions>` + strings.Repeat("<a>x</a>", 9) + `</extensions></gpx>`,
+			expectedErr: "limit exceeded: extensions longer than 64 bytes",
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := gpx.ReadWithOptions(context.Background(), strings.NewReader(tc.data), &gpx.ParseOptions{
+				MaxTextLen: 64,
+			})
+			if tc.expectedErr != "" {
+				assert.ErrorIs(t, err, gpx.ErrLimitExceeded)
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	var readers []io.Reader
+	var expected []*gpx.GPX
+	for _, name := range []string{"ashland", "fells_loop", "mystic_basin_trail", "fells_loop", "ashland"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name+".gpx"))
+		assert.NoError(t, err)
+		g, err := gpx.Read(bytes.NewReader(data))
+		assert.NoError(t, err)
+		readers = append(readers, bytes.NewReader(data))
+		expected = append(expected, g)
+	}
+
+	got, err := gpx.ReadAll(context.Background(), readers, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+
+	got, err = gpx.ReadAll(context.Background(), []io.Reader{strings.NewReader("<gpx></gpx>"), strings.NewReader("<gpx>")}, 0)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestReadWithOptionsUnknownElementHandler(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <trkseg>
+      <trkpt lat="1" lon="2">
+        <ele>3</ele>
+        <accuracy><horizontal>4.5</horizontal></accuracy>
+        <extensions><accuracy>6</accuracy></extensions>
+        <name>a</name>
+      </trkpt>
+      <trkpt lat="3" lon="4">
+        <accuracy><horizontal>7.5</horizontal></accuracy>
+        <battery>80</battery>
+      </trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+	type accuracy struct {
+		Horizontal float64 `xml:"horizontal"`
+	}
+	var paths []string
+	var accuracies []accuracy
+	g, err := gpx.ReadWithOptions(context.Background(), strings.NewReader(data), &gpx.ParseOptions{
+		UnknownElementHandler: func(path string, dec *xml.Decoder, start xml.StartElement) error {
+			paths = append(paths, path)
+			if start.Name.Local != "accuracy" {
+				return nil
+			}
+			var a accuracy
+			if err := dec.DecodeElement(&a, &start); err != nil {
+				return err
+			}
+			accuracies = append(accuracies, a)
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/gpx/trk/trkseg/trkpt/accuracy",
+		"/gpx/trk/trkseg/trkpt/accuracy",
+		"/gpx/trk/trkseg/trkpt/battery",
+	}, paths)
+	assert.Equal(t, []accuracy{{Horizontal: 4.5}, {Horizontal: 7.5}}, accuracies)
+	assert.Equal(t, []*gpx.WptType{
+		{
+			Lat:        1,
+			Lon:        2,
+			Ele:        3,
+			Name:       "a",
+			Extensions: &gpx.ExtensionsType{XML: []byte("<accuracy>6</accuracy>")},
+		},
+		{
+			Lat: 3,
+			Lon: 4,
+		},
+	}, g.Trk[0].TrkSeg[0].TrkPt)
+
+	errHandler := errors.New("handler")
+	_, err = gpx.ReadWithOptions(context.Background(), strings.NewReader(data), &gpx.ParseOptions{
+		UnknownElementHandler: func(string, *xml.Decoder, xml.StartElement) error {
+			return errHandler
+		},
+	})
+	assert.ErrorIs(t, err, errHandler)
+}
+
+func TestReadWithOptionsDuplicateElementPolicy(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <metadata>
+    <name>first</name>
+    <author><name>Alice</name></author>
+  </metadata>
+  <metadata>
+    <name>second</name>
+  </metadata>
+  <wpt lat="1" lon="2">
+    <name>a</name>
+    <link href="https://example.com/1"/>
+    <name>b</name>
+    <link href="https://example.com/2"/>
+    <extensions><x>1</x></extensions>
+    <extensions><x>2</x></extensions>
+  </wpt>
+  <trk>
+    <trkseg>
+      <trkpt lat="3" lon="4"><ele>5</ele></trkpt>
+      <trkpt lat="5" lon="6"><ele>6</ele><ele>7</ele></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+	for i, tc := range []struct {
+		policy           gpx.DuplicateElementPolicy
+		expectedErr      error
+		expectedMetadata *gpx.MetadataType
+		expectedName     string
+		expectedXML      string
+		expectedEle      float64
+	}{
+		{
+			policy:           gpx.DuplicateLastWins,
+			expectedMetadata: &gpx.MetadataType{Name: "second"},
+			expectedName:     "b",
+			expectedXML:      "<x>2</x>",
+			expectedEle:      7,
+		},
+		{
+			policy:           gpx.DuplicateFirstWins,
+			expectedMetadata: &gpx.MetadataType{Name: "first", Author: &gpx.PersonType{Name: "Alice"}},
+			expectedName:     "a",
+			expectedXML:      "<x>1</x>",
+			expectedEle:      6,
+		},
+		{
+			policy:      gpx.DuplicateError,
+			expectedErr: gpx.ErrDuplicateElement,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g, err := gpx.ReadWithOptions(context.Background(), strings.NewReader(data), &gpx.ParseOptions{
+				DuplicateElementPolicy: tc.policy,
+			})
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorContains(t, err, "/gpx/metadata")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMetadata, g.Metadata)
+			assert.Len(t, g.Wpt, 1)
+			assert.Equal(t, tc.expectedName, g.Wpt[0].Name)
+			assert.Len(t, g.Wpt[0].Link, 2)
+			assert.Equal(t, tc.expectedXML, string(g.Wpt[0].Extensions.XML))
+			assert.Len(t, g.Trk[0].TrkSeg[0].TrkPt, 2)
+			assert.Equal(t, 5.0, g.Trk[0].TrkSeg[0].TrkPt[0].Ele)
+			assert.Equal(t, tc.expectedEle, g.Trk[0].TrkSeg[0].TrkPt[1].Ele)
+		})
+	}
+
+	_, err := gpx.ReadWithOptions(context.Background(), strings.NewReader(`<gpx version="1.1"><wpt lat="1" lon="2"><name>a</name><name>b</name></wpt></gpx>`), &gpx.ParseOptions{
+		DuplicateElementPolicy: gpx.DuplicateError,
+	})
+	assert.ErrorIs(t, err, gpx.ErrDuplicateElement)
+	assert.ErrorContains(t, err, "/gpx/wpt/name")
+
+	assert.Equal(t, "first-wins", gpx.DuplicateFirstWins.String())
+}
+
+func TestReadWithOptionsPreviewPoints(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`<gpx version="1.1"><wpt lat="-1" lon="50"/><trk><name>a</name><trkseg>`)
+	for i := range 1000 {
+		fmt.Fprintf(&b, `<trkpt lat="%d" lon="%d"><ele>%d</ele></trkpt>`, i%90, i%7, i)
+	}
+	b.WriteString(`<extensions><x/></extensions></trkseg><trkseg><trkpt lat="1" lon="2"/></trkseg></trk><trk><trkseg/></trk></gpx>`)
+
+	for i, tc := range []struct {
+		previewPoints int
+		minPoints     int
+		maxPoints     int
+	}{
+		{previewPoints: 0, minPoints: 1000, maxPoints: 1000},
+		{previewPoints: 2, minPoints: 2, maxPoints: 2},
+		{previewPoints: 100, minPoints: 50, maxPoints: 100},
+		{previewPoints: 999, minPoints: 500, maxPoints: 999},
+		{previewPoints: 1000, minPoints: 1000, maxPoints: 1000},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var stats gpx.PreviewStats
+			g, err := gpx.ReadWithOptions(context.Background(), strings.NewReader(b.String()), &gpx.ParseOptions{
+				PreviewPoints: tc.previewPoints,
+				PreviewStats:  &stats,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "a", g.Trk[0].Name)
+			trkPts := g.Trk[0].TrkSeg[0].TrkPt
+			assert.GreaterOrEqual(t, len(trkPts), tc.minPoints)
+			assert.LessOrEqual(t, len(trkPts), tc.maxPoints)
+			assert.Equal(t, 0.0, trkPts[0].Ele)
+			assert.Equal(t, 999.0, trkPts[len(trkPts)-1].Ele)
+			for j := 1; j < len(trkPts); j++ {
+				assert.Less(t, trkPts[j-1].Ele, trkPts[j].Ele)
+			}
+			assert.Equal(t, []byte("<x/>"), g.Trk[0].TrkSeg[0].Extensions.XML)
+			assert.Len(t, g.Trk[0].TrkSeg[1].TrkPt, 1)
+			assert.Len(t, g.Wpt, 1)
+
+			assert.Equal(t, gpx.PreviewStats{
+				Points: 1002,
+				TrkPts: [][]int{{1000, 1}, {0}},
+				Bounds: &gpx.BoundsType{MinLat: -1, MinLon: 0, MaxLat: 89, MaxLon: 50},
+			}, stats)
+		})
+	}
+}
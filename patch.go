@@ -0,0 +1,283 @@
+package gpx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchVersion is the version of the patch format written by CreatePatch.
+const PatchVersion = 1
+
+// maxPatchDiffCells is the largest number of cells in the table used to find
+// the minimal difference between two point lists. Larger differences are
+// found greedily.
+const maxPatchDiffCells = 1 << 22
+
+// A PatchOpKind is a kind of patch operation.
+type PatchOpKind string
+
+// Patch operation kinds.
+const (
+	PatchOpSetMetadata PatchOpKind = "set-metadata"
+	PatchOpDelete      PatchOpKind = "delete"
+	PatchOpInsert      PatchOpKind = "insert"
+	PatchOpSetRtes     PatchOpKind = "set-rtes"
+	PatchOpSetTrks     PatchOpKind = "set-trks"
+)
+
+// A PatchOp is a single edit to a GPX document.
+type PatchOp struct {
+	Op PatchOpKind `json:"op"`
+	// List identifies the point list of delete and insert operations:
+	// "wpt" for waypoints, "rte/i" for the points of the ith route, and
+	// "trk/i/j" for the points of the jth segment of the ith track.
+	List string `json:"list,omitempty"`
+	// Index is the index in List of the point to delete or insert, after all
+	// previous operations have been applied.
+	Index int `json:"index"`
+	// Point is the point to insert.
+	Point *WptType `json:"point,omitempty"`
+	// Metadata is the new metadata of set-metadata operations.
+	Metadata *MetadataType `json:"metadata,omitempty"`
+	// Rte and Trk are the new routes and tracks of set-rtes and set-trks
+	// operations, used when the structure of the routes or tracks changes.
+	Rte []*RteType `json:"rte,omitempty"`
+	Trk []*TrkType `json:"trk,omitempty"`
+}
+
+// A Patch is a serializable set of edits to a GPX document.
+type Patch struct {
+	Version int `json:"version"`
+	// Base, if not empty, is the hex-encoded fingerprint of the points of the
+	// document to which the patch applies.
+	Base string    `json:"base,omitempty"`
+	Ops  []PatchOp `json:"ops"`
+}
+
+// CreatePatch returns a Patch that transforms old into new. Changed points
+// are expressed as deletions and insertions.
+func CreatePatch(old, new *GPX) *Patch {
+	patch := &Patch{
+		Version: PatchVersion,
+		Base:    old.Fingerprint(exactFingerprintOptions),
+		Ops:     []PatchOp{},
+	}
+	if !reflect.DeepEqual(old.Metadata, new.Metadata) {
+		patch.Ops = append(patch.Ops, PatchOp{Op: PatchOpSetMetadata, Metadata: new.Metadata})
+	}
+	patch.Ops = appendPointListDiff(patch.Ops, "wpt", old.Wpt, new.Wpt)
+
+	if sameRteStructure(old.Rte, new.Rte) {
+		for i := range old.Rte {
+			patch.Ops = appendPointListDiff(patch.Ops, "rte/"+strconv.Itoa(i), old.Rte[i].RtePt, new.Rte[i].RtePt)
+		}
+	} else {
+		patch.Ops = append(patch.Ops, PatchOp{Op: PatchOpSetRtes, Rte: new.Rte})
+	}
+
+	if sameTrkStructure(old.Trk, new.Trk) {
+		for i := range old.Trk {
+			for j := range old.Trk[i].TrkSeg {
+				patch.Ops = appendPointListDiff(patch.Ops, "trk/"+strconv.Itoa(i)+"/"+strconv.Itoa(j), old.Trk[i].TrkSeg[j].TrkPt, new.Trk[i].TrkSeg[j].TrkPt)
+			}
+		}
+	} else {
+		patch.Ops = append(patch.Ops, PatchOp{Op: PatchOpSetTrks, Trk: new.Trk})
+	}
+	return patch
+}
+
+// ApplyPatch applies patch to g. If patch has a base that does not match g
+// then g is not modified and an error is returned. If an operation is invalid
+// then the error is returned and g is left partially patched.
+func ApplyPatch(g *GPX, patch *Patch) error {
+	if patch.Version != PatchVersion {
+		return fmt.Errorf("%d: unsupported patch version", patch.Version)
+	}
+	if patch.Base != "" {
+		if patch.Base != g.Fingerprint(exactFingerprintOptions) {
+			return fmt.Errorf("%s: patch base does not match document", patch.Base)
+		}
+	}
+	for i, op := range patch.Ops {
+		if err := g.applyPatchOp(op); err != nil {
+			return fmt.Errorf("op %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// applyPatchOp applies op to g.
+func (g *GPX) applyPatchOp(op PatchOp) error {
+	switch op.Op {
+	case PatchOpSetMetadata:
+		g.Metadata = op.Metadata
+	case PatchOpSetRtes:
+		g.Rte = op.Rte
+	case PatchOpSetTrks:
+		g.Trk = op.Trk
+	case PatchOpDelete, PatchOpInsert:
+		list, err := g.pointList(op.List)
+		if err != nil {
+			return err
+		}
+		if op.Op == PatchOpDelete {
+			if op.Index < 0 || op.Index >= len(*list) {
+				return fmt.Errorf("%s: %d: index out of range", op.List, op.Index)
+			}
+			*list = append((*list)[:op.Index], (*list)[op.Index+1:]...)
+			return nil
+		}
+		if op.Index < 0 || op.Index > len(*list) {
+			return fmt.Errorf("%s: %d: index out of range", op.List, op.Index)
+		}
+		if op.Point == nil {
+			return fmt.Errorf("%s: %d: no point", op.List, op.Index)
+		}
+		*list = append(*list, nil)
+		copy((*list)[op.Index+1:], (*list)[op.Index:])
+		(*list)[op.Index] = op.Point
+	default:
+		return fmt.Errorf("%s: unknown patch operation", op.Op)
+	}
+	return nil
+}
+
+// pointList returns a pointer to the point list in g identified by name.
+func (g *GPX) pointList(name string) (*[]*WptType, error) {
+	fields := strings.Split(name, "/")
+	indexes := make([]int, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		index, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid point list", name)
+		}
+		indexes = append(indexes, index)
+	}
+	switch {
+	case fields[0] == "wpt" && len(indexes) == 0:
+		return &g.Wpt, nil
+	case fields[0] == "rte" && len(indexes) == 1:
+		if indexes[0] < 0 || indexes[0] >= len(g.Rte) {
+			return nil, fmt.Errorf("%s: route out of range", name)
+		}
+		return &g.Rte[indexes[0]].RtePt, nil
+	case fields[0] == "trk" && len(indexes) == 2:
+		if indexes[0] < 0 || indexes[0] >= len(g.Trk) {
+			return nil, fmt.Errorf("%s: track out of range", name)
+		}
+		trk := g.Trk[indexes[0]]
+		if indexes[1] < 0 || indexes[1] >= len(trk.TrkSeg) {
+			return nil, fmt.Errorf("%s: track segment out of range", name)
+		}
+		return &trk.TrkSeg[indexes[1]].TrkPt, nil
+	default:
+		return nil, fmt.Errorf("%s: invalid point list", name)
+	}
+}
+
+// sameRteStructure returns whether a and b have the same number of routes
+// with the same properties, ignoring their points.
+func sameRteStructure(a, b []*RteType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		aRte, bRte := *a[i], *b[i]
+		aRte.RtePt, bRte.RtePt = nil, nil
+		if !reflect.DeepEqual(aRte, bRte) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameTrkStructure returns whether a and b have the same number of tracks
+// with the same properties and the same number of segments with the same
+// extensions, ignoring their points.
+func sameTrkStructure(a, b []*TrkType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		aTrk, bTrk := *a[i], *b[i]
+		aTrk.TrkSeg, bTrk.TrkSeg = nil, nil
+		if !reflect.DeepEqual(aTrk, bTrk) || len(a[i].TrkSeg) != len(b[i].TrkSeg) {
+			return false
+		}
+		for j := range a[i].TrkSeg {
+			if !reflect.DeepEqual(a[i].TrkSeg[j].Extensions, b[i].TrkSeg[j].Extensions) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// appendPointListDiff appends to ops the deletions and insertions that
+// transform the point list a, called list, into b.
+func appendPointListDiff(ops []PatchOp, list string, a, b []*WptType) []PatchOp {
+	return appendPointListDiffFunc(ops, list, a, b, func(p, q *WptType) bool {
+		return reflect.DeepEqual(p, q)
+	})
+}
+
+// appendPointListDiffFunc is like appendPointListDiff but uses equalWpt to
+// compare points.
+func appendPointListDiffFunc(ops []PatchOp, list string, a, b []*WptType, equalWpt func(p, q *WptType) bool) []PatchOp {
+	equal := func(i, j int) bool {
+		return equalWpt(a[i], b[j])
+	}
+
+	// Trim the common prefix and suffix.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && equal(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && equal(len(a)-1-suffix, len(b)-1-suffix) {
+		suffix++
+	}
+	n, m := len(a)-prefix-suffix, len(b)-prefix-suffix
+
+	// lcs returns the length of the longest common subsequence of
+	// a[prefix+i:prefix+n] and b[prefix+j:prefix+m]. If the table is too
+	// large then lcs is nil and points are deleted until they match.
+	var lcs func(i, j int) int32
+	if (n+1)*(m+1) <= maxPatchDiffCells {
+		table := make([]int32, (n+1)*(m+1))
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if equal(prefix+i, prefix+j) {
+					table[i*(m+1)+j] = table[(i+1)*(m+1)+j+1] + 1
+				} else {
+					table[i*(m+1)+j] = max(table[(i+1)*(m+1)+j], table[i*(m+1)+j+1])
+				}
+			}
+		}
+		lcs = func(i, j int) int32 {
+			return table[i*(m+1)+j]
+		}
+	}
+
+	index := prefix
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && equal(prefix+i, prefix+j):
+			index++
+			i++
+			j++
+		case i < n && (j == m || lcs == nil || lcs(i+1, j) >= lcs(i, j+1)):
+			ops = append(ops, PatchOp{Op: PatchOpDelete, List: list, Index: index})
+			i++
+		default:
+			ops = append(ops, PatchOp{Op: PatchOpInsert, List: list, Index: index, Point: b[prefix+j]})
+			index++
+			j++
+		}
+	}
+	return ops
+}
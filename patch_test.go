@@ -0,0 +1,110 @@
+package gpx_test
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPatch(t *testing.T) {
+	read := func() *gpx.GPX {
+		f, err := os.Open("testdata/mystic_basin_trail.gpx")
+		assert.NoError(t, err)
+		defer f.Close()
+		g, err := gpx.Read(f)
+		assert.NoError(t, err)
+		return g
+	}
+
+	for i, tc := range []struct {
+		edit        func(*gpx.GPX)
+		expectedOps int
+	}{
+		{
+			edit:        func(*gpx.GPX) {},
+			expectedOps: 0,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Metadata.Name = "Renamed"
+			},
+			expectedOps: 1,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				ts := g.Trk[0].TrkSeg[0]
+				ts.TrkPt = append(ts.TrkPt[:10:10], ts.TrkPt[12:]...)
+				ts.TrkPt = ts.TrkPt[1:]
+			},
+			expectedOps: 3,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Wpt = append(g.Wpt, &gpx.WptType{Lat: 1, Lon: 2, Name: "New"})
+				g.Wpt[0] = &gpx.WptType{Lat: 3, Lon: 4}
+			},
+			expectedOps: 3,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk = append(g.Trk, &gpx.TrkType{Name: "Extra"})
+			},
+			expectedOps: 1,
+		},
+		{
+			edit: func(g *gpx.GPX) {
+				g.Trk[0].Name = "Renamed"
+			},
+			expectedOps: 1,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			old, expected := read(), read()
+			tc.edit(expected)
+
+			patch := gpx.CreatePatch(old, expected)
+			assert.Equal(t, gpx.PatchVersion, patch.Version)
+			assert.Len(t, patch.Ops, tc.expectedOps)
+
+			data, err := json.Marshal(patch)
+			assert.NoError(t, err)
+			var decoded gpx.Patch
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+
+			got := read()
+			assert.NoError(t, gpx.ApplyPatch(got, &decoded))
+			assert.Equal(t, expected, got)
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{{Lat: 1}},
+	}
+	other := &gpx.GPX{
+		Wpt: []*gpx.WptType{{Lat: 2}},
+	}
+	patch := gpx.CreatePatch(other, &gpx.GPX{})
+	assert.Error(t, gpx.ApplyPatch(g, patch))
+	assert.Equal(t, 1.0, g.Wpt[0].Lat)
+
+	for i, patch := range []*gpx.Patch{
+		{Version: 2},
+		{Version: 1, Ops: []gpx.PatchOp{{Op: "unknown"}}},
+		{Version: 1, Ops: []gpx.PatchOp{{Op: gpx.PatchOpDelete, List: "wpt", Index: 1}}},
+		{Version: 1, Ops: []gpx.PatchOp{{Op: gpx.PatchOpInsert, List: "wpt", Index: 0}}},
+		{Version: 1, Ops: []gpx.PatchOp{{Op: gpx.PatchOpDelete, List: "rte/0"}}},
+		{Version: 1, Ops: []gpx.PatchOp{{Op: gpx.PatchOpDelete, List: "trk/x/0"}}},
+		{Version: 1, Ops: []gpx.PatchOp{{Op: gpx.PatchOpDelete, List: "wpt/0"}}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Error(t, gpx.ApplyPatch(g, patch))
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Package pg generates rows for bulk loading GPX tracks into PostgreSQL with
+// PostGIS, for example with COPY.
+package pg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+// SRID is the spatial reference identifier of generated geometries, WGS 84.
+const SRID = 4326
+
+// Columns are the names of the columns of a Row, in the order returned by
+// Row.Values.
+var Columns = []string{
+	"track_id",
+	"segment_index",
+	"point_index",
+	"geom",
+	"time",
+	"ele",
+	"extensions",
+}
+
+// A Row is a single track point.
+type Row struct {
+	TrackID      int64
+	SegmentIndex int
+	PointIndex   int
+	// Geom is the point's location as an EWKB Point with SRID 4326.
+	Geom []byte
+	// Time is the point's time, or nil if the point has no time.
+	Time *time.Time
+	// Ele is the point's elevation, or nil if the point has no elevation.
+	Ele *float64
+	// Extensions is the point's extensions as a JSON object, or nil if the
+	// point has no extensions.
+	Extensions []byte
+}
+
+// TrackRows returns the rows for each point in trk, which is identified by
+// trackID.
+func TrackRows(trackID int64, trk *gpx.TrkType) ([]*Row, error) {
+	var rows []*Row
+	for segmentIndex, trkSeg := range trk.TrkSeg {
+		for pointIndex, trkPt := range trkSeg.TrkPt {
+			row, err := newRow(trackID, segmentIndex, pointIndex, trkPt)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// Values returns r's values in the order of Columns, suitable for passing to
+// a COPY implementation such as pgx's CopyFromRows.
+func (r *Row) Values() []any {
+	values := []any{
+		r.TrackID,
+		r.SegmentIndex,
+		r.PointIndex,
+		r.Geom,
+		nil,
+		nil,
+		nil,
+	}
+	if r.Time != nil {
+		values[4] = *r.Time
+	}
+	if r.Ele != nil {
+		values[5] = *r.Ele
+	}
+	if r.Extensions != nil {
+		values[6] = string(r.Extensions)
+	}
+	return values
+}
+
+// WriteText writes rows to w in PostgreSQL's COPY text format, with geometries
+// as hex-encoded EWKB, suitable for COPY ... FROM STDIN.
+func WriteText(w io.Writer, rows []*Row) error {
+	sb := &strings.Builder{}
+	for _, r := range rows {
+		sb.Reset()
+		sb.WriteString(strconv.FormatInt(r.TrackID, 10))
+		sb.WriteByte('\t')
+		sb.WriteString(strconv.Itoa(r.SegmentIndex))
+		sb.WriteByte('\t')
+		sb.WriteString(strconv.Itoa(r.PointIndex))
+		sb.WriteByte('\t')
+		sb.WriteString(hex.EncodeToString(r.Geom))
+		sb.WriteByte('\t')
+		if r.Time != nil {
+			sb.WriteString(r.Time.UTC().Format(time.RFC3339Nano))
+		} else {
+			sb.WriteString(`\N`)
+		}
+		sb.WriteByte('\t')
+		if r.Ele != nil {
+			sb.WriteString(strconv.FormatFloat(*r.Ele, 'f', -1, 64))
+		} else {
+			sb.WriteString(`\N`)
+		}
+		sb.WriteByte('\t')
+		if r.Extensions != nil {
+			sb.WriteString(escapeText(string(r.Extensions)))
+		} else {
+			sb.WriteString(`\N`)
+		}
+		sb.WriteByte('\n')
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRow returns a new Row for wpt.
+func newRow(trackID int64, segmentIndex, pointIndex int, wpt *gpx.WptType) (*Row, error) {
+	g, err := ewkb.Marshal(geom.NewPointFlat(geom.XY, []float64{wpt.Lon, wpt.Lat}).SetSRID(SRID), ewkb.NDR)
+	if err != nil {
+		return nil, err
+	}
+	row := &Row{
+		TrackID:      trackID,
+		SegmentIndex: segmentIndex,
+		PointIndex:   pointIndex,
+		Geom:         g,
+	}
+	if !wpt.Time.IsZero() {
+		t := wpt.Time
+		row.Time = &t
+	}
+	if wpt.Has(gpx.WptEle) {
+		ele := wpt.Ele
+		row.Ele = &ele
+	}
+	if wpt.Extensions != nil {
+		row.Extensions, err = extensionsJSON(wpt.Extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// extensionsJSON returns extensions as a JSON object. Elements are keyed by
+// their local names. Elements containing only text become strings and other
+// elements become nested objects.
+func extensionsJSON(extensions *gpx.ExtensionsType) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(extensions.XML))
+	object, err := decodeObject(d)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(object)
+}
+
+// decodeObject decodes elements from d until the end of the enclosing element
+// or the end of input.
+func decodeObject(d *xml.Decoder) (map[string]any, error) {
+	object := make(map[string]any)
+	for {
+		token, err := d.Token()
+		switch {
+		case errors.Is(err, io.EOF):
+			return object, nil
+		case err != nil:
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			value, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			object[token.Name.Local] = value
+		case xml.EndElement:
+			return object, nil
+		}
+	}
+}
+
+// decodeValue decodes the contents of the current element from d.
+func decodeValue(d *xml.Decoder) (any, error) {
+	sb := &strings.Builder{}
+	var object map[string]any
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			sb.Write(token)
+		case xml.StartElement:
+			if object == nil {
+				object = make(map[string]any)
+			}
+			value, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			object[token.Name.Local] = value
+		case xml.EndElement:
+			if object != nil {
+				return object, nil
+			}
+			return strings.TrimSpace(sb.String()), nil
+		}
+	}
+}
+
+// escapeText escapes s for PostgreSQL's COPY text format.
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"\t", `\t`,
+		"\n", `\n`,
+		"\r", `\r`,
+	).Replace(s)
+}
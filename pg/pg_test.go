@@ -0,0 +1,92 @@
+package pg_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+
+	gpx "github.com/twpayne/go-gpx"
+	"github.com/twpayne/go-gpx/pg"
+)
+
+func TestTrackRows(t *testing.T) {
+	tm := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{
+				TrkPt: []*gpx.WptType{
+					{
+						Lat:  46.5,
+						Lon:  7.25,
+						Ele:  1000,
+						Time: tm,
+						Extensions: &gpx.ExtensionsType{
+							XML: []byte("<gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr><gpxtpx:cad>\t90</gpxtpx:cad></gpxtpx:TrackPointExtension>"),
+						},
+					},
+				},
+			},
+			{
+				TrkPt: []*gpx.WptType{
+					{
+						Lat: 46.6,
+						Lon: 7.35,
+					},
+					{
+						Lat:        46.7,
+						Lon:        7.45,
+						ZeroFields: gpx.WptEle,
+					},
+				},
+			},
+		},
+	}
+
+	rows, err := pg.TrackRows(42, trk)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	g, err := ewkb.Unmarshal(rows[0].Geom)
+	assert.NoError(t, err)
+	assert.Equal(t, geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{7.25, 46.5}).SetSRID(pg.SRID), g)
+	assert.Equal(t, []any{
+		int64(42),
+		0,
+		0,
+		rows[0].Geom,
+		tm,
+		1000.0,
+		`{"TrackPointExtension":{"cad":"90","hr":"150"}}`,
+	}, rows[0].Values())
+	assert.Equal(t, []any{
+		int64(42),
+		1,
+		0,
+		rows[1].Geom,
+		nil,
+		nil,
+		nil,
+	}, rows[1].Values())
+	assert.Equal(t, []any{
+		int64(42),
+		1,
+		1,
+		rows[2].Geom,
+		nil,
+		0.0,
+		nil,
+	}, rows[2].Values())
+
+	sb := &strings.Builder{}
+	assert.NoError(t, pg.WriteText(sb, rows))
+	assert.Equal(t, ""+
+		"42\t0\t0\t"+hex.EncodeToString(rows[0].Geom)+"\t2023-07-01T10:00:00Z\t1000\t{\"TrackPointExtension\":{\"cad\":\"90\",\"hr\":\"150\"}}\n"+
+		"42\t1\t0\t"+hex.EncodeToString(rows[1].Geom)+"\t\\N\t\\N\t\\N\n"+
+		"42\t1\t1\t"+hex.EncodeToString(rows[2].Geom)+"\t\\N\t0\t\\N\n",
+		sb.String())
+}
@@ -0,0 +1,41 @@
+package gpx
+
+import (
+	"context"
+	"time"
+)
+
+// Playback returns a channel on which the points of ts are sent at intervals
+// matching their timestamps, scaled by speedFactor. A speedFactor of 2 plays
+// back twice as fast as real time. Points without a timestamp are sent
+// immediately. The channel is closed when all points have been sent or ctx is
+// done.
+func Playback(ctx context.Context, ts *TrkSegType, speedFactor float64) <-chan *WptType {
+	ch := make(chan *WptType)
+	go func() {
+		defer close(ch)
+		var prevTime time.Time
+		for _, tp := range ts.TrkPt {
+			if !prevTime.IsZero() && !tp.Time.IsZero() && speedFactor > 0 {
+				if d := time.Duration(float64(tp.Time.Sub(prevTime)) / speedFactor); d > 0 {
+					timer := time.NewTimer(d)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-timer.C:
+					}
+				}
+			}
+			if !tp.Time.IsZero() {
+				prevTime = tp.Time
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- tp:
+			}
+		}
+	}()
+	return ch
+}
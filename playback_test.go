@@ -0,0 +1,38 @@
+package gpx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPlayback(t *testing.T) {
+	t0 := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 1, Lon: 2, Time: t0},
+			{Lat: 3, Lon: 4, Time: t0.Add(time.Second)},
+			{Lat: 5, Lon: 6},
+			{Lat: 7, Lon: 8, Time: t0.Add(2 * time.Second)},
+		},
+	}
+
+	start := time.Now()
+	var got []*gpx.WptType
+	for wpt := range gpx.Playback(context.Background(), ts, 100) {
+		got = append(got, wpt)
+	}
+	assert.Equal(t, ts.TrkPt, got)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := gpx.Playback(ctx, ts, 1e-3)
+	assert.Equal(t, ts.TrkPt[0], <-ch)
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,99 @@
+package gpx
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// errInvalidPolyline is returned when a polyline is malformed.
+var errInvalidPolyline = errors.New("invalid polyline")
+
+// Polyline returns ts's points encoded with Google's encoded polyline
+// algorithm, with precision decimal places, typically 5, or 6 for OSRM and
+// Valhalla. Elevations and times are not encoded.
+func (ts *TrkSegType) Polyline(precision int) string {
+	return encodePolyline(ts.TrkPt, precision)
+}
+
+// Polyline returns r's points encoded with Google's encoded polyline
+// algorithm, with precision decimal places.
+func (r *RteType) Polyline(precision int) string {
+	return encodePolyline(r.RtePt, precision)
+}
+
+// DecodePolyline returns a new track with a single segment containing the
+// points in the Google encoded polyline s, with precision decimal places.
+func DecodePolyline(s string, precision int) (*TrkType, error) {
+	factor := math.Pow10(precision)
+	ts := &TrkSegType{}
+	var lat, lon int
+	for i := 0; i < len(s); {
+		var deltas [2]int
+		for j := range deltas {
+			result, shift := 0, 0
+			for {
+				if i == len(s) {
+					return nil, errInvalidPolyline
+				}
+				b := int(s[i]) - 63
+				i++
+				if b < 0 || b > 63 {
+					return nil, errInvalidPolyline
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+				if shift > 60 {
+					return nil, errInvalidPolyline
+				}
+			}
+			if result&1 != 0 {
+				deltas[j] = ^(result >> 1)
+			} else {
+				deltas[j] = result >> 1
+			}
+		}
+		lat += deltas[0]
+		lon += deltas[1]
+		ts.TrkPt = append(ts.TrkPt, &WptType{
+			Lat: float64(lat) / factor,
+			Lon: float64(lon) / factor,
+		})
+	}
+	return &TrkType{
+		TrkSeg: []*TrkSegType{ts},
+	}, nil
+}
+
+// encodePolyline returns wpts encoded with Google's encoded polyline
+// algorithm.
+func encodePolyline(wpts []*WptType, precision int) string {
+	factor := math.Pow10(precision)
+	var sb strings.Builder
+	var prevLat, prevLon int
+	for _, wpt := range wpts {
+		lat := int(math.Round(wpt.Lat * factor))
+		lon := int(math.Round(wpt.Lon * factor))
+		writePolylineValue(&sb, lat-prevLat)
+		writePolylineValue(&sb, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return sb.String()
+}
+
+// writePolylineValue writes the encoded polyline representation of value to
+// sb.
+func writePolylineValue(sb *strings.Builder, value int) {
+	u := value << 1
+	if value < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
@@ -0,0 +1,61 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPolyline(t *testing.T) {
+	for i, tc := range []struct {
+		precision int
+		wpts      []*gpx.WptType
+		polyline  string
+	}{
+		{
+			// The example from Google's documentation.
+			precision: 5,
+			wpts: []*gpx.WptType{
+				{Lat: 38.5, Lon: -120.2},
+				{Lat: 40.7, Lon: -120.95},
+				{Lat: 43.252, Lon: -126.453},
+			},
+			polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		{
+			precision: 6,
+			wpts: []*gpx.WptType{
+				{Lat: 38.5, Lon: -120.2},
+				{Lat: 40.7, Lon: -120.95},
+				{Lat: 43.252, Lon: -126.453},
+			},
+			polyline: "_izlhA~rlgdF_{geC~ywl@_kwzCn`{nI",
+		},
+		{
+			precision: 5,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ts := &gpx.TrkSegType{TrkPt: tc.wpts}
+			assert.Equal(t, tc.polyline, ts.Polyline(tc.precision))
+			assert.Equal(t, tc.polyline, (&gpx.RteType{RtePt: tc.wpts}).Polyline(tc.precision))
+
+			got, err := gpx.DecodePolyline(tc.polyline, tc.precision)
+			assert.NoError(t, err)
+			assert.Len(t, got.TrkSeg, 1)
+			assert.Len(t, got.TrkSeg[0].TrkPt, len(tc.wpts))
+			for j, wpt := range tc.wpts {
+				assert.InDelta(t, wpt.Lat, got.TrkSeg[0].TrkPt[j].Lat, 1e-9)
+				assert.InDelta(t, wpt.Lon, got.TrkSeg[0].TrkPt[j].Lon, 1e-9)
+			}
+		})
+	}
+
+	for _, polyline := range []string{"_p~iF", "_p~iF~ps|", " "} {
+		_, err := gpx.DecodePolyline(polyline, 5)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,160 @@
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+// A Projection projects WGS84 longitudes and latitudes in degrees to
+// coordinates in another coordinate reference system. Implementations may wrap
+// libraries such as PROJ to support national grids.
+type Projection interface {
+	// Project returns the projected x and y coordinates of lon and lat.
+	Project(lon, lat float64) (float64, float64, error)
+	// SRID returns the spatial reference identifier of the projected
+	// coordinate reference system.
+	SRID() int
+}
+
+// WebMercator is the Web Mercator projection, EPSG:3857.
+type WebMercator struct{}
+
+// A UTM is a Universal Transverse Mercator projection on the WGS84 ellipsoid.
+type UTM struct {
+	Zone  int
+	South bool
+}
+
+// UTM latitude limits in degrees. Polar regions use the Universal Polar
+// Stereographic projection instead.
+const (
+	utmMinLat = -80
+	utmMaxLat = 84
+)
+
+// WGS84 ellipsoid parameters.
+const (
+	wgs84SemiMajorAxis = 6378137
+	wgs84Flattening    = 1 / 298.257223563
+)
+
+// Project implements Projection.Project.
+func (WebMercator) Project(lon, lat float64) (float64, float64, error) {
+	if lat <= -90 || lat >= 90 {
+		return 0, 0, fmt.Errorf("%g: latitude out of range", lat)
+	}
+	x := wgs84SemiMajorAxis * lon * math.Pi / 180
+	y := wgs84SemiMajorAxis * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return x, y, nil
+}
+
+// SRID implements Projection.SRID.
+func (WebMercator) SRID() int {
+	return 3857
+}
+
+// NewUTM returns the UTM projection for the standard zone containing lat and
+// lon.
+func NewUTM(lat, lon float64) UTM {
+	zone := int(math.Floor((normalizeLon(lon)+180)/6)) + 1
+	return UTM{
+		Zone:  min(zone, 60),
+		South: lat < 0,
+	}
+}
+
+// Project implements Projection.Project. It uses the series expansion of
+// Krüger, which is accurate to within a millimeter near the zone. It returns
+// an error if lat is outside UTM's range of 80°S to 84°N.
+func (u UTM) Project(lon, lat float64) (float64, float64, error) {
+	if !(utmMinLat <= lat && lat <= utmMaxLat) {
+		return 0, 0, fmt.Errorf("%g: latitude out of range", lat)
+	}
+	const k0 = 0.9996
+	n := wgs84Flattening / (2 - wgs84Flattening)
+	a := wgs84SemiMajorAxis / (1 + n) * (1 + n*n/4 + n*n*n*n/64)
+	alpha := [3]float64{
+		n/2 - 2*n*n/3 + 5*n*n*n/16,
+		13*n*n/48 - 3*n*n*n/5,
+		61 * n * n * n / 240,
+	}
+
+	phi := lat * math.Pi / 180
+	lambda := (lon - float64(6*u.Zone-183)) * math.Pi / 180
+	c := 2 * math.Sqrt(n) / (1 + n)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xi := math.Atan2(t, math.Cos(lambda))
+	eta := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+
+	x, y := eta, xi
+	for j, alphaJ := range alpha {
+		k := 2 * float64(j+1)
+		x += alphaJ * math.Cos(k*xi) * math.Sinh(k*eta)
+		y += alphaJ * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	easting := 500000 + k0*a*x
+	northing := k0 * a * y
+	if u.South {
+		northing += 10000000
+	}
+	return easting, northing, nil
+}
+
+// SRID implements Projection.SRID.
+func (u UTM) SRID() int {
+	if u.South {
+		return 32700 + u.Zone
+	}
+	return 32600 + u.Zone
+}
+
+// ProjectedGeom returns w's geometry projected with p. The geometry's SRID
+// is set to p's SRID.
+func (w *WptType) ProjectedGeom(layout geom.Layout, p Projection) (*geom.Point, error) {
+	g := w.Geom(layout)
+	if err := projectFlatCoords(g.FlatCoords(), layout.Stride(), p); err != nil {
+		return nil, err
+	}
+	return g.SetSRID(p.SRID()), nil
+}
+
+// ProjectedGeom returns r's geometry projected with p.
+func (r *RteType) ProjectedGeom(layout geom.Layout, p Projection) (*geom.LineString, error) {
+	g := r.Geom(layout)
+	if err := projectFlatCoords(g.FlatCoords(), layout.Stride(), p); err != nil {
+		return nil, err
+	}
+	return g.SetSRID(p.SRID()), nil
+}
+
+// ProjectedGeom returns ts's geometry projected with p.
+func (ts *TrkSegType) ProjectedGeom(layout geom.Layout, p Projection) (*geom.LineString, error) {
+	g := ts.Geom(layout)
+	if err := projectFlatCoords(g.FlatCoords(), layout.Stride(), p); err != nil {
+		return nil, err
+	}
+	return g.SetSRID(p.SRID()), nil
+}
+
+// ProjectedGeom returns t's geometry projected with p.
+func (t *TrkType) ProjectedGeom(layout geom.Layout, p Projection) (*geom.MultiLineString, error) {
+	g := t.Geom(layout)
+	if err := projectFlatCoords(g.FlatCoords(), layout.Stride(), p); err != nil {
+		return nil, err
+	}
+	return g.SetSRID(p.SRID()), nil
+}
+
+// projectFlatCoords projects flatCoords in place with p.
+func projectFlatCoords(flatCoords []float64, stride int, p Projection) error {
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		x, y, err := p.Project(flatCoords[i], flatCoords[i+1])
+		if err != nil {
+			return fmt.Errorf("point %d: %w", i/stride, err)
+		}
+		flatCoords[i], flatCoords[i+1] = x, y
+	}
+	return nil
+}
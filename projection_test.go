@@ -0,0 +1,126 @@
+package gpx_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	geom "github.com/twpayne/go-geom"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestProjections(t *testing.T) {
+	for i, tc := range []struct {
+		p         gpx.Projection
+		lon, lat  float64
+		expectedX float64
+		expectedY float64
+		delta     float64
+	}{
+		{
+			p:         gpx.WebMercator{},
+			lon:       0,
+			lat:       0,
+			expectedX: 0,
+			expectedY: 0,
+			delta:     1e-6,
+		},
+		{
+			p:         gpx.WebMercator{},
+			lon:       180,
+			lat:       85.0511287798,
+			expectedX: 20037508.342789244,
+			expectedY: 20037508.342789244,
+			delta:     1e-3,
+		},
+		{
+			p:         gpx.UTM{Zone: 31},
+			lon:       3,
+			lat:       0,
+			expectedX: 500000,
+			expectedY: 0,
+			delta:     1e-6,
+		},
+		{
+			p:         gpx.UTM{Zone: 31},
+			lon:       2.294481,
+			lat:       48.858370,
+			expectedX: 448250.577,
+			expectedY: 5411951.588,
+			delta:     1e-2,
+		},
+		{
+			p:         gpx.UTM{Zone: 32},
+			lon:       7.5,
+			lat:       46.5,
+			expectedX: 384902.837,
+			expectedY: 5150696.347,
+			delta:     1e-2,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			x, y, err := tc.p.Project(tc.lon, tc.lat)
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.expectedX, x, tc.delta)
+			assert.InDelta(t, tc.expectedY, y, tc.delta)
+		})
+	}
+
+	// Southern zones mirror northern zones about the false northing.
+	x, y, err := gpx.UTM{Zone: 31}.Project(2.294481, 48.858370)
+	assert.NoError(t, err)
+	southX, southY, err := gpx.UTM{Zone: 31, South: true}.Project(2.294481, -48.858370)
+	assert.NoError(t, err)
+	assert.InDelta(t, x, southX, 1e-6)
+	assert.InDelta(t, 10000000-y, southY, 1e-6)
+
+	_, _, err = gpx.WebMercator{}.Project(0, 90)
+	assert.Error(t, err)
+
+	for _, lat := range []float64{-90, -80.1, 84.1, 90, math.NaN()} {
+		_, _, err = gpx.UTM{Zone: 31}.Project(3, lat)
+		assert.Error(t, err, lat)
+	}
+	for _, lat := range []float64{-80, 84} {
+		_, _, err = gpx.UTM{Zone: 31}.Project(3, lat)
+		assert.NoError(t, err, lat)
+	}
+}
+
+func TestNewUTM(t *testing.T) {
+	assert.Equal(t, gpx.UTM{Zone: 32}, gpx.NewUTM(46, 7))
+	assert.Equal(t, gpx.UTM{Zone: 23, South: true}, gpx.NewUTM(-22.9, -43.2))
+	assert.Equal(t, gpx.UTM{Zone: 60}, gpx.NewUTM(0, 179.9))
+	assert.Equal(t, gpx.UTM{Zone: 1}, gpx.NewUTM(0, 180))
+	assert.Equal(t, 32632, gpx.NewUTM(46, 7).SRID())
+	assert.Equal(t, 32723, gpx.NewUTM(-22.9, -43.2).SRID())
+}
+
+func TestProjectedGeom(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 3, Ele: 10},
+			{Lat: 0.001, Lon: 3, Ele: 20},
+		},
+	}
+	g, err := ts.ProjectedGeom(geom.XYZ, gpx.UTM{Zone: 31})
+	assert.NoError(t, err)
+	assert.Equal(t, 32631, g.SRID())
+	assert.Equal(t, geom.XYZ, g.Layout())
+	assert.InDelta(t, 500000, g.Coord(1).X(), 1e-6)
+	assert.Equal(t, 20.0, g.Coord(1)[2])
+	assert.InDelta(t, ts.Length(nil), g.Length()/0.9996, 1)
+
+	trk := &gpx.TrkType{TrkSeg: []*gpx.TrkSegType{ts}}
+	mls, err := trk.ProjectedGeom(geom.XY, gpx.WebMercator{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3857, mls.SRID())
+
+	_, err = (&gpx.WptType{Lat: 90}).ProjectedGeom(geom.XY, gpx.WebMercator{})
+	assert.EqualError(t, err, "point 0: 90: latitude out of range")
+
+	_, err = (&gpx.RteType{RtePt: ts.TrkPt}).ProjectedGeom(geom.XY, gpx.WebMercator{})
+	assert.NoError(t, err)
+}
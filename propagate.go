@@ -0,0 +1,210 @@
+package gpx
+
+import (
+	"slices"
+	"strings"
+)
+
+// A PropagateDirection is the direction in which PropagateMetadata copies
+// metadata.
+type PropagateDirection int
+
+// Propagate directions.
+const (
+	// PropagateDown copies document metadata to tracks and, optionally,
+	// waypoints.
+	PropagateDown PropagateDirection = iota
+	// PropagateUp copies metadata shared by tracks to the document metadata.
+	PropagateUp
+)
+
+// A ConflictPolicy determines what PropagateMetadata does when a destination
+// field is already set.
+type ConflictPolicy int
+
+// Conflict policies.
+const (
+	// ConflictKeep keeps existing values.
+	ConflictKeep ConflictPolicy = iota
+	// ConflictOverwrite replaces existing values.
+	ConflictOverwrite
+	// ConflictMerge combines existing and new values. Links and keywords are
+	// unioned and differing strings are joined with "; ".
+	ConflictMerge
+)
+
+// PropagateOptions control PropagateMetadata.
+type PropagateOptions struct {
+	Direction PropagateDirection
+	Conflict  ConflictPolicy
+	// Waypoints, if set, also propagates metadata down to waypoints.
+	Waypoints bool
+}
+
+// PropagateMetadata copies metadata between g's metadata and its tracks so
+// that documents derived by splitting or merging keep their provenance. The
+// fields are mapped as follows:
+//
+//	document                      track or waypoint
+//	metadata author name, creator src
+//	metadata desc                 desc
+//	metadata link                 link
+//	metadata keywords             keywords extension, see TrkType.Keywords
+//
+// When propagating up, track values are combined: links and keywords are
+// unioned, and src and desc are only used if all tracks with a value agree.
+// Author names are copied up to the metadata author; the creator is not
+// changed.
+func (g *GPX) PropagateMetadata(options PropagateOptions) {
+	switch options.Direction {
+	case PropagateDown:
+		g.propagateDown(options)
+	case PropagateUp:
+		g.propagateUp(options)
+	}
+}
+
+func (g *GPX) propagateDown(options PropagateOptions) {
+	var src, desc, keywords string
+	var links []*LinkType
+	if g.Metadata != nil {
+		if g.Metadata.Author != nil {
+			src = g.Metadata.Author.Name
+		}
+		desc = g.Metadata.Desc
+		keywords = g.Metadata.Keywords
+		links = g.Metadata.Link
+	}
+	if src == "" {
+		src = g.Creator
+	}
+	for _, trk := range g.Trk {
+		trk.Src = resolveString(trk.Src, src, options.Conflict)
+		trk.Desc = resolveString(trk.Desc, desc, options.Conflict)
+		trk.Extensions = resolveKeywordsExtension(trk.Extensions, keywords, options.Conflict)
+		trk.Link = resolveLinks(trk.Link, links, options.Conflict)
+	}
+	if options.Waypoints {
+		for _, wpt := range g.Wpt {
+			wpt.Src = resolveString(wpt.Src, src, options.Conflict)
+			wpt.Desc = resolveString(wpt.Desc, desc, options.Conflict)
+			wpt.Extensions = resolveKeywordsExtension(wpt.Extensions, keywords, options.Conflict)
+			wpt.Link = resolveLinks(wpt.Link, links, options.Conflict)
+		}
+	}
+}
+
+func (g *GPX) propagateUp(options PropagateOptions) {
+	if len(g.Trk) == 0 {
+		return
+	}
+	var srcs, descs, keywords []string
+	var links []*LinkType
+	for _, trk := range g.Trk {
+		srcs = appendUnique(srcs, trk.Src)
+		descs = appendUnique(descs, trk.Desc)
+		for _, keyword := range splitKeywords(trk.Keywords()) {
+			keywords = appendUnique(keywords, keyword)
+		}
+		links = resolveLinks(links, trk.Link, ConflictMerge)
+	}
+
+	if len(srcs) != 1 && len(descs) != 1 && len(keywords) == 0 && len(links) == 0 {
+		return
+	}
+	m := g.metadata()
+	if len(srcs) == 1 {
+		var author string
+		if m.Author != nil {
+			author = m.Author.Name
+		}
+		if author = resolveString(author, srcs[0], options.Conflict); author != "" {
+			if m.Author == nil {
+				m.Author = &PersonType{}
+			}
+			m.Author.Name = author
+		}
+	}
+	if len(descs) == 1 {
+		m.Desc = resolveString(m.Desc, descs[0], options.Conflict)
+	}
+	m.Keywords = resolveKeywords(m.Keywords, strings.Join(keywords, ", "), options.Conflict)
+	m.Link = resolveLinks(m.Link, links, options.Conflict)
+}
+
+// resolveString returns the result of setting a field with value existing to
+// value according to policy.
+func resolveString(existing, value string, policy ConflictPolicy) string {
+	switch {
+	case value == "" || existing == value:
+		return existing
+	case existing == "" || policy == ConflictOverwrite:
+		return value
+	case policy == ConflictMerge:
+		return existing + "; " + value
+	default:
+		return existing
+	}
+}
+
+// resolveKeywords is like resolveString for comma-separated keywords, except
+// that ConflictMerge unions the keywords.
+func resolveKeywords(existing, value string, policy ConflictPolicy) string {
+	if policy != ConflictMerge || existing == "" {
+		return resolveString(existing, value, policy)
+	}
+	keywords := splitKeywords(existing)
+	for _, keyword := range splitKeywords(value) {
+		keywords = appendUnique(keywords, keyword)
+	}
+	return strings.Join(keywords, ", ")
+}
+
+// resolveKeywordsExtension returns e with its keywords extension set to
+// keywords according to policy. e is returned unchanged if its keywords are
+// unchanged.
+func resolveKeywordsExtension(e *ExtensionsType, keywords string, policy ConflictPolicy) *ExtensionsType {
+	existing := e.namespacedExtensions(keywordsSpaces...)["keywords"]
+	if resolved := resolveKeywords(existing, keywords, policy); resolved != existing {
+		return e.withKeywords(resolved)
+	}
+	return e
+}
+
+// resolveLinks is like resolveString for links, except that ConflictMerge
+// unions the links by href.
+func resolveLinks(existing, links []*LinkType, policy ConflictPolicy) []*LinkType {
+	switch {
+	case len(links) == 0:
+		return existing
+	case len(existing) == 0 || policy == ConflictOverwrite:
+		return slices.Clone(links)
+	case policy == ConflictMerge:
+		result := slices.Clone(existing)
+		for _, link := range links {
+			if !slices.ContainsFunc(result, func(l *LinkType) bool { return l.HREF == link.HREF }) {
+				result = append(result, link)
+			}
+		}
+		return result
+	default:
+		return existing
+	}
+}
+
+// splitKeywords splits comma-separated keywords.
+func splitKeywords(keywords string) []string {
+	var result []string
+	for _, keyword := range strings.Split(keywords, ",") {
+		result = appendUnique(result, strings.TrimSpace(keyword))
+	}
+	return result
+}
+
+// appendUnique appends s to ss if it is not empty and not already present.
+func appendUnique(ss []string, s string) []string {
+	if s == "" || slices.Contains(ss, s) {
+		return ss
+	}
+	return append(ss, s)
+}
@@ -0,0 +1,137 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPropagateMetadataDown(t *testing.T) {
+	newGPX := func() *gpx.GPX {
+		g := &gpx.GPX{
+			Creator: "myapp",
+			Metadata: &gpx.MetadataType{
+				Author:   &gpx.PersonType{Name: "Alice"},
+				Desc:     "Alps 2024",
+				Keywords: "hiking, alps",
+				Link:     []*gpx.LinkType{{HREF: "https://example.com/alps"}},
+			},
+			Wpt: []*gpx.WptType{
+				{Name: "hut"},
+			},
+			Trk: []*gpx.TrkType{
+				{Name: "day 1"},
+				{
+					Name: "day 2",
+					Src:  "Bob",
+					Desc: "Rainy",
+					Link: []*gpx.LinkType{{HREF: "https://example.com/day2"}},
+				},
+			},
+		}
+		g.Trk[1].SetKeywords("hiking, rain")
+		return g
+	}
+
+	for i, tc := range []struct {
+		options              gpx.PropagateOptions
+		expectedTrk1         *gpx.TrkType
+		expectedTrk1Keywords string
+		expectedWpt0         *gpx.WptType
+		expectedWpt0Keywords string
+	}{
+		{
+			options: gpx.PropagateOptions{Conflict: gpx.ConflictKeep},
+			expectedTrk1: &gpx.TrkType{
+				Name: "day 2",
+				Src:  "Bob",
+				Desc: "Rainy",
+				Link: []*gpx.LinkType{{HREF: "https://example.com/day2"}},
+			},
+			expectedTrk1Keywords: "hiking, rain",
+			expectedWpt0:         &gpx.WptType{Name: "hut"},
+		},
+		{
+			options: gpx.PropagateOptions{Conflict: gpx.ConflictOverwrite, Waypoints: true},
+			expectedTrk1: &gpx.TrkType{
+				Name: "day 2",
+				Src:  "Alice",
+				Desc: "Alps 2024",
+				Link: []*gpx.LinkType{{HREF: "https://example.com/alps"}},
+			},
+			expectedTrk1Keywords: "hiking, alps",
+			expectedWpt0: &gpx.WptType{
+				Name: "hut",
+				Src:  "Alice",
+				Desc: "Alps 2024",
+				Link: []*gpx.LinkType{{HREF: "https://example.com/alps"}},
+			},
+			expectedWpt0Keywords: "hiking, alps",
+		},
+		{
+			options: gpx.PropagateOptions{Conflict: gpx.ConflictMerge},
+			expectedTrk1: &gpx.TrkType{
+				Name: "day 2",
+				Src:  "Bob; Alice",
+				Desc: "Rainy; Alps 2024",
+				Link: []*gpx.LinkType{{HREF: "https://example.com/day2"}, {HREF: "https://example.com/alps"}},
+			},
+			expectedTrk1Keywords: "hiking, rain, alps",
+			expectedWpt0:         &gpx.WptType{Name: "hut"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			g := newGPX()
+			g.PropagateMetadata(tc.options)
+			assert.Equal(t, "hiking, alps", g.Trk[0].Keywords())
+			assert.Equal(t, tc.expectedTrk1Keywords, g.Trk[1].Keywords())
+			assert.Equal(t, tc.expectedWpt0Keywords, g.Wpt[0].Keywords())
+			for _, trk := range g.Trk {
+				trk.Extensions = nil
+			}
+			g.Wpt[0].Extensions = nil
+			assert.Equal(t, &gpx.TrkType{
+				Name: "day 1",
+				Src:  "Alice",
+				Desc: "Alps 2024",
+				Link: []*gpx.LinkType{{HREF: "https://example.com/alps"}},
+			}, g.Trk[0])
+			assert.Equal(t, tc.expectedTrk1, g.Trk[1])
+			assert.Equal(t, tc.expectedWpt0, g.Wpt[0])
+		})
+	}
+
+	g := &gpx.GPX{Creator: "myapp", Trk: []*gpx.TrkType{{}}}
+	g.PropagateMetadata(gpx.PropagateOptions{})
+	assert.Equal(t, "myapp", g.Trk[0].Src)
+}
+
+func TestPropagateMetadataUp(t *testing.T) {
+	g := &gpx.GPX{
+		Trk: []*gpx.TrkType{
+			{Src: "Alice", Desc: "Morning", Link: []*gpx.LinkType{{HREF: "https://example.com/1"}}},
+			{Src: "Alice", Desc: "Evening", Link: []*gpx.LinkType{{HREF: "https://example.com/1"}}},
+			{Type: "running"},
+		},
+	}
+	g.Trk[0].SetKeywords("cycling")
+	g.Trk[1].SetKeywords("cycling, commute")
+	g.Trk[2].SetKeywords("running")
+	g.PropagateMetadata(gpx.PropagateOptions{Direction: gpx.PropagateUp})
+	assert.Equal(t, &gpx.MetadataType{
+		Author:   &gpx.PersonType{Name: "Alice"},
+		Keywords: "cycling, commute, running",
+		Link:     []*gpx.LinkType{{HREF: "https://example.com/1"}},
+	}, g.Metadata)
+
+	g.Metadata.Keywords = "bike"
+	g.PropagateMetadata(gpx.PropagateOptions{Direction: gpx.PropagateUp, Conflict: gpx.ConflictMerge})
+	assert.Equal(t, "bike, cycling, commute, running", g.Metadata.Keywords)
+
+	g = &gpx.GPX{Trk: []*gpx.TrkType{{Name: "a"}}}
+	g.PropagateMetadata(gpx.PropagateOptions{Direction: gpx.PropagateUp})
+	assert.Nil(t, g.Metadata)
+}
@@ -0,0 +1,143 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A PtType is a ptType, a point with an optional elevation and time. The GPX
+// 1.1 schema defines it for use in extensions.
+type PtType struct {
+	Lat  float64   `json:"lat"`
+	Lon  float64   `json:"lon"`
+	Ele  float64   `json:"ele,omitempty"`
+	Time time.Time `json:"time,omitempty"`
+}
+
+// A PtSegType is a ptsegType, an ordered list of points. The GPX 1.1 schema
+// defines it for use in extensions.
+type PtSegType struct {
+	Pt []*PtType `xml:"pt,omitempty" json:"pt,omitempty"`
+}
+
+// NewPtSegType returns a new PtSegType with geometry g.
+func NewPtSegType(g *geom.LineString) *PtSegType {
+	wpts := newWptTypes(g, MToTime)
+	pts := make([]*PtType, len(wpts))
+	for i, wpt := range wpts {
+		pts[i] = newPtType(wpt)
+	}
+	return &PtSegType{
+		Pt: pts,
+	}
+}
+
+// Geom returns ps's geometry.
+func (ps *PtSegType) Geom(layout geom.Layout) *geom.LineString {
+	flatCoords := make([]float64, 0, layout.Stride()*len(ps.Pt))
+	for _, pt := range ps.Pt {
+		flatCoords = pt.wpt().appendFlatCoords(flatCoords, layout)
+	}
+	return geom.NewLineStringFlat(layout, flatCoords)
+}
+
+// NewPtType returns a new PtType with geometry g. It returns nil if g is
+// empty.
+func NewPtType(g *geom.Point) *PtType {
+	wpt := newWptType(g, MToTime)
+	if wpt == nil {
+		return nil
+	}
+	return newPtType(wpt)
+}
+
+// Geom returns p's geometry.
+func (p *PtType) Geom(layout geom.Layout) *geom.Point {
+	return p.wpt().Geom(layout)
+}
+
+// MarshalXML implements xml.Marshaler.MarshalXML.
+func (p *PtType) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr,
+		xml.Attr{
+			Name:  xml.Name{Local: "lat"},
+			Value: formatLatLon(e, p.Lat),
+		},
+		xml.Attr{
+			Name:  xml.Name{Local: "lon"},
+			Value: formatLatLon(e, p.Lon),
+		},
+	)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := maybeEmitEleElement(e, p.Ele); err != nil {
+		return err
+	}
+	if !p.Time.IsZero() {
+		if err := emitStringElement(e, "time", p.Time.UTC().Format(timeLayout)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements xml.Unmarshaler.UnmarshalXML.
+func (p *PtType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var wpt WptType
+	if err := wpt.UnmarshalXML(d, start); err != nil {
+		return err
+	}
+	*p = *newPtType(&wpt)
+	return nil
+}
+
+// PtSegs returns the ptseg elements in e, at any depth.
+func (e *ExtensionsType) PtSegs() ([]*PtSegType, error) {
+	if e == nil {
+		return nil, nil
+	}
+	var ptSegs []*PtSegType
+	d := xml.NewDecoder(bytes.NewReader(e.XML))
+	for {
+		token, err := d.Token()
+		switch {
+		case errors.Is(err, io.EOF):
+			return ptSegs, nil
+		case err != nil:
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "ptseg" {
+			ptSeg := &PtSegType{}
+			if err := d.DecodeElement(ptSeg, &start); err != nil {
+				return nil, err
+			}
+			ptSegs = append(ptSegs, ptSeg)
+		}
+	}
+}
+
+// newPtType returns a new PtType with wpt's position, elevation, and time.
+func newPtType(wpt *WptType) *PtType {
+	return &PtType{
+		Lat:  wpt.Lat,
+		Lon:  wpt.Lon,
+		Ele:  wpt.Ele,
+		Time: wpt.Time,
+	}
+}
+
+// wpt returns a new WptType with p's position, elevation, and time.
+func (p *PtType) wpt() *WptType {
+	return &WptType{
+		Lat:  p.Lat,
+		Lon:  p.Lon,
+		Ele:  p.Ele,
+		Time: p.Time,
+	}
+}
@@ -0,0 +1,73 @@
+package gpx_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twpayne/go-geom"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPtSegType(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ps := &gpx.PtSegType{
+		Pt: []*gpx.PtType{
+			{Lat: 46.5, Lon: 7.25, Ele: 1000, Time: t0},
+			{Lat: 46.6, Lon: 7.5},
+		},
+	}
+
+	data, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"ptseg"`
+		*gpx.PtSegType
+	}{PtSegType: ps})
+	assert.NoError(t, err)
+	assert.Equal(t, `<ptseg><pt lat="46.5" lon="7.25"><ele>1000</ele><time>2024-05-01T10:00:00Z</time></pt><pt lat="46.6" lon="7.5"></pt></ptseg>`, string(data))
+
+	var got gpx.PtSegType
+	assert.NoError(t, xml.Unmarshal(data, &got))
+	assert.Equal(t, ps, &got)
+
+	lineString := ps.Geom(geom.XYZM)
+	assert.Equal(t, []float64{7.25, 46.5, 1000, gpx.TimeToM(t0), 7.5, 46.6, 0, gpx.TimeToM(time.Time{})}, lineString.FlatCoords())
+	assert.Equal(t, []float64{7.25, 46.5, 7.5, 46.6}, ps.Geom(geom.XY).FlatCoords())
+	assert.Equal(t, &gpx.PtSegType{
+		Pt: []*gpx.PtType{
+			{Lat: 46.5, Lon: 7.25, Ele: 1000},
+			{Lat: 46.6, Lon: 7.5},
+		},
+	}, gpx.NewPtSegType(ps.Geom(geom.XYZ)))
+
+	pt := gpx.NewPtType(geom.NewPointFlat(geom.XYM, []float64{1, 2, gpx.TimeToM(t0)}))
+	assert.Equal(t, &gpx.PtType{Lat: 2, Lon: 1, Time: t0}, pt)
+	assert.Equal(t, []float64{1, 2, gpx.TimeToM(t0)}, pt.Geom(geom.XYM).FlatCoords())
+}
+
+func TestExtensionsTypePtSegs(t *testing.T) {
+	g, err := gpx.Read(strings.NewReader(`<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <extensions>
+      <survey:transect xmlns:survey="https://example.com/survey">
+        <survey:ptseg><survey:pt lat="1" lon="2"><survey:ele>3</survey:ele></survey:pt></survey:ptseg>
+        <survey:ptseg><survey:pt lat="4" lon="5"/><survey:pt lat="6" lon="7"><survey:time>2024-05-01T10:00:00Z</survey:time></survey:pt></survey:ptseg>
+      </survey:transect>
+    </extensions>
+  </trk>
+</gpx>`))
+	assert.NoError(t, err)
+	ptSegs, err := g.Trk[0].Extensions.PtSegs()
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.PtSegType{
+		{Pt: []*gpx.PtType{{Lat: 1, Lon: 2, Ele: 3}}},
+		{Pt: []*gpx.PtType{{Lat: 4, Lon: 5}, {Lat: 6, Lon: 7, Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}}},
+	}, ptSegs)
+
+	var extensions *gpx.ExtensionsType
+	ptSegs, err = extensions.PtSegs()
+	assert.NoError(t, err)
+	assert.Nil(t, ptSegs)
+}
@@ -0,0 +1,112 @@
+package gpx
+
+import (
+	"fmt"
+	"math"
+)
+
+// A LatitudeType is a latitudeType, in degrees, from -90 to 90 inclusive.
+type LatitudeType float64
+
+// A LongitudeType is a longitudeType, in degrees, from -180 inclusive to 180
+// exclusive.
+type LongitudeType float64
+
+// A DegreesType is a degreesType, in degrees, from 0 inclusive to 360
+// exclusive.
+type DegreesType float64
+
+// A FixType is a fixType, the type of GPS fix. The empty FixType means that
+// the type of fix is unknown.
+type FixType string
+
+// Fix types.
+const (
+	FixNone FixType = "none" // No fix.
+	Fix2D   FixType = "2d"   // Position only.
+	Fix3D   FixType = "3d"   // Position and elevation.
+	FixDGPS FixType = "dgps" // Differential GPS.
+	FixPPS  FixType = "pps"  // Military signal.
+)
+
+// A DGPSStationType is a dgpsStationType, a DGPS station id from 0 to 1023
+// inclusive.
+type DGPSStationType int
+
+// Validate returns an error if l is out of range.
+func (l LatitudeType) Validate() error {
+	if !(-90 <= l && l <= 90) {
+		return fmt.Errorf("%v: latitude out of range", float64(l))
+	}
+	return nil
+}
+
+// Radians returns l in radians.
+func (l LatitudeType) Radians() float64 {
+	return float64(l) * math.Pi / 180
+}
+
+// Validate returns an error if l is out of range.
+func (l LongitudeType) Validate() error {
+	if !(-180 <= l && l < 180) {
+		return fmt.Errorf("%v: longitude out of range", float64(l))
+	}
+	return nil
+}
+
+// Normalize returns l normalized to the range of a LongitudeType.
+func (l LongitudeType) Normalize() LongitudeType {
+	return LongitudeType(normalizeLon(float64(l)))
+}
+
+// Radians returns l in radians.
+func (l LongitudeType) Radians() float64 {
+	return float64(l) * math.Pi / 180
+}
+
+// Validate returns an error if d is out of range.
+func (d DegreesType) Validate() error {
+	if !(0 <= d && d < 360) {
+		return fmt.Errorf("%v: degrees out of range", float64(d))
+	}
+	return nil
+}
+
+// Normalize returns d normalized to the range of a DegreesType.
+func (d DegreesType) Normalize() DegreesType {
+	return DegreesType(math.Mod(math.Mod(float64(d), 360)+360, 360))
+}
+
+// Radians returns d in radians.
+func (d DegreesType) Radians() float64 {
+	return float64(d) * math.Pi / 180
+}
+
+// Validate returns an error if s is out of range.
+func (s DGPSStationType) Validate() error {
+	if s < 0 || s > 1023 {
+		return fmt.Errorf("%d: DGPS station id out of range", int(s))
+	}
+	return nil
+}
+
+// Validate returns an error if f is not empty or one of the fix types.
+func (f FixType) Validate() error {
+	switch f {
+	case "", FixNone, Fix2D, Fix3D, FixDGPS, FixPPS:
+		return nil
+	default:
+		return fmt.Errorf("%s: invalid fix", string(f))
+	}
+}
+
+// HasFix returns whether w has a known fix with at least a position.
+func (w *WptType) HasFix() bool {
+	return w.Fix != "" && w.Fix != FixNone
+}
+
+// HasFix3D returns whether w has a known fix with a position and elevation,
+// which includes DGPS and PPS fixes.
+func (w *WptType) HasFix3D() bool {
+	return w.Fix == Fix3D || w.Fix == FixDGPS || w.Fix == FixPPS
+}
@@ -0,0 +1,67 @@
+package gpx_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestSchemaTypes(t *testing.T) {
+	assert.NoError(t, gpx.LatitudeType(-90).Validate())
+	assert.NoError(t, gpx.LatitudeType(90).Validate())
+	assert.Error(t, gpx.LatitudeType(90.1).Validate())
+	assert.Error(t, gpx.LatitudeType(math.NaN()).Validate())
+	assert.InDelta(t, math.Pi/4, gpx.LatitudeType(45).Radians(), 1e-15)
+
+	assert.NoError(t, gpx.LongitudeType(-180).Validate())
+	assert.Error(t, gpx.LongitudeType(180).Validate())
+	assert.Equal(t, gpx.LongitudeType(-180), gpx.LongitudeType(180).Normalize())
+	assert.Equal(t, gpx.LongitudeType(-170), gpx.LongitudeType(190).Normalize())
+	assert.InDelta(t, -math.Pi/2, gpx.LongitudeType(-90).Radians(), 1e-15)
+
+	assert.NoError(t, gpx.DegreesType(0).Validate())
+	assert.Error(t, gpx.DegreesType(360).Validate())
+	assert.Error(t, gpx.DegreesType(-1).Validate())
+	assert.Equal(t, gpx.DegreesType(0), gpx.DegreesType(360).Normalize())
+	assert.Equal(t, gpx.DegreesType(350), gpx.DegreesType(-10).Normalize())
+	assert.Equal(t, gpx.DegreesType(10), gpx.DegreesType(730).Normalize())
+	assert.NoError(t, gpx.DegreesType(-1e-14).Normalize().Validate())
+	assert.InDelta(t, math.Pi, gpx.DegreesType(180).Radians(), 1e-15)
+
+	assert.NoError(t, gpx.DGPSStationType(0).Validate())
+	assert.NoError(t, gpx.DGPSStationType(1023).Validate())
+	assert.Error(t, gpx.DGPSStationType(1024).Validate())
+	assert.Error(t, gpx.DGPSStationType(-1).Validate())
+}
+
+func TestFixType(t *testing.T) {
+	for i, tc := range []struct {
+		fix         gpx.FixType
+		expectedErr bool
+		hasFix      bool
+		hasFix3D    bool
+	}{
+		{fix: ""},
+		{fix: gpx.FixNone},
+		{fix: gpx.Fix2D, hasFix: true},
+		{fix: gpx.Fix3D, hasFix: true, hasFix3D: true},
+		{fix: gpx.FixDGPS, hasFix: true, hasFix3D: true},
+		{fix: gpx.FixPPS, hasFix: true, hasFix3D: true},
+		{fix: "3D", expectedErr: true, hasFix: true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if tc.expectedErr {
+				assert.Error(t, tc.fix.Validate())
+			} else {
+				assert.NoError(t, tc.fix.Validate())
+			}
+			wpt := &gpx.WptType{Fix: tc.fix}
+			assert.Equal(t, tc.hasFix, wpt.HasFix())
+			assert.Equal(t, tc.hasFix3D, wpt.HasFix3D())
+		})
+	}
+}
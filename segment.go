@@ -0,0 +1,44 @@
+package gpx
+
+// segmentNamespace is the namespace of the track segment name and
+// description extensions. The GPX schema has no name or description for
+// track segments, so this package stores them as extensions in this
+// namespace, which other tools can adopt.
+const segmentNamespace = "https://github.com/twpayne/go-gpx/xmlschemas/TrackSegmentExtension/v1"
+
+// segmentSpaces are the namespaces of track segment extension elements,
+// including the conventional prefix for when it is declared on the gpx
+// element.
+var segmentSpaces = []string{segmentNamespace, "gpxseg"}
+
+// Name returns ts's name from its extensions, or the empty string if it has
+// none.
+func (ts *TrkSegType) Name() string {
+	return ts.Extensions.namespacedExtensions(segmentSpaces...)["name"]
+}
+
+// SetName sets ts's name in its extensions, preserving its other extensions.
+// If name is empty then the name is removed.
+func (ts *TrkSegType) SetName(name string) {
+	ts.setSegmentExtensions(name, ts.Desc())
+}
+
+// Desc returns ts's description from its extensions, or the empty string if
+// it has none.
+func (ts *TrkSegType) Desc() string {
+	return ts.Extensions.namespacedExtensions(segmentSpaces...)["desc"]
+}
+
+// SetDesc sets ts's description in its extensions, preserving its other
+// extensions. If desc is empty then the description is removed.
+func (ts *TrkSegType) SetDesc(desc string) {
+	ts.setSegmentExtensions(ts.Name(), desc)
+}
+
+// setSegmentExtensions replaces ts's segment extensions with name and desc.
+func (ts *TrkSegType) setSegmentExtensions(name, desc string) {
+	ts.Extensions = ts.Extensions.withNamespacedExtensions(segmentSpaces, "gpxseg", segmentNamespace, [][2]string{
+		{"name", name},
+		{"desc", desc},
+	})
+}
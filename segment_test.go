@@ -0,0 +1,49 @@
+package gpx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestTrkSegTypeName(t *testing.T) {
+	ts := &gpx.TrkSegType{
+		Extensions: &gpx.ExtensionsType{XML: []byte("<other/>")},
+	}
+	assert.Equal(t, "", ts.Name())
+	ts.SetName("Climb")
+	ts.SetDesc("Col & summit")
+	assert.Equal(t, "Climb", ts.Name())
+	assert.Equal(t, "Col & summit", ts.Desc())
+	ts.SetName("Descent")
+	assert.Equal(t, "Descent", ts.Name())
+	assert.Equal(t, "Col & summit", ts.Desc())
+
+	g := &gpx.GPX{
+		Version: "1.1",
+		Trk: []*gpx.TrkType{
+			{TrkSeg: []*gpx.TrkSegType{ts}},
+		},
+	}
+	var b bytes.Buffer
+	assert.NoError(t, g.Write(&b))
+	g2, err := gpx.Read(&b)
+	assert.NoError(t, err)
+	ts2 := g2.Trk[0].TrkSeg[0]
+	assert.Equal(t, "Descent", ts2.Name())
+	assert.Equal(t, "Col & summit", ts2.Desc())
+	assert.True(t, strings.HasPrefix(string(ts2.Extensions.XML), "<other/>"))
+
+	ts2.SetName("")
+	ts2.SetDesc("")
+	assert.Equal(t, "<other/>", string(ts2.Extensions.XML))
+
+	ts3 := &gpx.TrkSegType{}
+	ts3.SetName("a")
+	ts3.SetName("")
+	assert.Nil(t, ts3.Extensions)
+}
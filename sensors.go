@@ -0,0 +1,82 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Sensors are the sensor readings recorded at a point. Absent readings are
+// NaN.
+type Sensors struct {
+	HeartRate        float64 // Beats per minute.
+	Cadence          float64 // Revolutions or steps per minute.
+	Power            float64 // Watts.
+	Temperature      float64 // Air temperature in degrees Celsius.
+	WaterTemperature float64 // Degrees Celsius.
+	Depth            float64 // Meters.
+	Distance         float64 // Meters from the start of the activity.
+}
+
+// sensorElements maps the local names of sensor extension elements to the
+// Sensors fields they set. They include the elements of Garmin's
+// TrackPointExtension v1 and v2, the Cluetrust gpxdata extension used by
+// older Polar and phone apps, and the power element written by Strava and
+// others.
+var sensorElements = map[string]func(*Sensors) *float64{
+	"hr":       func(s *Sensors) *float64 { return &s.HeartRate },
+	"cad":      func(s *Sensors) *float64 { return &s.Cadence },
+	"cadence":  func(s *Sensors) *float64 { return &s.Cadence },
+	"power":    func(s *Sensors) *float64 { return &s.Power },
+	"atemp":    func(s *Sensors) *float64 { return &s.Temperature },
+	"temp":     func(s *Sensors) *float64 { return &s.Temperature },
+	"wtemp":    func(s *Sensors) *float64 { return &s.WaterTemperature },
+	"depth":    func(s *Sensors) *float64 { return &s.Depth },
+	"distance": func(s *Sensors) *float64 { return &s.Distance },
+}
+
+// Sensors returns the sensor readings in w's extensions, regardless of which
+// vendor's extension recorded them. Where a reading occurs more than once,
+// the first is used.
+func (w *WptType) Sensors() Sensors {
+	sensors := Sensors{
+		HeartRate:        math.NaN(),
+		Cadence:          math.NaN(),
+		Power:            math.NaN(),
+		Temperature:      math.NaN(),
+		WaterTemperature: math.NaN(),
+		Depth:            math.NaN(),
+		Distance:         math.NaN(),
+	}
+	if w.Extensions == nil || len(w.Extensions.XML) == 0 {
+		return sensors
+	}
+	d := xml.NewDecoder(bytes.NewReader(w.Extensions.XML))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return sensors
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		field, ok := sensorElements[start.Name.Local]
+		if !ok {
+			continue
+		}
+		var text string
+		if err := d.DecodeElement(&text, &start); err != nil {
+			return sensors
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			continue
+		}
+		if p := field(&sensors); math.IsNaN(*p) {
+			*p = value
+		}
+	}
+}
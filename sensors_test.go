@@ -0,0 +1,75 @@
+package gpx_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestWptTypeSensors(t *testing.T) {
+	nan := math.NaN()
+	for i, tc := range []struct {
+		extensions string
+		expected   gpx.Sensors
+	}{
+		{
+			expected: gpx.Sensors{
+				HeartRate: nan, Cadence: nan, Power: nan, Temperature: nan, WaterTemperature: nan, Depth: nan, Distance: nan,
+			},
+		},
+		{
+			extensions: `<gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">` +
+				`<gpxtpx:atemp>21.5</gpxtpx:atemp><gpxtpx:wtemp>14</gpxtpx:wtemp><gpxtpx:depth>3.2</gpxtpx:depth><gpxtpx:hr>142</gpxtpx:hr><gpxtpx:cad>88</gpxtpx:cad>` +
+				`</gpxtpx:TrackPointExtension><power>250</power>`,
+			expected: gpx.Sensors{
+				HeartRate: 142, Cadence: 88, Power: 250, Temperature: 21.5, WaterTemperature: 14, Depth: 3.2, Distance: nan,
+			},
+		},
+		{
+			extensions: `<gpxdata:hr xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0">120</gpxdata:hr>` +
+				`<gpxdata:cadence xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0">80</gpxdata:cadence>` +
+				`<gpxdata:temp xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0">0</gpxdata:temp>` +
+				`<gpxdata:distance xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0">1234.5</gpxdata:distance>`,
+			expected: gpx.Sensors{
+				HeartRate: 120, Cadence: 80, Power: nan, Temperature: 0, WaterTemperature: nan, Depth: nan, Distance: 1234.5,
+			},
+		},
+		{
+			extensions: `<gpxdata:hr>bad</gpxdata:hr><gpxdata:hr>130</gpxdata:hr><hr>140</hr>`,
+			expected: gpx.Sensors{
+				HeartRate: 130, Cadence: nan, Power: nan, Temperature: nan, WaterTemperature: nan, Depth: nan, Distance: nan,
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			wpt := &gpx.WptType{}
+			if tc.extensions != "" {
+				wpt.Extensions = &gpx.ExtensionsType{XML: []byte(tc.extensions)}
+			}
+			got := wpt.Sensors()
+			for _, field := range []struct {
+				name     string
+				expected float64
+				got      float64
+			}{
+				{"HeartRate", tc.expected.HeartRate, got.HeartRate},
+				{"Cadence", tc.expected.Cadence, got.Cadence},
+				{"Power", tc.expected.Power, got.Power},
+				{"Temperature", tc.expected.Temperature, got.Temperature},
+				{"WaterTemperature", tc.expected.WaterTemperature, got.WaterTemperature},
+				{"Depth", tc.expected.Depth, got.Depth},
+				{"Distance", tc.expected.Distance, got.Distance},
+			} {
+				if math.IsNaN(field.expected) {
+					assert.True(t, math.IsNaN(field.got), field.name)
+				} else {
+					assert.Equal(t, field.expected, field.got, field.name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package gpx
+
+import "math"
+
+// FrechetDistance returns the discrete Fréchet distance in meters between a
+// and b, the smallest maximum distance between corresponding points when
+// traversing both segments in order. It returns +Inf if either segment is
+// empty.
+func FrechetDistance(a, b *TrkSegType) float64 {
+	return warpingDistance(a.TrkPt, b.TrkPt, math.Max)
+}
+
+// DTWDistance returns the dynamic time warping distance between a and b, the
+// smallest sum of distances in meters between corresponding points when
+// traversing both segments in order. The sum is not normalized, so it grows
+// with the number of points. It returns +Inf if either segment is empty.
+func DTWDistance(a, b *TrkSegType) float64 {
+	return warpingDistance(a.TrkPt, b.TrkPt, func(cost, distance float64) float64 {
+		return cost + distance
+	})
+}
+
+// Overlap returns the fraction of the length of a that lies within tolerance
+// meters of b, in the range [0, 1]. It is measured by sampling a at
+// intervals no longer than tolerance. Overlap is not symmetric.
+func Overlap(a, b *TrkSegType, tolerance float64) float64 {
+	total, overlapping := 0.0, 0.0
+	for i := 1; i < len(a.TrkPt); i++ {
+		p, q := a.TrkPt[i-1], a.TrkPt[i]
+		length := HaversineDistance(p.Lat, p.Lon, q.Lat, q.Lon)
+		total += length
+		samples := 1
+		if tolerance > 0 {
+			samples = int(math.Min(math.Ceil(length/tolerance), 1000))
+		}
+		for j := 0; j < samples; j++ {
+			f := (float64(j) + 0.5) / float64(samples)
+			lat := p.Lat + f*(q.Lat-p.Lat)
+			lon := p.Lon + f*normalizeLon(q.Lon-p.Lon)
+			if distanceToPath(b.TrkPt, lat, lon) <= tolerance {
+				overlapping += length / float64(samples)
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Min(overlapping/total, 1)
+}
+
+// warpingDistance returns the cost of the cheapest monotonic coupling of a
+// and b, where accumulate combines the cost of a coupling with the distance
+// between the next pair of points.
+func warpingDistance(a, b []*WptType, accumulate func(cost, distance float64) float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return math.Inf(1)
+	}
+	prev := make([]float64, len(b))
+	curr := make([]float64, len(b))
+	for i, p := range a {
+		for j, q := range b {
+			distance := HaversineDistance(p.Lat, p.Lon, q.Lat, q.Lon)
+			switch {
+			case i == 0 && j == 0:
+				curr[j] = distance
+			case i == 0:
+				curr[j] = accumulate(curr[j-1], distance)
+			case j == 0:
+				curr[j] = accumulate(prev[j], distance)
+			default:
+				curr[j] = accumulate(math.Min(prev[j-1], math.Min(prev[j], curr[j-1])), distance)
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)-1]
+}
+
+// distanceToPath returns the distance in meters from lat and lon to the
+// nearest point on the path through wpts, or +Inf if wpts is empty.
+func distanceToPath(wpts []*WptType, lat, lon float64) float64 {
+	switch len(wpts) {
+	case 0:
+		return math.Inf(1)
+	case 1:
+		return HaversineDistance(lat, lon, wpts[0].Lat, wpts[0].Lon)
+	}
+	result := math.Inf(1)
+	for i := 1; i < len(wpts); i++ {
+		a, b := wpts[i-1], wpts[i]
+		f := segmentFraction(a, b, lat, lon)
+		nearestLat := a.Lat + f*(b.Lat-a.Lat)
+		nearestLon := a.Lon + f*normalizeLon(b.Lon-a.Lon)
+		result = math.Min(result, HaversineDistance(lat, lon, nearestLat, nearestLon))
+	}
+	return result
+}
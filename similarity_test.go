@@ -0,0 +1,94 @@
+package gpx_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestFrechetDistance(t *testing.T) {
+	a := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.001},
+			{Lat: 0, Lon: 0.002},
+			{Lat: 0, Lon: 0.003},
+		},
+	}
+	b := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0.0001, Lon: 0},
+			{Lat: 0.0001, Lon: 0.0015},
+			{Lat: 0.0001, Lon: 0.003},
+		},
+	}
+	offset := gpx.HaversineDistance(0, 0, 0.0001, 0)
+
+	assert.Equal(t, 0.0, gpx.FrechetDistance(a, a))
+	assert.InDelta(t, gpx.HaversineDistance(0, 0.001, 0.0001, 0.0015), gpx.FrechetDistance(a, b), 1e-6)
+	assert.Equal(t, gpx.FrechetDistance(a, b), gpx.FrechetDistance(b, a))
+
+	// Reversing a segment makes it dissimilar.
+	reversed := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0.003},
+			{Lat: 0, Lon: 0.002},
+			{Lat: 0, Lon: 0.001},
+			{Lat: 0, Lon: 0},
+		},
+	}
+	assert.InDelta(t, gpx.HaversineDistance(0, 0, 0, 0.003), gpx.FrechetDistance(a, reversed), 1e-6)
+
+	assert.True(t, math.IsInf(gpx.FrechetDistance(a, &gpx.TrkSegType{}), 1))
+	assert.Less(t, offset, gpx.FrechetDistance(a, b))
+}
+
+func TestDTWDistance(t *testing.T) {
+	a := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.001},
+			{Lat: 0, Lon: 0.002},
+		},
+	}
+	b := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0.0001, Lon: 0},
+			{Lat: 0.0001, Lon: 0.001},
+			{Lat: 0.0001, Lon: 0.001},
+			{Lat: 0.0001, Lon: 0.002},
+		},
+	}
+	offset := gpx.HaversineDistance(0, 0, 0.0001, 0)
+
+	assert.Equal(t, 0.0, gpx.DTWDistance(a, a))
+	assert.InDelta(t, 4*offset, gpx.DTWDistance(a, b), 1e-6)
+	assert.True(t, math.IsInf(gpx.DTWDistance(&gpx.TrkSegType{}, b), 1))
+}
+
+func TestOverlap(t *testing.T) {
+	a := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Lon: 0},
+			{Lat: 0, Lon: 0.001},
+			{Lat: 0, Lon: 0.002},
+			{Lat: 0, Lon: 0.003},
+			{Lat: 0, Lon: 0.004},
+		},
+	}
+	b := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0.00005, Lon: 0.0005},
+			{Lat: 0.00005, Lon: 0.0025},
+		},
+	}
+
+	assert.InDelta(t, 1, gpx.Overlap(a, a, 1), 1e-9)
+	assert.InDelta(t, 0.5, gpx.Overlap(a, b, 10), 0.05)
+	assert.Equal(t, 0.0, gpx.Overlap(a, b, 1))
+	assert.InDelta(t, 1, gpx.Overlap(b, a, 10), 1e-9)
+	assert.Equal(t, 0.0, gpx.Overlap(&gpx.TrkSegType{}, a, 10))
+}
@@ -0,0 +1,50 @@
+package gpx
+
+import "math"
+
+// Speeds returns the speed in meters per second at each of ts's points,
+// smoothed over up to window timed points on either side. Values of window
+// less than one are treated as one. The speed at points without times, or at
+// which the speed cannot be determined, is NaN.
+func (ts *TrkSegType) Speeds(window int) []float64 {
+	if len(ts.TrkPt) == 0 {
+		return nil
+	}
+	window = max(window, 1)
+	distances := cumulativeDistances(ts.TrkPt)
+	var timed []int
+	for i, trkPt := range ts.TrkPt {
+		if !trkPt.Time.IsZero() {
+			timed = append(timed, i)
+		}
+	}
+	speeds := make([]float64, len(ts.TrkPt))
+	for i := range speeds {
+		speeds[i] = math.NaN()
+	}
+	for k, i := range timed {
+		lo := timed[max(k-window, 0)]
+		hi := timed[min(k+window, len(timed)-1)]
+		if dt := ts.TrkPt[hi].Time.Sub(ts.TrkPt[lo].Time).Seconds(); dt > 0 {
+			speeds[i] = (distances[hi] - distances[lo]) / dt
+		}
+	}
+	return speeds
+}
+
+// Paces returns the pace in seconds per kilometer at each of ts's points,
+// derived from Speeds(window). The pace at stationary points is +Inf and the
+// pace at points whose speed is unknown is NaN.
+func (ts *TrkSegType) Paces(window int) []float64 {
+	speeds := ts.Speeds(window)
+	for i, speed := range speeds {
+		switch {
+		case math.IsNaN(speed):
+		case speed == 0:
+			speeds[i] = math.Inf(1)
+		default:
+			speeds[i] = 1000 / speed
+		}
+	}
+	return speeds
+}
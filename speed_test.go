@@ -0,0 +1,70 @@
+package gpx_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestSpeeds(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	step := 10 / gpx.HaversineDistance(0, 0, 0, 1)
+	// Points every 10m, at 10s, 10s, untimed, 20s, and 10s intervals, then
+	// stationary for 10s.
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lon: 0 * step, Time: t0},
+			{Lon: 1 * step, Time: t0.Add(10 * time.Second)},
+			{Lon: 2 * step, Time: t0.Add(20 * time.Second)},
+			{Lon: 3 * step},
+			{Lon: 4 * step, Time: t0.Add(40 * time.Second)},
+			{Lon: 5 * step, Time: t0.Add(50 * time.Second)},
+			{Lon: 5 * step, Time: t0.Add(60 * time.Second)},
+		},
+	}
+
+	got := ts.Speeds(0)
+	assert.Len(t, got, 7)
+	for i, expected := range []float64{1, 1, 1, math.NaN(), 1, 0.5, 0} {
+		if math.IsNaN(expected) {
+			assert.True(t, math.IsNaN(got[i]))
+		} else {
+			assert.InDelta(t, expected, got[i], 1e-6)
+		}
+	}
+
+	got = ts.Speeds(100)
+	for i, speed := range got {
+		if i == 3 {
+			assert.True(t, math.IsNaN(speed))
+		} else {
+			assert.InDelta(t, 50.0/60, speed, 1e-6)
+		}
+	}
+
+	paces := ts.Paces(1)
+	assert.InDelta(t, 1000, paces[0], 1e-3)
+	assert.True(t, math.IsNaN(paces[3]))
+
+	stationary := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Time: t0},
+			{Time: t0.Add(time.Second)},
+		},
+	}
+	assert.True(t, math.IsInf(stationary.Paces(1)[0], 1))
+
+	sameTime := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lon: 0, Time: t0},
+			{Lon: step, Time: t0},
+		},
+	}
+	assert.True(t, math.IsNaN(sameTime.Speeds(1)[0]))
+
+	assert.Nil(t, (&gpx.TrkSegType{}).Speeds(1))
+}
@@ -0,0 +1,185 @@
+package gpx
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// dateLayout is the layout of the keys returned by SplitByDay.
+const dateLayout = "2006-01-02"
+
+// SplitByDay splits g into one document per calendar day in loc, keyed by
+// date in the form YYYY-MM-DD. Track segments that span midnight are split.
+// Track points without times belong to the same day as the previous point, or
+// the next point if they are at the start of a segment, and segments without
+// any times are returned under the key "". Waypoints without times and routes
+// are added to the document for the first day, or to the document under the
+// key "" if g contains no times. g is not modified, but the returned
+// documents share points with g.
+func SplitByDay(g *GPX, loc *time.Location) map[string]*GPX {
+	return splitByDay(g, func(wpt *WptType) string {
+		if wpt.Time.IsZero() {
+			return ""
+		}
+		return wpt.Time.In(loc).Format(dateLayout)
+	})
+}
+
+// SplitByLocalDay splits g into one document per calendar day, as SplitByDay,
+// except that the day of each point is determined in the time zone at its
+// position from tz, so that tours crossing time zones are split at local
+// midnight. The documents are returned in date order, followed by the
+// document for points without times, if any. Each document has its own copy
+// of g's metadata.
+func (g *GPX) SplitByLocalDay(tz TimeZoneResolver) ([]*GPX, error) {
+	var err error
+	docs := splitByDay(g, func(wpt *WptType) string {
+		if wpt.Time.IsZero() || err != nil {
+			return ""
+		}
+		var loc *time.Location
+		if loc, err = tz.Location(wpt.Lat, wpt.Lon); err != nil {
+			err = fmt.Errorf("%f,%f: %w", wpt.Lat, wpt.Lon, err)
+			return ""
+		}
+		return wpt.Time.In(loc).Format(dateLayout)
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(docs))
+	for key := range docs {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := docs[""]; ok {
+		keys = append(keys, "")
+	}
+	result := make([]*GPX, 0, len(keys))
+	for _, key := range keys {
+		doc := docs[key]
+		if doc.Metadata != nil {
+			metadata := *doc.Metadata
+			doc.Metadata = &metadata
+		}
+		result = append(result, doc)
+	}
+	return result, nil
+}
+
+// SplitByTrack splits g into one document per track, in order. Each
+// document has its own copy of g's metadata and contains g's waypoints and
+// routes. g is not modified, but the returned documents share points with g.
+func (g *GPX) SplitByTrack() []*GPX {
+	result := make([]*GPX, 0, len(g.Trk))
+	for _, trk := range g.Trk {
+		doc := &GPX{
+			XMLSchemaLocations: g.XMLSchemaLocations,
+			XMLAttrs:           g.XMLAttrs,
+			Version:            g.Version,
+			Creator:            g.Creator,
+			Wpt:                g.Wpt,
+			Rte:                g.Rte,
+			Trk:                []*TrkType{trk},
+		}
+		if g.Metadata != nil {
+			metadata := *g.Metadata
+			doc.Metadata = &metadata
+		}
+		result = append(result, doc)
+	}
+	return result
+}
+
+// splitByDay splits g into one document per day, as returned by day for
+// each point.
+func splitByDay(g *GPX, day func(*WptType) string) map[string]*GPX {
+	result := make(map[string]*GPX)
+	get := func(key string) *GPX {
+		if doc, ok := result[key]; ok {
+			return doc
+		}
+		doc := &GPX{
+			XMLSchemaLocations: g.XMLSchemaLocations,
+			XMLAttrs:           g.XMLAttrs,
+			Version:            g.Version,
+			Creator:            g.Creator,
+			Metadata:           g.Metadata,
+		}
+		result[key] = doc
+		return doc
+	}
+
+	for _, trk := range g.Trk {
+		trksByDay := make(map[string]*TrkType)
+		for _, ts := range trk.TrkSeg {
+			key := ""
+			for _, trkPt := range ts.TrkPt {
+				if key = day(trkPt); key != "" {
+					break
+				}
+			}
+			var trkPts []*WptType
+			flush := func() {
+				if len(trkPts) == 0 {
+					return
+				}
+				dayTrk, ok := trksByDay[key]
+				if !ok {
+					dayTrk = &TrkType{
+						Name:       trk.Name,
+						Cmt:        trk.Cmt,
+						Desc:       trk.Desc,
+						Src:        trk.Src,
+						Link:       trk.Link,
+						Number:     trk.Number,
+						Type:       trk.Type,
+						Extensions: trk.Extensions,
+					}
+					trksByDay[key] = dayTrk
+					doc := get(key)
+					doc.Trk = append(doc.Trk, dayTrk)
+				}
+				dayTrk.TrkSeg = append(dayTrk.TrkSeg, &TrkSegType{
+					TrkPt:      trkPts,
+					Extensions: ts.Extensions,
+				})
+				trkPts = nil
+			}
+			for _, trkPt := range ts.TrkPt {
+				if trkPtKey := day(trkPt); trkPtKey != "" && trkPtKey != key {
+					flush()
+					key = trkPtKey
+				}
+				trkPts = append(trkPts, trkPt)
+			}
+			flush()
+		}
+	}
+
+	var untimedWpts []*WptType
+	for _, wpt := range g.Wpt {
+		if key := day(wpt); key != "" {
+			doc := get(key)
+			doc.Wpt = append(doc.Wpt, wpt)
+		} else {
+			untimedWpts = append(untimedWpts, wpt)
+		}
+	}
+
+	if len(untimedWpts) != 0 || len(g.Rte) != 0 || len(result) == 0 {
+		first := ""
+		for key := range result {
+			if key != "" && (first == "" || key < first) {
+				first = key
+			}
+		}
+		doc := get(first)
+		doc.Wpt = append(untimedWpts, doc.Wpt...)
+		doc.Rte = append(doc.Rte, g.Rte...)
+	}
+	return result
+}
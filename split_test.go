@@ -0,0 +1,172 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestSplitByDay(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	day1 := time.Date(2024, 7, 1, 21, 0, 0, 0, time.UTC) // 23:00 local.
+	day2 := day1.Add(2 * time.Hour)                      // 01:00 local.
+	day3 := day2.Add(24 * time.Hour)
+
+	trkPts := []*gpx.WptType{
+		{Lat: 1, Time: day1},
+		{Lat: 2},
+		{Lat: 3, Time: day2},
+		{Lat: 4, Time: day3},
+	}
+	untimedWpt := &gpx.WptType{Name: "camp"}
+	timedWpt := &gpx.WptType{Name: "summit", Time: day3}
+	rte := &gpx.RteType{Name: "plan"}
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Wpt:     []*gpx.WptType{untimedWpt, timedWpt},
+		Rte:     []*gpx.RteType{rte},
+		Trk: []*gpx.TrkType{
+			{
+				Name: "expedition",
+				TrkSeg: []*gpx.TrkSegType{
+					{TrkPt: trkPts},
+				},
+			},
+		},
+	}
+
+	got := gpx.SplitByDay(g, loc)
+	assert.Equal(t, map[string]*gpx.GPX{
+		"2024-07-01": {
+			Version: "1.1",
+			Creator: "test",
+			Wpt:     []*gpx.WptType{untimedWpt},
+			Rte:     []*gpx.RteType{rte},
+			Trk: []*gpx.TrkType{
+				{
+					Name: "expedition",
+					TrkSeg: []*gpx.TrkSegType{
+						{TrkPt: trkPts[0:2]},
+					},
+				},
+			},
+		},
+		"2024-07-02": {
+			Version: "1.1",
+			Creator: "test",
+			Trk: []*gpx.TrkType{
+				{
+					Name: "expedition",
+					TrkSeg: []*gpx.TrkSegType{
+						{TrkPt: trkPts[2:3]},
+					},
+				},
+			},
+		},
+		"2024-07-03": {
+			Version: "1.1",
+			Creator: "test",
+			Wpt:     []*gpx.WptType{timedWpt},
+			Trk: []*gpx.TrkType{
+				{
+					Name: "expedition",
+					TrkSeg: []*gpx.TrkSegType{
+						{TrkPt: trkPts[3:4]},
+					},
+				},
+			},
+		},
+	}, got)
+	assert.Len(t, g.Trk[0].TrkSeg[0].TrkPt, 4)
+
+	// In UTC all the points before day3 are on the same day.
+	got = gpx.SplitByDay(g, time.UTC)
+	assert.Len(t, got, 2)
+	assert.Len(t, got["2024-07-01"].Trk[0].TrkSeg[0].TrkPt, 3)
+}
+
+func TestSplitByDayUntimed(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{{Name: "a"}},
+		Trk: []*gpx.TrkType{
+			{TrkSeg: []*gpx.TrkSegType{{TrkPt: []*gpx.WptType{{Lat: 1}}}}},
+		},
+	}
+	got := gpx.SplitByDay(g, time.UTC)
+	assert.Equal(t, map[string]*gpx.GPX{"": g}, got)
+
+	assert.Equal(t, map[string]*gpx.GPX{"": {}}, gpx.SplitByDay(&gpx.GPX{}, time.UTC))
+}
+
+func TestSplitByLocalDay(t *testing.T) {
+	// 22:30 UTC is 23:30 in Basel (UTC+1) but 00:30 the next day in Kyiv
+	// (UTC+2).
+	t0 := time.Date(2024, 7, 1, 22, 30, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Version: "1.1",
+		Metadata: &gpx.MetadataType{
+			Name: "Tour",
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 47.5, Lon: 7.6, Time: t0},
+							{Lat: 50.4, Lon: 30.5, Time: t0.Add(time.Minute)},
+						},
+					},
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 50.4, Lon: 30.5},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := g.SplitByLocalDay(gpx.NauticalTimeZones)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, 47.5, got[0].Trk[0].TrkSeg[0].TrkPt[0].Lat)
+	assert.Equal(t, 50.4, got[1].Trk[0].TrkSeg[0].TrkPt[0].Lat)
+	assert.True(t, got[2].Trk[0].TrkSeg[0].TrkPt[0].Time.IsZero())
+	for _, doc := range got {
+		assert.Equal(t, "Tour", doc.Metadata.Name)
+	}
+	got[0].Metadata.Name = "Day 1"
+	assert.Equal(t, "Tour", g.Metadata.Name)
+	assert.Equal(t, "Tour", got[1].Metadata.Name)
+
+	got, err = g.SplitByLocalDay(gpx.TimeZoneResolverFunc(func(_, _ float64) (*time.Location, error) {
+		return time.UTC, nil
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestSplitByTrack(t *testing.T) {
+	g := &gpx.GPX{
+		Version:  "1.1",
+		Metadata: &gpx.MetadataType{Name: "Tour"},
+		Wpt:      []*gpx.WptType{{Name: "camp"}},
+		Trk: []*gpx.TrkType{
+			{Name: "Day 1"},
+			{Name: "Day 2"},
+		},
+	}
+	got := g.SplitByTrack()
+	assert.Len(t, got, 2)
+	for i, doc := range got {
+		assert.Equal(t, "1.1", doc.Version)
+		assert.Equal(t, g.Wpt, doc.Wpt)
+		assert.Equal(t, []*gpx.TrkType{g.Trk[i]}, doc.Trk)
+		assert.Equal(t, g.Metadata, doc.Metadata)
+		assert.NotSame(t, g.Metadata, doc.Metadata)
+	}
+}
@@ -0,0 +1,65 @@
+package gpx
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+// Value implements database/sql/driver.Valuer.Value. It returns g in the
+// canonical format of CanonicalBytes as a string, for storing in text and XML
+// columns.
+func (g *GPX) Value() (driver.Value, error) {
+	data, err := CanonicalBytes(g)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements database/sql.Scanner.Scan. src must be a string or a []byte
+// containing a GPX document.
+func (g *GPX) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return fmt.Errorf("%T: cannot scan into GPX", src)
+	}
+	result, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	*g = *result
+	return nil
+}
+
+// Value implements database/sql/driver.Valuer.Value. It returns r's XY
+// geometry as a WKB LineString.
+func (r *RteType) Value() (driver.Value, error) {
+	return wkb.Marshal(r.Geom(geom.XY), wkb.NDR)
+}
+
+// Value implements database/sql/driver.Valuer.Value. It returns t's XY
+// geometry as a WKB MultiLineString.
+func (t *TrkType) Value() (driver.Value, error) {
+	return wkb.Marshal(t.Geom(geom.XY), wkb.NDR)
+}
+
+// Value implements database/sql/driver.Valuer.Value. It returns ts's XY
+// geometry as a WKB LineString.
+func (ts *TrkSegType) Value() (driver.Value, error) {
+	return wkb.Marshal(ts.Geom(geom.XY), wkb.NDR)
+}
+
+// Value implements database/sql/driver.Valuer.Value. It returns w's XY
+// geometry as a WKB Point.
+func (w *WptType) Value() (driver.Value, error) {
+	return wkb.Marshal(w.Geom(geom.XY), wkb.NDR)
+}
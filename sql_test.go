@@ -0,0 +1,76 @@
+package gpx_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestValue(t *testing.T) {
+	trkSeg := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 2, Lon: 1, Ele: 10},
+			{Lat: 4, Lon: 3, Ele: 20},
+		},
+	}
+	for _, tc := range []struct {
+		valuer   driver.Valuer
+		expected geom.T
+	}{
+		{
+			valuer:   trkSeg.TrkPt[0],
+			expected: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			valuer:   trkSeg,
+			expected: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+		},
+		{
+			valuer:   &gpx.RteType{RtePt: trkSeg.TrkPt},
+			expected: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+		},
+		{
+			valuer:   &gpx.TrkType{TrkSeg: []*gpx.TrkSegType{trkSeg}},
+			expected: geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}, {3, 4}}}),
+		},
+	} {
+		value, err := tc.valuer.Value()
+		assert.NoError(t, err)
+		data, ok := value.([]byte)
+		assert.True(t, ok)
+		got, err := wkb.Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, got)
+	}
+}
+
+func TestGPXValueScan(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Wpt: []*gpx.WptType{
+			{Lat: 1, Lon: 2, Name: "name"},
+		},
+	}
+	value, err := g.Value()
+	assert.NoError(t, err)
+	canonicalBytes, err := gpx.CanonicalBytes(g)
+	assert.NoError(t, err)
+	assert.Equal(t, string(canonicalBytes), value)
+
+	for _, src := range []any{value, canonicalBytes} {
+		var got gpx.GPX
+		assert.NoError(t, got.Scan(src))
+		assert.Equal(t, g, &got)
+	}
+
+	var got gpx.GPX
+	assert.Error(t, got.Scan(nil))
+	assert.Error(t, got.Scan(1))
+	assert.Error(t, got.Scan("<gpx>"))
+}
@@ -0,0 +1,162 @@
+package gpx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"sync"
+)
+
+// srtmVoid is the value of SRTM samples with no data.
+const srtmVoid = -32768
+
+// An SRTMTiles is an ElevationProvider that uses SRTM .hgt tiles, such as
+// N46E007.hgt, optionally gzip-compressed as N46E007.hgt.gz. Both 1 and 3
+// arc-second tiles are supported. Elevations are bilinearly interpolated.
+// Points in missing tiles or near voids have unknown elevations. Tiles are
+// loaded on demand and cached.
+type SRTMTiles struct {
+	store Store
+	mutex sync.Mutex
+	tiles map[string]*srtmTile
+}
+
+// An srtmTile is a loaded SRTM tile.
+type srtmTile struct {
+	size    int
+	samples []int16
+}
+
+// NewSRTMTiles returns a new SRTMTiles that reads tiles from fsys.
+func NewSRTMTiles(fsys fs.FS) *SRTMTiles {
+	return NewSRTMTilesStore(NewFSStore(fsys))
+}
+
+// NewSRTMTilesStore returns a new SRTMTiles that reads tiles from store, with
+// keys that are the tiles' filenames.
+func NewSRTMTilesStore(store Store) *SRTMTiles {
+	return &SRTMTiles{
+		store: store,
+		tiles: make(map[string]*srtmTile),
+	}
+}
+
+// Elevations implements ElevationProvider.
+func (s *SRTMTiles) Elevations(ctx context.Context, wpts []*WptType) ([]float64, error) {
+	elevations := make([]float64, len(wpts))
+	for i, wpt := range wpts {
+		elevation, err := s.elevation(ctx, wpt.Lat, normalizeLon(wpt.Lon))
+		if err != nil {
+			return nil, err
+		}
+		elevations[i] = elevation
+	}
+	return elevations, nil
+}
+
+// elevation returns the elevation at lat, lon.
+func (s *SRTMTiles) elevation(ctx context.Context, lat, lon float64) (float64, error) {
+	tileLat, tileLon := math.Floor(lat), math.Floor(lon)
+	tile, err := s.tile(ctx, int(tileLat), int(tileLon))
+	if err != nil || tile == nil {
+		return math.NaN(), err
+	}
+	// Rows run from north to south and columns from west to east.
+	y := (tileLat + 1 - lat) * float64(tile.size-1)
+	x := (lon - tileLon) * float64(tile.size-1)
+	row, col := min(int(y), tile.size-2), min(int(x), tile.size-2)
+	fy, fx := y-float64(row), x-float64(col)
+	result := 0.0
+	for _, corner := range []struct {
+		row, col int
+		weight   float64
+	}{
+		{row, col, (1 - fy) * (1 - fx)},
+		{row, col + 1, (1 - fy) * fx},
+		{row + 1, col, fy * (1 - fx)},
+		{row + 1, col + 1, fy * fx},
+	} {
+		sample := tile.samples[corner.row*tile.size+corner.col]
+		if sample == srtmVoid {
+			if corner.weight == 0 {
+				continue
+			}
+			return math.NaN(), nil
+		}
+		result += corner.weight * float64(sample)
+	}
+	return result, nil
+}
+
+// tile returns the tile whose south-west corner is at lat, lon, or nil if
+// there is no such tile.
+func (s *SRTMTiles) tile(ctx context.Context, lat, lon int) (*srtmTile, error) {
+	latHemisphere, lonHemisphere := 'N', 'E'
+	if lat < 0 {
+		latHemisphere = 'S'
+	}
+	if lon < 0 {
+		lonHemisphere = 'W'
+	}
+	name := fmt.Sprintf("%c%02d%c%03d.hgt", latHemisphere, abs(lat), lonHemisphere, abs(lon))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if tile, ok := s.tiles[name]; ok {
+		return tile, nil
+	}
+	tile, err := s.loadTile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.tiles[name] = tile
+	return tile, nil
+}
+
+// loadTile loads the tile called name, or its gzip-compressed equivalent. It
+// returns nil if neither exists.
+func (s *SRTMTiles) loadTile(ctx context.Context, name string) (*srtmTile, error) {
+	data, err := s.store.Get(ctx, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = s.readGzipped(ctx, name+".gz")
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil //nolint:nilnil
+	case err != nil:
+		return nil, err
+	}
+	size := int(math.Round(math.Sqrt(float64(len(data) / 2))))
+	if size < 2 || 2*size*size != len(data) {
+		return nil, fmt.Errorf("%s: invalid SRTM tile size %d", name, len(data))
+	}
+	tile := &srtmTile{
+		size:    size,
+		samples: make([]int16, size*size),
+	}
+	for i := range tile.samples {
+		tile.samples[i] = int16(binary.BigEndian.Uint16(data[2*i:])) //nolint:gosec
+	}
+	return tile, nil
+}
+
+// readGzipped returns the decompressed contents of the gzip-compressed file
+// called name.
+func (s *SRTMTiles) readGzipped(ctx context.Context, name string) ([]byte, error) {
+	data, err := s.store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
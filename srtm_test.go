@@ -0,0 +1,85 @@
+package gpx_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+// newSRTMTestTile returns an SRTM tile with the given samples, in rows from
+// north to south.
+func newSRTMTestTile(t *testing.T, samples ...int16) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	assert.NoError(t, binary.Write(&b, binary.BigEndian, samples))
+	return b.Bytes()
+}
+
+func TestSRTMTiles(t *testing.T) {
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	_, err := zw.Write(newSRTMTestTile(t,
+		10, 10,
+		10, 10,
+	))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	fsys := fstest.MapFS{
+		"N46E007.hgt": &fstest.MapFile{
+			Data: newSRTMTestTile(t,
+				200, 300, 400,
+				100, 200, -32768,
+				0, 100, 200,
+			),
+		},
+		"S01W001.hgt.gz": &fstest.MapFile{
+			Data: gzipped.Bytes(),
+		},
+		"N00E000.hgt": &fstest.MapFile{
+			Data: []byte{0, 1, 2},
+		},
+	}
+	provider := gpx.NewSRTMTiles(fsys)
+
+	got, err := provider.Elevations(context.Background(), []*gpx.WptType{
+		{Lat: 46, Lon: 7},
+		{Lat: 46.5, Lon: 7.5},
+		{Lat: 46.5, Lon: 7},
+		{Lat: 46.25, Lon: 7.25},
+		{Lat: 46.75, Lon: 7.75},
+		{Lat: 46.75, Lon: 7.25},
+		{Lat: -0.5, Lon: -0.5},
+		{Lat: 10, Lon: 10},
+	})
+	assert.NoError(t, err)
+	assertElevations(t, []float64{0, 200, 100, 100, math.NaN(), 200, 10, math.NaN()}, got)
+
+	_, err = provider.Elevations(context.Background(), []*gpx.WptType{{Lat: 0.5, Lon: 0.5}})
+	assert.Error(t, err)
+}
+
+func TestSRTMTilesStore(t *testing.T) {
+	store := gpx.NewMemoryStore()
+	assert.NoError(t, store.Put(context.Background(), "N46E007.hgt", newSRTMTestTile(t,
+		100, 200,
+		0, 100,
+	)))
+	provider := gpx.NewSRTMTilesStore(store)
+
+	got, err := provider.Elevations(context.Background(), []*gpx.WptType{
+		{Lat: 46.5, Lon: 7.5},
+		{Lat: 47.5, Lon: 7.5},
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 100, got[0], 1e-9)
+	assert.True(t, math.IsNaN(got[1]))
+}
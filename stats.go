@@ -0,0 +1,234 @@
+package gpx
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// monthLayout is the layout of the keys of StatsSummary.ByMonth.
+const monthLayout = "2006-01"
+
+// Stats are totals over a set of tracks.
+type Stats struct {
+	Tracks int
+	// Distance is the total length in meters.
+	Distance float64
+	// Duration is the total time between the first and last track points of
+	// each segment.
+	Duration time.Duration
+	// ElevationGain is the total ascent in meters. Points without elevations
+	// are ignored.
+	ElevationGain float64
+}
+
+// A StatsSummary is the result of AggregateStats.
+type StatsSummary struct {
+	Total Stats
+	// ByMonth contains stats by the month, in the form YYYY-MM in UTC, of each
+	// track's first timed point. Tracks without times are under the key "".
+	ByMonth map[string]*Stats
+	// ByType contains stats by each track's type.
+	ByType map[string]*Stats
+}
+
+// AggregateStats returns stats for all tracks in docs, computed concurrently
+// using up to workers goroutines, or runtime.GOMAXPROCS(0) goroutines if
+// workers is not positive.
+func AggregateStats(docs []*GPX, workers int) *StatsSummary {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(docs) {
+		workers = len(docs)
+	}
+
+	type trkStats struct {
+		month string
+		typ   string
+		stats Stats
+	}
+	docStats := make([][]trkStats, len(docs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				for _, trk := range docs[index].Trk {
+					docStats[index] = append(docStats[index], trkStats{
+						month: trkMonth(trk),
+						typ:   trk.Type,
+						stats: newTrkStats(trk),
+					})
+				}
+			}
+		}()
+	}
+	for index := range docs {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	summary := &StatsSummary{
+		ByMonth: make(map[string]*Stats),
+		ByType:  make(map[string]*Stats),
+	}
+	for _, trksStats := range docStats {
+		for _, ts := range trksStats {
+			summary.Total.add(ts.stats)
+			if _, ok := summary.ByMonth[ts.month]; !ok {
+				summary.ByMonth[ts.month] = &Stats{}
+			}
+			summary.ByMonth[ts.month].add(ts.stats)
+			if _, ok := summary.ByType[ts.typ]; !ok {
+				summary.ByType[ts.typ] = &Stats{}
+			}
+			summary.ByType[ts.typ].add(ts.stats)
+		}
+	}
+	return summary
+}
+
+// add adds other to s.
+func (s *Stats) add(other Stats) {
+	s.Tracks += other.Tracks
+	s.Distance += other.Distance
+	s.Duration += other.Duration
+	s.ElevationGain += other.ElevationGain
+}
+
+// newTrkStats returns the stats of trk.
+func newTrkStats(trk *TrkType) Stats {
+	stats := Stats{
+		Tracks:   1,
+		Distance: trk.Length(nil),
+	}
+	for _, ts := range trk.TrkSeg {
+		var first, last time.Time
+		var prevEle float64
+		hasPrevEle := false
+		for _, trkPt := range ts.TrkPt {
+			if !trkPt.Time.IsZero() {
+				if first.IsZero() {
+					first = trkPt.Time
+				}
+				last = trkPt.Time
+			}
+			if trkPt.Has(WptEle) {
+				if hasPrevEle && trkPt.Ele > prevEle {
+					stats.ElevationGain += trkPt.Ele - prevEle
+				}
+				prevEle, hasPrevEle = trkPt.Ele, true
+			}
+		}
+		stats.Duration += last.Sub(first)
+	}
+	return stats
+}
+
+// trkMonth returns the month of trk's first timed point, or "" if it has no
+// times.
+func trkMonth(trk *TrkType) string {
+	if t := trkStartTime(trk); !t.IsZero() {
+		return t.UTC().Format(monthLayout)
+	}
+	return ""
+}
+
+// trkStartTime returns the time of trk's first timed point, or the zero time
+// if it has no times.
+func trkStartTime(trk *TrkType) time.Time {
+	for _, ts := range trk.TrkSeg {
+		for _, trkPt := range ts.TrkPt {
+			if !trkPt.Time.IsZero() {
+				return trkPt.Time
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// BiggestMonth returns the key in s.ByMonth of the month with the greatest
+// distance, and its stats. Tracks without times are not considered. It
+// returns "" and nil if there are no months.
+func (s *StatsSummary) BiggestMonth() (string, *Stats) {
+	var biggestMonth string
+	var biggestStats *Stats
+	for month, stats := range s.ByMonth {
+		if month == "" {
+			continue
+		}
+		if biggestStats == nil || stats.Distance > biggestStats.Distance || stats.Distance == biggestStats.Distance && month < biggestMonth {
+			biggestMonth, biggestStats = month, stats
+		}
+	}
+	return biggestMonth, biggestStats
+}
+
+// EddingtonNumber returns the largest number n such that there are at least n
+// days on which the total distance of the tracks in docs is at least n units,
+// where unit is in meters, for example 1000 for kilometers. Each track counts
+// towards the day in loc of its first timed point. Tracks without times are
+// ignored.
+func EddingtonNumber(docs []*GPX, loc *time.Location, unit float64) int {
+	days := dailyDistances(docs, loc)
+	distances := make([]float64, 0, len(days))
+	for _, distance := range days {
+		distances = append(distances, distance/unit)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(distances)))
+	n := 0
+	for i, distance := range distances {
+		if distance < float64(i+1) {
+			break
+		}
+		n = i + 1
+	}
+	return n
+}
+
+// LongestStreak returns the greatest number of consecutive days in loc with
+// at least one track in docs, counting each track towards the day of its
+// first timed point. Tracks without times are ignored.
+func LongestStreak(docs []*GPX, loc *time.Location) int {
+	days := dailyDistances(docs, loc)
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	longest, streak := 0, 0
+	var prev time.Time
+	for _, date := range dates {
+		t, err := time.ParseInLocation(dateLayout, date, time.UTC)
+		if err != nil {
+			continue
+		}
+		if streak > 0 && prev.AddDate(0, 0, 1).Equal(t) {
+			streak++
+		} else {
+			streak = 1
+		}
+		longest = max(longest, streak)
+		prev = t
+	}
+	return longest
+}
+
+// dailyDistances returns the total distance of the tracks in docs by the day
+// in loc of each track's first timed point.
+func dailyDistances(docs []*GPX, loc *time.Location) map[string]float64 {
+	result := make(map[string]float64)
+	for _, g := range docs {
+		for _, trk := range g.Trk {
+			if t := trkStartTime(trk); !t.IsZero() {
+				result[t.In(loc).Format(dateLayout)] += trk.Length(nil)
+			}
+		}
+	}
+	return result
+}
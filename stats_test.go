@@ -0,0 +1,112 @@
+package gpx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestAggregateStats(t *testing.T) {
+	newDoc := func(typ string, start time.Time, eles ...float64) *gpx.GPX {
+		ts := &gpx.TrkSegType{}
+		for i, ele := range eles {
+			trkPt := &gpx.WptType{Lat: 0, Lon: float64(i) * 0.01, Ele: ele}
+			if !start.IsZero() {
+				trkPt.Time = start.Add(time.Duration(i) * time.Minute)
+			}
+			ts.TrkPt = append(ts.TrkPt, trkPt)
+		}
+		return &gpx.GPX{
+			Trk: []*gpx.TrkType{
+				{Type: typ, TrkSeg: []*gpx.TrkSegType{ts}},
+			},
+		}
+	}
+	jan := time.Date(2024, 1, 5, 8, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 5, 8, 0, 0, 0, time.UTC)
+	docs := []*gpx.GPX{
+		newDoc("running", jan, 100, 110, 105, 120),
+		newDoc("cycling", jan, 200, 0, 250),
+		newDoc("running", feb, 10, 20),
+		newDoc("", time.Time{}, 0, 0),
+		{},
+	}
+	step := gpx.HaversineDistance(0, 0, 0, 0.01)
+
+	for _, workers := range []int{0, 1, 3, 10} {
+		summary := gpx.AggregateStats(docs, workers)
+		assert.Equal(t, 4, summary.Total.Tracks)
+		assert.InDelta(t, 7*step, summary.Total.Distance, 1e-6)
+		assert.Equal(t, 6*time.Minute, summary.Total.Duration)
+		assert.Equal(t, 85.0, summary.Total.ElevationGain)
+
+		assert.Len(t, summary.ByMonth, 3)
+		assert.Equal(t, 2, summary.ByMonth["2024-01"].Tracks)
+		assert.Equal(t, 75.0, summary.ByMonth["2024-01"].ElevationGain)
+		assert.Equal(t, 5*time.Minute, summary.ByMonth["2024-01"].Duration)
+		assert.Equal(t, 1, summary.ByMonth["2024-02"].Tracks)
+		assert.Equal(t, 1, summary.ByMonth[""].Tracks)
+
+		assert.Len(t, summary.ByType, 3)
+		assert.Equal(t, 2, summary.ByType["running"].Tracks)
+		assert.InDelta(t, 4*step, summary.ByType["running"].Distance, 1e-6)
+		assert.Equal(t, 35.0, summary.ByType["running"].ElevationGain)
+		assert.Equal(t, 50.0, summary.ByType["cycling"].ElevationGain)
+	}
+
+	summary := gpx.AggregateStats(nil, 0)
+	assert.Equal(t, gpx.Stats{}, summary.Total)
+	assert.Empty(t, summary.ByMonth)
+}
+
+func TestVanityMetrics(t *testing.T) {
+	// newDoc returns a document with a track heading east along the equator
+	// for km kilometers starting at start, or without times if start is zero.
+	newDoc := func(start time.Time, km float64) *gpx.GPX {
+		ts := &gpx.TrkSegType{
+			TrkPt: []*gpx.WptType{
+				{Lat: 0, Lon: 0},
+				{Lat: 0, Lon: km * 1000 / gpx.HaversineDistance(0, 0, 0, 1)},
+			},
+		}
+		if !start.IsZero() {
+			ts.TrkPt[0].Time = start
+			ts.TrkPt[1].Time = start.Add(time.Hour)
+		}
+		return &gpx.GPX{
+			Trk: []*gpx.TrkType{
+				{TrkSeg: []*gpx.TrkSegType{ts}},
+			},
+		}
+	}
+	day := func(month, day int) time.Time {
+		return time.Date(2024, time.Month(month), day, 9, 0, 0, 0, time.UTC)
+	}
+	docs := []*gpx.GPX{
+		newDoc(day(1, 1), 5),
+		newDoc(day(1, 2), 3),
+		newDoc(day(1, 3), 1),
+		newDoc(day(1, 3), 2.5),
+		newDoc(day(1, 10), 4),
+		newDoc(day(2, 28), 1),
+		newDoc(day(2, 29), 2),
+		newDoc(day(3, 1), 1),
+		newDoc(day(3, 2), 1),
+		newDoc(time.Time{}, 100),
+	}
+
+	assert.Equal(t, 3, gpx.EddingtonNumber(docs, time.UTC, 1000))
+	assert.Equal(t, 0, gpx.EddingtonNumber(docs, time.UTC, 1e6))
+	assert.Equal(t, 4, gpx.LongestStreak(docs, time.UTC))
+	assert.Equal(t, 0, gpx.LongestStreak(nil, time.UTC))
+
+	month, stats := gpx.AggregateStats(docs, 0).BiggestMonth()
+	assert.Equal(t, "2024-01", month)
+	assert.Equal(t, 5, stats.Tracks)
+	month, stats = gpx.AggregateStats(nil, 0).BiggestMonth()
+	assert.Equal(t, "", month)
+	assert.Nil(t, stats)
+}
@@ -0,0 +1,187 @@
+package gpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// errReadOnlyStore is returned when writing to a read-only Store.
+var errReadOnlyStore = errors.New("read-only store")
+
+// A Store stores blobs by key. Keys are slash-separated paths, as accepted by
+// fs.ValidPath, for example "srtm/N46E007.hgt". Implementations must be safe
+// for concurrent use. Applications can implement Store to back the package's
+// caches with, for example, S3, SQLite, or bolt.
+type Store interface {
+	// Get returns the blob with the given key. If there is no such blob, the
+	// error satisfies errors.Is(err, fs.ErrNotExist).
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put stores data with the given key, replacing any existing blob.
+	Put(ctx context.Context, key string, data []byte) error
+	// List returns the keys that start with prefix, in order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// A MemoryStore is a Store that keeps blobs in memory.
+type MemoryStore struct {
+	mutex sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemoryStore returns a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blobs: make(map[string][]byte),
+	}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, &fs.PathError{Op: "get", Path: key, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(data), nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(_ context.Context, key string, data []byte) error {
+	if !fs.ValidPath(key) {
+		return &fs.PathError{Op: "put", Path: key, Err: fs.ErrInvalid}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.blobs[key] = slices.Clone(data)
+	return nil
+}
+
+// List implements Store.
+func (s *MemoryStore) List(_ context.Context, prefix string) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var keys []string
+	for key := range s.blobs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// A DirStore is a Store that keeps blobs as files in a directory.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a new DirStore that keeps blobs in dir.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{
+		dir: dir,
+	}
+}
+
+// Get implements Store.
+func (s *DirStore) Get(_ context.Context, key string) ([]byte, error) {
+	if !fs.ValidPath(key) {
+		return nil, &fs.PathError{Op: "get", Path: key, Err: fs.ErrInvalid}
+	}
+	return os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+}
+
+// Put implements Store. The blob is written to a temporary file which is then
+// renamed, so concurrent readers never see partial blobs.
+func (s *DirStore) Put(_ context.Context, key string, data []byte) error {
+	if !fs.ValidPath(key) || key == "." {
+		return &fs.PathError{Op: "put", Path: key, Err: fs.ErrInvalid}
+	}
+	name := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// List implements Store.
+func (s *DirStore) List(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := listFS(ctx, os.DirFS(s.dir), prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return keys, err
+}
+
+// An FSStore is a read-only Store backed by an fs.FS.
+type FSStore struct {
+	fsys fs.FS
+}
+
+// NewFSStore returns a new FSStore that reads blobs from fsys.
+func NewFSStore(fsys fs.FS) *FSStore {
+	return &FSStore{
+		fsys: fsys,
+	}
+}
+
+// Get implements Store.
+func (s *FSStore) Get(_ context.Context, key string) ([]byte, error) {
+	return fs.ReadFile(s.fsys, key)
+}
+
+// Put implements Store. It always returns an error.
+func (s *FSStore) Put(_ context.Context, key string, _ []byte) error {
+	return fmt.Errorf("%s: %w", key, errReadOnlyStore)
+}
+
+// List implements Store.
+func (s *FSStore) List(ctx context.Context, prefix string) ([]string, error) {
+	return listFS(ctx, s.fsys, prefix)
+}
+
+// listFS returns the names of the regular files in fsys that start with
+// prefix, in order, skipping temporary files left by DirStore.Put.
+func listFS(ctx context.Context, fsys fs.FS, prefix string) ([]string, error) {
+	var keys []string
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			if name != "." && !strings.HasPrefix(name+"/", prefix) && !strings.HasPrefix(prefix, name+"/") {
+				return fs.SkipDir
+			}
+		case entry.Type().IsRegular() && strings.HasPrefix(name, prefix) && !strings.HasPrefix(entry.Name(), ".tmp-"):
+			keys = append(keys, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
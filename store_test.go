@@ -0,0 +1,86 @@
+package gpx_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	for i, store := range []gpx.Store{
+		gpx.NewMemoryStore(),
+		gpx.NewDirStore(filepath.Join(t.TempDir(), "store")),
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			keys, err := store.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Empty(t, keys)
+
+			_, err = store.Get(ctx, "srtm/N46E007.hgt")
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+
+			assert.NoError(t, store.Put(ctx, "srtm/N46E007.hgt", []byte("a")))
+			assert.NoError(t, store.Put(ctx, "srtm/N47E007.hgt", []byte("b")))
+			assert.NoError(t, store.Put(ctx, "catalog.json", []byte("c")))
+			assert.NoError(t, store.Put(ctx, "srtm/N46E007.hgt", []byte("d")))
+			assert.Error(t, store.Put(ctx, "../escape", []byte("e")))
+
+			data, err := store.Get(ctx, "srtm/N46E007.hgt")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("d"), data)
+
+			keys, err = store.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"catalog.json", "srtm/N46E007.hgt", "srtm/N47E007.hgt"}, keys)
+
+			keys, err = store.List(ctx, "srtm/N46")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"srtm/N46E007.hgt"}, keys)
+		})
+	}
+}
+
+func TestDirStoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	store := gpx.NewDirStore(dir)
+	assert.NoError(t, store.Put(context.Background(), "a/b.gpx", []byte("x")))
+	data, err := os.ReadFile(filepath.Join(dir, "a", "b.gpx"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("x"), data)
+	entries, err := os.ReadDir(filepath.Join(dir, "a"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFSStore(t *testing.T) {
+	ctx := context.Background()
+	store := gpx.NewFSStore(fstest.MapFS{
+		"a/1":  &fstest.MapFile{Data: []byte("1")},
+		"a/2":  &fstest.MapFile{Data: []byte("2")},
+		"b/1":  &fstest.MapFile{Data: []byte("3")},
+		"ab/1": &fstest.MapFile{Data: []byte("4")},
+	})
+
+	data, err := store.Get(ctx, "a/2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2"), data)
+
+	keys, err := store.List(ctx, "a/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/1", "a/2"}, keys)
+
+	keys, err = store.List(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/1", "a/2", "ab/1"}, keys)
+
+	assert.Error(t, store.Put(ctx, "c", nil))
+}
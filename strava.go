@@ -0,0 +1,230 @@
+package gpx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// stravaDateLayout is the layout of dates in Strava's activities.csv.
+const stravaDateLayout = "Jan 2, 2006, 3:04:05 PM"
+
+// A StravaActivity is an activity from a Strava bulk export.
+type StravaActivity struct {
+	ID          string
+	Date        time.Time
+	Name        string
+	Type        string
+	Description string
+	// Filename is the name of the activity's file in the export, or empty if
+	// the activity has no file.
+	Filename string
+	// GPX is the activity's GPX document, with its metadata and track types
+	// filled in from the CSV, or nil if the activity has no file or its file
+	// is not GPX, for example FIT.
+	GPX *GPX
+	// Fields contains all the activity's CSV fields by column name. Where
+	// Strava repeats a column name, the first column is used.
+	Fields map[string]string
+}
+
+// ImportStravaExport reads the activities in the Strava bulk export zip
+// archive called zipPath, in the order of its activities.csv. Activity files
+// are read with WalkExport, so gzip-compressed files are decompressed
+// automatically.
+func ImportStravaExport(zipPath string) ([]*StravaActivity, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	rows, err := readExportCSV(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: activities.csv: %w", zipPath, err)
+	}
+
+	gpxsByName := make(map[string]*GPX)
+	if err := WalkExport(zr, func(activity *ExportActivity) error {
+		if activity.GPX != nil {
+			gpxsByName[activity.Name] = activity.GPX
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("%s: %w", zipPath, err)
+	}
+
+	activities := make([]*StravaActivity, 0, len(rows))
+	for _, fields := range rows {
+		activity := &StravaActivity{
+			ID:          fields["Activity ID"],
+			Name:        fields["Activity Name"],
+			Type:        fields["Activity Type"],
+			Description: fields["Activity Description"],
+			Filename:    exportFilename(fields),
+			Fields:      fields,
+		}
+		if date, err := time.ParseInLocation(stravaDateLayout, fields["Activity Date"], time.UTC); err == nil {
+			activity.Date = date
+		}
+		if g, ok := gpxsByName[activity.Filename]; ok {
+			activity.mergeInto(g)
+			activity.GPX = g
+		}
+		activities = append(activities, activity)
+	}
+	return activities, nil
+}
+
+// mergeInto sets g's metadata and track types from a, where they are not
+// already set.
+func (a *StravaActivity) mergeInto(g *GPX) {
+	metadata := g.metadata()
+	if metadata.Name == "" {
+		metadata.Name = a.Name
+	}
+	if metadata.Desc == "" {
+		metadata.Desc = a.Description
+	}
+	if metadata.Time.IsZero() {
+		metadata.Time = a.Date
+	}
+	for _, trk := range g.Trk {
+		if trk.Type == "" {
+			trk.Type = a.Type
+		}
+	}
+}
+
+// A stravaStream is a stream in a Strava streams API response. Only the
+// streams used by FromStravaStreams are decoded.
+type stravaStream struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// FromStravaStreams returns a new GPX 1.1 document containing a single track
+// built from the Strava streams API response read from r, which may be keyed
+// by type or not. The latlng stream is required. The altitude and time
+// streams, if present, give the points' elevations and times, with times
+// relative to start. The heartrate, cadence, temp, and watts streams, if
+// present, are written as hr, cad, atemp, and power extensions.
+func FromStravaStreams(r io.Reader, start time.Time) (*GPX, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	var streams []stravaStream
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(raw, &streams); err != nil {
+			return nil, err
+		}
+	} else {
+		var streamsByType map[string]stravaStream
+		if err := json.Unmarshal(raw, &streamsByType); err != nil {
+			return nil, err
+		}
+		for streamType, stream := range streamsByType {
+			stream.Type = streamType
+			streams = append(streams, stream)
+		}
+	}
+
+	var latLngs [][2]float64
+	var altitudes, times []float64
+	sensors := make(map[string][]float64)
+	for _, stream := range streams {
+		var err error
+		switch stream.Type {
+		case "latlng":
+			err = json.Unmarshal(stream.Data, &latLngs)
+		case "altitude":
+			err = json.Unmarshal(stream.Data, &altitudes)
+		case "time":
+			err = json.Unmarshal(stream.Data, &times)
+		case "heartrate", "cadence", "temp", "watts":
+			var values []float64
+			err = json.Unmarshal(stream.Data, &values)
+			sensors[stream.Type] = values
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stream.Type, err)
+		}
+	}
+	if latLngs == nil {
+		return nil, errors.New("missing latlng stream")
+	}
+
+	ts := &TrkSegType{
+		TrkPt: make([]*WptType, 0, len(latLngs)),
+	}
+	for i, latLng := range latLngs {
+		trkPt := &WptType{
+			Lat: latLng[0],
+			Lon: latLng[1],
+		}
+		if i < len(altitudes) {
+			trkPt.setEle(altitudes[i])
+		}
+		if i < len(times) && !start.IsZero() {
+			trkPt.Time = start.Add(time.Duration(times[i] * float64(time.Second)))
+		}
+		trkPt.Extensions = stravaSensorExtensions(sensors, i)
+		ts.TrkPt = append(ts.TrkPt, trkPt)
+	}
+	return &GPX{
+		Version: "1.1",
+		Trk: []*TrkType{
+			{
+				TrkSeg: []*TrkSegType{ts},
+			},
+		},
+	}, nil
+}
+
+// stravaSensorExtensions returns the extensions for the ith values of the
+// sensor streams, or nil if there are none. Heart rate, cadence, and
+// temperature are written as a Garmin TrackPointExtension and power as a
+// power element, as in Strava's own GPX exports.
+func stravaSensorExtensions(sensors map[string][]float64, i int) *ExtensionsType {
+	value := func(streamType string) (string, bool) {
+		values := sensors[streamType]
+		if i >= len(values) {
+			return "", false
+		}
+		return strconv.FormatFloat(values[i], 'f', -1, 64), true
+	}
+	var b bytes.Buffer
+	if power, ok := value("watts"); ok {
+		b.WriteString("<power>" + power + "</power>")
+	}
+	var tpx bytes.Buffer
+	for _, element := range []struct {
+		streamType string
+		localName  string
+	}{
+		{"temp", "atemp"},
+		{"heartrate", "hr"},
+		{"cadence", "cad"},
+	} {
+		if v, ok := value(element.streamType); ok {
+			tpx.WriteString("<gpxtpx:" + element.localName + ">" + v + "</gpxtpx:" + element.localName + ">")
+		}
+	}
+	if tpx.Len() > 0 {
+		b.WriteString(`<gpxtpx:TrackPointExtension xmlns:gpxtpx="` + trackPointExtensionV2Namespace + `">`)
+		b.Write(tpx.Bytes())
+		b.WriteString("</gpxtpx:TrackPointExtension>")
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	return &ExtensionsType{
+		XML: b.Bytes(),
+	}
+}
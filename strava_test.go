@@ -0,0 +1,136 @@
+package gpx_test
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestImportStravaExport(t *testing.T) {
+	g, err := gpx.ReadFile("testdata/fells_loop.gpx")
+	assert.NoError(t, err)
+
+	zipName := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(zipName)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("activities.csv")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("Activity ID,Activity Date,Activity Name,Activity Type,Activity Description,Elapsed Time,Distance,Filename,Distance\n" +
+		"1,\"Jan 2, 2024, 7:15:32 AM\",Morning Run,Run,\"Easy, slow\",3600,10.5,activities/1.gpx,10500\n" +
+		"2,\"Jan 3, 2024, 6:00:00 PM\",Evening Ride,Ride,,7200,40.2,activities/2.gpx.gz,40200\n" +
+		"3,\"Jan 4, 2024, 6:00:00 PM\",Swim,Swim,,1800,1.5,activities/3.fit.gz,1500\n" +
+		"4,\"Jan 5, 2024, 6:00:00 PM\",Yoga,Yoga,,1800,0,,0\n"))
+	assert.NoError(t, err)
+	w, err = zw.Create("activities/1.gpx")
+	assert.NoError(t, err)
+	assert.NoError(t, g.Write(w))
+	w, err = zw.Create("activities/2.gpx.gz")
+	assert.NoError(t, err)
+	gw := gzip.NewWriter(w)
+	assert.NoError(t, g.Write(gw))
+	assert.NoError(t, gw.Close())
+	w, err = zw.Create("activities/3.fit.gz")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("not gpx"))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+
+	activities, err := gpx.ImportStravaExport(zipName)
+	assert.NoError(t, err)
+	assert.Len(t, activities, 4)
+
+	run := activities[0]
+	assert.Equal(t, "1", run.ID)
+	assert.Equal(t, time.Date(2024, 1, 2, 7, 15, 32, 0, time.UTC), run.Date)
+	assert.Equal(t, "Morning Run", run.Name)
+	assert.Equal(t, "Run", run.Type)
+	assert.Equal(t, "Easy, slow", run.Description)
+	assert.Equal(t, "10.5", run.Fields["Distance"])
+	assert.Equal(t, "3600", run.Fields["Elapsed Time"])
+	assert.NotNil(t, run.GPX)
+	assert.Equal(t, "Morning Run", run.GPX.Metadata.Name)
+	assert.Equal(t, "Easy, slow", run.GPX.Metadata.Desc)
+	assert.Equal(t, len(g.Wpt), len(run.GPX.Wpt))
+
+	ride := activities[1]
+	assert.NotNil(t, ride.GPX)
+	assert.Equal(t, "Evening Ride", ride.GPX.Metadata.Name)
+
+	assert.Equal(t, "activities/3.fit.gz", activities[2].Filename)
+	assert.Nil(t, activities[2].GPX)
+	assert.Nil(t, activities[3].GPX)
+
+	_, err = gpx.ImportStravaExport(filepath.Join(t.TempDir(), "missing.zip"))
+	assert.Error(t, err)
+}
+
+func TestFromStravaStreams(t *testing.T) {
+	start := time.Date(2024, 1, 2, 7, 15, 32, 0, time.UTC)
+	expected := []*gpx.WptType{
+		{
+			Lat:  47.5,
+			Lon:  7.5,
+			Ele:  260,
+			Time: start,
+			Extensions: &gpx.ExtensionsType{
+				XML: []byte(`<power>210</power><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension>`),
+			},
+		},
+		{
+			Lat:  47.501,
+			Lon:  7.502,
+			Ele:  262.5,
+			Time: start.Add(5 * time.Second),
+			Extensions: &gpx.ExtensionsType{
+				XML: []byte(`<power>0</power><gpxtpx:TrackPointExtension xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"><gpxtpx:hr>125</gpxtpx:hr></gpxtpx:TrackPointExtension>`),
+			},
+		},
+	}
+
+	for i, tc := range []string{
+		`{
+  "latlng": {"data": [[47.5, 7.5], [47.501, 7.502]], "series_type": "distance"},
+  "altitude": {"data": [260, 262.5]},
+  "time": {"data": [0, 5]},
+  "heartrate": {"data": [120, 125]},
+  "watts": {"data": [210, 0]},
+  "distance": {"data": [0, 170.2]}
+}`,
+		`[
+  {"type": "distance", "data": [0, 170.2]},
+  {"type": "latlng", "data": [[47.5, 7.5], [47.501, 7.502]]},
+  {"type": "altitude", "data": [260, 262.5]},
+  {"type": "time", "data": [0, 5]},
+  {"type": "heartrate", "data": [120, 125]},
+  {"type": "watts", "data": [210, 0]}
+]`,
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := gpx.FromStravaStreams(strings.NewReader(tc), start)
+			assert.NoError(t, err)
+			assert.Equal(t, "1.1", got.Version)
+			assert.Len(t, got.Trk, 1)
+			assert.Equal(t, expected, got.Trk[0].TrkSeg[0].TrkPt)
+		})
+	}
+
+	got, err := gpx.FromStravaStreams(strings.NewReader(`{"latlng": {"data": [[1, 2]]}, "time": {"data": [0]}}`), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*gpx.WptType{{Lat: 1, Lon: 2}}, got.Trk[0].TrkSeg[0].TrkPt)
+
+	_, err = gpx.FromStravaStreams(strings.NewReader(`{"time": {"data": [0]}}`), start)
+	assert.Error(t, err)
+	_, err = gpx.FromStravaStreams(strings.NewReader(`{"latlng": {"data": [1, 2]}}`), start)
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+
+	"golang.org/x/net/html/charset"
+)
+
+// A TrkSegChunk is a run of consecutive points from a track segment.
+type TrkSegChunk struct {
+	// TrkIndex is the index of the chunk's track in the document.
+	TrkIndex int
+	// TrkSegIndex is the index of the chunk's segment in its track.
+	TrkSegIndex int
+	// Continued is true if the chunk continues the segment of the previous
+	// chunk.
+	Continued bool
+	// More is true if further chunks of the same segment follow.
+	More bool
+	// TrkSeg contains the chunk's points.
+	TrkSeg *TrkSegType
+}
+
+// A TrkSegDecoder reads track segments from a GPX document as a stream of
+// chunks of bounded size, so that arbitrarily large documents can be
+// processed segment by segment in fixed memory. Waypoints, routes, metadata,
+// and segment extensions are skipped.
+type TrkSegDecoder struct {
+	d           *xml.Decoder
+	maxPoints   int
+	trkIndex    int
+	trkSegIndex int
+	inTrkSeg    bool
+	continued   bool
+	trkPts      []*WptType
+}
+
+// NewTrkSegDecoder returns a new TrkSegDecoder that reads from r and returns
+// chunks of at most maxPoints points. If maxPoints is not positive then
+// segments are not split.
+func NewTrkSegDecoder(r io.Reader, maxPoints int) *TrkSegDecoder {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+	return &TrkSegDecoder{
+		d:           d,
+		maxPoints:   maxPoints,
+		trkIndex:    -1,
+		trkSegIndex: -1,
+	}
+}
+
+// Next returns the next chunk. Every segment, including empty ones, produces
+// at least one chunk. At the end of the document, Next returns io.EOF.
+func (d *TrkSegDecoder) Next() (*TrkSegChunk, error) {
+	for {
+		token, err := d.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch {
+			case token.Name.Local == "trk" && !d.inTrkSeg:
+				d.trkIndex++
+				d.trkSegIndex = -1
+			case token.Name.Local == "trkseg" && !d.inTrkSeg:
+				d.trkSegIndex++
+				d.inTrkSeg = true
+				d.continued = false
+				d.trkPts = nil
+			case token.Name.Local == "trkpt" && d.inTrkSeg:
+				trkPt := &WptType{}
+				if err := d.d.DecodeElement(trkPt, &token); err != nil {
+					return nil, err
+				}
+				if d.maxPoints > 0 && len(d.trkPts) == d.maxPoints {
+					chunk := d.chunk(true)
+					d.continued = true
+					d.trkPts = []*WptType{trkPt}
+					return chunk, nil
+				}
+				d.trkPts = append(d.trkPts, trkPt)
+			case d.inTrkSeg:
+				if err := d.d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if token.Name.Local == "trkseg" && d.inTrkSeg {
+				d.inTrkSeg = false
+				chunk := d.chunk(false)
+				d.trkPts = nil
+				return chunk, nil
+			}
+		}
+	}
+}
+
+// chunk returns a new chunk containing the buffered points.
+func (d *TrkSegDecoder) chunk(more bool) *TrkSegChunk {
+	return &TrkSegChunk{
+		TrkIndex:    d.trkIndex,
+		TrkSegIndex: d.trkSegIndex,
+		Continued:   d.continued,
+		More:        more,
+		TrkSeg: &TrkSegType{
+			TrkPt: d.trkPts,
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package gpx_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestTrkSegDecoder(t *testing.T) {
+	data := "<gpx version=\"1.1\">" +
+		"<wpt lat=\"0\" lon=\"0\"></wpt>" +
+		"<trk><name>a</name>" +
+		"<trkseg>" +
+		"<trkpt lat=\"1\" lon=\"1\"></trkpt>" +
+		"<trkpt lat=\"2\" lon=\"2\"></trkpt>" +
+		"<trkpt lat=\"3\" lon=\"3\"></trkpt>" +
+		"<trkpt lat=\"4\" lon=\"4\"></trkpt>" +
+		"<trkpt lat=\"5\" lon=\"5\"></trkpt>" +
+		"<extensions><foo></foo></extensions>" +
+		"</trkseg>" +
+		"<trkseg></trkseg>" +
+		"</trk>" +
+		"<trk><trkseg>" +
+		"<trkpt lat=\"6\" lon=\"6\"></trkpt>" +
+		"<trkpt lat=\"7\" lon=\"7\"></trkpt>" +
+		"</trkseg></trk>" +
+		"</gpx>"
+
+	d := gpx.NewTrkSegDecoder(strings.NewReader(data), 2)
+	var got []*gpx.TrkSegChunk
+	for {
+		chunk, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		got = append(got, chunk)
+	}
+
+	trkSeg := func(lats ...float64) *gpx.TrkSegType {
+		ts := &gpx.TrkSegType{}
+		for _, lat := range lats {
+			ts.TrkPt = append(ts.TrkPt, &gpx.WptType{Lat: lat, Lon: lat})
+		}
+		return ts
+	}
+	assert.Equal(t, []*gpx.TrkSegChunk{
+		{TrkIndex: 0, TrkSegIndex: 0, More: true, TrkSeg: trkSeg(1, 2)},
+		{TrkIndex: 0, TrkSegIndex: 0, Continued: true, More: true, TrkSeg: trkSeg(3, 4)},
+		{TrkIndex: 0, TrkSegIndex: 0, Continued: true, TrkSeg: trkSeg(5)},
+		{TrkIndex: 0, TrkSegIndex: 1, TrkSeg: trkSeg()},
+		{TrkIndex: 1, TrkSegIndex: 0, TrkSeg: trkSeg(6, 7)},
+	}, got)
+}
+
+func TestTrkSegDecoderUnbounded(t *testing.T) {
+	g, err := gpx.ReadFile("testdata/ashland.gpx")
+	assert.NoError(t, err)
+
+	f, err := os.Open("testdata/ashland.gpx")
+	assert.NoError(t, err)
+	defer f.Close()
+	d := gpx.NewTrkSegDecoder(f, 0)
+	for i, trk := range g.Trk {
+		for j, trkSeg := range trk.TrkSeg {
+			chunk, err := d.Next()
+			assert.NoError(t, err)
+			assert.Equal(t, &gpx.TrkSegChunk{TrkIndex: i, TrkSegIndex: j, TrkSeg: trkSeg}, chunk)
+		}
+	}
+	_, err = d.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
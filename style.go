@@ -0,0 +1,205 @@
+package gpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Style extension namespaces.
+const (
+	gpxStyleNamespace     = "http://www.topografix.com/GPX/gpx_style/0/2"
+	garminGPXXV3Namespace = "http://www.garmin.com/xmlschemas/GpxExtensions/v3"
+)
+
+// Local names of Garmin extension elements containing display colors.
+const (
+	garminTrackExtension = "TrackExtension"
+	garminRouteExtension = "RouteExtension"
+)
+
+var (
+	gpxStyleSpaces   = []string{gpxStyleNamespace, "gpx_style"}
+	garminGPXXSpaces = []string{garminGPXXV3Namespace, "gpxx"}
+)
+
+// GarminDisplayColors are the valid Garmin display colors, as used by
+// BaseCamp and Garmin devices.
+var GarminDisplayColors = []string{
+	"Black",
+	"DarkRed",
+	"DarkGreen",
+	"DarkYellow",
+	"DarkBlue",
+	"DarkMagenta",
+	"DarkCyan",
+	"LightGray",
+	"DarkGray",
+	"Red",
+	"Green",
+	"Yellow",
+	"Blue",
+	"Magenta",
+	"Cyan",
+	"White",
+	"Transparent",
+}
+
+// A LineStyle is the style of a track or route's line from the gpx_style
+// extension, as written by GPXSee, Locus Map, and others. Zero values are
+// absent.
+type LineStyle struct {
+	// Color is the color as six hexadecimal digits, RRGGBB, without a
+	// leading #.
+	Color string
+	// Opacity is the opacity between 0 and 1.
+	Opacity float64
+	// Width is the width in millimeters.
+	Width float64
+}
+
+// gpxStyleLine is the gpx_style line element, matched by local names.
+type gpxStyleLine struct {
+	Color   string `xml:"color"`
+	Opacity string `xml:"opacity"`
+	Width   string `xml:"width"`
+}
+
+// garminDisplayColorExtension is a Garmin TrackExtension or RouteExtension
+// element, matched by local names.
+type garminDisplayColorExtension struct {
+	IsAutoNamed  string `xml:"IsAutoNamed"`
+	DisplayColor string `xml:"DisplayColor"`
+}
+
+// LineStyle returns t's gpx_style line style.
+func (t *TrkType) LineStyle() LineStyle {
+	return t.Extensions.lineStyle()
+}
+
+// SetLineStyle replaces t's gpx_style line style with s, preserving its
+// other extensions. If s is zero, the line style is removed.
+func (t *TrkType) SetLineStyle(s LineStyle) {
+	t.Extensions = t.Extensions.withLineStyle(s)
+}
+
+// DisplayColor returns t's Garmin display color, or the empty string if it
+// has none.
+func (t *TrkType) DisplayColor() string {
+	return t.Extensions.displayColor(garminTrackExtension)
+}
+
+// SetDisplayColor sets t's Garmin display color to color, which must be one
+// of GarminDisplayColors or empty to remove it, preserving its other
+// extensions.
+func (t *TrkType) SetDisplayColor(color string) error {
+	extensions, err := t.Extensions.withDisplayColor(garminTrackExtension, color)
+	if err != nil {
+		return err
+	}
+	t.Extensions = extensions
+	return nil
+}
+
+// LineStyle returns r's gpx_style line style.
+func (r *RteType) LineStyle() LineStyle {
+	return r.Extensions.lineStyle()
+}
+
+// SetLineStyle replaces r's gpx_style line style with s, preserving its
+// other extensions. If s is zero, the line style is removed.
+func (r *RteType) SetLineStyle(s LineStyle) {
+	r.Extensions = r.Extensions.withLineStyle(s)
+}
+
+// DisplayColor returns r's Garmin display color, or the empty string if it
+// has none.
+func (r *RteType) DisplayColor() string {
+	return r.Extensions.displayColor(garminRouteExtension)
+}
+
+// SetDisplayColor sets r's Garmin display color to color, which must be one
+// of GarminDisplayColors or empty to remove it, preserving its other
+// extensions.
+func (r *RteType) SetDisplayColor(color string) error {
+	extensions, err := r.Extensions.withDisplayColor(garminRouteExtension, color)
+	if err != nil {
+		return err
+	}
+	r.Extensions = extensions
+	return nil
+}
+
+// lineStyle returns the gpx_style line style in e.
+func (e *ExtensionsType) lineStyle() LineStyle {
+	var line gpxStyleLine
+	if !e.decodeNamespacedExtension("line", gpxStyleSpaces, &line) {
+		return LineStyle{}
+	}
+	style := LineStyle{
+		Color: strings.TrimPrefix(strings.TrimSpace(line.Color), "#"),
+	}
+	style.Opacity, _ = parseFloat(line.Opacity)
+	style.Width, _ = parseFloat(line.Width)
+	return style
+}
+
+// withLineStyle returns e with its gpx_style line element replaced by s.
+func (e *ExtensionsType) withLineStyle(s LineStyle) *ExtensionsType {
+	var b bytes.Buffer
+	if s != (LineStyle{}) {
+		b.WriteString(`<gpx_style:line xmlns:gpx_style="` + gpxStyleNamespace + `">`)
+		if s.Color != "" {
+			b.WriteString("<gpx_style:color>")
+			_ = xml.EscapeText(&b, []byte(strings.TrimPrefix(s.Color, "#")))
+			b.WriteString("</gpx_style:color>")
+		}
+		if s.Opacity != 0 {
+			b.WriteString("<gpx_style:opacity>" + strconv.FormatFloat(s.Opacity, 'f', -1, 64) + "</gpx_style:opacity>")
+		}
+		if s.Width != 0 {
+			b.WriteString("<gpx_style:width>" + strconv.FormatFloat(s.Width, 'f', -1, 64) + "</gpx_style:width>")
+		}
+		b.WriteString("</gpx_style:line>")
+	}
+	return e.replaceExtensions(func(name xml.Name) bool {
+		return name.Local == "line" && slices.Contains(gpxStyleSpaces, name.Space)
+	}, b.Bytes())
+}
+
+// displayColor returns the Garmin display color in e's element with the
+// given local name.
+func (e *ExtensionsType) displayColor(localName string) string {
+	var extension garminDisplayColorExtension
+	e.decodeNamespacedExtension(localName, garminGPXXSpaces, &extension)
+	return strings.TrimSpace(extension.DisplayColor)
+}
+
+// withDisplayColor returns e with the Garmin display color in its element
+// with the given local name replaced by color.
+func (e *ExtensionsType) withDisplayColor(localName, color string) (*ExtensionsType, error) {
+	if color != "" && !slices.Contains(GarminDisplayColors, color) {
+		return nil, fmt.Errorf("%s: invalid Garmin display color", color)
+	}
+	var extension garminDisplayColorExtension
+	e.decodeNamespacedExtension(localName, garminGPXXSpaces, &extension)
+	var b bytes.Buffer
+	if color != "" || extension.IsAutoNamed != "" {
+		b.WriteString("<gpxx:" + localName + ` xmlns:gpxx="` + garminGPXXV3Namespace + `">`)
+		if autoNamed := strings.TrimSpace(extension.IsAutoNamed); autoNamed != "" {
+			b.WriteString("<gpxx:IsAutoNamed>")
+			_ = xml.EscapeText(&b, []byte(autoNamed))
+			b.WriteString("</gpxx:IsAutoNamed>")
+		}
+		if color != "" {
+			b.WriteString("<gpxx:DisplayColor>" + color + "</gpxx:DisplayColor>")
+		}
+		b.WriteString("</gpxx:" + localName + ">")
+	}
+	return e.replaceExtensions(func(name xml.Name) bool {
+		return name.Local == localName && slices.Contains(garminGPXXSpaces, name.Space)
+	}, b.Bytes()), nil
+}
@@ -0,0 +1,69 @@
+package gpx_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestLineStyle(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="GPXSee" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpx_style="http://www.topografix.com/GPX/gpx_style/0/2" xmlns:gpxx="http://www.garmin.com/xmlschemas/GpxExtensions/v3">
+  <rte>
+    <extensions>
+      <gpxx:RouteExtension>
+        <gpxx:IsAutoNamed>false</gpxx:IsAutoNamed>
+        <gpxx:DisplayColor>DarkBlue</gpxx:DisplayColor>
+      </gpxx:RouteExtension>
+    </extensions>
+  </rte>
+  <trk>
+    <extensions>
+      <gpx_style:line>
+        <gpx_style:color>#FF0000</gpx_style:color>
+        <gpx_style:opacity>0.5</gpx_style:opacity>
+        <gpx_style:width>2.5</gpx_style:width>
+      </gpx_style:line>
+      <gpxx:TrackExtension><gpxx:DisplayColor>Red</gpxx:DisplayColor></gpxx:TrackExtension>
+      <other/>
+    </extensions>
+  </trk>
+</gpx>`
+
+	g, err := gpx.Read(strings.NewReader(data))
+	assert.NoError(t, err)
+	trk, rte := g.Trk[0], g.Rte[0]
+	assert.Equal(t, gpx.LineStyle{Color: "FF0000", Opacity: 0.5, Width: 2.5}, trk.LineStyle())
+	assert.Equal(t, "Red", trk.DisplayColor())
+	assert.Equal(t, gpx.LineStyle{}, rte.LineStyle())
+	assert.Equal(t, "DarkBlue", rte.DisplayColor())
+
+	trk.SetLineStyle(gpx.LineStyle{Color: "#00FF00", Width: 1})
+	assert.NoError(t, trk.SetDisplayColor("Green"))
+	assert.Error(t, trk.SetDisplayColor("Orange"))
+	assert.NoError(t, rte.SetDisplayColor("Yellow"))
+	rte.SetLineStyle(gpx.LineStyle{Color: "FFFF00"})
+
+	var b bytes.Buffer
+	assert.NoError(t, g.Write(&b))
+	g2, err := gpx.Read(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, gpx.LineStyle{Color: "00FF00", Width: 1}, g2.Trk[0].LineStyle())
+	assert.Equal(t, "Green", g2.Trk[0].DisplayColor())
+	assert.Contains(t, string(g2.Trk[0].Extensions.XML), "<other")
+	assert.Equal(t, gpx.LineStyle{Color: "FFFF00"}, g2.Rte[0].LineStyle())
+	assert.Equal(t, "Yellow", g2.Rte[0].DisplayColor())
+	assert.Contains(t, string(g2.Rte[0].Extensions.XML), "<gpxx:IsAutoNamed>false</gpxx:IsAutoNamed>")
+
+	rte2 := &gpx.RteType{}
+	rte2.SetLineStyle(gpx.LineStyle{Opacity: 1})
+	assert.Equal(t, gpx.LineStyle{Opacity: 1}, rte2.LineStyle())
+	rte2.SetLineStyle(gpx.LineStyle{})
+	assert.NoError(t, rte2.SetDisplayColor(""))
+	assert.Nil(t, rte2.Extensions)
+	assert.Equal(t, "", rte2.DisplayColor())
+}
@@ -0,0 +1,227 @@
+package gpx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNonMonotonicTime is returned when points' times are missing or
+// decreasing.
+var ErrNonMonotonicTime = errors.New("non-monotonic time")
+
+// ShiftTime adds d to the times of the metadata and all waypoints, route
+// points, and track points in g. Missing times remain missing.
+func (g *GPX) ShiftTime(d time.Duration) {
+	if g.Metadata != nil && !g.Metadata.Time.IsZero() {
+		g.Metadata.Time = g.Metadata.Time.Add(d)
+	}
+	for wpt := range g.Points() {
+		if !wpt.Time.IsZero() {
+			wpt.Time = wpt.Time.Add(d)
+		}
+	}
+}
+
+// InterpolateTimes sets the times of all of ts's points so that the first
+// point is at start, the last point is at end, and the times of the points in
+// between are proportional to their distance along ts. If ts has zero length
+// then the times are evenly spaced.
+func (ts *TrkSegType) InterpolateTimes(start, end time.Time) {
+	if len(ts.TrkPt) == 0 {
+		return
+	}
+	ts.TrkPt[0].Time = start
+	interpolateTimes(ts.TrkPt, cumulativeDistances(ts.TrkPt), 0, len(ts.TrkPt)-1, start, end)
+}
+
+// FillMissingTimes sets the times of points in ts without times that lie
+// between two points with times, in proportion to their distance along ts, or
+// evenly spaced if the points between are at the same position. Points before
+// the first or after the last point with a time are not changed. It returns
+// the number of points whose time was set.
+func (ts *TrkSegType) FillMissingTimes() int {
+	distances := cumulativeDistances(ts.TrkPt)
+	n := 0
+	prev := -1
+	for i, trkPt := range ts.TrkPt {
+		if trkPt.Time.IsZero() {
+			continue
+		}
+		if prev != -1 && i-prev > 1 {
+			interpolateTimes(ts.TrkPt, distances, prev, i, ts.TrkPt[prev].Time, trkPt.Time)
+			n += i - prev - 1
+		}
+		prev = i
+	}
+	return n
+}
+
+// interpolateTimes sets the times of wpts[i:j+1] between start and end in
+// proportion to distances, or evenly spaced if wpts[i] and wpts[j] are the
+// same distance along the path.
+func interpolateTimes(wpts []*WptType, distances []float64, i, j int, start, end time.Time) {
+	duration := float64(end.Sub(start))
+	total := distances[j] - distances[i]
+	for k := i + 1; k <= j; k++ {
+		var f float64
+		if total > 0 {
+			f = (distances[k] - distances[i]) / total
+		} else {
+			f = float64(k-i) / float64(j-i)
+		}
+		wpts[k].Time = start.Add(time.Duration(f * duration))
+	}
+}
+
+// ValidateTimes returns an error wrapping ErrNonMonotonicTime if any of ts's
+// points has no time or a time before that of the previous point. The time
+// queries IndexAtTime, PointAtTime, and IndexRange use binary search and
+// require valid times, so callers that do not trust their input should call
+// ValidateTimes once before querying.
+func (ts *TrkSegType) ValidateTimes() error {
+	for i, trkPt := range ts.TrkPt {
+		switch {
+		case trkPt.Time.IsZero():
+			return fmt.Errorf("point %d: %w: missing time", i, ErrNonMonotonicTime)
+		case i > 0 && trkPt.Time.Before(ts.TrkPt[i-1].Time):
+			return fmt.Errorf("point %d: %w: %s before %s", i, ErrNonMonotonicTime, trkPt.Time.Format(timeLayout), ts.TrkPt[i-1].Time.Format(timeLayout))
+		}
+	}
+	return nil
+}
+
+// IndexAtTime returns the index of the last point of ts at or before t, so
+// that t is in the half-open interval from its time to the next point's time.
+// It returns -1 if ts is empty, t is before the first point, or t is after the
+// last point. ts's times must be valid, see ValidateTimes.
+func (ts *TrkSegType) IndexAtTime(t time.Time) int {
+	n := len(ts.TrkPt)
+	if n == 0 || t.Before(ts.TrkPt[0].Time) || t.After(ts.TrkPt[n-1].Time) {
+		return -1
+	}
+	return sort.Search(n, func(i int) bool {
+		return ts.TrkPt[i].Time.After(t)
+	}) - 1
+}
+
+// PointAtTime returns a new point at time t, interpolated between the points
+// of ts before and after it, or nil if t is outside the times of ts. ts's
+// times must be valid, see ValidateTimes.
+func (ts *TrkSegType) PointAtTime(t time.Time) *WptType {
+	i := ts.IndexAtTime(t)
+	switch {
+	case i == -1:
+		return nil
+	case i == len(ts.TrkPt)-1 || ts.TrkPt[i].Time.Equal(t):
+		wpt := *ts.TrkPt[i]
+		return &wpt
+	}
+	a, b := ts.TrkPt[i], ts.TrkPt[i+1]
+	f := float64(t.Sub(a.Time)) / float64(b.Time.Sub(a.Time))
+	return interpolate(a, b, f)
+}
+
+// An Extrapolation is a policy for positions at times outside the times of a
+// track.
+type Extrapolation int
+
+// Extrapolations.
+const (
+	// ExtrapolateNone returns no position.
+	ExtrapolateNone Extrapolation = iota
+	// ExtrapolateClamp returns the position of the nearest point.
+	ExtrapolateClamp
+	// ExtrapolateLinear continues the motion between the first two or last
+	// two points. It falls back to ExtrapolateClamp if there is only one
+	// point.
+	ExtrapolateLinear
+)
+
+// PositionAt returns a new point with the latitude, longitude, and elevation
+// of t at time tm, interpolated between the points of t before and after it.
+// Gaps between segments are interpolated across. Times before the first point
+// or after the last point are handled according to extrapolation. It returns
+// nil if there is no position. t's times must be valid and increasing across
+// segments, see TrkSegType.ValidateTimes.
+func (t *TrkType) PositionAt(tm time.Time, extrapolation Extrapolation) *WptType {
+	var prev, last *WptType
+	for i, ts := range t.TrkSeg {
+		n := len(ts.TrkPt)
+		if n == 0 {
+			continue
+		}
+		switch {
+		case tm.Before(ts.TrkPt[0].Time):
+			if prev == nil {
+				var next *WptType
+				if n > 1 {
+					next = ts.TrkPt[1]
+				} else {
+					for _, ts := range t.TrkSeg[i+1:] {
+						if len(ts.TrkPt) > 0 {
+							next = ts.TrkPt[0]
+							break
+						}
+					}
+				}
+				return extrapolate(ts.TrkPt[0], next, tm, extrapolation)
+			}
+			f := float64(tm.Sub(prev.Time)) / float64(ts.TrkPt[0].Time.Sub(prev.Time))
+			return position(interpolate(prev, ts.TrkPt[0], f), tm)
+		case !tm.After(ts.TrkPt[n-1].Time):
+			return position(ts.PointAtTime(tm), tm)
+		}
+		if n > 1 {
+			last = ts.TrkPt[n-2]
+		} else {
+			last = prev
+		}
+		prev = ts.TrkPt[n-1]
+	}
+	if prev == nil {
+		return nil
+	}
+	return extrapolate(prev, last, tm, extrapolation)
+}
+
+// extrapolate returns the position at tm, outside the times of a track, where
+// end is the nearest point of the track and next, which may be nil, is its
+// neighbor.
+func extrapolate(end, next *WptType, tm time.Time, extrapolation Extrapolation) *WptType {
+	switch {
+	case extrapolation == ExtrapolateLinear && next != nil && !next.Time.Equal(end.Time):
+		f := float64(tm.Sub(end.Time)) / float64(next.Time.Sub(end.Time))
+		return position(interpolate(end, next, f), tm)
+	case extrapolation == ExtrapolateLinear || extrapolation == ExtrapolateClamp:
+		return position(end, tm)
+	default:
+		return nil
+	}
+}
+
+// position returns a new point with the position of wpt at time tm.
+func position(wpt *WptType, tm time.Time) *WptType {
+	return &WptType{
+		Lat:        wpt.Lat,
+		Lon:        wpt.Lon,
+		Ele:        wpt.Ele,
+		Time:       tm,
+		ZeroFields: wpt.ZeroFields & WptEle,
+	}
+}
+
+// IndexRange returns the indexes i and j such that ts.TrkPt[i:j] are the
+// points with times in the half-open interval from start to end. ts's times
+// must be valid, see ValidateTimes.
+func (ts *TrkSegType) IndexRange(start, end time.Time) (int, int) {
+	n := len(ts.TrkPt)
+	i := sort.Search(n, func(i int) bool {
+		return !ts.TrkPt[i].Time.Before(start)
+	})
+	j := sort.Search(n, func(j int) bool {
+		return !ts.TrkPt[j].Time.Before(end)
+	})
+	return i, max(i, j)
+}
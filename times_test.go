@@ -0,0 +1,193 @@
+package gpx_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestInterpolateTimes(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	step := 100 / gpx.HaversineDistance(0, 0, 0, 1)
+
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lon: 0},
+			{Lon: 1 * step},
+			{Lon: 4 * step},
+		},
+	}
+	ts.InterpolateTimes(t0, t0.Add(4*time.Minute))
+	assert.Equal(t, t0, ts.TrkPt[0].Time)
+	assert.WithinDuration(t, t0.Add(time.Minute), ts.TrkPt[1].Time, time.Millisecond)
+	assert.Equal(t, t0.Add(4*time.Minute), ts.TrkPt[2].Time)
+
+	stationary := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{{}, {}, {}},
+	}
+	stationary.InterpolateTimes(t0, t0.Add(2*time.Minute))
+	assert.Equal(t, t0.Add(time.Minute), stationary.TrkPt[1].Time)
+
+	(&gpx.TrkSegType{}).InterpolateTimes(t0, t0)
+}
+
+func TestFillMissingTimes(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	step := 100 / gpx.HaversineDistance(0, 0, 0, 1)
+
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lon: 0},
+			{Lon: 1 * step, Time: t0},
+			{Lon: 2 * step},
+			{Lon: 4 * step},
+			{Lon: 5 * step, Time: t0.Add(4 * time.Minute)},
+			{Lon: 5 * step},
+			{Lon: 5 * step},
+			{Lon: 5 * step, Time: t0.Add(6 * time.Minute)},
+			{Lon: 6 * step},
+		},
+	}
+	assert.Equal(t, 4, ts.FillMissingTimes())
+	assert.True(t, ts.TrkPt[0].Time.IsZero())
+	assert.WithinDuration(t, t0.Add(time.Minute), ts.TrkPt[2].Time, time.Millisecond)
+	assert.WithinDuration(t, t0.Add(3*time.Minute), ts.TrkPt[3].Time, time.Millisecond)
+	assert.Equal(t, t0.Add(4*time.Minute), ts.TrkPt[4].Time)
+	assert.Equal(t, t0.Add(4*time.Minute+40*time.Second), ts.TrkPt[5].Time)
+	assert.Equal(t, t0.Add(5*time.Minute+20*time.Second), ts.TrkPt[6].Time)
+	assert.True(t, ts.TrkPt[8].Time.IsZero())
+
+	assert.Equal(t, 0, ts.FillMissingTimes())
+}
+
+func TestTimeQueries(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ts := &gpx.TrkSegType{
+		TrkPt: []*gpx.WptType{
+			{Lat: 0, Time: t0},
+			{Lat: 1, Time: t0.Add(10 * time.Second)},
+			{Lat: 2, Time: t0.Add(10 * time.Second)},
+			{Lat: 4, Time: t0.Add(30 * time.Second)},
+		},
+	}
+	assert.NoError(t, ts.ValidateTimes())
+
+	for i, tc := range []struct {
+		seconds  float64
+		expected int
+	}{
+		{seconds: -1, expected: -1},
+		{seconds: 0, expected: 0},
+		{seconds: 9.5, expected: 0},
+		{seconds: 10, expected: 2},
+		{seconds: 29, expected: 2},
+		{seconds: 30, expected: 3},
+		{seconds: 31, expected: -1},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, ts.IndexAtTime(t0.Add(time.Duration(tc.seconds*float64(time.Second)))))
+		})
+	}
+
+	assert.Nil(t, ts.PointAtTime(t0.Add(-time.Second)))
+	assert.Equal(t, &gpx.WptType{Lat: 0.5, Time: t0.Add(5 * time.Second)}, ts.PointAtTime(t0.Add(5*time.Second)))
+	assert.Equal(t, &gpx.WptType{Lat: 3, Time: t0.Add(20 * time.Second)}, ts.PointAtTime(t0.Add(20*time.Second)))
+	got := ts.PointAtTime(t0.Add(30 * time.Second))
+	assert.Equal(t, ts.TrkPt[3], got)
+	assert.NotSame(t, ts.TrkPt[3], got)
+
+	for i, tc := range []struct {
+		start, end    time.Duration
+		expectedStart int
+		expectedEnd   int
+	}{
+		{start: 0, end: 30 * time.Second, expectedStart: 0, expectedEnd: 3},
+		{start: 0, end: time.Minute, expectedStart: 0, expectedEnd: 4},
+		{start: 10 * time.Second, end: 11 * time.Second, expectedStart: 1, expectedEnd: 3},
+		{start: 11 * time.Second, end: 12 * time.Second, expectedStart: 3, expectedEnd: 3},
+		{start: time.Minute, end: 0, expectedStart: 4, expectedEnd: 4},
+		{start: -time.Minute, end: -time.Second, expectedStart: 0, expectedEnd: 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			start, end := ts.IndexRange(t0.Add(tc.start), t0.Add(tc.end))
+			assert.Equal(t, tc.expectedStart, start)
+			assert.Equal(t, tc.expectedEnd, end)
+		})
+	}
+
+	assert.Equal(t, -1, (&gpx.TrkSegType{}).IndexAtTime(t0))
+	assert.NoError(t, (&gpx.TrkSegType{}).ValidateTimes())
+
+	ts.TrkPt[2].Time = t0.Add(5 * time.Second)
+	assert.ErrorIs(t, ts.ValidateTimes(), gpx.ErrNonMonotonicTime)
+	ts.TrkPt[2].Time = time.Time{}
+	assert.ErrorIs(t, ts.ValidateTimes(), gpx.ErrNonMonotonicTime)
+}
+
+func TestTrkTypePositionAt(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{},
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 1, Lon: 1, Ele: 10, Time: t0, Name: "start"},
+					{Lat: 2, Lon: 1, Ele: 20, Time: t0.Add(10 * time.Second)},
+				},
+			},
+			{
+				TrkPt: []*gpx.WptType{
+					{Lat: 4, Lon: 1, Ele: 40, Time: t0.Add(30 * time.Second)},
+				},
+			},
+		},
+	}
+	for i, tc := range []struct {
+		seconds       float64
+		extrapolation gpx.Extrapolation
+		expectedLat   float64
+		expectedEle   float64
+		expectedNil   bool
+	}{
+		{seconds: 0, expectedLat: 1, expectedEle: 10},
+		{seconds: 5, expectedLat: 1.5, expectedEle: 15},
+		{seconds: 20, expectedLat: 3, expectedEle: 30},
+		{seconds: 30, expectedLat: 4, expectedEle: 40},
+		{seconds: -10, extrapolation: gpx.ExtrapolateNone, expectedNil: true},
+		{seconds: -10, extrapolation: gpx.ExtrapolateClamp, expectedLat: 1, expectedEle: 10},
+		{seconds: -10, extrapolation: gpx.ExtrapolateLinear, expectedLat: 0, expectedEle: 0},
+		{seconds: 40, extrapolation: gpx.ExtrapolateNone, expectedNil: true},
+		{seconds: 40, extrapolation: gpx.ExtrapolateClamp, expectedLat: 4, expectedEle: 40},
+		{seconds: 40, extrapolation: gpx.ExtrapolateLinear, expectedLat: 5, expectedEle: 50},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tm := t0.Add(time.Duration(tc.seconds * float64(time.Second)))
+			actual := trk.PositionAt(tm, tc.extrapolation)
+			if tc.expectedNil {
+				assert.Nil(t, actual)
+				return
+			}
+			expected := &gpx.WptType{Lat: tc.expectedLat, Lon: 1, Ele: tc.expectedEle, Time: tm}
+			if tc.expectedEle == 0 {
+				// Extrapolated elevations are present even at sea level.
+				expected.SetZero(gpx.WptEle)
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	single := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{TrkPt: []*gpx.WptType{{Lat: 1, Lon: 1, Time: t0}}},
+			{TrkPt: []*gpx.WptType{{Lat: 2, Lon: 1, Time: t0.Add(10 * time.Second)}}},
+		},
+	}
+	assert.Equal(t, &gpx.WptType{Lat: 0, Lon: 1, Time: t0.Add(-10 * time.Second)}, single.PositionAt(t0.Add(-10*time.Second), gpx.ExtrapolateLinear))
+	assert.Equal(t, &gpx.WptType{Lat: 3, Lon: 1, Time: t0.Add(20 * time.Second)}, single.PositionAt(t0.Add(20*time.Second), gpx.ExtrapolateLinear))
+
+	assert.Nil(t, (&gpx.TrkType{}).PositionAt(t0, gpx.ExtrapolateClamp))
+}
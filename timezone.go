@@ -0,0 +1,55 @@
+package gpx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// A TimeZoneResolver returns the time zone at a position.
+type TimeZoneResolver interface {
+	Location(lat, lon float64) (*time.Location, error)
+}
+
+// A TimeZoneResolverFunc is a function that implements TimeZoneResolver.
+type TimeZoneResolverFunc func(lat, lon float64) (*time.Location, error)
+
+// Location implements TimeZoneResolver.
+func (f TimeZoneResolverFunc) Location(lat, lon float64) (*time.Location, error) {
+	return f(lat, lon)
+}
+
+// NauticalTimeZones is a TimeZoneResolver that returns the nautical time zone
+// at each position, which is offset from UTC by a whole number of hours
+// determined only by longitude. It is only an approximation of civil time
+// and ignores daylight saving time, but needs no time zone database.
+var NauticalTimeZones TimeZoneResolver = TimeZoneResolverFunc(func(_, lon float64) (*time.Location, error) {
+	hours := int(math.Round(normalizeLon(lon) / 15))
+	switch {
+	case hours == 0:
+		return time.UTC, nil
+	case hours > 0:
+		return time.FixedZone(fmt.Sprintf("UTC+%d", hours), hours*60*60), nil
+	default:
+		return time.FixedZone(fmt.Sprintf("UTC%d", hours), hours*60*60), nil
+	}
+})
+
+// LocalizeTimes sets the location of the time of each waypoint, route point,
+// and track point in g to the time zone at the point's position from
+// resolver. The instants in time are not changed, so times are still written
+// in UTC, but methods such as time.Time.Day and time.Time.Format return local
+// values.
+func (g *GPX) LocalizeTimes(resolver TimeZoneResolver) error {
+	for wpt := range g.Points() {
+		if wpt.Time.IsZero() {
+			continue
+		}
+		loc, err := resolver.Location(wpt.Lat, wpt.Lon)
+		if err != nil {
+			return fmt.Errorf("%f,%f: %w", wpt.Lat, wpt.Lon, err)
+		}
+		wpt.Time = wpt.Time.In(loc)
+	}
+	return nil
+}
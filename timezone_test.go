@@ -0,0 +1,46 @@
+package gpx_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestLocalizeTimes(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC)
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 47.5, Lon: 7.5, Time: t0},
+			{Lat: 47.5, Lon: 7.5},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 40.7, Lon: -74, Time: t0},
+							{Lat: 51.5, Lon: 0, Time: t0},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.NoError(t, g.LocalizeTimes(gpx.NauticalTimeZones))
+
+	assert.True(t, t0.Equal(g.Wpt[0].Time))
+	assert.Equal(t, 3, g.Wpt[0].Time.Day())
+	assert.Equal(t, "00:30 UTC+1", g.Wpt[0].Time.Format("15:04 MST"))
+	assert.True(t, g.Wpt[1].Time.IsZero())
+	assert.Equal(t, "18:30 UTC-5", g.Trk[0].TrkSeg[0].TrkPt[0].Time.Format("15:04 MST"))
+	assert.Equal(t, time.UTC, g.Trk[0].TrkSeg[0].TrkPt[1].Time.Location())
+
+	errResolver := errors.New("resolver")
+	assert.ErrorIs(t, g.LocalizeTimes(gpx.TimeZoneResolverFunc(func(_, _ float64) (*time.Location, error) {
+		return nil, errResolver
+	})), errResolver)
+}
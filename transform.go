@@ -0,0 +1,93 @@
+package gpx
+
+// A PointKind is the kind of a point in a GPX document.
+type PointKind int
+
+// Point kinds.
+const (
+	PointKindWpt PointKind = iota
+	PointKindRtePt
+	PointKindTrkPt
+)
+
+// String returns the XML element name of k.
+func (k PointKind) String() string {
+	switch k {
+	case PointKindWpt:
+		return "wpt"
+	case PointKindRtePt:
+		return "rtept"
+	case PointKindTrkPt:
+		return "trkpt"
+	default:
+		return "unknown"
+	}
+}
+
+// Walk calls fn for each waypoint, route point, and track point in g, in
+// document order. fn must not modify the point. If fn returns an error then
+// the walk stops and the error is returned.
+func (g *GPX) Walk(fn func(PointKind, *WptType) error) error {
+	for _, wpt := range g.Wpt {
+		if err := fn(PointKindWpt, wpt); err != nil {
+			return err
+		}
+	}
+	for _, rte := range g.Rte {
+		for _, rtePt := range rte.RtePt {
+			if err := fn(PointKindRtePt, rtePt); err != nil {
+				return err
+			}
+		}
+	}
+	for _, trk := range g.Trk {
+		for _, trkSeg := range trk.TrkSeg {
+			for _, trkPt := range trkSeg.TrkPt {
+				if err := fn(PointKindTrkPt, trkPt); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// TransformPoints replaces each waypoint, route point, and track point in g
+// with the result of calling fn on it. If fn returns nil then the point is
+// removed. If fn returns an error then the transformation stops, leaving g
+// partially transformed, and the error is returned.
+func (g *GPX) TransformPoints(fn func(*WptType) (*WptType, error)) error {
+	var err error
+	if g.Wpt, err = transformPoints(g.Wpt, fn); err != nil {
+		return err
+	}
+	for _, rte := range g.Rte {
+		if rte.RtePt, err = transformPoints(rte.RtePt, fn); err != nil {
+			return err
+		}
+	}
+	for _, trk := range g.Trk {
+		for _, trkSeg := range trk.TrkSeg {
+			if trkSeg.TrkPt, err = transformPoints(trkSeg.TrkPt, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// transformPoints returns wpts transformed by fn, reusing wpts's storage.
+func transformPoints(wpts []*WptType, fn func(*WptType) (*WptType, error)) ([]*WptType, error) {
+	result := wpts[:0]
+	for i, wpt := range wpts {
+		newWpt, err := fn(wpt)
+		if err != nil {
+			return append(result, wpts[i:]...), err
+		}
+		if newWpt != nil {
+			result = append(result, newWpt)
+		}
+	}
+	clear(wpts[len(result):])
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package gpx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestPointVisitors(t *testing.T) {
+	g := &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 1, Name: "wpt"},
+		},
+		Rte: []*gpx.RteType{
+			{
+				RtePt: []*gpx.WptType{
+					{Lat: 2, Name: "rtept"},
+				},
+			},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 3, Name: "trkpt1"},
+							{Lat: 4, Name: "trkpt2"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var got []string
+	assert.NoError(t, g.Walk(func(kind gpx.PointKind, wpt *gpx.WptType) error {
+		got = append(got, kind.String()+":"+wpt.Name)
+		return nil
+	}))
+	assert.Equal(t, []string{"wpt:wpt", "rtept:rtept", "trkpt:trkpt1", "trkpt:trkpt2"}, got)
+
+	errStop := errors.New("stop")
+	n := 0
+	assert.ErrorIs(t, g.Walk(func(gpx.PointKind, *gpx.WptType) error {
+		n++
+		return errStop
+	}), errStop)
+	assert.Equal(t, 1, n)
+
+	assert.NoError(t, g.TransformPoints(func(wpt *gpx.WptType) (*gpx.WptType, error) {
+		if wpt.Name == "trkpt1" {
+			return nil, nil
+		}
+		newWpt := *wpt
+		newWpt.Lat += 10
+		return &newWpt, nil
+	}))
+	assert.Equal(t, &gpx.GPX{
+		Wpt: []*gpx.WptType{
+			{Lat: 11, Name: "wpt"},
+		},
+		Rte: []*gpx.RteType{
+			{
+				RtePt: []*gpx.WptType{
+					{Lat: 12, Name: "rtept"},
+				},
+			},
+		},
+		Trk: []*gpx.TrkType{
+			{
+				TrkSeg: []*gpx.TrkSegType{
+					{
+						TrkPt: []*gpx.WptType{
+							{Lat: 14, Name: "trkpt2"},
+						},
+					},
+				},
+			},
+		},
+	}, g)
+
+	errFail := errors.New("fail")
+	assert.ErrorIs(t, g.TransformPoints(func(*gpx.WptType) (*gpx.WptType, error) {
+		return nil, errFail
+	}), errFail)
+	assert.Len(t, g.Wpt, 1)
+}
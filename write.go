@@ -0,0 +1,171 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// WriteOptions control how GPX documents are written. Zero values mean the
+// default.
+type WriteOptions struct {
+	// Prefix and Indent are the prefix and indent of each line, as for
+	// xml.Encoder.Indent. If both are empty then no newlines are written.
+	Prefix string
+	Indent string
+	// LatLonPrecision, if positive, is the number of digits after the
+	// decimal point of latitudes and longitudes. Six digits are about 0.1m,
+	// seven digits are about 1cm. If zero, the shortest representation that
+	// reads back to the same value is used.
+	LatLonPrecision int
+	// ElePrecision, if positive, is the number of digits after the decimal
+	// point of elevations. If zero, the shortest representation that reads
+	// back to the same value is used.
+	ElePrecision int
+	// OmitXMLDeclaration omits the XML declaration that is otherwise written
+	// before the gpx element.
+	OmitXMLDeclaration bool
+	// Encoding is the character encoding, either UTF-8 or ISO-8859-1, also
+	// called Latin-1. If empty, UTF-8 is used. Characters that cannot be
+	// encoded in ISO-8859-1 are written as character references, so they
+	// must only occur in text and attribute values.
+	Encoding string
+}
+
+// Character encodings.
+const (
+	encodingUTF8     = "UTF-8"
+	encodingISO88591 = "ISO-8859-1"
+)
+
+// writeEncodings maps the lowercase labels of the encodings supported by
+// WriteWithOptions to their names.
+var writeEncodings = map[string]string{
+	"":           encodingUTF8,
+	"utf-8":      encodingUTF8,
+	"utf8":       encodingUTF8,
+	"iso-8859-1": encodingISO88591,
+	"iso8859-1":  encodingISO88591,
+	"latin1":     encodingISO88591,
+	"latin-1":    encodingISO88591,
+}
+
+// writeOptionsEncoders maps the xml.Encoders created by WriteWithOptions to
+// their *WriteOptions.
+var writeOptionsEncoders sync.Map
+
+// WriteWithOptions writes g to w with options. If options is nil then g is
+// written as by Write.
+func (g *GPX) WriteWithOptions(w io.Writer, options *WriteOptions) error {
+	if options == nil {
+		options = &WriteOptions{}
+	}
+	encoding, ok := writeEncodings[strings.ToLower(options.Encoding)]
+	if !ok {
+		return fmt.Errorf("%s: unsupported encoding", options.Encoding)
+	}
+
+	if encoding == encodingISO88591 {
+		w = &iso88591Writer{w: w}
+	}
+	if !options.OmitXMLDeclaration {
+		if _, err := io.WriteString(w, `<?xml version="1.0" encoding="`+encoding+`"?>`+"\n"); err != nil {
+			return err
+		}
+	}
+	e := xml.NewEncoder(w)
+	e.Indent(options.Prefix, options.Indent)
+	writeOptionsEncoders.Store(e, options)
+	defer writeOptionsEncoders.Delete(e)
+	return e.EncodeElement(g, StartElement)
+}
+
+// An iso88591Writer encodes UTF-8 written to it as ISO-8859-1, with
+// characters that cannot be encoded replaced by character references.
+type iso88591Writer struct {
+	w       io.Writer
+	partial []byte
+	buf     []byte
+}
+
+// Write implements io.Writer.Write.
+func (w *iso88591Writer) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	w.buf = w.buf[:0]
+	for len(data) > 0 && utf8.FullRune(data) {
+		r, size := utf8.DecodeRune(data)
+		if r < 0x100 {
+			w.buf = append(w.buf, byte(r))
+		} else {
+			w.buf = append(w.buf, "&#"+strconv.Itoa(int(r))+";"...)
+		}
+		data = data[size:]
+	}
+	w.partial = append(w.partial[:0], data...)
+	if _, err := w.w.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// MarshalXML implements xml.Marshaler.MarshalXML.
+func (b *BoundsType) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	for _, attr := range []struct {
+		name  string
+		value float64
+	}{
+		{"minlat", b.MinLat},
+		{"minlon", b.MinLon},
+		{"maxlat", b.MaxLat},
+		{"maxlon", b.MaxLon},
+	} {
+		start.Attr = append(start.Attr, xml.Attr{
+			Name:  xml.Name{Local: attr.name},
+			Value: formatLatLon(e, attr.value),
+		})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// writeOptions returns the WriteOptions of e, or nil if it has none.
+func writeOptions(e *xml.Encoder) *WriteOptions {
+	options, ok := writeOptionsEncoders.Load(e)
+	if !ok {
+		return nil
+	}
+	return options.(*WriteOptions) //nolint:forcetypeassert
+}
+
+// formatLatLon formats the latitude or longitude value for e.
+func formatLatLon(e *xml.Encoder, value float64) string {
+	precision := -1
+	if options := writeOptions(e); options != nil && options.LatLonPrecision > 0 {
+		precision = options.LatLonPrecision
+	}
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
+
+// maybeEmitEleElement emits an ele element with value formatted for e, if
+// value is non-zero.
+func maybeEmitEleElement(e *xml.Encoder, value float64) error {
+	if value == 0 {
+		return nil
+	}
+	return emitEleElement(e, value)
+}
+
+// emitEleElement emits an ele element with value formatted for e.
+func emitEleElement(e *xml.Encoder, value float64) error {
+	precision := -1
+	if options := writeOptions(e); options != nil && options.ElePrecision > 0 {
+		precision = options.ElePrecision
+	}
+	return emitStringElement(e, "ele", strconv.FormatFloat(value, 'f', precision, 64))
+}
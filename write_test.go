@@ -0,0 +1,115 @@
+package gpx_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html/charset"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestWriteWithOptions(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Metadata: &gpx.MetadataType{
+			Bounds: &gpx.BoundsType{MinLat: 47.123456789, MinLon: 7.5, MaxLat: 47.2, MaxLon: 7.6},
+		},
+		Wpt: []*gpx.WptType{
+			{Lat: 47.123456789, Lon: 7.98765432, Ele: 301.23456},
+		},
+	}
+
+	for i, tc := range []struct {
+		options  *gpx.WriteOptions
+		expected []string
+	}{
+		{
+			expected: []string{
+				`<bounds minlat="47.123456789" minlon="7.5" maxlat="47.2" maxlon="7.6"></bounds>`,
+				`<wpt lat="47.123456789" lon="7.98765432"><ele>301.23456</ele></wpt>`,
+			},
+		},
+		{
+			options: &gpx.WriteOptions{
+				LatLonPrecision: 6,
+				ElePrecision:    2,
+			},
+			expected: []string{
+				`<bounds minlat="47.123457" minlon="7.500000" maxlat="47.200000" maxlon="7.600000"></bounds>`,
+				`<wpt lat="47.123457" lon="7.987654"><ele>301.23</ele></wpt>`,
+			},
+		},
+		{
+			options: &gpx.WriteOptions{
+				Indent:       "\t",
+				ElePrecision: 1,
+			},
+			expected: []string{
+				"<wpt lat=\"47.123456789\" lon=\"7.98765432\">\n\t\t<ele>301.2</ele>\n\t</wpt>",
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var b strings.Builder
+			assert.NoError(t, g.WriteWithOptions(&b, tc.options))
+			for _, expected := range tc.expected {
+				assert.Contains(t, b.String(), expected)
+			}
+		})
+	}
+}
+
+func TestWriteEncoding(t *testing.T) {
+	g := &gpx.GPX{
+		Version: "1.1",
+		Creator: "test",
+		Wpt: []*gpx.WptType{
+			{Lat: 1, Lon: 2, Name: "Zürich €"},
+		},
+	}
+
+	var b strings.Builder
+	assert.NoError(t, g.Write(&b))
+	assert.True(t, strings.HasPrefix(b.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n<gpx "))
+	assert.Contains(t, b.String(), "<name>Zürich €</name>")
+
+	b.Reset()
+	assert.NoError(t, g.WriteWithOptions(&b, &gpx.WriteOptions{OmitXMLDeclaration: true}))
+	assert.True(t, strings.HasPrefix(b.String(), "<gpx "))
+
+	b.Reset()
+	assert.NoError(t, g.WriteWithOptions(&b, &gpx.WriteOptions{Encoding: "latin1"}))
+	assert.True(t, strings.HasPrefix(b.String(), `<?xml version="1.0" encoding="ISO-8859-1"?>`+"\n<gpx "))
+	assert.Contains(t, b.String(), "<name>Z\xfcrich &#8364;</name>")
+
+	got, err := gpx.Read(strings.NewReader(b.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, "Zürich €", got.Wpt[0].Name)
+
+	var labels []string
+	got, err = gpx.ReadWithOptions(context.Background(), strings.NewReader(b.String()), &gpx.ParseOptions{
+		CharsetReader: func(label string, input io.Reader) (io.Reader, error) {
+			labels = append(labels, label)
+			return charset.NewReaderLabel(label, input)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Zürich €", got.Wpt[0].Name)
+	assert.Equal(t, []string{"ISO-8859-1"}, labels)
+
+	// Characters split between writes are encoded.
+	g.Wpt[0].Name = strings.Repeat("é€", 5000)
+	b.Reset()
+	assert.NoError(t, g.WriteWithOptions(&b, &gpx.WriteOptions{Encoding: "ISO-8859-1"}))
+	got, err = gpx.Read(strings.NewReader(b.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, g.Wpt[0].Name, got.Wpt[0].Name)
+
+	assert.Error(t, g.WriteWithOptions(&b, &gpx.WriteOptions{Encoding: "UTF-16"}))
+}
@@ -0,0 +1,109 @@
+package gpx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// normalizedPowerWindow is the length in seconds of the rolling average used
+// to compute normalized power.
+const normalizedPowerWindow = 30
+
+// SensorStats are statistics of the heart rate, cadence, and power recorded in
+// a track's extensions.
+type SensorStats struct {
+	// HeartRateZones is the time spent in each heart rate zone. Zone 0 is
+	// below the first zone boundary and zone i is at or above the ith
+	// boundary and below the i+1th.
+	HeartRateZones []time.Duration
+	AvgHeartRate   float64 // Beats per minute.
+	MaxHeartRate   float64 // Beats per minute.
+	AvgCadence     float64 // Revolutions per minute.
+	AvgPower       float64 // Watts.
+	// NormalizedPower is the fourth root of the mean of the fourth powers of
+	// the 30 second rolling average of the power, in watts. It is zero if
+	// there are less than 30 seconds of power data.
+	NormalizedPower float64
+}
+
+// SensorStats returns statistics of the heart rate, cadence, and power in the
+// extensions of t's points, with the time in the heart rate zones delimited by
+// the ascending heartRateZones boundaries in beats per minute. Each timed
+// point's values are taken to last until the next timed point in the same
+// segment. Averages are over the points that have each value.
+func (t *TrkType) SensorStats(heartRateZones []float64) *SensorStats {
+	stats := &SensorStats{
+		HeartRateZones: make([]time.Duration, len(heartRateZones)+1),
+	}
+	var sumHeartRates, sumCadences, sumPowers float64
+	var heartRates, cadences, powers int
+	var powerSeries []float64
+	for _, ts := range t.TrkSeg {
+		for i, trkPt := range ts.TrkPt {
+			var duration time.Duration
+			if !trkPt.Time.IsZero() {
+				for _, next := range ts.TrkPt[i+1:] {
+					if !next.Time.IsZero() {
+						duration = max(next.Time.Sub(trkPt.Time), 0)
+						break
+					}
+				}
+			}
+			sensors := trkPt.Sensors()
+			if heartRate := sensors.HeartRate; !math.IsNaN(heartRate) {
+				sumHeartRates += heartRate
+				heartRates++
+				stats.MaxHeartRate = math.Max(stats.MaxHeartRate, heartRate)
+				zone := sort.Search(len(heartRateZones), func(j int) bool {
+					return heartRate < heartRateZones[j]
+				})
+				stats.HeartRateZones[zone] += duration
+			}
+			if cadence := sensors.Cadence; !math.IsNaN(cadence) {
+				sumCadences += cadence
+				cadences++
+			}
+			if power := sensors.Power; !math.IsNaN(power) {
+				sumPowers += power
+				powers++
+				for range int(duration / time.Second) {
+					powerSeries = append(powerSeries, power)
+				}
+			}
+		}
+	}
+	if heartRates > 0 {
+		stats.AvgHeartRate = sumHeartRates / float64(heartRates)
+	}
+	if cadences > 0 {
+		stats.AvgCadence = sumCadences / float64(cadences)
+	}
+	if powers > 0 {
+		stats.AvgPower = sumPowers / float64(powers)
+	}
+	stats.NormalizedPower = normalizedPower(powerSeries)
+	return stats
+}
+
+// normalizedPower returns the normalized power of the one second power
+// samples in powers, or zero if there are too few samples.
+func normalizedPower(powers []float64) float64 {
+	if len(powers) < normalizedPowerWindow {
+		return 0
+	}
+	sum := 0.0
+	for _, power := range powers[:normalizedPowerWindow-1] {
+		sum += power
+	}
+	sumFourthPowers := 0.0
+	n := 0
+	for i := normalizedPowerWindow - 1; i < len(powers); i++ {
+		sum += powers[i]
+		average := sum / normalizedPowerWindow
+		sumFourthPowers += average * average * average * average
+		n++
+		sum -= powers[i-normalizedPowerWindow+1]
+	}
+	return math.Pow(sumFourthPowers/float64(n), 0.25)
+}
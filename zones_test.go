@@ -0,0 +1,100 @@
+package gpx_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	gpx "github.com/twpayne/go-gpx"
+)
+
+func TestSensorStats(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newTrkPt := func(seconds int, hr, cad, power float64) *gpx.WptType {
+		return &gpx.WptType{
+			Time: t0.Add(time.Duration(seconds) * time.Second),
+			Extensions: &gpx.ExtensionsType{
+				XML: []byte(fmt.Sprintf(`<power>%g</power><gpxtpx:TrackPointExtension><gpxtpx:hr>%g</gpxtpx:hr><gpxtpx:cad>%g</gpxtpx:cad></gpxtpx:TrackPointExtension>`, power, hr, cad)),
+			},
+		}
+	}
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{
+				TrkPt: []*gpx.WptType{
+					newTrkPt(0, 100, 80, 100),
+					newTrkPt(60, 130, 90, 300),
+					newTrkPt(120, 160, 100, 200),
+				},
+			},
+			{
+				TrkPt: []*gpx.WptType{
+					newTrkPt(600, 150, 90, 200),
+					{Time: t0.Add(630 * time.Second)},
+				},
+			},
+		},
+	}
+
+	got := trk.SensorStats([]float64{120, 140, 160})
+	assert.Equal(t, []time.Duration{time.Minute, time.Minute, 30 * time.Second, 0}, got.HeartRateZones)
+	assert.InDelta(t, 135, got.AvgHeartRate, 1e-9)
+	assert.Equal(t, 160.0, got.MaxHeartRate)
+	assert.InDelta(t, 90, got.AvgCadence, 1e-9)
+	assert.InDelta(t, 200, got.AvgPower, 1e-9)
+
+	// 60s at 100W, 60s at 300W, and 30s at 200W.
+	var expectedSum float64
+	var n int
+	powers := make([]float64, 0, 150)
+	for _, p := range []struct {
+		seconds int
+		power   float64
+	}{{60, 100}, {60, 300}, {30, 200}} {
+		for range p.seconds {
+			powers = append(powers, p.power)
+		}
+	}
+	for i := 29; i < len(powers); i++ {
+		sum := 0.0
+		for _, power := range powers[i-29 : i+1] {
+			sum += power
+		}
+		expectedSum += math.Pow(sum/30, 4)
+		n++
+	}
+	assert.InDelta(t, math.Pow(expectedSum/float64(n), 0.25), got.NormalizedPower, 1e-9)
+
+	got = (&gpx.TrkType{}).SensorStats(nil)
+	assert.Equal(t, &gpx.SensorStats{HeartRateZones: []time.Duration{0}}, got)
+}
+
+func TestSensorStatsGPXData(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newTrkPt := func(seconds int, heartRate, cadence float64) *gpx.WptType {
+		return &gpx.WptType{
+			Time: t0.Add(time.Duration(seconds) * time.Second),
+			Extensions: &gpx.ExtensionsType{
+				XML: []byte(fmt.Sprintf(`<gpxdata:hr xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0">%g</gpxdata:hr><gpxdata:cadence xmlns:gpxdata="http://www.cluetrust.com/XML/GPXDATA/1/0">%g</gpxdata:cadence>`, heartRate, cadence)),
+			},
+		}
+	}
+	trk := &gpx.TrkType{
+		TrkSeg: []*gpx.TrkSegType{
+			{
+				TrkPt: []*gpx.WptType{
+					newTrkPt(0, 100, 80),
+					newTrkPt(60, 140, 90),
+				},
+			},
+		},
+	}
+	got := trk.SensorStats([]float64{120})
+	assert.Equal(t, 120.0, got.AvgHeartRate)
+	assert.Equal(t, 140.0, got.MaxHeartRate)
+	assert.Equal(t, 85.0, got.AvgCadence)
+	assert.Equal(t, []time.Duration{time.Minute, 0}, got.HeartRateZones)
+}